@@ -0,0 +1,201 @@
+package rng
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrUnknownAlgorithm is returned for draw algorithm IDs that are not
+	// registered.
+	ErrUnknownAlgorithm = errors.New("rng: unknown draw algorithm")
+	// ErrOutcomeMismatch is returned by VerifyDrawResult when a rerun draw
+	// does not reproduce the recorded result.
+	ErrOutcomeMismatch = errors.New("rng: draw outcome mismatch")
+)
+
+// MaxDrawSize bounds the size of outcomes of built-in algorithms: n of "perm"
+// and "shuffle", k of "sample". Larger recorded params are rejected instead
+// of allocating unbounded memory when a draw is rerun.
+const MaxDrawSize = 1 << 20
+
+// Algorithm is a versioned draw function variant, e.g. "sample/v2". Draw
+// results record the ID of the algorithm that made them, registered versions
+// are never removed or changed, so historical draws can be rerun with the
+// exact same algorithm after the default implementation is optimized.
+type Algorithm struct {
+	// ID uniquely identifies the variant, by convention type "/v" version.
+	ID string
+	// Type is the DrawResult type of draws made by the algorithm.
+	Type string
+	// Run draws an outcome for given params reading randomness from src. It
+	// panics on invalid params like other draw functions.
+	Run func(src io.Reader, params map[string]int64) []int64
+}
+
+var algorithms = struct {
+	sync.RWMutex
+	byID   map[string]Algorithm
+	byType map[string][]string // IDs in registration order
+}{
+	byID:   make(map[string]Algorithm),
+	byType: make(map[string][]string),
+}
+
+func init() {
+	// param returns a recorded param as int, it panics if the value does
+	// not fit, like specRun rejects it
+	param := func(params map[string]int64, name string) int {
+		v := params[name]
+		if v < 0 || v > math.MaxInt {
+			panic(fmt.Sprintf("invalid argument, parameter %s out of range", name))
+		}
+		return int(v)
+	}
+	// size is a param holding the number of drawn values
+	size := func(params map[string]int64, name string) int {
+		v := param(params, name)
+		if v > MaxDrawSize {
+			panic(fmt.Sprintf("invalid argument, parameter %s exceeds %d", name, MaxDrawSize))
+		}
+		return v
+	}
+	RegisterAlgorithm(Algorithm{ID: "intn/v1", Type: "intn", Run: func(src io.Reader, p map[string]int64) []int64 {
+		return []int64{int64(ReadIntn(src, param(p, "n")))}
+	}})
+	RegisterAlgorithm(Algorithm{ID: "float64/v1", Type: "float64", Run: func(src io.Reader, p map[string]int64) []int64 {
+		return []int64{int64(ReadUint64Bits(src, 53))}
+	}})
+	RegisterAlgorithm(Algorithm{ID: "perm/v1", Type: "perm", Run: func(src io.Reader, p map[string]int64) []int64 {
+		return intsToInt64s(ReadPerm(src, size(p, "n")))
+	}})
+	for _, version := range []string{SampleV1, SampleV2, SampleV3} {
+		version := version
+		RegisterAlgorithm(Algorithm{ID: version, Type: "sample", Run: func(src io.Reader, p map[string]int64) []int64 {
+			return intsToInt64s(ReadSampleVersion(src, version, param(p, "n"), size(p, "k")))
+		}})
+	}
+	RegisterAlgorithm(Algorithm{ID: "shuffle/v1", Type: "shuffle", Run: func(src io.Reader, p map[string]int64) []int64 {
+		js := []int64{}
+		ReadShuffle(src, size(p, "n"), func(i, j int) {
+			js = append(js, int64(j))
+		})
+		return js
+	}})
+}
+
+// RegisterAlgorithm registers a draw algorithm. Versions of a type must be
+// registered oldest first, the last registered version is the current one
+// used for new draws. It will panic if a field is empty or the ID is already
+// registered.
+func RegisterAlgorithm(a Algorithm) {
+	if a.ID == "" || a.Type == "" || a.Run == nil {
+		panic("invalid argument to RegisterAlgorithm")
+	}
+	algorithms.Lock()
+	defer algorithms.Unlock()
+	if _, ok := algorithms.byID[a.ID]; ok {
+		panic("invalid argument to RegisterAlgorithm, duplicate ID " + a.ID)
+	}
+	algorithms.byID[a.ID] = a
+	algorithms.byType[a.Type] = append(algorithms.byType[a.Type], a.ID)
+}
+
+// LookupAlgorithm returns a registered algorithm by ID.
+func LookupAlgorithm(id string) (Algorithm, bool) {
+	algorithms.RLock()
+	defer algorithms.RUnlock()
+	a, ok := algorithms.byID[id]
+	return a, ok
+}
+
+// CurrentAlgorithm returns the current algorithm of a draw type, the one
+// used by package draw functions.
+func CurrentAlgorithm(typ string) (Algorithm, bool) {
+	algorithms.RLock()
+	defer algorithms.RUnlock()
+	ids := algorithms.byType[typ]
+	if len(ids) == 0 {
+		return Algorithm{}, false
+	}
+	return algorithms.byID[ids[len(ids)-1]], true
+}
+
+// Algorithms returns all registered algorithms sorted by ID.
+func Algorithms() []Algorithm {
+	algorithms.RLock()
+	defer algorithms.RUnlock()
+	as := make([]Algorithm, 0, len(algorithms.byID))
+	for _, a := range algorithms.byID {
+		as = append(as, a)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].ID < as[j].ID })
+	return as
+}
+
+// ResolveAlgorithm returns the algorithm of a recorded draw of a given type.
+// Draws recorded before versioning have no algorithm ID, they were made with
+// the first registered version of their type. It returns an error wrapping
+// ErrUnknownAlgorithm if no such algorithm is registered or it is of another
+// type.
+func ResolveAlgorithm(typ, id string) (Algorithm, error) {
+	algorithms.RLock()
+	defer algorithms.RUnlock()
+	if id == "" {
+		if ids := algorithms.byType[typ]; len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	a, ok := algorithms.byID[id]
+	if !ok || a.Type != typ {
+		return Algorithm{}, fmt.Errorf("%w %q for type %q", ErrUnknownAlgorithm, id, typ)
+	}
+	return a, nil
+}
+
+// RerunDraw reruns a recorded draw with its algorithm reading randomness from
+// src, e.g. a FairSource rebuilt from revealed seeds, and returns the
+// outcome. Invalid recorded params, including params that do not fit an int
+// or sizes above MaxDrawSize, are returned as errors.
+func RerunDraw(src io.Reader, d *DrawResult) (outcome []int64, err error) {
+	a, err := ResolveAlgorithm(d.Type, d.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			outcome, err = nil, fmt.Errorf("rng: rerunning draw %q: %v", d.ID, r)
+		}
+	}()
+	return a.Run(src, d.Params), nil
+}
+
+// VerifyDrawResult reruns a recorded draw, see RerunDraw, and checks that it
+// reproduces the recorded outcome and, if recorded, the digest of consumed
+// entropy. It returns an error wrapping ErrOutcomeMismatch otherwise.
+func VerifyDrawResult(src io.Reader, d *DrawResult) error {
+	h := sha256.New()
+	src, enc := sourceEncoding(src)
+	outcome, err := RerunDraw(EncodedSource(io.TeeReader(src, h), enc), d)
+	if err != nil {
+		return err
+	}
+	if len(outcome) != len(d.Outcome) {
+		return fmt.Errorf("%w: draw %q has %d values, rerun %d", ErrOutcomeMismatch, d.ID, len(d.Outcome), len(outcome))
+	}
+	for i, v := range outcome {
+		if v != d.Outcome[i] {
+			return fmt.Errorf("%w: draw %q value %d is %d, rerun %d", ErrOutcomeMismatch, d.ID, i, d.Outcome[i], v)
+		}
+	}
+	if len(d.EntropyDigest) > 0 && !bytes.Equal(d.EntropyDigest, h.Sum(nil)) {
+		return fmt.Errorf("%w: draw %q entropy digest differs", ErrOutcomeMismatch, d.ID)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgorithmRegistry(t *testing.T) {
+	a, ok := CurrentAlgorithm("sample")
+	require.True(t, ok)
+	assert.Equal(t, SampleVersion, a.ID)
+	a, ok = LookupAlgorithm(SampleV1)
+	require.True(t, ok)
+	assert.Equal(t, "sample", a.Type)
+	_, ok = LookupAlgorithm("sample/v0")
+	assert.False(t, ok)
+	_, ok = CurrentAlgorithm("dice")
+	assert.False(t, ok)
+
+	var ids []string
+	for _, a := range Algorithms() {
+		ids = append(ids, a.ID)
+	}
+	assert.Subset(t, ids, []string{"float64/v1", "intn/v1", "perm/v1", "sample/v1", "sample/v2", "sample/v3", "shuffle/v1"})
+	assert.True(t, sort.StringsAreSorted(ids))
+
+	// unversioned records resolve to the first version
+	a, err := ResolveAlgorithm("sample", "")
+	require.NoError(t, err)
+	assert.Equal(t, SampleV1, a.ID)
+	_, err = ResolveAlgorithm("perm", SampleV2)
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+	_, err = ResolveAlgorithm("dice", "")
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+
+	assert.Panics(t, func() { RegisterAlgorithm(Algorithm{ID: "intn/v1", Type: "intn", Run: a.Run}) })
+	assert.Panics(t, func() { RegisterAlgorithm(Algorithm{ID: "x/v1", Type: "x"}) })
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	double := func(v int64) func(io.Reader, map[string]int64) []int64 {
+		return func(src io.Reader, p map[string]int64) []int64 {
+			return []int64{v * int64(ReadIntn(src, int(p["n"])))}
+		}
+	}
+	if _, ok := LookupAlgorithm("test-dice/v1"); !ok {
+		RegisterAlgorithm(Algorithm{ID: "test-dice/v1", Type: "test-dice", Run: double(1)})
+		RegisterAlgorithm(Algorithm{ID: "test-dice/v2", Type: "test-dice", Run: double(2)})
+	}
+	a, ok := CurrentAlgorithm("test-dice")
+	require.True(t, ok)
+	assert.Equal(t, "test-dice/v2", a.ID)
+
+	d := runDraw(NewDRBG(nil), "test-dice", map[string]int64{"n": 6}, func(src io.Reader) []int64 {
+		return a.Run(src, map[string]int64{"n": 6})
+	})
+	assert.Equal(t, "test-dice/v2", d.Algorithm)
+	assert.NoError(t, VerifyDrawResult(NewDRBG(nil), d))
+}
+
+func TestVerifyDrawResult(t *testing.T) {
+	seed := []byte("server")
+	var d *DrawResult
+	g := NewPublishing(New(FairSource(seed, "client", 1)), PublisherFunc(func(r *DrawResult) error {
+		d = r
+		return nil
+	}))
+	g.Sample(49, 6)
+	require.NotNil(t, d)
+	assert.Equal(t, SampleVersion, d.Algorithm)
+	assert.NoError(t, VerifyDrawResult(FairSource(seed, "client", 1), d))
+
+	// records made before versioning are verified with the first version
+	legacy := *d
+	legacy.Algorithm = ""
+	legacy.EntropyDigest = nil
+	legacy.Outcome = intsToInt64s(ReadSampleVersion(FairSource(seed, "client", 1), SampleV1, 49, 6))
+	assert.NoError(t, VerifyDrawResult(FairSource(seed, "client", 1), &legacy))
+	outcome, err := RerunDraw(FairSource(seed, "client", 1), &legacy)
+	require.NoError(t, err)
+	assert.Equal(t, legacy.Outcome, outcome)
+
+	err = VerifyDrawResult(FairSource(seed, "client", 2), d)
+	assert.True(t, errors.Is(err, ErrOutcomeMismatch))
+	short := *d
+	short.Outcome = d.Outcome[:5]
+	assert.True(t, errors.Is(VerifyDrawResult(FairSource(seed, "client", 1), &short), ErrOutcomeMismatch))
+	digest := *d
+	digest.EntropyDigest = make([]byte, 32)
+	assert.True(t, errors.Is(VerifyDrawResult(FairSource(seed, "client", 1), &digest), ErrOutcomeMismatch))
+
+	invalid := &DrawResult{Type: "intn", Params: map[string]int64{"n": 0}}
+	_, err = RerunDraw(NewDRBG(nil), invalid)
+	assert.Error(t, err)
+	_, err = RerunDraw(NewDRBG(nil), &DrawResult{Type: "dice"})
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+
+	// params are range checked instead of truncated or allocated
+	for _, d := range []*DrawResult{
+		{Type: "intn", Params: map[string]int64{"n": -6}},
+		{Type: "perm", Params: map[string]int64{"n": 1 << 40}},
+		{Type: "perm", Params: map[string]int64{"n": MaxDrawSize + 1}},
+		{Type: "shuffle", Params: map[string]int64{"n": math.MaxInt64}},
+		{Type: "sample", Params: map[string]int64{"n": 1 << 40, "k": MaxDrawSize + 1}},
+		{Type: "sample", Params: map[string]int64{"n": math.MinInt64, "k": 6}},
+	} {
+		_, err = RerunDraw(NewDRBG(nil), d)
+		assert.Error(t, err, "%v", d.Params)
+	}
+	outcome, err = RerunDraw(NewDRBG(nil), &DrawResult{Type: "perm", Params: map[string]int64{"n": MaxDrawSize}})
+	require.NoError(t, err)
+	assert.Len(t, outcome, MaxDrawSize)
+}
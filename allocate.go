@@ -0,0 +1,94 @@
+package rng
+
+import (
+	"io"
+	"math"
+)
+
+// Allocate randomly splits total units across buckets proportionally to
+// weights, see ReadAllocate.
+func Allocate(total int, weights []float64) []int {
+	return ReadAllocate(defaultSource(), total, weights)
+}
+
+// ReadAllocate randomly splits total units across buckets reading randomness
+// from a given source, e.g. a bonus pool in cents split between eligible
+// players. Counts follow the multinomial distribution: each unit lands in
+// bucket i independently with probability weights[i] / sum(weights), and
+// counts always add up to total exactly. Zero weight buckets get nothing.
+//
+// Counts are drawn as a chain of conditional binomials, running time grows
+// linearly with total in the worst case. It will panic if total < 0, any
+// weight is negative or not finite, or total > 0 and all weights are zero.
+func ReadAllocate(src io.Reader, total int, weights []float64) []int {
+	if total < 0 {
+		panic("invalid argument to Allocate")
+	}
+	// suffix[i] is the sum of weights[i:], computed once so the last
+	// positive weight bucket gets probability exactly 1
+	suffix := make([]float64, len(weights)+1)
+	for i := len(weights) - 1; i >= 0; i-- {
+		w := weights[i]
+		if !(w >= 0) || math.IsInf(w, 1) {
+			panic("invalid weight argument to Allocate")
+		}
+		suffix[i] = suffix[i+1] + w
+	}
+	if math.IsInf(suffix[0], 1) || (total > 0 && suffix[0] == 0) {
+		panic("invalid weight argument to Allocate")
+	}
+
+	counts := make([]int, len(weights))
+	rem := total
+	for i, w := range weights {
+		if rem == 0 {
+			break
+		}
+		if w == 0 {
+			continue
+		}
+		counts[i] = readBinomial(src, rem, math.Min(w/suffix[i], 1))
+		rem -= counts[i]
+	}
+	return counts
+}
+
+// maxBinomialStep keeps (1-p)^n of a single inversion step well above float64
+// underflow.
+const maxBinomialStep = 500
+
+// readBinomial returns the number of successes in n independent trials with
+// success probability p reading randomness from a given source. It inverts
+// the CDF, trials are split into batches with a mean of at most
+// maxBinomialStep successes.
+func readBinomial(src io.Reader, n int, p float64) int {
+	switch {
+	case p <= 0 || n == 0:
+		return 0
+	case p >= 1:
+		return n
+	case p > 0.5:
+		return n - readBinomial(src, n, 1-p)
+	}
+
+	sum := 0
+	// compared in floating point, maxBinomialStep/p overflows int for tiny p
+	if float64(n)*p > maxBinomialStep {
+		// maxBinomialStep/p < n here, so it fits an int
+		step := int(maxBinomialStep / p)
+		for ; n > step; n -= step {
+			sum += readBinomial(src, step, p)
+		}
+	}
+	u := ReadFloat64(src)
+	k := 0
+	pk := math.Pow(1-p, float64(n))
+	cdf := pk
+	odds := p / (1 - p)
+	for u >= cdf && k < n {
+		pk *= float64(n-k) / float64(k+1) * odds
+		k++
+		cdf += pk
+	}
+	return sum + k
+}
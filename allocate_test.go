@@ -0,0 +1,76 @@
+package rng
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocate(t *testing.T) {
+	src := NewDRBG([]byte("bonus pool"))
+	weights := []float64{1, 0, 2, 7}
+	sums := make([]float64, len(weights))
+	for i := 0; i < 2000; i++ {
+		counts := ReadAllocate(src, 1000, weights)
+		total := 0
+		for j, c := range counts {
+			assert.True(t, c >= 0)
+			total += c
+			sums[j] += float64(c)
+		}
+		assert.Equal(t, 1000, total)
+		assert.Zero(t, counts[1])
+	}
+	assert.InDelta(t, 100, sums[0]/2000, 1)
+	assert.InDelta(t, 200, sums[2]/2000, 1.5)
+	assert.InDelta(t, 700, sums[3]/2000, 1.5)
+
+	// large totals are split into inversion batches
+	counts := ReadAllocate(src, 10000000, []float64{1, 1})
+	assert.Equal(t, 10000000, counts[0]+counts[1])
+	assert.InDelta(t, 5000000, counts[0], 10000)
+
+	// tiny weights do not overflow the batch size
+	counts = ReadAllocate(src, 10, []float64{1e-20, 1})
+	assert.Equal(t, []int{0, 10}, counts)
+
+	assert.Equal(t, []int{0, 0}, ReadAllocate(src, 0, []float64{0, 0}))
+	assert.Equal(t, []int{0, 5, 0}, ReadAllocate(src, 5, []float64{0, 3, 0}))
+	assert.Empty(t, ReadAllocate(src, 0, nil))
+
+	assert.Panics(t, func() { ReadAllocate(src, -1, []float64{1}) })
+	assert.Panics(t, func() { ReadAllocate(src, 1, []float64{0}) })
+	assert.Panics(t, func() { ReadAllocate(src, 1, nil) })
+	assert.Panics(t, func() { ReadAllocate(src, 1, []float64{1, -1}) })
+	assert.Panics(t, func() { ReadAllocate(src, 1, []float64{math.NaN()}) })
+	assert.Panics(t, func() { ReadAllocate(src, 1, []float64{math.MaxFloat64, math.MaxFloat64}) })
+}
+
+func TestReadBinomial(t *testing.T) {
+	src := NewDRBG([]byte("binomial"))
+	for _, test := range []struct {
+		n int
+		p float64
+	}{
+		{10, 0.3},
+		{100, 0.9},
+		{5000, 0.5},
+	} {
+		sum, sq := 0.0, 0.0
+		for i := 0; i < 4000; i++ {
+			k := float64(readBinomial(src, test.n, test.p))
+			assert.True(t, k >= 0 && k <= float64(test.n))
+			sum += k
+			sq += k * k
+		}
+		mean := sum / 4000
+		variance := sq/4000 - mean*mean
+		np := float64(test.n) * test.p
+		assert.InDelta(t, np, mean, 4*math.Sqrt(np*(1-test.p)/4000), "n = %d, p = %g", test.n, test.p)
+		assert.InDelta(t, np*(1-test.p), variance, 0.1*np*(1-test.p), "n = %d, p = %g", test.n, test.p)
+	}
+	assert.Equal(t, 0, readBinomial(src, 10, 0))
+	assert.Equal(t, 10, readBinomial(src, 10, 1))
+	assert.Equal(t, 0, readBinomial(src, math.MaxInt32, math.SmallestNonzeroFloat64))
+}
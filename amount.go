@@ -0,0 +1,65 @@
+package rng
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount of money in minor currency units, e.g. cents. Exponent
+// is the number of minor unit decimal places of the currency (2 for EUR, 0
+// for JPY, 3 for KWD).
+type Money struct {
+	Minor    int64
+	Exponent int
+}
+
+// String formats the amount as a decimal number with Exponent decimal places,
+// e.g. Money{-1205, 2} is "-12.05".
+func (m Money) String() string {
+	neg := m.Minor < 0
+	u := uint64(m.Minor)
+	if neg {
+		u = -u
+	}
+	digits := strconv.FormatUint(u, 10)
+	if m.Exponent > 0 {
+		if len(digits) <= m.Exponent {
+			digits = strings.Repeat("0", m.Exponent-len(digits)+1) + digits
+		}
+		point := len(digits) - m.Exponent
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Amount returns a random amount in [minMinor, maxMinor] minor units, see
+// ReadAmount.
+func Amount(minMinor, maxMinor int64, currencyExponent int) Money {
+	return ReadAmount(defaultSource(), minMinor, maxMinor, currencyExponent)
+}
+
+// ReadAmount returns a random amount in [minMinor, maxMinor] minor units
+// reading randomness from a given source. All amounts are equally likely, the
+// draw and formatting never go through floating point. It will panic if
+// minMinor > maxMinor or currencyExponent is not in [0, 18].
+func ReadAmount(src io.Reader, minMinor, maxMinor int64, currencyExponent int) Money {
+	if minMinor > maxMinor || currencyExponent < 0 || currencyExponent > 18 {
+		panic("invalid argument to Amount")
+	}
+	// width wraps to 0 for the full int64 range
+	width := uint64(maxMinor-minMinor) + 1
+	var offset uint64
+	if width == 0 {
+		offset = ReadUint64Bits(src, 64)
+	} else {
+		offset = readUint64n(src, width)
+	}
+	return Money{
+		Minor:    minMinor + int64(offset),
+		Exponent: currencyExponent,
+	}
+}
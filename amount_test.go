@@ -0,0 +1,44 @@
+package rng
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyString(t *testing.T) {
+	for _, tt := range []struct {
+		m    Money
+		want string
+	}{
+		{Money{1234, 2}, "12.34"},
+		{Money{-1205, 2}, "-12.05"},
+		{Money{5, 2}, "0.05"},
+		{Money{-5, 3}, "-0.005"},
+		{Money{0, 2}, "0.00"},
+		{Money{1500, 0}, "1500"},
+		{Money{math.MinInt64, 18}, "-9.223372036854775808"},
+	} {
+		assert.Equal(t, tt.want, tt.m.String())
+	}
+}
+
+func TestReadAmount(t *testing.T) {
+	assert.Equal(t, Money{-100, 2}, ReadAmount(bytes.NewBuffer([]byte{0}), -100, 100, 2))
+	assert.Equal(t, Money{100, 2}, ReadAmount(bytes.NewBuffer([]byte{200}), -100, 100, 2))
+	assert.Equal(t, Money{7, 0}, ReadAmount(bytes.NewBuffer(nil), 7, 7, 0))
+
+	full := ReadAmount(bytes.NewBuffer([]byte{0, 0, 0, 0, 0, 0, 0, 0}), math.MinInt64, math.MaxInt64, 2)
+	assert.Equal(t, Money{math.MinInt64, 2}, full)
+
+	for i := 0; i < 100; i++ {
+		m := Amount(100, 10000, 2)
+		assert.True(t, m.Minor >= 100 && m.Minor <= 10000)
+	}
+
+	assert.Panics(t, func() { Amount(2, 1, 2) })
+	assert.Panics(t, func() { Amount(1, 2, -1) })
+	assert.Panics(t, func() { Amount(1, 2, 19) })
+}
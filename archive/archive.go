@@ -0,0 +1,278 @@
+// Package archive stores draw results in an append-only, hash-chained archive
+// with retention and a query API.
+//
+// Records are grouped into daily segments (UTC) named YYYY-MM-DD by the day
+// they are appended, so segments hold consecutive runs of records. Each record
+// holds the hash of its predecessor, so any modification or removal of
+// archived records other than pruning whole segments from the start is
+// detected by Verify. Storage is pluggable via Backend, local files and S3
+// style object stores are supported.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/advbet/rng"
+)
+
+// segmentLayout is the time layout of segment names.
+const segmentLayout = "2006-01-02"
+
+// ErrNotFound is returned when a queried draw is not archived.
+var ErrNotFound = errors.New("archive: draw not found")
+
+// Backend stores archive segments. Segments are append-only byte streams
+// identified by name.
+type Backend interface {
+	// Append appends data to a segment creating it if needed. Data must
+	// be durably stored when Append returns.
+	Append(ctx context.Context, segment string, data []byte) error
+	// Read returns the full segment content.
+	Read(ctx context.Context, segment string) ([]byte, error)
+	// List returns names of all segments in ascending order.
+	List(ctx context.Context) ([]string, error)
+	// Delete removes a segment.
+	Delete(ctx context.Context, segment string) error
+}
+
+// Record is a single archived draw.
+type Record struct {
+	Seq  uint64          `json:"seq"`
+	Prev []byte          `json:"prev"`
+	Hash []byte          `json:"hash"`
+	Draw *rng.DrawResult `json:"draw"`
+}
+
+// recordHash returns SHA256(seq || prev || canonical draw).
+func recordHash(seq uint64, prev []byte, d *rng.DrawResult) []byte {
+	h := sha256.New()
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], seq)
+	h.Write(b[:])
+	h.Write(prev)
+	h.Write(d.Canonical())
+	return h.Sum(nil)
+}
+
+// Archive is an append-only hash-chained draw archive. Archive is safe for
+// concurrent use.
+type Archive struct {
+	backend Backend
+	// Retention is how long segments are kept by Prune. Zero keeps
+	// segments forever.
+	Retention time.Duration
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	seq     uint64 // sequence number of the next record
+	last    []byte // hash of the last record
+	segment string // segment of the last record
+}
+
+var _ rng.Publisher = (*Archive)(nil)
+
+// Open opens an archive stored in backend, restoring the hash chain head from
+// the last segment.
+func Open(ctx context.Context, backend Backend) (*Archive, error) {
+	a := &Archive{backend: backend, now: time.Now}
+	segments, err := backend.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) > 0 {
+		a.segment = segments[len(segments)-1]
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		records, err := a.readSegment(ctx, segments[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			last := records[len(records)-1]
+			a.seq = last.Seq + 1
+			a.last = last.Hash
+			break
+		}
+	}
+	return a, nil
+}
+
+// Append archives a draw result and returns its record. Draw is stored in the
+// segment of the current day, or the last segment if the clock went back, so
+// records never land in a segment before their predecessor regardless of
+// FinishedAt.
+func (a *Archive) Append(ctx context.Context, d *rng.DrawResult) (*Record, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r := &Record{
+		Seq:  a.seq,
+		Prev: a.last,
+		Draw: d,
+	}
+	r.Hash = recordHash(r.Seq, r.Prev, d)
+	line, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	line = append(line, '\n')
+	segment := segmentName(a.now())
+	if segment < a.segment {
+		segment = a.segment
+	}
+	if err := a.backend.Append(ctx, segment, line); err != nil {
+		return nil, err
+	}
+	a.seq++
+	a.last = r.Hash
+	a.segment = segment
+	return r, nil
+}
+
+// Publish archives a draw result, it allows using an Archive as a publisher of
+// rng.PublishingGenerator.
+func (a *Archive) Publish(d *rng.DrawResult) error {
+	_, err := a.Append(context.Background(), d)
+	return err
+}
+
+// Get returns the record of a draw with a given ID. It returns ErrNotFound if
+// the draw is not archived.
+func (a *Archive) Get(ctx context.Context, id string) (*Record, error) {
+	segments, err := a.backend.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// recent draws are queried most often, search from the newest segment
+	for i := len(segments) - 1; i >= 0; i-- {
+		records, err := a.readSegment(ctx, segments[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range records {
+			if records[j].Draw.ID == id {
+				return &records[j], nil
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Range returns records of draws finished in [from, to) in archive order.
+// Draws are archived after they finish, so segments before the day of from
+// are skipped, later segments are searched because draws may be archived
+// days after they finish.
+func (a *Archive) Range(ctx context.Context, from, to time.Time) ([]Record, error) {
+	segments, err := a.backend.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	first := segmentName(from)
+	var out []Record
+	for _, s := range segments {
+		if s < first {
+			continue
+		}
+		records, err := a.readSegment(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			t := r.Draw.FinishedAt
+			if !t.Before(from) && t.Before(to) {
+				out = append(out, r)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Prune deletes segments whose whole day ended more than Retention before now.
+// It returns names of deleted segments.
+func (a *Archive) Prune(ctx context.Context, now time.Time) ([]string, error) {
+	if a.Retention <= 0 {
+		return nil, nil
+	}
+	segments, err := a.backend.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(-a.Retention)
+	var deleted []string
+	for _, s := range segments {
+		day, err := time.Parse(segmentLayout, s)
+		if err != nil {
+			return deleted, fmt.Errorf("archive: invalid segment name %q", s)
+		}
+		if !day.AddDate(0, 0, 1).Before(cutoff) {
+			break
+		}
+		if err := a.backend.Delete(ctx, s); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, s)
+	}
+	return deleted, nil
+}
+
+// Verify checks the hash chain of all archived records. The first remaining
+// record is trusted as the chain anchor because earlier records may have been
+// pruned.
+func (a *Archive) Verify(ctx context.Context) error {
+	segments, err := a.backend.List(ctx)
+	if err != nil {
+		return err
+	}
+	var prev *Record
+	for _, s := range segments {
+		records, err := a.readSegment(ctx, s)
+		if err != nil {
+			return err
+		}
+		for i := range records {
+			r := &records[i]
+			if prev != nil && (r.Seq != prev.Seq+1 || !bytes.Equal(r.Prev, prev.Hash)) {
+				return fmt.Errorf("archive: chain broken at record %d in segment %s", r.Seq, s)
+			}
+			if !bytes.Equal(r.Hash, recordHash(r.Seq, r.Prev, r.Draw)) {
+				return fmt.Errorf("archive: hash mismatch at record %d in segment %s", r.Seq, s)
+			}
+			prev = r
+		}
+	}
+	return nil
+}
+
+func (a *Archive) readSegment(ctx context.Context, segment string) ([]Record, error) {
+	data, err := a.backend.Read(ctx, segment)
+	if err != nil {
+		return nil, err
+	}
+	// a torn last line was left by a failed Append that never returned
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+	var records []Record
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, fmt.Errorf("archive: corrupt record in segment %s: %w", segment, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func segmentName(t time.Time) string {
+	return t.UTC().Format(segmentLayout)
+}
@@ -0,0 +1,196 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func draw(id string, at time.Time) *rng.DrawResult {
+	return &rng.DrawResult{
+		ID:         id,
+		Type:       "intn",
+		Params:     map[string]int64{"n": 10},
+		Outcome:    []int64{7},
+		StartedAt:  at,
+		FinishedAt: at,
+	}
+}
+
+func testBackends(t *testing.T) map[string]Backend {
+	fb, err := NewFileBackend(filepath.Join(t.TempDir(), "archive"))
+	require.NoError(t, err)
+	return map[string]Backend{
+		"file":   fb,
+		"object": NewObjectBackend(&MemoryStore{}, "draws"),
+	}
+}
+
+func TestArchive(t *testing.T) {
+	ctx := context.Background()
+	day1 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			a, err := Open(ctx, backend)
+			require.NoError(t, err)
+			now := day1
+			a.now = func() time.Time { return now }
+
+			r0, err := a.Append(ctx, draw("a", day1))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(0), r0.Seq)
+			assert.Nil(t, r0.Prev)
+			_, err = a.Append(ctx, draw("b", day1.Add(time.Hour)))
+			require.NoError(t, err)
+			now = day2
+			assert.NoError(t, a.Publish(draw("c", day2)))
+
+			// reopening must continue the chain
+			a, err = Open(ctx, backend)
+			require.NoError(t, err)
+			now = day2.Add(time.Minute)
+			a.now = func() time.Time { return now }
+			r3, err := a.Append(ctx, draw("d", day2.Add(time.Minute)))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), r3.Seq)
+			assert.NoError(t, a.Verify(ctx))
+
+			segments, err := backend.List(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"2024-03-01", "2024-03-02"}, segments)
+
+			r, err := a.Get(ctx, "b")
+			require.NoError(t, err)
+			assert.Equal(t, uint64(1), r.Seq)
+			assert.Equal(t, []int64{7}, r.Draw.Outcome)
+			_, err = a.Get(ctx, "missing")
+			assert.Equal(t, ErrNotFound, err)
+
+			records, err := a.Range(ctx, day1.Add(time.Minute), day2.Add(time.Minute))
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			assert.Equal(t, "b", records[0].Draw.ID)
+			assert.Equal(t, "c", records[1].Draw.ID)
+
+			// retention removes whole expired days only
+			a.Retention = 24 * time.Hour
+			deleted, err := a.Prune(ctx, day2.Add(time.Hour))
+			require.NoError(t, err)
+			assert.Empty(t, deleted)
+			deleted, err = a.Prune(ctx, day2.Add(13*time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"2024-03-01"}, deleted)
+			assert.NoError(t, a.Verify(ctx))
+			_, err = a.Get(ctx, "a")
+			assert.Equal(t, ErrNotFound, err)
+		})
+	}
+}
+
+func TestArchiveTamper(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend, err := NewFileBackend(dir)
+	require.NoError(t, err)
+	a, err := Open(ctx, backend)
+	require.NoError(t, err)
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return at }
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := a.Append(ctx, draw(id, at))
+		require.NoError(t, err)
+	}
+	require.NoError(t, a.Verify(ctx))
+
+	path := filepath.Join(dir, "2024-03-01.jsonl")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	// change the first outcome from 7 to 8
+	tampered := bytes.Replace(data, []byte(`"outcome":[7]`), []byte(`"outcome":[8]`), 1)
+	require.NoError(t, os.WriteFile(path, tampered, 0o640))
+	assert.Error(t, a.Verify(ctx))
+}
+
+func TestArchiveTornWrite(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend, err := NewFileBackend(dir)
+	require.NoError(t, err)
+	a, err := Open(ctx, backend)
+	require.NoError(t, err)
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return at }
+	_, err = a.Append(ctx, draw("a", at))
+	require.NoError(t, err)
+
+	// a crash during the next append leaves a partial line
+	path := filepath.Join(dir, "2024-03-01.jsonl")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o640)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"seq":1,"prev":"`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	a, err = Open(ctx, backend)
+	require.NoError(t, err)
+	a.now = func() time.Time { return at }
+	_, err = a.Get(ctx, "a")
+	require.NoError(t, err)
+	_, err = a.Append(ctx, draw("b", at))
+	require.NoError(t, err)
+	assert.NoError(t, a.Verify(ctx))
+	records, err := a.Range(ctx, at, at.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "b", records[1].Draw.ID)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+}
+
+func TestArchiveSegmentOrder(t *testing.T) {
+	ctx := context.Background()
+	backend := NewObjectBackend(&MemoryStore{}, "draws")
+	a, err := Open(ctx, backend)
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	// zero or early FinishedAt does not move a record before its predecessor
+	_, err = a.Append(ctx, draw("a", now))
+	require.NoError(t, err)
+	_, err = a.Append(ctx, draw("b", time.Time{}))
+	require.NoError(t, err)
+	// neither does a clock going back
+	now = now.AddDate(0, 0, -1)
+	_, err = a.Append(ctx, draw("c", now))
+	require.NoError(t, err)
+
+	a, err = Open(ctx, backend)
+	require.NoError(t, err)
+	a.now = func() time.Time { return now }
+	_, err = a.Append(ctx, draw("d", now))
+	require.NoError(t, err)
+
+	segments, err := backend.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-03-02"}, segments)
+	assert.NoError(t, a.Verify(ctx))
+
+	// draws archived after the day they finished are found by Range
+	records, err := a.Range(ctx, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "c", records[0].Draw.ID)
+	assert.Equal(t, "d", records[1].Draw.ID)
+}
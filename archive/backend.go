@@ -0,0 +1,289 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// segmentExt is the file extension of local segment files.
+const segmentExt = ".jsonl"
+
+// FileBackend stores each segment as a JSON lines file in a directory.
+type FileBackend struct {
+	dir string
+}
+
+var _ Backend = (*FileBackend)(nil)
+
+// NewFileBackend returns a backend storing segments in dir, creating it if
+// needed.
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileBackend{dir: dir}, nil
+}
+
+func (b *FileBackend) path(segment string) string {
+	return filepath.Join(b.dir, segment+segmentExt)
+}
+
+// Append appends data to a segment file and syncs it to disk. A failed write
+// is truncated away, and a torn last line left by a crash during an earlier
+// Append is dropped first, so data is never glued to a partial record.
+func (b *FileBackend) Append(ctx context.Context, segment string, data []byte) error {
+	f, err := os.OpenFile(b.path(segment), os.O_RDWR|os.O_CREATE, 0o640)
+	if err != nil {
+		return err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	end, err := lineEnd(f, size)
+	if err == nil && end < size {
+		err = f.Truncate(end)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if _, err = f.WriteAt(data, end); err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Truncate(end)
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// lineEnd returns the offset after the last newline of the first size bytes
+// of f, i.e. size without a torn last line.
+func lineEnd(f *os.File, size int64) (int64, error) {
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		n := min(end, int64(len(buf)))
+		if _, err := f.ReadAt(buf[:n], end-n); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return end - n + int64(i) + 1, nil
+		}
+		end -= n
+	}
+	return 0, nil
+}
+
+// Read returns segment file content.
+func (b *FileBackend) Read(ctx context.Context, segment string) ([]byte, error) {
+	return os.ReadFile(b.path(segment))
+}
+
+// List returns segment names of all segment files.
+func (b *FileBackend) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []string
+	for _, e := range entries {
+		if name := e.Name(); !e.IsDir() && strings.HasSuffix(name, segmentExt) {
+			segments = append(segments, strings.TrimSuffix(name, segmentExt))
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// Delete removes a segment file.
+func (b *FileBackend) Delete(ctx context.Context, segment string) error {
+	return os.Remove(b.path(segment))
+}
+
+// ObjectStore is the subset of an S3 compatible object store API used by
+// ObjectBackend. It is implemented by a small adapter around the AWS SDK (or
+// any other S3 client), keeping this module free of its dependencies.
+type ObjectStore interface {
+	PutObject(ctx context.Context, key string, data []byte) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// ListObjects returns all keys starting with prefix.
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// ObjectBackend stores segments in an object store. Objects can not be
+// appended to, so every Append writes a new immutable part object named
+// prefix/segment/NNNNNNNNNN and Read concatenates parts in order.
+type ObjectBackend struct {
+	store  ObjectStore
+	prefix string
+
+	mu    sync.Mutex
+	parts map[string]int // next part number per segment
+}
+
+var _ Backend = (*ObjectBackend)(nil)
+
+// NewObjectBackend returns a backend storing segments under key prefix.
+func NewObjectBackend(store ObjectStore, prefix string) *ObjectBackend {
+	return &ObjectBackend{
+		store:  store,
+		prefix: strings.TrimSuffix(prefix, "/") + "/",
+		parts:  make(map[string]int),
+	}
+}
+
+func (b *ObjectBackend) partKeys(ctx context.Context, segment string) ([]string, error) {
+	keys, err := b.store.ListObjects(ctx, b.prefix+segment+"/")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Append writes data as the next part object of a segment.
+func (b *ObjectBackend) Append(ctx context.Context, segment string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	part, ok := b.parts[segment]
+	if !ok {
+		keys, err := b.partKeys(ctx, segment)
+		if err != nil {
+			return err
+		}
+		part = len(keys)
+	}
+	key := fmt.Sprintf("%s%s/%010d", b.prefix, segment, part)
+	if err := b.store.PutObject(ctx, key, data); err != nil {
+		return err
+	}
+	b.parts[segment] = part + 1
+	return nil
+}
+
+// Read concatenates all part objects of a segment.
+func (b *ObjectBackend) Read(ctx context.Context, segment string) ([]byte, error) {
+	keys, err := b.partKeys(ctx, segment)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	for _, k := range keys {
+		part, err := b.store.GetObject(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, part...)
+	}
+	return data, nil
+}
+
+// List returns names of segments having at least one part object.
+func (b *ObjectBackend) List(ctx context.Context) ([]string, error) {
+	keys, err := b.store.ListObjects(ctx, b.prefix)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var segments []string
+	for _, k := range keys {
+		rest := strings.TrimPrefix(k, b.prefix)
+		if i := strings.IndexByte(rest, '/'); i > 0 && !seen[rest[:i]] {
+			seen[rest[:i]] = true
+			segments = append(segments, rest[:i])
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// Delete removes all part objects of a segment.
+func (b *ObjectBackend) Delete(ctx context.Context, segment string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	keys, err := b.partKeys(ctx, segment)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := b.store.DeleteObject(ctx, k); err != nil {
+			return err
+		}
+	}
+	delete(b.parts, segment)
+	return nil
+}
+
+// MemoryStore is an in-memory ObjectStore, useful for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+var _ ObjectStore = (*MemoryStore)(nil)
+
+// PutObject stores a copy of data under key.
+func (s *MemoryStore) PutObject(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects == nil {
+		s.objects = make(map[string][]byte)
+	}
+	s.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// GetObject returns object data.
+func (s *MemoryStore) GetObject(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("archive: object %q not found", key)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// ListObjects returns sorted keys starting with prefix.
+func (s *MemoryStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for _, k := range sortedKeys(s.objects) {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// DeleteObject removes an object.
+func (s *MemoryStore) DeleteObject(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// sortedKeys returns sorted map keys.
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package rng
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// DrawSpec describes a draw of a batch.
+type DrawSpec struct {
+	// ID is copied to the result, a random ID is generated if empty.
+	ID string `json:"id,omitempty"`
+	// Type is one of "intn" (n), "float64", "perm" (n), "sample" (n, k) or
+	// "shuffle" (n), outcomes are encoded as by PublishingGenerator.
+	Type   string           `json:"type"`
+	Params map[string]int64 `json:"params,omitempty"`
+}
+
+// BatchDraw executes many independent draws concurrently reading randomness
+// from the default source, see ReadBatchDraw. It will panic if workers <= 0.
+func BatchDraw(jobs []DrawSpec, workers int) []DrawResult {
+	if workers <= 0 {
+		panic("invalid argument to BatchDraw")
+	}
+	srcs := make([]io.Reader, workers)
+	for i := range srcs {
+		srcs[i] = defaultSource()
+	}
+	return ReadBatchDraw(srcs, jobs)
+}
+
+// ReadBatchDraw executes draws over a pool of sources with one worker per
+// source, e.g. settling thousands of pending games at once. Result i is the
+// draw of jobs[i]. Job i is drawn from source i % len(srcs) and jobs of a
+// source are drawn in order, so with deterministic sources outcomes are
+// reproducible regardless of scheduling. The same source may be given more
+// than once only if it is safe for concurrent use, like crypto/rand.Reader.
+//
+// All specs are validated before any draw is made, it will panic if srcs is
+// empty or a spec has an unknown type or invalid parameters.
+func ReadBatchDraw(srcs []io.Reader, jobs []DrawSpec) []DrawResult {
+	if len(srcs) == 0 {
+		panic("invalid argument to BatchDraw")
+	}
+	runs := make([]func(src io.Reader) []int64, len(jobs))
+	for i, job := range jobs {
+		run, err := specRun(job)
+		if err != nil {
+			panic(fmt.Sprintf("invalid argument to BatchDraw, job %d: %v", i, err))
+		}
+		runs[i] = run
+	}
+
+	results := make([]DrawResult, len(jobs))
+	var wg sync.WaitGroup
+	for w, src := range srcs {
+		wg.Add(1)
+		go func(w int, src io.Reader) {
+			defer wg.Done()
+			for i := w; i < len(jobs); i += len(srcs) {
+				d := runDraw(src, jobs[i].Type, jobs[i].Params, runs[i])
+				if jobs[i].ID != "" {
+					d.ID = jobs[i].ID
+				}
+				results[i] = *d
+			}
+		}(w, src)
+	}
+	wg.Wait()
+	return results
+}
+
+// specRun returns the draw function of a spec.
+func specRun(s DrawSpec) (func(src io.Reader) []int64, error) {
+	for name := range s.Params {
+		if name != "n" && name != "k" {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+	n, k := s.Params["n"], s.Params["k"]
+	if n < 0 || n > math.MaxInt || k < 0 || k > math.MaxInt {
+		return nil, fmt.Errorf("parameters out of range")
+	}
+	switch s.Type {
+	case "intn":
+		if n <= 0 {
+			return nil, fmt.Errorf("intn requires n > 0")
+		}
+		return func(src io.Reader) []int64 {
+			return []int64{int64(ReadIntn(src, int(n)))}
+		}, nil
+	case "float64":
+		return func(src io.Reader) []int64 {
+			return []int64{int64(ReadUint64Bits(src, 53))}
+		}, nil
+	case "perm":
+		return func(src io.Reader) []int64 {
+			return intsToInt64s(ReadPerm(src, int(n)))
+		}, nil
+	case "sample":
+		return func(src io.Reader) []int64 {
+			return intsToInt64s(ReadSample(src, int(n), int(k)))
+		}, nil
+	case "shuffle":
+		return func(src io.Reader) []int64 {
+			js := []int64{}
+			ReadShuffle(src, int(n), func(i, j int) {
+				js = append(js, int64(j))
+			})
+			return js
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown draw type %q", s.Type)
+}
@@ -0,0 +1,75 @@
+package rng
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBatchDraw(t *testing.T) {
+	var jobs []DrawSpec
+	for i := 0; i < 1000; i++ {
+		jobs = append(jobs, DrawSpec{ID: fmt.Sprint("game-", i), Type: "sample", Params: map[string]int64{"n": 49, "k": 6}})
+	}
+	jobs = append(jobs,
+		DrawSpec{Type: "intn", Params: map[string]int64{"n": 10}},
+		DrawSpec{Type: "float64"},
+		DrawSpec{Type: "perm", Params: map[string]int64{"n": 5}},
+		DrawSpec{Type: "shuffle", Params: map[string]int64{"n": 5}},
+	)
+
+	shards := func() []io.Reader {
+		return []io.Reader{NewDRBG([]byte("0")), NewDRBG([]byte("1")), NewDRBG([]byte("2"))}
+	}
+	a := ReadBatchDraw(shards(), jobs)
+	b := ReadBatchDraw(shards(), jobs)
+	require.Len(t, a, len(jobs))
+	for i := range a {
+		assert.Equal(t, a[i].Outcome, b[i].Outcome, "deterministic outcome of job %d", i)
+		assert.Equal(t, jobs[i].Type, a[i].Type)
+	}
+	assert.Equal(t, "game-7", a[7].ID)
+	assert.Len(t, a[1000].ID, 32)
+
+	// job i is the (i / 3)-th draw of shard i % 3
+	shard := NewDRBG([]byte("1"))
+	var want []int
+	for i := 1; i <= 7; i += 3 {
+		want = ReadSample(shard, 49, 6)
+	}
+	assert.Equal(t, intsToInt64s(want), a[7].Outcome)
+
+	// shuffle outcome lists swap indexes as PublishingGenerator
+	assert.Len(t, a[1003].Outcome, 4)
+}
+
+func TestBatchDraw(t *testing.T) {
+	jobs := make([]DrawSpec, 100)
+	for i := range jobs {
+		jobs[i] = DrawSpec{Type: "intn", Params: map[string]int64{"n": 6}}
+	}
+	res := BatchDraw(jobs, 8)
+	require.Len(t, res, 100)
+	for _, r := range res {
+		require.Len(t, r.Outcome, 1)
+		assert.True(t, r.Outcome[0] >= 0 && r.Outcome[0] < 6)
+		assert.Len(t, r.EntropyDigest, 32)
+	}
+	assert.Empty(t, BatchDraw(nil, 1))
+}
+
+func TestBatchDrawInvalid(t *testing.T) {
+	assert.Panics(t, func() { BatchDraw(nil, 0) })
+	assert.Panics(t, func() { ReadBatchDraw(nil, nil) })
+	for _, spec := range []DrawSpec{
+		{Type: "dice"},
+		{Type: "intn"},
+		{Type: "perm", Params: map[string]int64{"n": -1}},
+		{Type: "sample", Params: map[string]int64{"n": 10, "m": 1}},
+	} {
+		assert.Panics(t, func() { BatchDraw([]DrawSpec{{Type: "float64"}, spec}, 2) }, spec.Type)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !js
+
+package main
+
+// hasFileSystem reports whether commands can read and write files.
+const hasFileSystem = true
@@ -0,0 +1,11 @@
+//go:build js
+
+package main
+
+import "syscall/js"
+
+// hasFileSystem reports whether commands can read and write files. Under
+// Node.js files are backed by its fs module, browsers have none: file
+// commands and flags fail early with errNoFileSystem there, while draws from
+// crypto.getRandomValues, seeds and scripts on stdin still work.
+var hasFileSystem = js.Global().Get("require").Type() == js.TypeFunction
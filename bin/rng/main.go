@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/advbet/rng"
+	"github.com/advbet/rng/offline"
+)
+
+const usage = `usage: rng <command> [flags]
+
+commands:
+  prefetch  generate an entropy file for offline draws, run
+            "rng prefetch -h" for flags
+  repl      interactive prompt for draws, run "rng repl -h" for flags
+  script    run a JSON draw script and print a signed transcript, run
+            "rng script -h" for flags
+  seal      encrypt an entropy file or DRBG state, run "rng seal -h" for
+            flags
+  unseal    verify and decrypt a sealed file, run "rng unseal -h" for flags
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "prefetch":
+		os.Exit(prefetchMain(os.Args[2:]))
+	case "repl":
+		os.Exit(replMain(os.Args[2:]))
+	case "script":
+		os.Exit(scriptMain(os.Args[2:]))
+	case "seal", "unseal":
+		os.Exit(sealMain(os.Args[2:], os.Args[1] == "unseal"))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// errNoFileSystem is returned for files given on platforms without a file
+// system, see hasFileSystem.
+var errNoFileSystem = errors.New("files are not supported on this platform")
+
+// checkFiles returns errNoFileSystem if any of the given paths is used on a
+// platform without a file system, empty paths and "-" (stdin) are ignored.
+func checkFiles(paths ...string) error {
+	for _, p := range paths {
+		if !hasFileSystem && p != "" && p != "-" {
+			return fmt.Errorf("%s: %w", p, errNoFileSystem)
+		}
+	}
+	return nil
+}
+
+// sourceFlags select the randomness source of a command.
+type sourceFlags struct {
+	seed       string
+	serverSeed string
+	clientSeed string
+	nonce      uint64
+	entropy    string
+}
+
+func (f *sourceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.seed, "seed", "", "hex encoded DRBG seed, crypto/rand is used by default")
+	fs.StringVar(&f.serverSeed, "server-seed", "", "hex encoded server seed of a provably fair source")
+	fs.StringVar(&f.clientSeed, "client-seed", "", "client seed of a provably fair source")
+	fs.Uint64Var(&f.nonce, "nonce", 0, "nonce of a provably fair source")
+	fs.StringVar(&f.entropy, "entropy", "", "entropy file written by rng prefetch, draws consume it in offline mode")
+}
+
+func replMain(args []string) int {
+	var sf sourceFlags
+	var transcript string
+
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	sf.register(fs)
+	fs.StringVar(&transcript, "transcript", "", "write the session transcript to a file on exit")
+	fs.Parse(args)
+	if err := checkFiles(transcript); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	src, name, err := sf.source()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	r := newREPL(src, name)
+	r.Run(os.Stdin, os.Stdout)
+	if transcript != "" {
+		if err := r.Export(transcript); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// source returns the randomness source selected by flags and its
+// description printed in transcripts.
+func (f *sourceFlags) source() (io.Reader, string, error) {
+	seed, serverSeed, clientSeed, nonce := f.seed, f.serverSeed, f.clientSeed, f.nonce
+	selected := 0
+	for _, s := range []string{seed, serverSeed, f.entropy} {
+		if s != "" {
+			selected++
+		}
+	}
+	switch {
+	case selected > 1:
+		return nil, "", fmt.Errorf("-seed, -server-seed and -entropy are mutually exclusive")
+	case f.entropy != "":
+		if err := checkFiles(f.entropy); err != nil {
+			return nil, "", err
+		}
+		s, err := offline.Open(f.entropy)
+		if err != nil {
+			return nil, "", err
+		}
+		digest, err := s.Digest()
+		if err != nil {
+			return nil, "", err
+		}
+		name := fmt.Sprintf("offline file=%s sha256=%x position=%d", f.entropy, digest, s.Position())
+		return s, name, nil
+	case seed != "":
+		s, err := hex.DecodeString(seed)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid seed: %w", err)
+		}
+		return rng.NewDRBG(s), "drbg seed=" + seed, nil
+	case serverSeed != "":
+		s, err := hex.DecodeString(serverSeed)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid server seed: %w", err)
+		}
+		name := fmt.Sprintf("fair server-seed-hash=%x client-seed=%q nonce=%d",
+			rng.HashServerSeed(s), clientSeed, nonce)
+		return rng.FairSource(s, clientSeed, nonce), name, nil
+	default:
+		return rng.DefaultSource(), "crypto/rand", nil
+	}
+}
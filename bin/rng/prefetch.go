@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/advbet/rng"
+	"github.com/advbet/rng/offline"
+)
+
+func prefetchMain(args []string) int {
+	var size string
+	var out string
+
+	fs := flag.NewFlagSet("prefetch", flag.ExitOnError)
+	fs.StringVar(&size, "bytes", "", "number of bytes to generate, KB, MB and GB suffixes are multiples of 1024")
+	fs.StringVar(&out, "o", "", "entropy file to create")
+	fs.Parse(args)
+
+	n, err := parseSize(size)
+	if err != nil || out == "" {
+		fmt.Fprintln(os.Stderr, "usage: rng prefetch -bytes SIZE -o FILE")
+		return 2
+	}
+	if err := prefetch(out, n); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// prefetch writes n bytes of default source entropy to a new file and prints
+// its seal.
+func prefetch(path string, n int64) error {
+	if err := checkFiles(path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	digest, err := offline.Prefetch(f, rng.DefaultSource(), n)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	fmt.Printf("%x  %s\n", digest, path)
+	return nil
+}
+
+// parseSize parses a byte count with an optional KB, MB or GB suffix.
+func parseSize(s string) (int64, error) {
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(strings.ToUpper(s), u.suffix) {
+			s, mult = s[:len(s)-len(u.suffix)], u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 || n > (1<<62)/mult {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/advbet/rng/outcome"
+)
+
+const help = `commands:
+  intn N          random integer in [0, N)
+  perm N          random permutation of [0, N)
+  dice N [SIDES]  roll N dice with SIDES sides, 6 by default
+  cards N         deal N cards from a shuffled 52 card deck
+  seed HEX        switch to a DRBG seeded with HEX
+  source          print the current source
+  history         list issued commands
+  !N              repeat command N of history
+  save FILE       write the session transcript to FILE
+  help            print this help
+  quit            leave the prompt
+`
+
+// maxN bounds draw sizes so a typo does not allocate gigabytes.
+const maxN = 1 << 20
+
+var errQuit = errors.New("quit")
+
+// entry is a transcript line: an issued command and its output.
+type entry struct {
+	Time    time.Time
+	Command string
+	Output  string
+}
+
+// repl holds an interactive session, the current source and the history of
+// issued commands.
+type repl struct {
+	src     io.Reader
+	name    string
+	initial string // source at the start of the session
+	started time.Time
+	history []entry
+}
+
+func newREPL(src io.Reader, name string) *repl {
+	return &repl{src: src, name: name, initial: name, started: time.Now().UTC()}
+}
+
+// Run reads commands from in until EOF or quit, writing prompts and output
+// to out.
+func (r *repl) Run(in io.Reader, out io.Writer) {
+	fmt.Fprintf(out, "source: %s\ntype help for commands\n", r.name)
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "rng> ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		s, err := r.Exec(line)
+		if err == errQuit {
+			return
+		}
+		if err != nil {
+			fmt.Fprintln(out, "error:", err)
+			continue
+		}
+		if s != "" {
+			fmt.Fprintln(out, s)
+		}
+	}
+}
+
+// Exec runs a single command line and returns its output. Draws and source
+// changes are recorded in history.
+func (r *repl) Exec(line string) (string, error) {
+	if strings.HasPrefix(line, "!") {
+		i, err := strconv.Atoi(line[1:])
+		if err != nil || i < 1 || i > len(r.history) {
+			return "", fmt.Errorf("no history entry %q", line[1:])
+		}
+		line = r.history[i-1].Command
+	}
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+
+	var out string
+	var err error
+	switch cmd {
+	case "help":
+		return strings.TrimSuffix(help, "\n"), nil
+	case "quit", "exit":
+		return "", errQuit
+	case "source":
+		return r.name, nil
+	case "history":
+		lines := make([]string, len(r.history))
+		for i, e := range r.history {
+			lines[i] = fmt.Sprintf("%4d  %s", i+1, e.Command)
+		}
+		return strings.Join(lines, "\n"), nil
+	case "save":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: save FILE")
+		}
+		if err := r.Export(args[0]); err != nil {
+			return "", err
+		}
+		return "transcript written to " + args[0], nil
+	case "seed":
+		out, err = r.seed(args)
+	case "intn", "perm", "dice", "cards":
+		out, err = r.draw(cmd, args)
+	default:
+		return "", fmt.Errorf("unknown command %q, type help for commands", cmd)
+	}
+	if err != nil {
+		return "", err
+	}
+	r.history = append(r.history, entry{Time: time.Now().UTC(), Command: line, Output: out})
+	return out, nil
+}
+
+func (r *repl) seed(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: seed HEX")
+	}
+	s, err := hex.DecodeString(args[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid seed: %w", err)
+	}
+	r.src = rng.NewDRBG(s)
+	r.name = "drbg seed=" + args[0]
+	return "source: " + r.name, nil
+}
+
+func (r *repl) draw(cmd string, args []string) (out string, err error) {
+	// source failures, e.g. an exhausted entropy file, panic in draws
+	defer func() {
+		if e := recover(); e != nil {
+			out, err = "", fmt.Errorf("%v", e)
+		}
+	}()
+	nums, err := parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+	arity := map[string][2]int{"intn": {1, 1}, "perm": {1, 1}, "dice": {1, 2}, "cards": {1, 1}}[cmd]
+	if len(nums) < arity[0] || len(nums) > arity[1] {
+		return "", fmt.Errorf("wrong number of arguments, type help for usage")
+	}
+	n := nums[0]
+	switch cmd {
+	case "intn":
+		if n <= 0 {
+			return "", fmt.Errorf("N must be positive")
+		}
+		return strconv.Itoa(rng.ReadIntn(r.src, n)), nil
+	case "perm":
+		if n < 0 || n > maxN {
+			return "", fmt.Errorf("N must be in [0, %d]", maxN)
+		}
+		return joinInts(rng.ReadPerm(r.src, n), " "), nil
+	case "dice":
+		sides := 6
+		if len(nums) == 2 {
+			sides = nums[1]
+		}
+		if n < 0 || n > maxN || sides <= 0 {
+			return "", fmt.Errorf("N must be in [0, %d] and SIDES positive", maxN)
+		}
+		rolls := make([]int, n)
+		for i := range rolls {
+			rolls[i] = rng.ReadIntn(r.src, sides) + 1
+		}
+		return joinInts(rolls, " "), nil
+	default: // cards
+		if n < 0 || n > rng.CardsPerDeck {
+			return "", fmt.Errorf("N must be in [0, %d]", rng.CardsPerDeck)
+		}
+		cards := rng.ReadPermPrefix(r.src, rng.CardsPerDeck, n)
+		idx := make([]int64, len(cards))
+		for i, c := range cards {
+			idx[i] = int64(c)
+		}
+		return outcome.Cards{}.Format(idx)
+	}
+}
+
+// Export writes the session transcript to a file: the initial source
+// followed by every recorded command and its output, seed commands record
+// later source changes.
+func (r *repl) Export(path string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# rng repl transcript\n# started: %s\n# source: %s\n",
+		r.started.Format(time.RFC3339), r.initial)
+	for i, e := range r.history {
+		fmt.Fprintf(&b, "\n[%d] %s\n> %s\n%s\n", i+1, e.Time.Format(time.RFC3339), e.Command, e.Output)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+func parseArgs(args []string) ([]int, error) {
+	nums := make([]int, len(args))
+	for i, a := range args {
+		n, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", a)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+func joinInts(v []int, sep string) string {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i] = strconv.Itoa(x)
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/advbet/rng/script"
+)
+
+func scriptMain(args []string) int {
+	var sf sourceFlags
+	var keyFile string
+
+	fs := flag.NewFlagSet("script", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: rng script [flags] [draws.json]\n\nthe script is read from stdin if no file or - is given\n\nflags:")
+		fs.PrintDefaults()
+	}
+	sf.register(fs)
+	fs.StringVar(&keyFile, "key", "", "file holding a hex encoded Ed25519 seed signing the transcript, an ephemeral key is used by default")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if err := runScript(&sf, fs.Arg(0), keyFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runScript(sf *sourceFlags, path, keyFile string) error {
+	if err := checkFiles(path, keyFile); err != nil {
+		return err
+	}
+	in := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	s, err := script.Parse(in)
+	if err != nil {
+		return err
+	}
+	key, err := signingKey(keyFile)
+	if err != nil {
+		return err
+	}
+	src, name, err := sf.source()
+	if err != nil {
+		return err
+	}
+
+	t, err := script.Run(src, s)
+	if err != nil {
+		return err
+	}
+	t.Source = name
+	t.Sign(key)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// signingKey reads an Ed25519 key from a file holding its hex encoded seed,
+// or generates an ephemeral key if no file is given.
+func signingKey(path string) (ed25519.PrivateKey, error) {
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "signing with ephemeral key %x\n", key.Public())
+		}
+		return key, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("key file %s must hold a hex encoded %d byte Ed25519 seed", path, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/advbet/rng/offline"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func sealMain(args []string, unseal bool) int {
+	var keyFile string
+	var keyID string
+	var kind string
+	var in string
+	var out string
+
+	name := "seal"
+	if unseal {
+		name = "unseal"
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&keyFile, "key", "", "file holding a hex encoded 32 byte sealing key")
+	fs.StringVar(&keyID, "key-id", "", "ID of the sealing key recorded in sealed files")
+	fs.StringVar(&kind, "kind", offline.KindEntropy, `kind of sealed material, "entropy" or "drbg-state"`)
+	fs.StringVar(&in, "in", "", "input file")
+	fs.StringVar(&out, "o", "", "output file to create")
+	fs.Parse(args)
+	if keyFile == "" || keyID == "" || in == "" || out == "" {
+		fmt.Fprintf(os.Stderr, "usage: rng %s -key FILE -key-id ID -in FILE -o FILE\n", name)
+		return 2
+	}
+	if err := checkFiles(keyFile, in, out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	err := convertFile(in, out, func(w io.Writer, r io.Reader) error {
+		key, err := sealingKey(keyFile, keyID)
+		if err != nil {
+			return err
+		}
+		if unseal {
+			return offline.Unseal(w, r, kind, key)
+		}
+		return offline.Seal(w, r, kind, key)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// convertFile writes a new file converted from another one, the output file
+// is removed if conversion fails.
+func convertFile(in, out string, convert func(w io.Writer, r io.Reader) error) error {
+	r, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	err = convert(w, r)
+	if err == nil {
+		err = w.Sync()
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out)
+	}
+	return err
+}
+
+// sealingKey reads a sealing key from a file holding its hex encoding.
+func sealingKey(path, id string) (offline.Key, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return offline.Key{}, err
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(secret) != chacha20poly1305.KeySize {
+		return offline.Key{}, fmt.Errorf("key file %s must hold a hex encoded %d byte key", path, chacha20poly1305.KeySize)
+	}
+	return offline.Key{ID: id, Secret: secret}, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/advbet/rng/spec"
+)
+
+func main() {
+	var run string
+	var maxBytes int
+	var asJSON bool
+
+	flag.StringVar(&run, "run", "", "run only cases with names matching the regular expression")
+	flag.IntVar(&maxBytes, "bytes", spec.MaxBytes, "skip cases enumerating longer byte strings")
+	flag.BoolVar(&asJSON, "json", false, "print reports as JSON lines")
+	flag.Parse()
+
+	re, err := regexp.Compile(run)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	failed := false
+	enc := json.NewEncoder(os.Stdout)
+	for _, c := range spec.Standard() {
+		if !re.MatchString(c.Name) || c.Bytes > maxBytes {
+			continue
+		}
+		r, err := spec.Check(c)
+		if r == nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		failed = failed || err != nil
+		if asJSON {
+			enc.Encode(r)
+		} else {
+			fmt.Println(r)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
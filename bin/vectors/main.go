@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/advbet/rng/rngtest"
+)
+
+func main() {
+	var mode string
+	var seed string
+	var clientSeed string
+	var nonce uint64
+
+	flag.StringVar(&mode, "mode", "", `"drbg" or "fair" to emit a single vector, reference vectors are emitted by default`)
+	flag.StringVar(&seed, "seed", "", "hex encoded DRBG seed or server seed")
+	flag.StringVar(&clientSeed, "client-seed", "", "client seed of fair mode")
+	flag.Uint64Var(&nonce, "nonce", 0, "nonce of fair mode")
+	flag.Parse()
+
+	vs, err := vectors(mode, seed, clientSeed, nonce)
+	if err == nil {
+		err = rngtest.EncodeVectors(os.Stdout, vs)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func vectors(mode, seed, clientSeed string, nonce uint64) ([]*rngtest.Vector, error) {
+	s, err := hex.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed: %w", err)
+	}
+	var v *rngtest.Vector
+	switch mode {
+	case "":
+		return rngtest.ReferenceVectors()
+	case "drbg":
+		v, err = rngtest.DRBGVector(s, rngtest.VectorScript)
+	case "fair":
+		v, err = rngtest.FairVector(s, clientSeed, nonce, rngtest.VectorScript)
+	default:
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*rngtest.Vector{v}, nil
+}
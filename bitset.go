@@ -0,0 +1,126 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// Bits returns a non negative big.Int with n random bits, see ReadBits.
+func Bits(n int) *big.Int {
+	return ReadBits(defaultSource(), n)
+}
+
+// ReadBits returns a uniformly random big.Int in [0, 2^n) reading randomness
+// from a given source. It will panic if n < 0.
+func ReadBits(src io.Reader, n int) *big.Int {
+	words := ReadBitset(src, n, 0.5)
+	b := make([]big.Word, 0, len(words)*64/bitsPerWord)
+	for _, w := range words {
+		for i := 0; i < 64/bitsPerWord; i++ {
+			b = append(b, big.Word(w>>(i*bitsPerWord)))
+		}
+	}
+	return new(big.Int).SetBits(b)
+}
+
+// bitsPerWord is the size of big.Word in bits.
+const bitsPerWord = 32 << (^uint(0) >> 63)
+
+// Bitset returns n random bits packed into 64-bit words, see ReadBitset.
+func Bitset(n int, p float64) []uint64 {
+	return ReadBitset(defaultSource(), n, p)
+}
+
+// ReadBitset returns n random bits packed into 64-bit words reading randomness
+// from a given source. Bit i is stored in words[i/64] at position i%64 and is
+// set with probability p, unused high bits of the last word are zero.
+//
+// For p = 0.5 bits are copied from the source, p = 0 and p = 1 read nothing.
+// Other values are exact for the float64 p: each bit compares a lazily drawn
+// uniform number with the binary expansion of p, consuming 2 random bits per
+// output bit on average. It will panic if n < 0 or p is not in [0, 1].
+func ReadBitset(src io.Reader, n int, p float64) []uint64 {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		panic("invalid argument to Bitset")
+	}
+	words := make([]uint64, (n+63)/64)
+	if n == 0 {
+		return words
+	}
+
+	switch p {
+	case 0:
+	case 1:
+		for i := range words {
+			words[i] = math.MaxUint64
+		}
+	case 0.5:
+		buf := make([]byte, (n+7)/8)
+		if _, err := io.ReadFull(src, buf); err != nil {
+			panic(err)
+		}
+		for i, b := range buf {
+			words[i/8] |= uint64(b) << (8 * (i % 8))
+		}
+	default:
+		digits := binaryDigits(p)
+		bits := bitReader{src: src}
+		for i := 0; i < n; i++ {
+			if bits.below(digits) {
+				words[i/64] |= 1 << (i % 64)
+			}
+		}
+	}
+	if n%64 != 0 {
+		words[len(words)-1] &= 1<<(n%64) - 1
+	}
+	return words
+}
+
+// binaryDigits returns the binary expansion of p in (0, 1) after the binary
+// point up to its last non zero digit.
+func binaryDigits(p float64) []bool {
+	frac, exp := math.Frexp(p) // p = frac * 2^exp, frac in [0.5, 1)
+	mant := uint64(frac * (1 << 53))
+	// digit k (1-based) has weight 2^-k, that is mant bit 53 - k - exp
+	last := 53 - exp - bits.TrailingZeros64(mant)
+	digits := make([]bool, last)
+	for k := 1; k <= last; k++ {
+		if j := 53 - k - exp; j <= 52 {
+			digits[k-1] = mant>>uint(j)&1 == 1
+		}
+	}
+	return digits
+}
+
+// bitReader reads single bits from a source.
+type bitReader struct {
+	src  io.Reader
+	buf  [1]byte
+	left int
+}
+
+func (b *bitReader) bit() bool {
+	if b.left == 0 {
+		if _, err := io.ReadFull(b.src, b.buf[:]); err != nil {
+			panic(err)
+		}
+		b.left = 8
+	}
+	b.left--
+	return b.buf[0]>>uint(b.left)&1 == 1
+}
+
+// below reports whether a uniform number in [0, 1), whose binary digits are
+// drawn one at a time, is below the number with given digits.
+func (b *bitReader) below(digits []bool) bool {
+	for _, d := range digits {
+		if b.bit() != d {
+			return d
+		}
+	}
+	// equal so far and all remaining digits of p are zero
+	return false
+}
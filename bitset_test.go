@@ -0,0 +1,50 @@
+package rng
+
+import (
+	"bytes"
+	"math"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryDigits(t *testing.T) {
+	assert.Equal(t, []bool{false, true}, binaryDigits(0.25))
+	assert.Equal(t, []bool{true, false, true}, binaryDigits(0.625))
+	assert.Len(t, binaryDigits(0.1), 55)
+	assert.Len(t, binaryDigits(math.SmallestNonzeroFloat64), 1074)
+}
+
+func TestReadBitset(t *testing.T) {
+	src := bytes.NewBuffer([]byte{0xff, 0x01, 0, 0, 0, 0, 0, 0})
+	assert.Equal(t, []uint64{0x1ff}, ReadBitset(src, 10, 0.5))
+	assert.Equal(t, []uint64{}, Bitset(0, 0.5))
+	assert.Equal(t, []uint64{math.MaxUint64, 0x7}, Bitset(67, 1))
+	assert.Equal(t, []uint64{0, 0}, Bitset(67, 0))
+
+	// p = 0.25 is 0.01b, random bits 1|00|01|01|00 give 0, 1, 0, 0, 1
+	src = bytes.NewBuffer([]byte{0x8a, 0x00})
+	assert.Equal(t, []uint64{0x12}, ReadBitset(src, 5, 0.25))
+
+	g := NewDRBG([]byte("bitset"))
+	const n = 64000
+	for _, p := range []float64{0.5, 0.1, 0.9} {
+		ones := 0
+		for _, w := range ReadBitset(g, n, p) {
+			ones += bits.OnesCount64(w)
+		}
+		assert.InDelta(t, p*n, ones, 5*math.Sqrt(n*p*(1-p)), "p = %g", p)
+	}
+
+	assert.Panics(t, func() { Bitset(-1, 0.5) })
+	assert.Panics(t, func() { Bitset(1, 1.5) })
+	assert.Panics(t, func() { Bitset(1, math.NaN()) })
+}
+
+func TestReadBits(t *testing.T) {
+	src := bytes.NewBuffer([]byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, 0x03, 0, 0, 0, 0, 0, 0, 0})
+	assert.Equal(t, "3000000000000ffff", ReadBits(src, 66).Text(16))
+	assert.Equal(t, 0, Bits(0).Sign())
+	assert.True(t, Bits(100).BitLen() <= 100)
+}
@@ -0,0 +1,126 @@
+// Package blockhash derives draw entropy from finalized blockchain block
+// hashes combined with a committed server seed.
+//
+// Block hashes are public and unpredictable in advance, but not unbiasable: a
+// block producer can withhold a block whose hash it dislikes, and a reorg can
+// replace a recent block. The package therefore never uses a block hash
+// alone:
+//
+//   - Commit publishes the server seed hash and binds the draw to a block
+//     number that does not exist yet, so neither party knows the hash when
+//     committing.
+//   - Resolve only accepts the block once it has the configured number of
+//     confirmations.
+//   - The draw stream is rng.FairSource keyed by the server seed with the
+//     block number and hash as client seed, so a block producer not knowing
+//     the seed can not predict or steer outcomes.
+//
+// Anyone can recompute a draw with Verify once the server seed is revealed.
+package blockhash
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/advbet/rng"
+)
+
+var (
+	// ErrNotFinal is returned by Resolve when the committed block does not
+	// have enough confirmations yet.
+	ErrNotFinal = errors.New("blockhash: block is not final yet")
+	// ErrSeedMismatch is returned when a server seed does not match the
+	// committed hash.
+	ErrSeedMismatch = errors.New("blockhash: server seed does not match commitment")
+)
+
+// Block is a chain block.
+type Block struct {
+	Number uint64 `json:"number"`
+	Hash   []byte `json:"hash"`
+}
+
+// Chain reads blocks of a chain, see RPC for an Ethereum JSON-RPC
+// implementation.
+type Chain interface {
+	// Head returns the number of the latest block.
+	Head(ctx context.Context) (uint64, error)
+	// Block returns a block by number.
+	Block(ctx context.Context, number uint64) (Block, error)
+}
+
+// Commitment binds a draw to a server seed and a future block. It is
+// published before the block is produced.
+type Commitment struct {
+	ServerSeedHash []byte `json:"server_seed_hash"`
+	Block          uint64 `json:"block"`
+	// Confirmations is the number of blocks that must follow Block before
+	// it is used.
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// Commit returns a commitment to serverSeed for the block delay blocks after
+// the current head. It returns an error if delay is 0, the current head block
+// may already be known to the committing party.
+func Commit(ctx context.Context, chain Chain, serverSeed []byte, delay, confirmations uint64) (Commitment, error) {
+	if delay == 0 {
+		return Commitment{}, errors.New("blockhash: delay must be at least one block")
+	}
+	head, err := chain.Head(ctx)
+	if err != nil {
+		return Commitment{}, fmt.Errorf("blockhash: %w", err)
+	}
+	return Commitment{
+		ServerSeedHash: rng.HashServerSeed(serverSeed),
+		Block:          head + delay,
+		Confirmations:  confirmations,
+	}, nil
+}
+
+// Resolve returns the draw stream of a commitment and the block it is
+// derived from. It returns ErrNotFinal until the block has c.Confirmations
+// confirmations and ErrSeedMismatch if serverSeed is not the committed one.
+func Resolve(ctx context.Context, chain Chain, c Commitment, serverSeed []byte) (io.Reader, Block, error) {
+	if !bytes.Equal(rng.HashServerSeed(serverSeed), c.ServerSeedHash) {
+		return nil, Block{}, ErrSeedMismatch
+	}
+	head, err := chain.Head(ctx)
+	if err != nil {
+		return nil, Block{}, fmt.Errorf("blockhash: %w", err)
+	}
+	if head < c.Block || head-c.Block < c.Confirmations {
+		return nil, Block{}, ErrNotFinal
+	}
+	b, err := chain.Block(ctx, c.Block)
+	if err != nil {
+		return nil, Block{}, fmt.Errorf("blockhash: %w", err)
+	}
+	if b.Number != c.Block {
+		return nil, Block{}, fmt.Errorf("blockhash: chain returned block %d, requested %d", b.Number, c.Block)
+	}
+	src, err := Verify(c, serverSeed, b)
+	return src, b, err
+}
+
+// Verify returns the draw stream of a commitment for a revealed server seed
+// and the committed block, without contacting the chain.
+func Verify(c Commitment, serverSeed []byte, b Block) (io.Reader, error) {
+	if !bytes.Equal(rng.HashServerSeed(serverSeed), c.ServerSeedHash) {
+		return nil, ErrSeedMismatch
+	}
+	if b.Number != c.Block || len(b.Hash) == 0 {
+		return nil, fmt.Errorf("blockhash: block %d does not match commitment to block %d", b.Number, c.Block)
+	}
+	return rng.FairSource(serverSeed, ClientSeed(b), 0), nil
+}
+
+// ClientSeed returns the FairSource client seed derived from a block,
+// "block:" number ":" hex(hash).
+func ClientSeed(b Block) string {
+	return "block:" + strconv.FormatUint(b.Number, 10) + ":" + hex.EncodeToString(b.Hash)
+}
@@ -0,0 +1,74 @@
+package blockhash
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain has blocks [0, head] with hashes SHA-256 of the block number.
+type fakeChain struct {
+	head uint64
+}
+
+func (c *fakeChain) Head(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func (c *fakeChain) Block(ctx context.Context, number uint64) (Block, error) {
+	if number > c.head {
+		return Block{}, errors.New("unknown block")
+	}
+	h := sha256.Sum256([]byte(fmt.Sprint(number)))
+	return Block{Number: number, Hash: h[:]}, nil
+}
+
+func TestCommitResolve(t *testing.T) {
+	ctx := context.Background()
+	chain := &fakeChain{head: 100}
+	seed := []byte("server seed")
+
+	c, err := Commit(ctx, chain, seed, 2, 6)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102), c.Block)
+	assert.Equal(t, rng.HashServerSeed(seed), c.ServerSeedHash)
+
+	for _, head := range []uint64{101, 102, 107} {
+		chain.head = head
+		_, _, err = Resolve(ctx, chain, c, seed)
+		assert.Equal(t, ErrNotFinal, err, "head %d", head)
+	}
+
+	chain.head = 108
+	src, b, err := Resolve(ctx, chain, c, seed)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102), b.Number)
+	draw := rng.ReadPerm(src, 10)
+
+	// verification needs only the revealed seed and the public block
+	src, err = Verify(c, seed, b)
+	require.NoError(t, err)
+	assert.Equal(t, draw, rng.ReadPerm(src, 10))
+	assert.Equal(t, draw, rng.ReadPerm(rng.FairSource(seed, ClientSeed(b), 0), 10))
+
+	_, _, err = Resolve(ctx, chain, c, []byte("other seed"))
+	assert.Equal(t, ErrSeedMismatch, err)
+	_, err = Verify(c, []byte("other seed"), b)
+	assert.Equal(t, ErrSeedMismatch, err)
+	other, _ := chain.Block(ctx, 103)
+	_, err = Verify(c, seed, other)
+	assert.Error(t, err)
+
+	_, err = Commit(ctx, chain, seed, 0, 6)
+	assert.Error(t, err)
+}
+
+func TestClientSeed(t *testing.T) {
+	assert.Equal(t, "block:258:0102ff", ClientSeed(Block{Number: 258, Hash: []byte{1, 2, 255}}))
+}
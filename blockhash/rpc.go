@@ -0,0 +1,120 @@
+package blockhash
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// RPC is a Chain reading an Ethereum compatible JSON-RPC endpoint with
+// eth_blockNumber and eth_getBlockByNumber.
+type RPC struct {
+	URL    string
+	Client *http.Client
+	id     atomic.Uint64
+}
+
+var _ Chain = (*RPC)(nil)
+
+// NewRPC returns a chain client for an endpoint URL using
+// http.DefaultClient.
+func NewRPC(url string) *RPC {
+	return &RPC{URL: url, Client: http.DefaultClient}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *RPC) call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: c.id.Add(1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP status %s", method, resp.Status)
+	}
+	var r rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if r.Error != nil {
+		return fmt.Errorf("%s: error %d: %s", method, r.Error.Code, r.Error.Message)
+	}
+	if len(r.Result) == 0 || string(r.Result) == "null" {
+		return fmt.Errorf("%s: empty result", method)
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+// Head returns the latest block number.
+func (c *RPC) Head(ctx context.Context) (uint64, error) {
+	var q string
+	if err := c.call(ctx, &q, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return parseQuantity(q)
+}
+
+// Block returns a block by number.
+func (c *RPC) Block(ctx context.Context, number uint64) (Block, error) {
+	var r struct {
+		Number string `json:"number"`
+		Hash   string `json:"hash"`
+	}
+	if err := c.call(ctx, &r, "eth_getBlockByNumber", "0x"+strconv.FormatUint(number, 16), false); err != nil {
+		return Block{}, err
+	}
+	n, err := parseQuantity(r.Number)
+	if err != nil {
+		return Block{}, err
+	}
+	hash, err := hex.DecodeString(strings.TrimPrefix(r.Hash, "0x"))
+	if err != nil || len(hash) != 32 {
+		return Block{}, fmt.Errorf("invalid block hash %q", r.Hash)
+	}
+	return Block{Number: n, Hash: hash}, nil
+}
+
+// parseQuantity parses a 0x prefixed hex quantity.
+func parseQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	n, err := strconv.ParseUint(s[2:], 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	return n, nil
+}
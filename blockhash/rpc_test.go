@@ -0,0 +1,77 @@
+package blockhash
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRPC(t *testing.T) {
+	hash := "0x" + strings.Repeat("ab", 32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "2.0", req.JSONRPC)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_blockNumber":
+			resp["result"] = "0x1b4"
+		case "eth_getBlockByNumber":
+			assert.Equal(t, []interface{}{"0x1ae", false}, req.Params)
+			resp["result"] = map[string]string{"number": "0x1ae", "hash": hash}
+		default:
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewRPC(srv.URL)
+	head, err := c.Head(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(436), head)
+
+	b, err := c.Block(ctx, 430)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(430), b.Number)
+	assert.Equal(t, strings.Repeat("\xab", 32), string(b.Hash))
+
+	err = c.call(ctx, new(string), "eth_unknown")
+	assert.EqualError(t, err, "eth_unknown: error -32601: method not found")
+}
+
+func TestRPCErrors(t *testing.T) {
+	var result string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if result == "" {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(result))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewRPC(srv.URL)
+	_, err := c.Head(ctx)
+	assert.EqualError(t, err, "eth_blockNumber: HTTP status 502 Bad Gateway")
+
+	result = `{"jsonrpc":"2.0","id":1,"result":null}`
+	_, err = c.Block(ctx, 1)
+	assert.EqualError(t, err, "eth_getBlockByNumber: empty result")
+
+	result = `{"jsonrpc":"2.0","id":1,"result":"1b4"}`
+	_, err = c.Head(ctx)
+	assert.EqualError(t, err, `invalid quantity "1b4"`)
+
+	result = `{"jsonrpc":"2.0","id":1,"result":{"number":"0x1","hash":"0x1234"}}`
+	_, err = c.Block(ctx, 1)
+	assert.EqualError(t, err, `invalid block hash "0x1234"`)
+}
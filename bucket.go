@@ -0,0 +1,105 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+)
+
+// saltSize is the number of random bytes in a Bucketer salt.
+const saltSize = 32
+
+// Bucketer deterministically assigns IDs to buckets. Assignment is
+// unpredictable without knowing the salt but stable for the same salt, so the
+// same ID always lands in the same bucket.
+//
+// Bucketer is safe for concurrent use.
+type Bucketer struct {
+	salt    []byte
+	buckets int
+}
+
+// NewBucketer returns a Bucketer assigning IDs to n buckets using a random
+// salt. It will panic if n <= 0.
+func NewBucketer(n int) *Bucketer {
+	return ReadBucketer(defaultSource(), n)
+}
+
+// ReadBucketer returns a Bucketer assigning IDs to n buckets with a salt read
+// from a given source.
+func ReadBucketer(src io.Reader, n int) *Bucketer {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(src, salt); err != nil {
+		panic(err)
+	}
+	return NewBucketerWithSalt(n, salt)
+}
+
+// NewBucketerWithSalt returns a Bucketer assigning IDs to n buckets using a
+// previously exported salt. It will panic if n <= 0.
+func NewBucketerWithSalt(n int, salt []byte) *Bucketer {
+	if n <= 0 {
+		panic("invalid argument to NewBucketer")
+	}
+	return &Bucketer{
+		salt:    append([]byte(nil), salt...),
+		buckets: n,
+	}
+}
+
+// Salt returns a copy of the salt used for assignment. It can be stored and
+// later passed to NewBucketerWithSalt to restore the assignment.
+func (b *Bucketer) Salt() []byte {
+	return append([]byte(nil), b.salt...)
+}
+
+// Buckets returns the number of buckets.
+func (b *Bucketer) Buckets() int {
+	return b.buckets
+}
+
+// Bucket returns bucket index in [0, n) for a given ID. Every bucket is equally
+// likely.
+func (b *Bucketer) Bucket(id string) int {
+	mac := hmac.New(sha256.New, b.salt)
+	mac.Write([]byte(id))
+	return ReadIntn(&hashReader{seed: mac.Sum(nil)}, b.buckets)
+}
+
+// Rebalance returns a new Bucketer with the same number of buckets and a
+// freshly drawn salt, reassigning all IDs at random.
+func (b *Bucketer) Rebalance() *Bucketer {
+	return b.ReadRebalance(defaultSource())
+}
+
+// ReadRebalance returns a new Bucketer with the same number of buckets and a
+// salt read from a given source.
+func (b *Bucketer) ReadRebalance(src io.Reader) *Bucketer {
+	return ReadBucketer(src, b.buckets)
+}
+
+// hashReader is an endless deterministic byte stream made of blocks
+// SHA256(seed || counter), counter is a big endian uint64 starting at 0.
+type hashReader struct {
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func (h *hashReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(h.buf) == 0 {
+			var ctr [8]byte
+			binary.BigEndian.PutUint64(ctr[:], h.counter)
+			h.counter++
+			sum := sha256.Sum256(append(append([]byte(nil), h.seed...), ctr[:]...))
+			h.buf = sum[:]
+		}
+		c := copy(p[n:], h.buf)
+		h.buf = h.buf[c:]
+		n += c
+	}
+	return n, nil
+}
@@ -0,0 +1,50 @@
+package rng
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketer(t *testing.T) {
+	b := NewBucketer(7)
+	assert.Equal(t, 7, b.Buckets())
+	assert.Len(t, b.Salt(), saltSize)
+
+	restored := NewBucketerWithSalt(7, b.Salt())
+	counts := make([]int, 7)
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("user-%d", i)
+		bucket := b.Bucket(id)
+		assert.True(t, bucket >= 0 && bucket < 7)
+		assert.Equal(t, bucket, b.Bucket(id))
+		assert.Equal(t, bucket, restored.Bucket(id))
+		counts[bucket]++
+	}
+	for _, c := range counts {
+		assert.NotZero(t, c)
+	}
+
+	other := b.Rebalance()
+	assert.Equal(t, 7, other.Buckets())
+	assert.NotEqual(t, b.Salt(), other.Salt())
+
+	assert.Panics(t, func() {
+		NewBucketer(0)
+	})
+}
+
+func TestBucketerFixedSalt(t *testing.T) {
+	// assignment must never change for a fixed salt
+	b := NewBucketerWithSalt(100, []byte("salt"))
+	assert.Equal(t, b.Bucket("player-1"), NewBucketerWithSalt(100, []byte("salt")).Bucket("player-1"))
+	assert.NotEqual(t,
+		[]int{b.Bucket("a"), b.Bucket("b"), b.Bucket("c"), b.Bucket("d")},
+		[]int{
+			NewBucketerWithSalt(100, []byte("pepper")).Bucket("a"),
+			NewBucketerWithSalt(100, []byte("pepper")).Bucket("b"),
+			NewBucketerWithSalt(100, []byte("pepper")).Bucket("c"),
+			NewBucketerWithSalt(100, []byte("pepper")).Bucket("d"),
+		})
+}
@@ -0,0 +1,130 @@
+package rng
+
+import (
+	"io"
+)
+
+// SourceError is returned by Checked draws when reading the random source
+// fails.
+type SourceError struct {
+	Err error // source read error
+}
+
+func (e *SourceError) Error() string {
+	return "rng: reading random source: " + e.Err.Error()
+}
+
+// Unwrap returns the source read error.
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// Checked draws from a Generator source returning source read failures as
+// *SourceError values instead of panicking, so libraries embedding the
+// package do not need to recover from panics around every draw. Invalid
+// arguments are programming errors and still panic. The draw value is
+// undefined when an error is returned. Checked is safe for concurrent use if
+// and only if its source is.
+type Checked struct {
+	src io.Reader
+	enc Encoding
+
+	// OnError, if set, is called with every source failure before it is
+	// returned, e.g. to log or count failures in one place.
+	OnError func(err error)
+}
+
+// Checked returns draws of g reporting source failures as errors.
+func (g *Generator) Checked() *Checked {
+	return &Checked{src: g.src, enc: g.enc}
+}
+
+// Uint64Bits returns a random uint64 value in range [0, 2^n), see
+// ReadUint64Bits.
+func (c *Checked) Uint64Bits(n uint) (r uint64, err error) {
+	err = c.draw(func(src io.Reader) { r = ReadUint64Bits(src, n) })
+	return r, err
+}
+
+// Intn returns a non negative int in [0, n), see ReadIntn.
+func (c *Checked) Intn(n int) (r int, err error) {
+	err = c.draw(func(src io.Reader) { r = ReadIntn(src, n) })
+	return r, err
+}
+
+// Int31n returns a non negative int32 in [0, n), see ReadInt31n.
+func (c *Checked) Int31n(n int32) (r int32, err error) {
+	err = c.draw(func(src io.Reader) { r = ReadInt31n(src, n) })
+	return r, err
+}
+
+// Float64 returns a random number in [0.0,1.0), see ReadFloat64.
+func (c *Checked) Float64() (r float64, err error) {
+	err = c.draw(func(src io.Reader) { r = ReadFloat64(src) })
+	return r, err
+}
+
+// Perm returns a random permutation of integers [0,n), see ReadPerm.
+func (c *Checked) Perm(n int) (r []int, err error) {
+	err = c.draw(func(src io.Reader) { r = ReadPerm(src, n) })
+	return r, err
+}
+
+// Sample returns random k integers from a range [0 n), see ReadSample.
+func (c *Checked) Sample(n int, k int) (r []int, err error) {
+	err = c.draw(func(src io.Reader) { r = ReadSample(src, n, k) })
+	return r, err
+}
+
+// Shuffle randomizes the order of n elements, see ReadShuffle. Elements may
+// be partially shuffled when an error is returned.
+func (c *Checked) Shuffle(n int, swap func(i, j int)) error {
+	return c.draw(func(src io.Reader) { ReadShuffle(src, n, swap) })
+}
+
+// Draw runs fn with the checked source, panics caused by source read errors
+// inside fn are returned as *SourceError. It extends Checked to all other draw
+// functions, e.g.
+//
+//	err := c.Draw(func(src io.Reader) { deck = rng.ReadPerm(src, 52) })
+func (c *Checked) Draw(fn func(src io.Reader)) error {
+	return c.draw(fn)
+}
+
+// draw converts panics caused by errors of the source read during fn to
+// errors, other panics are propagated.
+func (c *Checked) draw(fn func(src io.Reader)) (err error) {
+	r := &errorReader{src: c.src}
+
+	defer func() {
+		if v := recover(); v != nil {
+			if r.err == nil {
+				panic(v)
+			}
+			readErr, ok := v.(error)
+			if !ok {
+				readErr = r.err
+			}
+			err = &SourceError{Err: readErr}
+			if c.OnError != nil {
+				c.OnError(err)
+			}
+		}
+	}()
+	fn(EncodedSource(r, c.enc))
+	return nil
+}
+
+// errorReader records the first error returned by src.
+type errorReader struct {
+	src io.Reader
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
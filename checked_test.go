@@ -0,0 +1,76 @@
+package rng
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecked(t *testing.T) {
+	c := New(NewDRBG([]byte("checked"))).Checked()
+	expected := New(NewDRBG([]byte("checked")))
+
+	n, err := c.Intn(10)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Intn(10), n)
+	p, err := c.Perm(5)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Perm(5), p)
+	f, err := c.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Float64(), f)
+
+	// invalid arguments still panic
+	assert.Panics(t, func() { c.Intn(0) })
+}
+
+func TestCheckedSourceError(t *testing.T) {
+	var reported []error
+	c := New(bytes.NewReader([]byte{1, 2})).Checked()
+	c.OnError = func(err error) { reported = append(reported, err) }
+
+	_, err := c.Uint64Bits(16)
+	assert.NoError(t, err)
+	_, err = c.Uint64Bits(16)
+	var se *SourceError
+	assert.True(t, errors.As(err, &se))
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, "rng: reading random source: EOF", err.Error())
+	assert.Equal(t, []error{err}, reported)
+
+	_, err = c.Sample(10, 3)
+	assert.Error(t, err)
+	_, err = c.Int31n(7)
+	assert.Error(t, err)
+	assert.Error(t, c.Shuffle(3, func(i, j int) {}))
+	assert.NoError(t, c.Shuffle(1, func(i, j int) {}))
+	assert.Len(t, reported, 4)
+
+	// partial reads surface the io.ReadFull error
+	c = New(bytes.NewReader([]byte{1})).Checked()
+	_, err = c.Uint64Bits(16)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	// Draw extends checking to any draw function
+	c = New(NewDRBG(nil)).Checked()
+	assert.NoError(t, c.Draw(func(src io.Reader) { ReadCycle(src, 52) }))
+	c = New(bytes.NewReader(nil)).Checked()
+	assert.Error(t, c.Draw(func(src io.Reader) { ReadCycle(src, 52) }))
+
+	// panics unrelated to the source are propagated
+	assert.PanicsWithValue(t, "boom", func() {
+		c.Draw(func(io.Reader) { panic("boom") })
+	})
+}
+
+func TestCheckedEncoding(t *testing.T) {
+	c := New(bytes.NewReader([]byte{0x12, 0x34})).WithEncoding(Encoding{BigEndian: true}).Checked()
+	r, err := c.Uint64Bits(16)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x1234), r)
+	_, err = c.Uint64Bits(8)
+	assert.True(t, errors.Is(err, io.EOF))
+}
@@ -0,0 +1,66 @@
+package rng
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// MinSaltSize is the minimum salt length accepted by commitment functions.
+const MinSaltSize = 16
+
+// commitDomain separates commitments from other uses of the same hash.
+const commitDomain = "advbet/rng commitment v1"
+
+// Commit returns a SHA-256 commitment to secret (e.g. a server seed) that can
+// be published before the secret is revealed. It hashes
+//
+//	"advbet/rng commitment v1" 0x00 len(salt) salt secret
+//
+// where len(salt) is a big endian uint64. A random salt of at least
+// MinSaltSize bytes prevents precomputed guesses of low entropy secrets. It
+// panics if the salt is shorter.
+func Commit(secret, salt []byte) []byte {
+	return commit(sha256.New(), secret, salt)
+}
+
+// VerifyCommitment reports whether commitment was returned by Commit for a
+// given secret and salt.
+func VerifyCommitment(commitment, secret, salt []byte) bool {
+	return verifyCommitment(sha256.New(), commitment, secret, salt)
+}
+
+// CommitSHA3 is Commit using SHA3-256.
+func CommitSHA3(secret, salt []byte) []byte {
+	return commit(sha3.New256(), secret, salt)
+}
+
+// VerifyCommitmentSHA3 reports whether commitment was returned by CommitSHA3
+// for a given secret and salt.
+func VerifyCommitmentSHA3(commitment, secret, salt []byte) bool {
+	return verifyCommitment(sha3.New256(), commitment, secret, salt)
+}
+
+func commit(h hash.Hash, secret, salt []byte) []byte {
+	if len(salt) < MinSaltSize {
+		panic("invalid argument to Commit")
+	}
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(salt)))
+	h.Write([]byte(commitDomain))
+	h.Write([]byte{0})
+	h.Write(n[:])
+	h.Write(salt)
+	h.Write(secret)
+	return h.Sum(nil)
+}
+
+func verifyCommitment(h hash.Hash, commitment, secret, salt []byte) bool {
+	if len(salt) < MinSaltSize {
+		return false
+	}
+	return subtle.ConstantTimeCompare(commitment, commit(h, secret, salt)) == 1
+}
@@ -0,0 +1,40 @@
+package rng
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommit(t *testing.T) {
+	secret := []byte("server seed")
+	salt := []byte("0123456789abcdef")
+
+	c := Commit(secret, salt)
+	assert.Len(t, c, 32)
+	assert.True(t, VerifyCommitment(c, secret, salt))
+	assert.False(t, VerifyCommitment(c, []byte("server seee"), salt))
+	assert.False(t, VerifyCommitment(c, secret, []byte("0123456789abcdeF")))
+	// salt and secret boundary is fixed by the salt length prefix
+	assert.False(t, VerifyCommitment(c, []byte("f"+string(secret)), salt[:15]))
+
+	// commitment differs from a plain hash of the secret
+	assert.NotEqual(t, HashServerSeed(secret), c)
+
+	c3 := CommitSHA3(secret, salt)
+	assert.Len(t, c3, 32)
+	assert.NotEqual(t, c, c3)
+	assert.True(t, VerifyCommitmentSHA3(c3, secret, salt))
+	assert.False(t, VerifyCommitmentSHA3(c, secret, salt))
+
+	assert.Panics(t, func() { Commit(secret, nil) })
+}
+
+func TestCommitVector(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	assert.Equal(t, "148b9ffa41175d4224513c98ef562693f3b78b7648c43126463f38191cc25bae",
+		hex.EncodeToString(Commit([]byte("secret"), salt)))
+	assert.Equal(t, "b1a6bb323ba43226837cdfa3a6da9f9b0c213a34ae4094d886f9cfed02c07f1f",
+		hex.EncodeToString(CommitSHA3([]byte("secret"), salt)))
+}
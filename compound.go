@@ -0,0 +1,99 @@
+package rng
+
+import (
+	"io"
+	"math"
+)
+
+// Number is the set of types CompoundSum can add up.
+type Number interface {
+	~int | ~int32 | ~int64 | ~float32 | ~float64
+}
+
+// CompoundSum draws a count N and returns the sum of N amounts, see
+// ReadCompoundSum.
+func CompoundSum[T Number](count func(src io.Reader) int, amount func(src io.Reader) T) T {
+	return ReadCompoundSum(defaultSource(), count, amount)
+}
+
+// ReadCompoundSum draws a count N with count and returns the sum of N
+// independent draws of amount, both reading randomness from a given source.
+// It models aggregate quantities such as the total payout of a random number
+// of winning bets, e.g. with count from PoissonCount. It will panic if count
+// returns a negative number.
+func ReadCompoundSum[T Number](src io.Reader, count func(src io.Reader) int, amount func(src io.Reader) T) T {
+	n := count(src)
+	if n < 0 {
+		panic("invalid argument to CompoundSum, negative count")
+	}
+	var sum T
+	for i := 0; i < n; i++ {
+		sum += amount(src)
+	}
+	return sum
+}
+
+// CompoundSums returns n independent compound sums, see ReadCompoundSums.
+func CompoundSums[T Number](count func(src io.Reader) int, amount func(src io.Reader) T, n int) []T {
+	return ReadCompoundSums(defaultSource(), count, amount, n)
+}
+
+// ReadCompoundSums returns n independent compound sums reading randomness
+// from a given source, e.g. the liability of n simulated match days in a
+// Monte Carlo run. It will panic if n < 0.
+func ReadCompoundSums[T Number](src io.Reader, count func(src io.Reader) int, amount func(src io.Reader) T, n int) []T {
+	if n < 0 {
+		panic("invalid argument to CompoundSums")
+	}
+	sums := make([]T, n)
+	for i := range sums {
+		sums[i] = ReadCompoundSum(src, count, amount)
+	}
+	return sums
+}
+
+// PoissonCount returns a count distribution for CompoundSum drawing Poisson
+// distributed counts with a given mean, see ReadPoisson.
+func PoissonCount(mean float64) func(src io.Reader) int {
+	if !(mean >= 0) || math.IsInf(mean, 0) {
+		panic("invalid argument to PoissonCount")
+	}
+	return func(src io.Reader) int {
+		return ReadPoisson(src, mean)
+	}
+}
+
+// Poisson returns a Poisson distributed count with a given mean, see
+// ReadPoisson.
+func Poisson(mean float64) int {
+	return ReadPoisson(defaultSource(), mean)
+}
+
+// maxPoissonStep keeps exp(-mean) of a single inversion step well above
+// float64 underflow.
+const maxPoissonStep = 500
+
+// ReadPoisson returns a Poisson distributed count with a given mean reading
+// randomness from a given source. It inverts the CDF, large means are split
+// into a sum of smaller independent Poisson counts, so running time grows
+// linearly with the mean. It will panic if mean is negative or not finite.
+func ReadPoisson(src io.Reader, mean float64) int {
+	if !(mean >= 0) || math.IsInf(mean, 0) {
+		panic("invalid argument to Poisson")
+	}
+	sum := 0
+	for mean > maxPoissonStep {
+		sum += ReadPoisson(src, maxPoissonStep)
+		mean -= maxPoissonStep
+	}
+	u := ReadFloat64(src)
+	k := 0
+	p := math.Exp(-mean)
+	cdf := p
+	for u >= cdf && p > 0 {
+		k++
+		p *= mean / float64(k)
+		cdf += p
+	}
+	return sum + k
+}
@@ -0,0 +1,46 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPoisson(t *testing.T) {
+	g := NewDRBG([]byte("poisson"))
+	for _, mean := range []float64{0, 3, 700} {
+		const samples = 4000
+		sum := 0
+		for i := 0; i < samples; i++ {
+			sum += ReadPoisson(g, mean)
+		}
+		assert.InDelta(t, mean, float64(sum)/samples, 5*math.Sqrt(mean/samples), "mean %g", mean)
+	}
+	assert.Panics(t, func() { Poisson(-1) })
+	assert.Panics(t, func() { PoissonCount(math.Inf(1)) })
+}
+
+func TestCompoundSum(t *testing.T) {
+	fixed := func(n int) func(io.Reader) int {
+		return func(io.Reader) int { return n }
+	}
+	stake := func(src io.Reader) int64 { return int64(ReadIntn(src, 10)) + 1 }
+
+	assert.Equal(t, int64(0), CompoundSum(fixed(0), stake))
+	assert.Panics(t, func() { CompoundSum(fixed(-1), stake) })
+
+	// E[sum] = E[N] * E[amount] = 20 * 5.5
+	sums := ReadCompoundSums(NewDRBG([]byte("compound")), PoissonCount(20), stake, 5000)
+	assert.Len(t, sums, 5000)
+	total := int64(0)
+	for _, s := range sums {
+		total += s
+	}
+	assert.InDelta(t, 110, float64(total)/5000, 1.5)
+
+	floats := CompoundSums(fixed(2), func(io.Reader) float64 { return 0.5 }, 3)
+	assert.Equal(t, []float64{1, 1, 1}, floats)
+	assert.Panics(t, func() { CompoundSums(fixed(1), stake, -1) })
+}
@@ -0,0 +1,134 @@
+package rng
+
+import "io"
+
+// DefaultShuffleTries is the number of candidate orderings tried by
+// ConstrainedShuffle when MaxTries is 0.
+const DefaultShuffleTries = 10000
+
+// ShuffleConstraint is a rule a ConstrainedShuffle ordering must satisfy.
+// Orderings are permutations of item indexes, order[i] is the item at
+// position i.
+type ShuffleConstraint interface {
+	Allows(order []int) bool
+}
+
+// ConstraintFunc adapts an ordinary function to the ShuffleConstraint
+// interface.
+type ConstraintFunc func(order []int) bool
+
+// Allows calls f(order).
+func (f ConstraintFunc) Allows(order []int) bool {
+	return f(order)
+}
+
+// NotInFirst forbids Item in the first K positions.
+type NotInFirst struct {
+	Item int
+	K    int
+}
+
+// Allows reports whether Item is not in order[:K].
+func (c NotInFirst) Allows(order []int) bool {
+	for i := 0; i < c.K && i < len(order); i++ {
+		if order[i] == c.Item {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxAdjacent allows at most Max items of a category next to each other.
+type MaxAdjacent struct {
+	// Items lists item indexes of the category.
+	Items []int
+	Max   int
+}
+
+// Allows reports whether no run of category items is longer than Max.
+func (c MaxAdjacent) Allows(order []int) bool {
+	in := make(map[int]bool, len(c.Items))
+	for _, it := range c.Items {
+		in[it] = true
+	}
+	run := 0
+	for _, it := range order {
+		if !in[it] {
+			run = 0
+			continue
+		}
+		if run++; run > c.Max {
+			return false
+		}
+	}
+	return true
+}
+
+// ConstrainedShuffle randomizes the order of items subject to constraints,
+// e.g. for randomized but compliant content ordering. Candidate orderings are
+// drawn and rejected until one satisfies all constraints, so the result is
+// distributed exactly as the unconstrained shuffle conditioned on the
+// constraints: uniform over all allowed orderings without weights.
+//
+// Rejection is efficient while a fair share of orderings is allowed. Tight
+// constraints may exhaust MaxTries, Stats reports the acceptance rate.
+//
+// ConstrainedShuffle is not safe for concurrent use.
+type ConstrainedShuffle struct {
+	// Weights, if set, draws candidates with WeightedPerm instead of a
+	// uniform permutation, heavier items tend to come first.
+	Weights     []float64
+	Constraints []ShuffleConstraint
+	// MaxTries limits candidates per ordering, DefaultShuffleTries if 0.
+	MaxTries int
+
+	proposals uint64
+	accepted  uint64
+}
+
+// Perm returns an allowed ordering of n items.
+func (s *ConstrainedShuffle) Perm(n int) ([]int, error) {
+	return s.ReadPerm(defaultSource(), n)
+}
+
+// ReadPerm returns an allowed ordering of n items reading randomness from a
+// given source. It returns ErrTooManyRejections if no allowed ordering was
+// found within MaxTries candidates. It will panic if Weights is set and its
+// length is not n.
+func (s *ConstrainedShuffle) ReadPerm(src io.Reader, n int) ([]int, error) {
+	if s.Weights != nil && len(s.Weights) != n {
+		panic("invalid argument to ConstrainedShuffle.Perm, weights length differs from n")
+	}
+	tries := s.MaxTries
+	if tries <= 0 {
+		tries = DefaultShuffleTries
+	}
+	for ; tries > 0; tries-- {
+		var order []int
+		if s.Weights != nil {
+			order = ReadWeightedPerm(src, s.Weights)
+		} else {
+			order = ReadPerm(src, n)
+		}
+		s.proposals++
+		if s.allows(order) {
+			s.accepted++
+			return order, nil
+		}
+	}
+	return nil, ErrTooManyRejections
+}
+
+func (s *ConstrainedShuffle) allows(order []int) bool {
+	for _, c := range s.Constraints {
+		if !c.Allows(order) {
+			return false
+		}
+	}
+	return true
+}
+
+// Stats returns the total number of candidate orderings drawn and accepted.
+func (s *ConstrainedShuffle) Stats() (proposals, accepted uint64) {
+	return s.proposals, s.accepted
+}
@@ -0,0 +1,95 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffleConstraints(t *testing.T) {
+	c := NotInFirst{Item: 2, K: 2}
+	assert.True(t, c.Allows([]int{0, 1, 2}))
+	assert.False(t, c.Allows([]int{0, 2, 1}))
+	assert.False(t, NotInFirst{Item: 0, K: 5}.Allows([]int{1, 0}))
+
+	a := MaxAdjacent{Items: []int{1, 2, 3}, Max: 2}
+	assert.True(t, a.Allows([]int{1, 2, 0, 3, 4}))
+	assert.False(t, a.Allows([]int{0, 3, 1, 2, 4}))
+	assert.True(t, MaxAdjacent{Items: []int{1}, Max: 0}.Allows([]int{0, 2}))
+}
+
+func TestConstrainedShuffle(t *testing.T) {
+	s := &ConstrainedShuffle{Constraints: []ShuffleConstraint{
+		NotInFirst{Item: 0, K: 3},
+		MaxAdjacent{Items: []int{1, 2, 3}, Max: 2},
+		ConstraintFunc(func(order []int) bool { return order[len(order)-1] != 5 }),
+	}}
+	src := NewDRBG([]byte("constrained"))
+	counts := make(map[[6]int]int)
+	for i := 0; i < 20000; i++ {
+		order, err := s.ReadPerm(src, 6)
+		require.NoError(t, err)
+		assert.True(t, s.allows(order))
+		counts[[6]int(order)]++
+	}
+
+	// uniform over allowed orderings
+	allowed := 0
+	var perm func(order []int, used int)
+	perm = func(order []int, used int) {
+		if len(order) == 6 {
+			if s.allows(order) {
+				allowed++
+			}
+			return
+		}
+		for i := 0; i < 6; i++ {
+			if used&(1<<i) == 0 {
+				perm(append(order, i), used|1<<i)
+			}
+		}
+	}
+	perm(nil, 0)
+	assert.Len(t, counts, allowed)
+	for _, c := range counts {
+		assert.InDelta(t, 20000/float64(allowed), c, 40)
+	}
+
+	proposals, accepted := s.Stats()
+	assert.Equal(t, uint64(20000), accepted)
+	assert.InDelta(t, float64(allowed)/720, float64(accepted)/float64(proposals), 0.01)
+}
+
+func TestConstrainedShuffleWeighted(t *testing.T) {
+	s := &ConstrainedShuffle{
+		Weights:     []float64{100, 1, 1},
+		Constraints: []ShuffleConstraint{NotInFirst{Item: 0, K: 1}},
+	}
+	src := NewDRBG([]byte("weighted"))
+	second := 0
+	for i := 0; i < 1000; i++ {
+		order, err := s.ReadPerm(src, 3)
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, order[0])
+		if order[1] == 0 {
+			second++
+		}
+	}
+	// heavy item is pushed to the first allowed position
+	assert.True(t, second > 950)
+
+	assert.Panics(t, func() { s.ReadPerm(src, 4) })
+}
+
+func TestConstrainedShuffleImpossible(t *testing.T) {
+	s := &ConstrainedShuffle{
+		Constraints: []ShuffleConstraint{NotInFirst{Item: 0, K: 3}},
+		MaxTries:    50,
+	}
+	_, err := s.ReadPerm(NewDRBG(nil), 3)
+	assert.Equal(t, ErrTooManyRejections, err)
+	proposals, accepted := s.Stats()
+	assert.Equal(t, uint64(50), proposals)
+	assert.Zero(t, accepted)
+}
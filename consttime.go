@@ -0,0 +1,42 @@
+package rng
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+)
+
+// IntnConstantTime returns a non negative int in [0, n) with a fixed amount
+// of work, see ReadIntnConstantTime.
+func IntnConstantTime(n int) int {
+	return ReadIntnConstantTime(defaultSource(), n)
+}
+
+// ReadIntnConstantTime returns a non negative int in [0, n) reading
+// randomness from a given source, for contexts where the timing of a draw
+// must not reveal the drawn value or the range.
+//
+// Unlike ReadIntn it has no data dependent rejection loop. It always reads 16
+// bytes, a 128-bit little endian fraction r, and returns floor(r * n / 2^128)
+// computed with branch free multiplications. The result is not exactly
+// uniform: the probability of any value differs from 1/n by less than 2^-128,
+// far below what any number of draws can detect. Timing is constant only as
+// far as the source read and math/bits.Mul64 are. It will panic if n <= 0.
+func ReadIntnConstantTime(src io.Reader, n int) int {
+	if n <= 0 {
+		panic("invalid argument to IntnConstantTime")
+	}
+	var b [16]byte
+	if _, err := io.ReadFull(src, b[:]); err != nil {
+		panic(err)
+	}
+	lo := binary.LittleEndian.Uint64(b[:8])
+	hi := binary.LittleEndian.Uint64(b[8:])
+
+	// (hi*2^64 + lo) * n / 2^128 is the high word of hi*n plus the carry of
+	// adding the high word of lo*n to the low word of hi*n
+	h1, l1 := bits.Mul64(hi, uint64(n))
+	h0, _ := bits.Mul64(lo, uint64(n))
+	_, carry := bits.Add64(l1, h0, 0)
+	return int(h1 + carry)
+}
@@ -0,0 +1,47 @@
+package rng
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntnConstantTime(t *testing.T) {
+	assert.Panics(t, func() { IntnConstantTime(0) })
+	assert.Panics(t, func() { IntnConstantTime(-1) })
+	assert.Equal(t, 0, IntnConstantTime(1))
+
+	ones := bytes.Repeat([]byte{0xff}, 16)
+	for _, n := range []int{1, 6, 1000, math.MaxInt} {
+		assert.Equal(t, 0, ReadIntnConstantTime(bytes.NewReader(make([]byte, 16)), n))
+		assert.Equal(t, n-1, ReadIntnConstantTime(bytes.NewReader(ones), n))
+	}
+
+	// r = 1/2 maps to n/2
+	half := make([]byte, 16)
+	half[15] = 0x80
+	assert.Equal(t, 3, ReadIntnConstantTime(bytes.NewReader(half), 6))
+	// r = 1/3 - 2^-64/3 + 2^-65 is just above 1/3, only the carry from the
+	// low word product makes it map to 1 for n = 3
+	r := make([]byte, 16)
+	r[7] = 0x80
+	copy(r[8:], bytes.Repeat([]byte{0x55}, 8))
+	assert.Equal(t, 1, ReadIntnConstantTime(bytes.NewReader(r), 3))
+
+	// fixed number of bytes regardless of n
+	src := bytes.NewBuffer(make([]byte, 48))
+	ReadIntnConstantTime(src, 2)
+	ReadIntnConstantTime(src, math.MaxInt)
+	assert.Equal(t, 16, src.Len())
+
+	g := NewDRBG([]byte("constant time"))
+	counts := make([]int, 6)
+	for i := 0; i < 60000; i++ {
+		counts[ReadIntnConstantTime(g, 6)]++
+	}
+	for _, c := range counts {
+		assert.InDelta(t, 10000, c, 400)
+	}
+}
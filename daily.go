@@ -0,0 +1,24 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"time"
+)
+
+// DailyDraw returns the "number of the day" in [0, n) for the calendar day of
+// date in its location. The value is drawn from the stream of blocks
+//
+//	HMAC-SHA256(key = secret, message = "daily:" YYYY-MM-DD ":" counter)
+//
+// with unbiased reduction, so it is fixed for the day, unpredictable without
+// the secret and verifiable by anyone once the secret is published. Callers
+// must convert date to the promotion time zone before calling. It will panic
+// if n <= 0.
+func DailyDraw(secret []byte, date time.Time, n int) int {
+	src := &fairSource{
+		mac:    hmac.New(sha256.New, secret),
+		prefix: "daily:" + date.Format("2006-01-02") + ":",
+	}
+	return ReadIntn(src, n)
+}
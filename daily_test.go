@@ -0,0 +1,29 @@
+package rng
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyDraw(t *testing.T) {
+	secret := []byte("promo secret")
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	v := DailyDraw(secret, day, 1000)
+	assert.True(t, v >= 0 && v < 1000)
+	// same calendar day gives the same value
+	assert.Equal(t, v, DailyDraw(secret, day.Add(23*time.Hour), 1000))
+
+	// 2024-05-01 23:00 UTC is already 2024-05-02 in Vilnius
+	vilnius := time.FixedZone("EEST", 3*60*60)
+	next := DailyDraw(secret, day.AddDate(0, 0, 1), 1000)
+	assert.Equal(t, next, DailyDraw(secret, day.Add(23*time.Hour).In(vilnius), 1000))
+
+	// first HMAC block starts with df 6a, 0x6adf = 27359 is below the
+	// rejection limit 65000
+	assert.Equal(t, 359, v)
+
+	assert.Panics(t, func() { DailyDraw(secret, day, 0) })
+}
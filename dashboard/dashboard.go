@@ -0,0 +1,237 @@
+// Package dashboard serves an admin page with entropy health for on-call
+// diagnosis without a metrics stack.
+//
+// A Dashboard collects accounting scope counters, health checks, source
+// statistics of probe.Prober instances and recent anomaly events. It renders
+// them as JSON and as a simple HTML page. It is not registered automatically,
+// mount it on an internal listener only:
+//
+//	d := dashboard.New()
+//	d.AddProber(prober)
+//	d.AddHealthCheck("hsm", hsmSource.HealthCheck)
+//	prober.OnResult = d.ProbeEvents()
+//	mux.Handle(dashboard.Path, d)
+package dashboard
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/advbet/rng/probe"
+)
+
+// Path is the conventional mount path of the dashboard.
+const Path = "/debug/rng"
+
+// MaxEvents is the number of most recent events kept.
+const MaxEvents = 100
+
+// Event is an anomaly, e.g. a failed probe or a source failover.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+}
+
+// Check is the result of a health check.
+type Check struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// ScopeStats are counters of an accounting scope.
+type ScopeStats struct {
+	Name  string `json:"name"`
+	Draws int64  `json:"draws"`
+	Bits  int64  `json:"bits"`
+}
+
+// Status is the dashboard content.
+type Status struct {
+	Time    time.Time              `json:"time"`
+	Healthy bool                   `json:"healthy"`
+	Checks  []Check                `json:"checks"`
+	Scopes  []ScopeStats           `json:"scopes"`
+	Sources map[string]probe.Stats `json:"sources"`
+	// Events are listed newest first.
+	Events []Event `json:"events"`
+}
+
+// Dashboard is an http.Handler rendering Status. Responses are JSON if the
+// request has query parameter format=json or accepts application/json and
+// HTML otherwise. Dashboard is safe for concurrent use.
+type Dashboard struct {
+	mu      sync.Mutex
+	scopes  []*rng.Scope
+	probers []*probe.Prober
+	checks  map[string]func() error
+	events  []Event // ring buffer of up to MaxEvents events
+	next    int
+}
+
+// New returns an empty dashboard.
+func New() *Dashboard {
+	return &Dashboard{checks: make(map[string]func() error)}
+}
+
+// AddScope adds an accounting scope whose draw and bit counters are shown.
+func (d *Dashboard) AddScope(s *rng.Scope) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.scopes = append(d.scopes, s)
+}
+
+// AddProber adds a prober whose source statistics are shown. Its HealthCheck
+// is added as check "probe".
+func (d *Dashboard) AddProber(p *probe.Prober) {
+	d.mu.Lock()
+	d.probers = append(d.probers, p)
+	d.mu.Unlock()
+	d.AddHealthCheck("probe", func() error {
+		d.mu.Lock()
+		probers := append([]*probe.Prober(nil), d.probers...)
+		d.mu.Unlock()
+		for _, p := range probers {
+			if err := p.HealthCheck(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddHealthCheck adds a named check run on every request, e.g.
+// hsm.Source.HealthCheck. A check with the same name is replaced.
+func (d *Dashboard) AddHealthCheck(name string, check func() error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checks[name] = check
+}
+
+// Event records an anomaly event.
+func (d *Dashboard) Event(kind, message string) {
+	e := Event{Time: time.Now().UTC(), Kind: kind, Message: message}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.events) < MaxEvents {
+		d.events = append(d.events, e)
+	} else {
+		d.events[d.next%MaxEvents] = e
+	}
+	d.next++
+}
+
+// ProbeEvents returns a probe.Prober OnResult callback recording failed
+// probes as events of kind "probe".
+func (d *Dashboard) ProbeEvents() func(probe.Result) {
+	return func(r probe.Result) {
+		if r.Err != nil {
+			d.Event("probe", r.Name+": "+r.Err.Error())
+		}
+	}
+}
+
+// Status runs health checks and returns the current dashboard content.
+func (d *Dashboard) Status() Status {
+	d.mu.Lock()
+	scopes := append([]*rng.Scope(nil), d.scopes...)
+	probers := append([]*probe.Prober(nil), d.probers...)
+	checks := make(map[string]func() error, len(d.checks))
+	for name, c := range d.checks {
+		checks[name] = c
+	}
+	events := make([]Event, 0, len(d.events))
+	for i := d.next - 1; i >= 0 && i >= d.next-len(d.events); i-- {
+		events = append(events, d.events[i%MaxEvents])
+	}
+	d.mu.Unlock()
+
+	s := Status{
+		Time:    time.Now().UTC(),
+		Healthy: true,
+		Checks:  []Check{},
+		Scopes:  []ScopeStats{},
+		Sources: make(map[string]probe.Stats),
+		Events:  events,
+	}
+	// checks may be slow, they run without the lock held
+	for name, check := range checks {
+		c := Check{Name: name, OK: true}
+		if err := check(); err != nil {
+			c.OK, c.Error = false, err.Error()
+			s.Healthy = false
+		}
+		s.Checks = append(s.Checks, c)
+	}
+	sort.Slice(s.Checks, func(i, j int) bool { return s.Checks[i].Name < s.Checks[j].Name })
+	for _, sc := range scopes {
+		s.Scopes = append(s.Scopes, ScopeStats{Name: sc.Name(), Draws: sc.Draws(), Bits: sc.Bits()})
+	}
+	for _, p := range probers {
+		for name, stats := range p.Snapshot() {
+			s.Sources[name] = stats
+		}
+	}
+	return s
+}
+
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s := d.Status()
+	w.Header().Set("Cache-Control", "no-store")
+	code := http.StatusOK
+	if !s.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(s)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+	page.Execute(w, s)
+}
+
+var page = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head><title>rng</title></head>
+<body>
+<h1>rng: {{if .Healthy}}healthy{{else}}UNHEALTHY{{end}}</h1>
+<p>{{.Time.Format "2006-01-02 15:04:05 MST"}}, <a href="?format=json">json</a></p>
+<h2>Health checks</h2>
+<table border="1">
+<tr><th>check</th><th>status</th></tr>
+{{range .Checks}}<tr><td>{{.Name}}</td><td>{{if .OK}}ok{{else}}{{.Error}}{{end}}</td></tr>
+{{end}}</table>
+<h2>Sources</h2>
+<table border="1">
+<tr><th>source</th><th>probes</th><th>errors</th><th>error rate</th><th>last latency</th><th>mean latency</th><th>mean byte</th><th>last error</th></tr>
+{{range $name, $s := .Sources}}<tr><td>{{$name}}</td><td>{{$s.Probes}}</td><td>{{$s.Errors}}</td><td>{{printf "%.3f" $s.ErrorRate}}</td><td>{{$s.LastLatency}}</td><td>{{$s.MeanLatency}}</td><td>{{printf "%.2f" $s.MeanByte}}</td><td>{{$s.LastError}}</td></tr>
+{{end}}</table>
+<h2>Scopes</h2>
+<table border="1">
+<tr><th>scope</th><th>draws</th><th>bits</th></tr>
+{{range .Scopes}}<tr><td>{{.Name}}</td><td>{{.Draws}}</td><td>{{.Bits}}</td></tr>
+{{end}}</table>
+<h2>Recent events</h2>
+<table border="1">
+<tr><th>time</th><th>kind</th><th>message</th></tr>
+{{range .Events}}<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{.Kind}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
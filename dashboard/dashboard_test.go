@@ -0,0 +1,107 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/advbet/rng/probe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("token removed")
+}
+
+func TestDashboard(t *testing.T) {
+	d := New()
+
+	scope := rng.New(rng.NewDRBG(nil)).Scope("round <1>", rng.Quota{})
+	scope.Intn(10)
+	d.AddScope(scope)
+
+	p := probe.New(time.Minute, probe.Target{Name: "drbg", Source: rng.NewDRBG(nil)})
+	p.OnResult = d.ProbeEvents()
+	d.AddProber(p)
+	p.Probe()
+
+	hsmOK := true
+	d.AddHealthCheck("hsm", func() error {
+		if !hsmOK {
+			return errors.New("no healthy session")
+		}
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path+"?format=json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var s Status
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+	assert.True(t, s.Healthy)
+	assert.Equal(t, []Check{{Name: "hsm", OK: true}, {Name: "probe", OK: true}}, s.Checks)
+	assert.Equal(t, []ScopeStats{{Name: "round <1>", Draws: 1, Bits: 8}}, s.Scopes)
+	assert.Equal(t, uint64(1), s.Sources["drbg"].Probes)
+	assert.Empty(t, s.Events)
+
+	hsmOK = false
+	d.Event("failover", "switched to fallback")
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, Path, nil)
+	req.Header.Set("Accept", "application/json")
+	d.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &s))
+	assert.False(t, s.Healthy)
+	assert.Equal(t, "no healthy session", s.Checks[0].Error)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "failover", s.Events[0].Kind)
+
+	rec = httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "UNHEALTHY")
+	assert.Contains(t, body, "no healthy session")
+	assert.Contains(t, body, "round &lt;1&gt;")
+	assert.Contains(t, body, "switched to fallback")
+
+	rec = httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestProbeEvents(t *testing.T) {
+	d := New()
+	p := probe.New(time.Minute, probe.Target{Name: "hsm", Source: errReader{}})
+	p.OnResult = d.ProbeEvents()
+	d.AddProber(p)
+	p.Probe()
+
+	s := d.Status()
+	assert.False(t, s.Healthy)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, Event{Time: s.Events[0].Time, Kind: "probe", Message: "hsm: token removed"}, s.Events[0])
+}
+
+func TestEventsRing(t *testing.T) {
+	d := New()
+	for i := 0; i < MaxEvents+5; i++ {
+		d.Event("test", fmt.Sprint(i))
+	}
+	events := d.Status().Events
+	require.Len(t, events, MaxEvents)
+	assert.Equal(t, fmt.Sprint(MaxEvents+4), events[0].Message)
+	assert.Equal(t, "5", events[MaxEvents-1].Message)
+	assert.Equal(t, fmt.Sprint(MaxEvents+3), events[1].Message)
+}
@@ -0,0 +1,143 @@
+package rng
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CardsPerDeck is the number of cards of a standard deck. Card indexes are in
+// [0, CardsPerDeck), see outcome.Cards for their names.
+const CardsPerDeck = 52
+
+// deckDomain separates deck encodings from other committed secrets.
+const deckDomain = "advbet/rng deck v1"
+
+// ErrDeckMismatch is returned by VerifyDeckHash when a revealed shoe or the
+// dealt cards do not match the commitment.
+var ErrDeckMismatch = errors.New("rng: deck does not match commitment")
+
+// Shoe is a shuffled deck or multi-deck shoe of a card game round, e.g.
+// blackjack or baccarat.
+type Shoe struct {
+	// Decks is the number of standard decks in the shoe.
+	Decks int
+	// Cards holds card indexes in dealing order, every index in [0, 52)
+	// appears Decks times.
+	Cards []int
+	// Cut is the position of the cut card, it lies before Cards[Cut]. A
+	// round reaching it is finished, so its last cards may come from
+	// Cards[Cut:], and the shoe is reshuffled before the next round.
+	Cut int
+}
+
+// ShuffleShoe returns a shuffled shoe of a given number of decks, see
+// ReadShuffleShoe.
+func ShuffleShoe(decks, cut int) *Shoe {
+	return ReadShuffleShoe(defaultSource(), decks, cut)
+}
+
+// ReadShuffleShoe returns a uniformly shuffled shoe of a given number of
+// decks with the cut card at position cut reading randomness from a given
+// source. It will panic if decks <= 0 or cut is not in [0, 52*decks].
+func ReadShuffleShoe(src io.Reader, decks, cut int) *Shoe {
+	if decks <= 0 || decks > maxDecks || cut < 0 || cut > decks*CardsPerDeck {
+		panic("invalid argument to ShuffleShoe")
+	}
+	cards := ReadPerm(src, decks*CardsPerDeck)
+	for i, c := range cards {
+		cards[i] = c % CardsPerDeck
+	}
+	return &Shoe{Decks: decks, Cards: cards, Cut: cut}
+}
+
+// maxDecks keeps card counts and the canonical encoding within uint16.
+const maxDecks = 1000
+
+// Validate returns an error if the shoe does not hold exactly Decks copies of
+// every card or the cut card is outside the shoe.
+func (s *Shoe) Validate() error {
+	if s.Decks <= 0 || s.Decks > maxDecks {
+		return fmt.Errorf("rng: invalid number of decks %d", s.Decks)
+	}
+	if len(s.Cards) != s.Decks*CardsPerDeck {
+		return fmt.Errorf("rng: shoe of %d decks has %d cards", s.Decks, len(s.Cards))
+	}
+	if s.Cut < 0 || s.Cut > len(s.Cards) {
+		return fmt.Errorf("rng: cut card position %d outside the shoe", s.Cut)
+	}
+	var counts [CardsPerDeck]int
+	for _, c := range s.Cards {
+		if c < 0 || c >= CardsPerDeck {
+			return fmt.Errorf("rng: card index %d out of range [0, %d)", c, CardsPerDeck)
+		}
+		counts[c]++
+	}
+	for c, n := range counts {
+		if n != s.Decks {
+			return fmt.Errorf("rng: card %d appears %d times in a shoe of %d decks", c, n, s.Decks)
+		}
+	}
+	return nil
+}
+
+// Canonical returns the byte encoding of the shoe that is hashed by DeckHash:
+//
+//	"advbet/rng deck v1" 0x00 decks cut cards
+//
+// where decks, cut and every card are big endian uint16 values.
+func (s *Shoe) Canonical() []byte {
+	b := make([]byte, 0, len(deckDomain)+1+4+2*len(s.Cards))
+	b = append(b, deckDomain...)
+	b = append(b, 0)
+	b = binary.BigEndian.AppendUint16(b, uint16(s.Decks))
+	b = binary.BigEndian.AppendUint16(b, uint16(s.Cut))
+	for _, c := range s.Cards {
+		b = binary.BigEndian.AppendUint16(b, uint16(c))
+	}
+	return b
+}
+
+// DeckHash returns a commitment to a shoe published before the round, the
+// Commit hash of its canonical encoding with a salt. The salt hides the
+// undealt cards, without it the few cards left at the end of a round could be
+// recovered by trying all their orders. It will panic if the shoe is invalid
+// or the salt is shorter than MinSaltSize.
+func DeckHash(s *Shoe, salt []byte) []byte {
+	if err := s.Validate(); err != nil {
+		panic("invalid argument to DeckHash: " + err.Error())
+	}
+	return Commit(s.Canonical(), salt)
+}
+
+// VerifyDeckHash verifies a round after the shoe and salt are revealed: the
+// shoe must be valid and match the commitment returned by DeckHash, and
+// dealt, the cards seen by players of the round in dealing order, must be the
+// cards of the shoe from position start on. Rounds must start at or before the
+// cut card, the last one may deal past it. It returns an error wrapping
+// ErrDeckMismatch otherwise.
+func VerifyDeckHash(commitment []byte, s *Shoe, salt []byte, start int, dealt []int) error {
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDeckMismatch, err)
+	}
+	if len(salt) < MinSaltSize {
+		return fmt.Errorf("%w: salt shorter than %d bytes", ErrDeckMismatch, MinSaltSize)
+	}
+	if subtle.ConstantTimeCompare(commitment, Commit(s.Canonical(), salt)) != 1 {
+		return fmt.Errorf("%w: hash differs", ErrDeckMismatch)
+	}
+	if start < 0 || start > s.Cut {
+		return fmt.Errorf("%w: round starts at card %d, the cut card is at %d", ErrDeckMismatch, start, s.Cut)
+	}
+	if len(dealt) > len(s.Cards)-start {
+		return fmt.Errorf("%w: %d cards dealt from %d, shoe holds %d", ErrDeckMismatch, len(dealt), start, len(s.Cards))
+	}
+	for i, c := range dealt {
+		if c != s.Cards[start+i] {
+			return fmt.Errorf("%w: card %d dealt as %d, shoe holds %d", ErrDeckMismatch, start+i, c, s.Cards[start+i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package rng
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffleShoe(t *testing.T) {
+	s := ShuffleShoe(6, 260)
+	assert.Equal(t, 6, s.Decks)
+	assert.Len(t, s.Cards, 312)
+	assert.Equal(t, 260, s.Cut)
+	assert.NoError(t, s.Validate())
+
+	assert.Panics(t, func() { ShuffleShoe(0, 0) })
+	assert.Panics(t, func() { ShuffleShoe(1, -1) })
+	assert.Panics(t, func() { ShuffleShoe(1, 53) })
+}
+
+func TestShoeValidate(t *testing.T) {
+	s := sortedShoe(2, 80)
+	require.NoError(t, s.Validate())
+
+	s.Cards[0] = 1
+	assert.Error(t, s.Validate())
+	s.Cards[0] = 52
+	assert.Error(t, s.Validate())
+	assert.Error(t, (&Shoe{Decks: 1, Cards: s.Cards[:52]}).Validate())
+	assert.Error(t, (&Shoe{Decks: 0}).Validate())
+	s = sortedShoe(1, 53)
+	assert.Error(t, s.Validate())
+}
+
+func TestDeckHash(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	s := ShuffleShoe(1, 40)
+	h := DeckHash(s, salt)
+	assert.Len(t, h, 32)
+
+	dealt := append([]int(nil), s.Cards[:10]...)
+	assert.NoError(t, VerifyDeckHash(h, s, salt, 0, dealt))
+	assert.NoError(t, VerifyDeckHash(h, s, salt, 0, nil))
+	assert.NoError(t, VerifyDeckHash(h, s, salt, 0, s.Cards[:40]))
+
+	// later rounds are verified on their own, the last one reaching the
+	// cut card is finished past it
+	assert.NoError(t, VerifyDeckHash(h, s, salt, 10, s.Cards[10:20]))
+	assert.NoError(t, VerifyDeckHash(h, s, salt, 36, s.Cards[36:44]))
+	assert.NoError(t, VerifyDeckHash(h, s, salt, 40, s.Cards[40:]))
+
+	// rounds starting after the cut card or running out of cards
+	err := VerifyDeckHash(h, s, salt, 41, s.Cards[41:43])
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+	err = VerifyDeckHash(h, s, salt, -1, nil)
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+	err = VerifyDeckHash(h, s, salt, 30, append(s.Cards[30:], 0))
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+
+	// cards of another round
+	err = VerifyDeckHash(h, s, salt, 10, s.Cards[:10])
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+
+	// dealt card differs from the shoe
+	dealt[3] = (dealt[3] + 1) % CardsPerDeck
+	err = VerifyDeckHash(h, s, salt, 0, dealt)
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+
+	// cut position is committed
+	moved := &Shoe{Decks: s.Decks, Cards: s.Cards, Cut: 41}
+	err = VerifyDeckHash(h, moved, salt, 0, nil)
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+
+	// swapped cards
+	swapped := &Shoe{Decks: s.Decks, Cards: append([]int(nil), s.Cards...), Cut: s.Cut}
+	swapped.Cards[50], swapped.Cards[51] = swapped.Cards[51], swapped.Cards[50]
+	err = VerifyDeckHash(h, swapped, salt, 0, nil)
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+
+	err = VerifyDeckHash(h, s, []byte("0123456789abcdeF"), 0, nil)
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+	err = VerifyDeckHash(h, s, salt[:8], 0, nil)
+	assert.True(t, errors.Is(err, ErrDeckMismatch))
+
+	assert.Panics(t, func() { DeckHash(s, nil) })
+	assert.Panics(t, func() { DeckHash(&Shoe{Decks: 1}, salt) })
+}
+
+func TestDeckHashVector(t *testing.T) {
+	s := sortedShoe(1, 52)
+	c := s.Canonical()
+	assert.Equal(t, "advbet/rng deck v1\x00\x00\x01\x00\x34\x00\x00\x00\x01", string(c[:27]))
+	assert.Len(t, c, 19+4+2*52)
+	assert.Equal(t, "fcff46d4d765cda04632a26cf6c44eaa3496e4f1aae595631029a5f29a1d4231",
+		hex.EncodeToString(DeckHash(s, []byte("0123456789abcdef"))))
+}
+
+// sortedShoe returns an unshuffled shoe of decks ordered by card index.
+func sortedShoe(decks, cut int) *Shoe {
+	s := &Shoe{Decks: decks, Cut: cut}
+	for i := 0; i < decks*CardsPerDeck; i++ {
+		s.Cards = append(s.Cards, i%CardsPerDeck)
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package dp
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/advbet/rng"
+)
+
+var (
+	ratOne  = big.NewRat(1, 1)
+	ratHalf = big.NewRat(1, 2)
+)
+
+// readBigIntn returns a uniform integer in [0, n) reading randomness from
+// src. n must be positive.
+func readBigIntn(src io.Reader, n *big.Int) *big.Int {
+	if n.IsInt64() && n.Int64() <= int64(^uint(0)>>1) {
+		return big.NewInt(int64(rng.ReadIntn(src, int(n.Int64()))))
+	}
+	bits := n.BitLen()
+	buf := make([]byte, (bits+7)/8)
+	r := new(big.Int)
+	for {
+		if _, err := io.ReadFull(src, buf); err != nil {
+			panic(err)
+		}
+		// mask excess bits of the most significant byte
+		buf[0] &= byte(0xff >> (8*len(buf) - bits))
+		if r.SetBytes(buf).Cmp(n) < 0 {
+			return r
+		}
+	}
+}
+
+// bernoulli returns true with probability p, p must be in [0, 1].
+func bernoulli(src io.Reader, p *big.Rat) bool {
+	return readBigIntn(src, p.Denom()).Cmp(p.Num()) < 0
+}
+
+// bernoulliExp returns true with probability exp(-gamma) for gamma >= 0
+// (Canonne, Kamath, Steinke 2020, Algorithm 1).
+func bernoulliExp(src io.Reader, gamma *big.Rat) bool {
+	g := new(big.Rat).Set(gamma)
+	for g.Cmp(ratOne) > 0 {
+		if !bernoulliExpUnit(src, ratOne) {
+			return false
+		}
+		g.Sub(g, ratOne)
+	}
+	return bernoulliExpUnit(src, g)
+}
+
+// bernoulliExpUnit is bernoulliExp for gamma in [0, 1].
+func bernoulliExpUnit(src io.Reader, gamma *big.Rat) bool {
+	p := new(big.Rat)
+	k := int64(1)
+	for {
+		p.Quo(gamma, big.NewRat(k, 1))
+		if !bernoulli(src, p) {
+			break
+		}
+		k++
+	}
+	return k%2 == 1
+}
+
+// discreteLaplace returns an integer with probability proportional to
+// exp(-|x|*s/t) for positive integers s and t (Canonne, Kamath, Steinke 2020,
+// Algorithm 2).
+func discreteLaplace(src io.Reader, s, t *big.Int) *big.Int {
+	u := new(big.Rat)
+	for {
+		uInt := readBigIntn(src, t)
+		if !bernoulliExp(src, u.SetFrac(uInt, t)) {
+			continue
+		}
+		v := new(big.Int)
+		for bernoulliExp(src, ratOne) {
+			v.Add(v, big.NewInt(1))
+		}
+		x := v.Mul(v, t)
+		x.Add(x, uInt)
+		y := x.Quo(x, s)
+		negative := bernoulli(src, ratHalf)
+		if negative && y.Sign() == 0 {
+			continue
+		}
+		if negative {
+			y.Neg(y)
+		}
+		return y
+	}
+}
@@ -0,0 +1,52 @@
+package dp
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBigIntn(t *testing.T) {
+	src := rng.NewDRBG([]byte("big"))
+	n, _ := new(big.Int).SetString("100000000000000000000000000001", 10)
+	for i := 0; i < 100; i++ {
+		r := readBigIntn(src, n)
+		assert.True(t, r.Sign() >= 0 && r.Cmp(n) < 0)
+	}
+	assert.Equal(t, int64(0), readBigIntn(src, big.NewInt(1)).Int64())
+}
+
+func TestBernoulliExp(t *testing.T) {
+	src := rng.NewDRBG([]byte("bernoulli"))
+	const samples = 20000
+	for _, gamma := range []*big.Rat{big.NewRat(0, 1), big.NewRat(1, 3), big.NewRat(5, 2)} {
+		hits := 0
+		for i := 0; i < samples; i++ {
+			if bernoulliExp(src, gamma) {
+				hits++
+			}
+		}
+		g, _ := gamma.Float64()
+		p := math.Exp(-g)
+		assert.InDelta(t, p*samples, hits, 5*math.Sqrt(samples*p*(1-p))+1, "gamma = %v", gamma)
+	}
+}
+
+func TestDiscreteLaplace(t *testing.T) {
+	src := rng.NewDRBG([]byte("laplace"))
+	const samples = 20000
+	// scale t/s = 3/2
+	s, tt := big.NewInt(2), big.NewInt(3)
+	counts := make(map[int64]int)
+	for i := 0; i < samples; i++ {
+		counts[discreteLaplace(src, s, tt).Int64()]++
+	}
+	q := math.Exp(-2.0 / 3)
+	for x := int64(-3); x <= 3; x++ {
+		p := (1 - q) / (1 + q) * math.Pow(q, math.Abs(float64(x)))
+		assert.InDelta(t, p*samples, counts[x], 5*math.Sqrt(samples*p*(1-p)), "x = %d", x)
+	}
+}
@@ -0,0 +1,152 @@
+// Package dp provides noise samplers for differentially private reporting of
+// aggregate statistics.
+//
+// Samplers draw from rng sources and are exact: they use only integer
+// arithmetic and uniform integer draws, never floating point approximations
+// of the target distribution (Canonne, Kamath, Steinke, "The Discrete Gaussian
+// for Differential Privacy", 2020). The constant time discrete Gaussian is the
+// only exception, see NewConstantTimeDiscreteGaussian.
+//
+// Laplace, Gaussian and ExponentialMechanism implement the standard
+// mechanisms parameterized by privacy budget epsilon (and delta).
+// RandomizedResponse privatizes individual survey answers instead.
+package dp
+
+import (
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/advbet/rng"
+)
+
+// MaxConstantTimeSigma is the largest sigma accepted by
+// NewConstantTimeDiscreteGaussian, it bounds the size of the scanned table.
+const MaxConstantTimeSigma = 1024
+
+// tailCut is the number of standard deviations covered by the constant time
+// table. The remaining tail mass is below 2^-120, far below the 2^-64
+// resolution of the table, so the cut adds no error of its own.
+const tailCut = 13
+
+// tablePrec is the big.Float precision used to sum and normalize constant
+// time table weights, enough to hold their sum exactly.
+const tablePrec = 256
+
+// DiscreteGaussian samples integers x with probability proportional to
+// exp(-x^2 / (2 sigma^2)). It is safe for concurrent use if its source is.
+type DiscreteGaussian struct {
+	sigma  float64
+	sigma2 *big.Rat // sigma^2
+	t      *big.Int // discrete Laplace scale floor(sigma)+1
+	cdf    []uint64 // constant time table, nil for the exact sampler
+}
+
+// NewDiscreteGaussian returns an exact discrete Gaussian sampler. Sampling
+// time depends on the drawn value, see NewConstantTimeDiscreteGaussian if
+// that leaks information. It will panic if sigma is not positive and finite.
+func NewDiscreteGaussian(sigma float64) *DiscreteGaussian {
+	if !(sigma > 0) || math.IsInf(sigma, 0) {
+		panic("invalid argument to NewDiscreteGaussian")
+	}
+	s := new(big.Rat).SetFloat64(sigma)
+	return &DiscreteGaussian{
+		sigma:  sigma,
+		sigma2: s.Mul(s, s),
+		t:      big.NewInt(int64(math.Floor(sigma)) + 1),
+	}
+}
+
+// NewConstantTimeDiscreteGaussian returns a discrete Gaussian sampler whose
+// running time and entropy use do not depend on the drawn value. It inverts a
+// 64-bit fixed point CDF table with a full scan of branch free comparisons.
+// Weights come from math.Exp with 2^-52 relative error, they are summed and
+// normalized exactly and cumulative probabilities are rounded to multiples of
+// 2^-64. Each probability is therefore off by at most 2^-52 relative plus
+// 2^-64 absolute error, and values less likely than 2^-64, roughly beyond 9.4
+// sigma, are never drawn. It will panic if sigma is not in (0,
+// MaxConstantTimeSigma].
+func NewConstantTimeDiscreteGaussian(sigma float64) *DiscreteGaussian {
+	if !(sigma > 0) || sigma > MaxConstantTimeSigma {
+		panic("invalid argument to NewConstantTimeDiscreteGaussian")
+	}
+	d := NewDiscreteGaussian(sigma)
+
+	// weights of |x| = 0, 1, ..., tail
+	tail := int(math.Ceil(tailCut * sigma))
+	w := make([]float64, tail+1)
+	total := new(big.Float).SetPrec(tablePrec)
+	for i := range w {
+		w[i] = math.Exp(-float64(i) * float64(i) / (2 * sigma * sigma))
+		if i > 0 {
+			w[i] *= 2 // both signs
+		}
+		total.Add(total, big.NewFloat(w[i]))
+	}
+	// cdf[i] = P(|X| <= i) * 2^64 rounded to nearest, the last entry would
+	// be 2^64 and is dropped
+	d.cdf = make([]uint64, tail)
+	acc := new(big.Float).SetPrec(tablePrec)
+	v := new(big.Float).SetPrec(tablePrec)
+	half := big.NewFloat(0.5)
+	limit := new(big.Float).SetMantExp(big.NewFloat(1), 64) // 2^64
+	for i := range d.cdf {
+		acc.Add(acc, big.NewFloat(w[i]))
+		v.Quo(acc, total)
+		v.SetMantExp(v, 64)
+		v.Add(v, half)
+		if v.Cmp(limit) < 0 {
+			d.cdf[i], _ = v.Uint64()
+		} else {
+			d.cdf[i] = math.MaxUint64
+		}
+	}
+	return d
+}
+
+// Sigma returns the sigma parameter of the sampler.
+func (d *DiscreteGaussian) Sigma() float64 {
+	return d.sigma
+}
+
+// Sample returns a sample reading randomness from rng.DefaultSource().
+func (d *DiscreteGaussian) Sample() int64 {
+	return d.ReadSample(rng.DefaultSource())
+}
+
+// ReadSample returns a sample reading randomness from a given source. It will
+// panic if the source returns a read error.
+func (d *DiscreteGaussian) ReadSample(src io.Reader) int64 {
+	if d.cdf != nil {
+		return d.readConstantTime(src)
+	}
+	// Canonne, Kamath, Steinke 2020, Algorithm 3
+	gamma := new(big.Rat)
+	tRat := new(big.Rat).SetInt(d.t)
+	twoSigma2 := new(big.Rat).Add(d.sigma2, d.sigma2)
+	for {
+		y := discreteLaplace(src, big.NewInt(1), d.t)
+		// gamma = (|y| - sigma^2/t)^2 / (2 sigma^2)
+		gamma.SetInt(new(big.Int).Abs(y))
+		gamma.Sub(gamma, new(big.Rat).Quo(d.sigma2, tRat))
+		gamma.Mul(gamma, gamma)
+		gamma.Quo(gamma, twoSigma2)
+		if bernoulliExp(src, gamma) {
+			return y.Int64()
+		}
+	}
+}
+
+func (d *DiscreteGaussian) readConstantTime(src io.Reader) int64 {
+	u := rng.ReadUint64Bits(src, 64)
+	sign := int64(rng.ReadUint64Bits(src, 1))
+	// m counts table entries <= u without data dependent branches
+	var m uint64
+	for _, c := range d.cdf {
+		_, borrow := bits.Sub64(u, c, 0)
+		m += 1 - borrow
+	}
+	// |x| = 0 has no sign, multiplying keeps it 0
+	return int64(m) * (1 - 2*sign)
+}
@@ -0,0 +1,75 @@
+package dp
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+// checkGaussian compares sample frequencies of values within 3 sigma with
+// probabilities of the discrete Gaussian.
+func checkGaussian(t *testing.T, d *DiscreteGaussian, samples int) {
+	t.Helper()
+	src := rng.NewDRBG([]byte("dp"))
+	counts := make(map[int64]int)
+	for i := 0; i < samples; i++ {
+		counts[d.ReadSample(src)]++
+	}
+
+	sigma := d.Sigma()
+	total := 0.0
+	for x := -40 * sigma; x <= 40*sigma; x++ {
+		total += math.Exp(-x * x / (2 * sigma * sigma))
+	}
+	for x := int64(-3 * sigma); x <= int64(3*sigma); x++ {
+		p := math.Exp(-float64(x*x)/(2*sigma*sigma)) / total
+		expected := p * float64(samples)
+		// 5 standard deviations of a binomial count
+		tolerance := 5 * math.Sqrt(expected*(1-p))
+		assert.InDelta(t, expected, counts[x], tolerance, "x = %d", x)
+	}
+}
+
+func TestDiscreteGaussian(t *testing.T) {
+	checkGaussian(t, NewDiscreteGaussian(1.5), 20000)
+	checkGaussian(t, NewDiscreteGaussian(0.5), 20000)
+}
+
+func TestConstantTimeDiscreteGaussian(t *testing.T) {
+	d := NewConstantTimeDiscreteGaussian(3)
+	assert.Len(t, d.cdf, 39)
+	checkGaussian(t, d, 50000)
+
+	// every sample reads exactly 9 bytes
+	src := bytes.NewBuffer(make([]byte, 18))
+	assert.Equal(t, int64(0), d.ReadSample(src))
+	assert.Equal(t, int64(0), d.ReadSample(src))
+	assert.Equal(t, 0, src.Len())
+
+	// largest u maps to the end of the table
+	src = bytes.NewBuffer([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})
+	assert.Equal(t, int64(-39), d.ReadSample(src))
+
+	// table holds P(|X| <= i) in 64-bit fixed point, probabilities below
+	// 2^-64 are cut
+	total := 1.0
+	for x := 1; x <= 39; x++ {
+		total += 2 * math.Exp(-float64(x*x)/18)
+	}
+	assert.InEpsilon(t, 1/total, float64(d.cdf[0])/(1<<64), 1e-15)
+	assert.InEpsilon(t, (1+2*math.Exp(-1.0/18))/total, float64(d.cdf[1])/(1<<64), 1e-15)
+	for i := 1; i < len(d.cdf); i++ {
+		assert.True(t, d.cdf[i] >= d.cdf[i-1])
+	}
+	assert.Equal(t, uint64(math.MaxUint64), d.cdf[29])
+}
+
+func TestDiscreteGaussianPanics(t *testing.T) {
+	assert.Panics(t, func() { NewDiscreteGaussian(0) })
+	assert.Panics(t, func() { NewDiscreteGaussian(math.NaN()) })
+	assert.Panics(t, func() { NewDiscreteGaussian(math.Inf(1)) })
+	assert.Panics(t, func() { NewConstantTimeDiscreteGaussian(MaxConstantTimeSigma + 1) })
+}
@@ -0,0 +1,101 @@
+package dp
+
+import (
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/advbet/rng"
+)
+
+// Laplace returns discrete Laplace noise for an epsilon-differentially private
+// release of an integer statistic, see ReadLaplace.
+func Laplace(sensitivity int64, epsilon float64) int64 {
+	return ReadLaplace(rng.DefaultSource(), sensitivity, epsilon)
+}
+
+// ReadLaplace returns discrete Laplace noise with scale sensitivity/epsilon
+// reading randomness from a given source. Adding it to an integer statistic
+// whose value changes by at most sensitivity when one user's data changes
+// (e.g. 1 for counts) gives epsilon-differential privacy. It will panic if
+// sensitivity or epsilon is not positive or epsilon is not finite.
+func ReadLaplace(src io.Reader, sensitivity int64, epsilon float64) int64 {
+	if sensitivity <= 0 || !(epsilon > 0) || math.IsInf(epsilon, 0) {
+		panic("invalid argument to Laplace")
+	}
+	// scale t/s = sensitivity/epsilon with epsilon = a/b exactly
+	eps := new(big.Rat).SetFloat64(epsilon)
+	t := new(big.Int).Mul(big.NewInt(sensitivity), eps.Denom())
+	return discreteLaplace(src, eps.Num(), t).Int64()
+}
+
+// GaussianSigma returns the noise scale of the Gaussian mechanism giving
+// (epsilon, delta)-differential privacy for a given sensitivity:
+//
+//	sigma = sensitivity * sqrt(2 ln(1.25/delta)) / epsilon
+//
+// It will panic if epsilon is not in (0, 1), delta is not in (0, 1) or
+// sensitivity is not positive.
+func GaussianSigma(sensitivity int64, epsilon, delta float64) float64 {
+	if sensitivity <= 0 || !(epsilon > 0 && epsilon < 1) || !(delta > 0 && delta < 1) {
+		panic("invalid argument to GaussianSigma")
+	}
+	return float64(sensitivity) * math.Sqrt(2*math.Log(1.25/delta)) / epsilon
+}
+
+// Gaussian returns discrete Gaussian noise for an (epsilon, delta)
+// differentially private release of an integer statistic, see ReadGaussian.
+func Gaussian(sensitivity int64, epsilon, delta float64) int64 {
+	return ReadGaussian(rng.DefaultSource(), sensitivity, epsilon, delta)
+}
+
+// ReadGaussian returns exact discrete Gaussian noise with sigma given by
+// GaussianSigma reading randomness from a given source. Callers drawing many
+// samples with the same parameters should reuse a DiscreteGaussian instead.
+func ReadGaussian(src io.Reader, sensitivity int64, epsilon, delta float64) int64 {
+	return NewDiscreteGaussian(GaussianSigma(sensitivity, epsilon, delta)).ReadSample(src)
+}
+
+// ExponentialMechanism returns the index of a candidate selected with
+// probability proportional to exp(epsilon * score / (2 * sensitivity)), see
+// ReadExponentialMechanism.
+func ExponentialMechanism(scores []float64, sensitivity, epsilon float64) int {
+	return ReadExponentialMechanism(rng.DefaultSource(), scores, sensitivity, epsilon)
+}
+
+// ReadExponentialMechanism returns the index of a candidate selected with
+// probability proportional to exp(epsilon * score / (2 * sensitivity))
+// reading randomness from a given source, which gives epsilon-differential
+// privacy if scores change by at most sensitivity when one user's data
+// changes. Selection is exact: a uniformly drawn candidate is accepted with
+// probability exp(-epsilon * (max - score) / (2 * sensitivity)), the best
+// candidate is always accepted. It will panic if scores is empty, any score is
+// not finite or sensitivity or epsilon is not positive and finite.
+func ReadExponentialMechanism(src io.Reader, scores []float64, sensitivity, epsilon float64) int {
+	if len(scores) == 0 || !(sensitivity > 0) || math.IsInf(sensitivity, 0) ||
+		!(epsilon > 0) || math.IsInf(epsilon, 0) {
+		panic("invalid argument to ExponentialMechanism")
+	}
+	max := math.Inf(-1)
+	for _, s := range scores {
+		if math.IsNaN(s) || math.IsInf(s, 0) {
+			panic("invalid argument to ExponentialMechanism")
+		}
+		max = math.Max(max, s)
+	}
+
+	maxRat := new(big.Rat).SetFloat64(max)
+	// factor = epsilon / (2 sensitivity)
+	factor := new(big.Rat).SetFloat64(epsilon)
+	factor.Quo(factor, new(big.Rat).SetFloat64(2*sensitivity))
+	gamma := new(big.Rat)
+	for {
+		i := rng.ReadIntn(src, len(scores))
+		gamma.SetFloat64(scores[i])
+		gamma.Sub(maxRat, gamma)
+		gamma.Mul(gamma, factor)
+		if bernoulliExp(src, gamma) {
+			return i
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package dp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaplace(t *testing.T) {
+	src := rng.NewDRBG([]byte("laplace"))
+	const samples = 20000
+	counts := make(map[int64]int)
+	for i := 0; i < samples; i++ {
+		// scale 2/0.5 = 4
+		counts[ReadLaplace(src, 2, 0.5)]++
+	}
+	q := math.Exp(-0.25)
+	for x := int64(-5); x <= 5; x++ {
+		p := (1 - q) / (1 + q) * math.Pow(q, math.Abs(float64(x)))
+		assert.InDelta(t, p*samples, counts[x], 5*math.Sqrt(samples*p*(1-p)), "x = %d", x)
+	}
+
+	assert.Panics(t, func() { Laplace(0, 1) })
+	assert.Panics(t, func() { Laplace(1, 0) })
+	assert.Panics(t, func() { Laplace(1, math.Inf(1)) })
+}
+
+func TestGaussian(t *testing.T) {
+	assert.InDelta(t, 9.690, GaussianSigma(1, 0.5, 1e-5), 0.001)
+	assert.Panics(t, func() { GaussianSigma(1, 1, 1e-5) })
+	assert.Panics(t, func() { GaussianSigma(1, 0.5, 0) })
+	assert.Panics(t, func() { GaussianSigma(0, 0.5, 1e-5) })
+
+	src := rng.NewDRBG([]byte("gaussian"))
+	const samples = 5000
+	sum, sum2 := 0.0, 0.0
+	for i := 0; i < samples; i++ {
+		x := float64(ReadGaussian(src, 1, 0.5, 1e-5))
+		sum += x
+		sum2 += x * x
+	}
+	sigma := GaussianSigma(1, 0.5, 1e-5)
+	assert.InDelta(t, 0, sum/samples, 5*sigma/math.Sqrt(samples))
+	assert.InDelta(t, sigma*sigma, sum2/samples, 0.1*sigma*sigma)
+}
+
+func TestExponentialMechanism(t *testing.T) {
+	src := rng.NewDRBG([]byte("exponential"))
+	const samples = 20000
+	scores := []float64{0, 1, 2, 2}
+	counts := make([]int, len(scores))
+	for i := 0; i < samples; i++ {
+		counts[ReadExponentialMechanism(src, scores, 1, 2)]++
+	}
+	total := 0.0
+	for _, s := range scores {
+		total += math.Exp(s)
+	}
+	for i, s := range scores {
+		p := math.Exp(s) / total
+		assert.InDelta(t, p*samples, counts[i], 5*math.Sqrt(samples*p*(1-p)), "i = %d", i)
+	}
+
+	assert.Equal(t, 0, ExponentialMechanism([]float64{5}, 1, 1))
+	assert.Panics(t, func() { ExponentialMechanism(nil, 1, 1) })
+	assert.Panics(t, func() { ExponentialMechanism([]float64{math.NaN()}, 1, 1) })
+	assert.Panics(t, func() { ExponentialMechanism([]float64{1}, 0, 1) })
+}
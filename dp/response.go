@@ -0,0 +1,62 @@
+package dp
+
+import (
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/advbet/rng"
+)
+
+// RandomizedResponse returns a survey answer answering truthfully with
+// probability p, see ReadRandomizedResponse.
+func RandomizedResponse(truth bool, p float64) bool {
+	return ReadRandomizedResponse(rng.DefaultSource(), truth, p)
+}
+
+// ReadRandomizedResponse returns truth with probability p and its negation
+// otherwise reading randomness from a given source (Warner's randomized
+// response). A single answer reveals little about the respondent, it gives
+// RandomizedResponseEpsilon(p)-differential privacy, while aggregate
+// proportions are recovered with RandomizedResponseEstimate. The probability
+// is exact for the float64 p. It will panic if p is not in [0, 1].
+func ReadRandomizedResponse(src io.Reader, truth bool, p float64) bool {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid argument to RandomizedResponse")
+	}
+	if bernoulli(src, new(big.Rat).SetFloat64(p)) {
+		return truth
+	}
+	return !truth
+}
+
+// RandomizedResponseEpsilon returns the privacy budget epsilon of a
+// randomized response answering truthfully with probability p, |ln(p/(1-p))|.
+// It is +Inf for p = 0 and p = 1 and 0 for p = 0.5. It will panic if p is not
+// in [0, 1].
+func RandomizedResponseEpsilon(p float64) float64 {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid argument to RandomizedResponseEpsilon")
+	}
+	return math.Abs(math.Log(p) - math.Log1p(-p))
+}
+
+// RandomizedResponseEstimate returns the unbiased estimate of the true
+// proportion of "yes" answers from yes randomized "yes" responses of n
+// respondents answering truthfully with probability p, together with its
+// standard error:
+//
+//	estimate = (yes/n + p - 1) / (2p - 1)
+//
+// Sampling noise can move the estimate outside [0, 1], it is not clamped so
+// averages of estimates stay unbiased. It will panic if n <= 0, yes is not in
+// [0, n], p is not in [0, 1] or p = 0.5, where answers carry no information.
+func RandomizedResponseEstimate(yes, n int, p float64) (estimate, stderr float64) {
+	if n <= 0 || yes < 0 || yes > n || !(p >= 0 && p <= 1) || p == 0.5 {
+		panic("invalid argument to RandomizedResponseEstimate")
+	}
+	lambda := float64(yes) / float64(n)
+	estimate = (lambda + p - 1) / (2*p - 1)
+	stderr = math.Sqrt(lambda*(1-lambda)/float64(n)) / math.Abs(2*p-1)
+	return estimate, stderr
+}
@@ -0,0 +1,57 @@
+package dp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomizedResponse(t *testing.T) {
+	src := rng.NewDRBG([]byte("survey"))
+	const n = 20000
+	// 30% of respondents have the sensitive attribute
+	yes := 0
+	for i := 0; i < n; i++ {
+		if ReadRandomizedResponse(src, i%10 < 3, 0.75) {
+			yes++
+		}
+	}
+	// expected yes rate is 0.3*0.75 + 0.7*0.25 = 0.4
+	assert.InDelta(t, 0.4*n, yes, 5*math.Sqrt(n*0.4*0.6))
+	estimate, stderr := RandomizedResponseEstimate(yes, n, 0.75)
+	assert.InDelta(t, 0.3, estimate, 5*stderr)
+	assert.InDelta(t, math.Sqrt(0.4*0.6/n)/0.5, stderr, 0.001)
+
+	assert.True(t, ReadRandomizedResponse(src, true, 1))
+	assert.True(t, ReadRandomizedResponse(src, false, 0))
+	assert.Panics(t, func() { RandomizedResponse(true, 1.5) })
+	assert.Panics(t, func() { RandomizedResponse(true, math.NaN()) })
+}
+
+func TestRandomizedResponseEstimate(t *testing.T) {
+	// lying with p < 0.5 inverts answers
+	estimate, _ := RandomizedResponseEstimate(25, 100, 0.25)
+	assert.InDelta(t, 1, estimate, 1e-12)
+	estimate, stderr := RandomizedResponseEstimate(100, 100, 1)
+	assert.Equal(t, 1.0, estimate)
+	assert.Equal(t, 0.0, stderr)
+	// not clamped
+	estimate, _ = RandomizedResponseEstimate(0, 100, 0.75)
+	assert.InDelta(t, -0.5, estimate, 1e-12)
+
+	assert.Panics(t, func() { RandomizedResponseEstimate(1, 10, 0.5) })
+	assert.Panics(t, func() { RandomizedResponseEstimate(11, 10, 0.75) })
+	assert.Panics(t, func() { RandomizedResponseEstimate(0, 0, 0.75) })
+	assert.Panics(t, func() { RandomizedResponseEstimate(0, 10, -0.1) })
+}
+
+func TestRandomizedResponseEpsilon(t *testing.T) {
+	assert.InDelta(t, math.Log(3), RandomizedResponseEpsilon(0.75), 1e-12)
+	assert.InDelta(t, math.Log(3), RandomizedResponseEpsilon(0.25), 1e-12)
+	assert.Equal(t, 0.0, RandomizedResponseEpsilon(0.5))
+	assert.True(t, math.IsInf(RandomizedResponseEpsilon(1), 1))
+	assert.True(t, math.IsInf(RandomizedResponseEpsilon(0), 1))
+	assert.Panics(t, func() { RandomizedResponseEpsilon(2) })
+}
@@ -0,0 +1,344 @@
+package rng
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// drawsMagic starts every EncodeDraws encoding, followed by a format version
+// byte. Version 1 has no algorithm IDs, it is still decoded.
+const (
+	drawsMagic   = "RNGD"
+	drawsVersion = 2
+)
+
+// ID encodings of EncodeDraws.
+const (
+	drawIDString = 0
+	drawIDHex    = 1 // lower case hex string stored as raw bytes
+)
+
+var errDrawsTruncated = errors.New("rng: truncated draw encoding")
+
+// Compressor compresses EncodeDraws output for archival. Implementations wrap
+// a compression library, e.g. zstd from github.com/klauspost/compress, the
+// standard library provides FlateCompressor.
+type Compressor interface {
+	// Name identifies the compression in encoded data, e.g. "zstd".
+	Name() string
+	Compress(b []byte) ([]byte, error)
+	Decompress(b []byte) ([]byte, error)
+}
+
+// FlateCompressor is a Compressor using compress/flate with a given level,
+// zero Level is flate.DefaultCompression.
+type FlateCompressor struct {
+	Level int
+}
+
+// Name returns "flate".
+func (c FlateCompressor) Name() string {
+	return "flate"
+}
+
+// Compress returns b compressed with flate.
+func (c FlateCompressor) Compress(b []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	w.Write(b)
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress returns b decompressed with flate.
+func (c FlateCompressor) Decompress(b []byte) ([]byte, error) {
+	return io.ReadAll(flate.NewReader(bytes.NewReader(b)))
+}
+
+// EncodeDraws encodes draw results in a compact binary format for archival,
+// decoded with DecodeDraws. Draw types and param names are stored once and
+// referenced by index, hex draw IDs are stored as raw bytes, outcome values
+// and timestamps as zigzag varint deltas from the previous value. Timestamps
+// are kept with nanosecond precision and decoded in UTC, nil and empty
+// slices and maps are not distinguished. Params are written sorted by name so
+// the encoding is deterministic.
+func EncodeDraws(draws []DrawResult) []byte {
+	b := append([]byte(drawsMagic), drawsVersion)
+	b = appendUvarint(b, 0) // no compression
+	return appendDraws(b, draws)
+}
+
+// EncodeDrawsCompressed returns EncodeDraws output with the draws compressed
+// by c. The compressor name is stored in the header, DecodeDraws needs a
+// compressor of the same name.
+func EncodeDrawsCompressed(draws []DrawResult, c Compressor) ([]byte, error) {
+	data, err := c.Compress(appendDraws(nil, draws))
+	if err != nil {
+		return nil, fmt.Errorf("rng: compressing draws: %w", err)
+	}
+	b := append([]byte(drawsMagic), drawsVersion)
+	b = appendUvarint(b, uint64(len(c.Name())))
+	b = append(b, c.Name()...)
+	return append(b, data...), nil
+}
+
+// DecodeDraws decodes draw results encoded by EncodeDraws or
+// EncodeDrawsCompressed. Compressed data is decompressed with the compressor
+// of cs matching the name stored in the header.
+func DecodeDraws(b []byte, cs ...Compressor) ([]DrawResult, error) {
+	if !bytes.HasPrefix(b, []byte(drawsMagic)) || len(b) == len(drawsMagic) {
+		return nil, errors.New("rng: not a draw encoding")
+	}
+	version := b[len(drawsMagic)]
+	if version < 1 || version > drawsVersion {
+		return nil, fmt.Errorf("rng: unsupported draw encoding version %d", version)
+	}
+	d := drawDecoder{b: b[len(drawsMagic)+1:], version: version}
+	name := string(d.bytes())
+	if d.err != nil {
+		return nil, d.err
+	}
+	if name != "" {
+		var c Compressor
+		for _, c = range cs {
+			if c.Name() == name {
+				break
+			}
+			c = nil
+		}
+		if c == nil {
+			return nil, fmt.Errorf("rng: unknown draw compression %q", name)
+		}
+		data, err := c.Decompress(d.b)
+		if err != nil {
+			return nil, fmt.Errorf("rng: decompressing draws: %w", err)
+		}
+		d.b = data
+	}
+	return d.draws()
+}
+
+func appendDraws(b []byte, draws []DrawResult) []byte {
+	strs := make(map[string]uint64)
+	appendStr := func(b []byte, s string) []byte {
+		if i, ok := strs[s]; ok {
+			return appendUvarint(b, i)
+		}
+		// a new string is stored inline with the next free index
+		strs[s] = uint64(len(strs))
+		b = appendUvarint(b, strs[s])
+		b = appendUvarint(b, uint64(len(s)))
+		return append(b, s...)
+	}
+
+	var prevSec int64
+	b = appendUvarint(b, uint64(len(draws)))
+	for _, d := range draws {
+		if id, err := hex.DecodeString(d.ID); err == nil && hex.EncodeToString(id) == d.ID {
+			b = append(b, drawIDHex)
+			b = appendUvarint(b, uint64(len(id)))
+			b = append(b, id...)
+		} else {
+			b = append(b, drawIDString)
+			b = appendUvarint(b, uint64(len(d.ID)))
+			b = append(b, d.ID...)
+		}
+		b = appendStr(b, d.Type)
+		b = appendStr(b, d.Algorithm)
+
+		b = appendUvarint(b, uint64(len(d.Params)))
+		keys := make([]string, 0, len(d.Params))
+		for k := range d.Params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b = appendStr(b, k)
+			b = appendVarint(b, d.Params[k])
+		}
+
+		b = appendUvarint(b, uint64(len(d.Outcome)))
+		var prev int64
+		for _, v := range d.Outcome {
+			b = appendVarint(b, v-prev)
+			prev = v
+		}
+
+		b = appendUvarint(b, uint64(len(d.EntropyDigest)))
+		b = append(b, d.EntropyDigest...)
+
+		// started relative to the previous draw, finished to started
+		b = appendVarint(b, d.StartedAt.Unix()-prevSec)
+		b = appendUvarint(b, uint64(d.StartedAt.Nanosecond()))
+		b = appendVarint(b, d.FinishedAt.Unix()-d.StartedAt.Unix())
+		b = appendUvarint(b, uint64(d.FinishedAt.Nanosecond()))
+		prevSec = d.StartedAt.Unix()
+	}
+	return b
+}
+
+// appendVarint appends zigzag varint encoding of v.
+func appendVarint(b []byte, v int64) []byte {
+	return appendUvarint(b, uint64(v<<1)^uint64(v>>63))
+}
+
+// drawDecoder reads EncodeDraws data, the first error is kept in err and
+// makes all further reads return zero values.
+type drawDecoder struct {
+	b       []byte
+	version byte
+	err     error
+	strs    []string
+}
+
+func (d *drawDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errDrawsTruncated
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *drawDecoder) varint() int64 {
+	v := d.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// count reads a length of items taking at least min bytes each, lengths
+// exceeding the remaining data are rejected before allocating.
+func (d *drawDecoder) count(min int) int {
+	n := d.uvarint()
+	if d.err == nil && n > uint64(len(d.b)/min) {
+		d.err = errDrawsTruncated
+		return 0
+	}
+	return int(n)
+}
+
+func (d *drawDecoder) bytes() []byte {
+	n := d.count(1)
+	if d.err != nil {
+		return nil
+	}
+	b := d.b[:n:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *drawDecoder) str() string {
+	i := d.uvarint()
+	switch {
+	case d.err != nil:
+		return ""
+	case i < uint64(len(d.strs)):
+		return d.strs[i]
+	case i == uint64(len(d.strs)):
+		s := string(d.bytes())
+		d.strs = append(d.strs, s)
+		return s
+	}
+	d.err = fmt.Errorf("rng: invalid string reference %d in draw encoding", i)
+	return ""
+}
+
+func (d *drawDecoder) draws() ([]DrawResult, error) {
+	// a draw takes at least 9 bytes
+	draws := make([]DrawResult, d.count(9))
+	var prevSec int64
+	for i := range draws {
+		r := &draws[i]
+		switch kind := d.readByte(); kind {
+		case drawIDString:
+			r.ID = string(d.bytes())
+		case drawIDHex:
+			r.ID = hex.EncodeToString(d.bytes())
+		default:
+			if d.err == nil {
+				d.err = fmt.Errorf("rng: invalid draw ID encoding %d", kind)
+			}
+		}
+		r.Type = d.str()
+		if d.version >= 2 {
+			r.Algorithm = d.str()
+		}
+
+		if n := d.count(2); n > 0 {
+			r.Params = make(map[string]int64, n)
+			for j := 0; j < n; j++ {
+				k := d.str()
+				r.Params[k] = d.varint()
+			}
+		}
+
+		if n := d.count(1); n > 0 {
+			r.Outcome = make([]int64, n)
+			var prev int64
+			for j := range r.Outcome {
+				prev += d.varint()
+				r.Outcome[j] = prev
+			}
+		}
+
+		if digest := d.bytes(); len(digest) > 0 {
+			r.EntropyDigest = append([]byte(nil), digest...)
+		}
+
+		sec := prevSec + d.varint()
+		r.StartedAt = d.time(sec)
+		r.FinishedAt = d.time(sec + d.varint())
+		prevSec = sec
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+	if d.err == nil && len(d.b) > 0 {
+		d.err = errors.New("rng: trailing data in draw encoding")
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return draws, nil
+}
+
+// time reads nanoseconds of a time with given Unix seconds.
+func (d *drawDecoder) time(sec int64) time.Time {
+	nsec := d.uvarint()
+	if d.err == nil && nsec >= 1e9 {
+		d.err = fmt.Errorf("rng: invalid nanoseconds %d in draw encoding", nsec)
+	}
+	return time.Unix(sec, int64(nsec)).UTC()
+}
+
+func (d *drawDecoder) readByte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.b) == 0 {
+		d.err = errDrawsTruncated
+		return 0
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
@@ -0,0 +1,102 @@
+package rng
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type upperCompressor struct{}
+
+func (upperCompressor) Name() string                        { return "upper" }
+func (upperCompressor) Compress(b []byte) ([]byte, error)   { return b, nil }
+func (upperCompressor) Decompress(b []byte) ([]byte, error) { return nil, errors.New("broken") }
+
+func testDraws(n int) []DrawResult {
+	g := New(NewDRBG([]byte("archive")))
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	draws := make([]DrawResult, n)
+	for i := range draws {
+		d := runDraw(g.Source(), "sample", map[string]int64{"n": 49, "k": 6}, func(src io.Reader) []int64 {
+			return intsToInt64s(ReadSample(src, 49, 6))
+		})
+		d.StartedAt = start.Add(time.Duration(i) * 1500 * time.Millisecond)
+		d.FinishedAt = d.StartedAt.Add(time.Duration(i) * time.Microsecond)
+		draws[i] = *d
+	}
+	return draws
+}
+
+func TestEncodeDraws(t *testing.T) {
+	draws := testDraws(1000)
+	draws[1].ID = "not-hex"
+	draws[2].Type = "float64"
+	draws[2].Params = nil
+	draws[2].Outcome = []int64{-5, 1 << 53, -1 << 63, 1<<63 - 1}
+	draws[3].EntropyDigest = nil
+	draws[4].StartedAt = time.Time{}
+	draws[4].FinishedAt = time.Date(2024, 3, 1, 12, 0, 0, 999999999, time.FixedZone("X", 3600))
+
+	b := EncodeDraws(draws)
+	decoded, err := DecodeDraws(b)
+	require.NoError(t, err)
+	require.Len(t, decoded, len(draws))
+	for i := range draws {
+		assert.Equal(t, draws[i].Canonical(), decoded[i].Canonical(), "draw %d", i)
+	}
+	assert.Equal(t, time.Time{}, decoded[4].StartedAt)
+
+	data, err := json.Marshal(draws)
+	require.NoError(t, err)
+	assert.True(t, len(b) < len(data)/3, "encoded %d bytes, JSON %d bytes", len(b), len(data))
+
+	compressed, err := EncodeDrawsCompressed(draws, FlateCompressor{})
+	require.NoError(t, err)
+	decoded, err = DecodeDraws(compressed, upperCompressor{}, FlateCompressor{})
+	require.NoError(t, err)
+	assert.Equal(t, draws[999].Canonical(), decoded[999].Canonical())
+
+	// version 1 encodings have no algorithm
+	v1 := []byte(drawsMagic + "\x01\x00\x01\x00\x02ab\x00\x04intn\x01\x00\x0e\x00\x00\x00\x00\x00\x00")
+	decoded, err = DecodeDraws(v1)
+	require.NoError(t, err)
+	assert.Equal(t, "ab", decoded[0].ID)
+	assert.Equal(t, "intn", decoded[0].Type)
+	assert.Equal(t, "", decoded[0].Algorithm)
+	assert.Equal(t, map[string]int64{"intn": 7}, decoded[0].Params)
+
+	empty, err := DecodeDraws(EncodeDraws(nil))
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestDecodeDrawsErrors(t *testing.T) {
+	b := EncodeDraws(testDraws(3))
+	for i := 0; i < len(b); i++ {
+		_, err := DecodeDraws(b[:i])
+		assert.Error(t, err, "truncated at %d", i)
+	}
+	_, err := DecodeDraws(append(b, 0))
+	assert.Error(t, err)
+	_, err = DecodeDraws([]byte("{}"))
+	assert.Error(t, err)
+
+	compressed, err := EncodeDrawsCompressed(testDraws(1), upperCompressor{})
+	require.NoError(t, err)
+	_, err = DecodeDraws(compressed)
+	assert.True(t, strings.Contains(err.Error(), `"upper"`))
+	_, err = DecodeDraws(compressed, upperCompressor{})
+	assert.Error(t, err)
+
+	// huge counts are rejected before allocation
+	_, err = DecodeDraws([]byte(drawsMagic + "\x02\x00\xff\xff\xff\xff\x0f"))
+	assert.Error(t, err)
+	_, err = DecodeDraws([]byte(drawsMagic + "\x03\x00\x00"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package rng
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"time"
+)
+
+// DrawResult is the canonical record of a single draw exchanged between
+// services. Its protobuf schema is defined in drawresult.proto.
+type DrawResult struct {
+	// ID uniquely identifies the draw.
+	ID string `json:"id"`
+	// Type names the draw function, e.g. "intn", "perm" or "sample".
+	Type string `json:"type"`
+	// Algorithm is the ID of the registered algorithm that made the draw,
+	// e.g. "sample/v2". Empty for draws recorded before versioning, those
+	// were made with the first version of their type.
+	Algorithm string `json:"algorithm,omitempty"`
+	// Params holds draw arguments by name, e.g. {"n": 49, "k": 6}.
+	Params map[string]int64 `json:"params,omitempty"`
+	// Outcome holds drawn values in draw order. Float draws store the raw
+	// 53-bit integer, the float is Outcome[i] / 2^53.
+	Outcome []int64 `json:"outcome"`
+	// EntropyDigest is SHA-256 of all source bytes consumed by the draw.
+	EntropyDigest []byte `json:"entropy_digest,omitempty"`
+	// StartedAt and FinishedAt are draw start and end times.
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Canonical returns a stable byte encoding of the draw result suitable for
+// hashing and signing. It is compact JSON with fields in schema order, params
+// sorted by name, timestamps in UTC and nil slices encoded as empty ones, so
+// equal results always encode to identical bytes.
+func (d *DrawResult) Canonical() []byte {
+	c := *d
+	c.StartedAt = c.StartedAt.UTC()
+	c.FinishedAt = c.FinishedAt.UTC()
+	if c.Outcome == nil {
+		c.Outcome = []int64{}
+	}
+	if len(c.Params) == 0 {
+		c.Params = nil
+	}
+	if len(c.EntropyDigest) == 0 {
+		c.EntropyDigest = nil
+	}
+	// encoding/json sorts map keys and can not fail for this type
+	b, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Digest returns SHA-256 of the canonical encoding.
+func (d *DrawResult) Digest() [sha256.Size]byte {
+	return sha256.Sum256(d.Canonical())
+}
@@ -0,0 +1,232 @@
+package rng
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// DrawResult protobuf field numbers, see drawresult.proto.
+const (
+	fieldID            = 1
+	fieldType          = 2
+	fieldParams        = 3
+	fieldOutcome       = 4
+	fieldEntropyDigest = 5
+	fieldStartedAt     = 6
+	fieldFinishedAt    = 7
+	fieldAlgorithm     = 8
+)
+
+var errProtoTruncated = errors.New("rng: truncated protobuf message")
+
+// MarshalProto encodes the draw result in protobuf wire format. Map entries
+// are written sorted by key so the encoding is deterministic.
+func (d *DrawResult) MarshalProto() []byte {
+	var b []byte
+	b = appendProtoString(b, fieldID, d.ID)
+	b = appendProtoString(b, fieldType, d.Type)
+
+	keys := make([]string, 0, len(d.Params))
+	for k := range d.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = appendProtoString(entry, 1, k)
+		entry = appendProtoVarint(entry, 2, uint64(d.Params[k]))
+		b = appendProtoBytes(b, fieldParams, entry)
+	}
+
+	if len(d.Outcome) > 0 {
+		var packed []byte
+		for _, v := range d.Outcome {
+			packed = appendUvarint(packed, uint64(v))
+		}
+		b = appendProtoBytes(b, fieldOutcome, packed)
+	}
+	if len(d.EntropyDigest) > 0 {
+		b = appendProtoBytes(b, fieldEntropyDigest, d.EntropyDigest)
+	}
+	b = appendProtoTimestamp(b, fieldStartedAt, d.StartedAt)
+	b = appendProtoTimestamp(b, fieldFinishedAt, d.FinishedAt)
+	b = appendProtoString(b, fieldAlgorithm, d.Algorithm)
+	return b
+}
+
+// UnmarshalProto decodes a draw result from protobuf wire format. Unknown
+// fields are skipped.
+func (d *DrawResult) UnmarshalProto(b []byte) error {
+	*d = DrawResult{}
+	return walkProto(b, func(field int, wire int, v uint64, data []byte) error {
+		var err error
+		switch {
+		case field == fieldID && wire == wireBytes:
+			d.ID = string(data)
+		case field == fieldType && wire == wireBytes:
+			d.Type = string(data)
+		case field == fieldParams && wire == wireBytes:
+			var key string
+			var value int64
+			err = walkProto(data, func(field int, wire int, v uint64, data []byte) error {
+				switch {
+				case field == 1 && wire == wireBytes:
+					key = string(data)
+				case field == 2 && wire == wireVarint:
+					value = int64(v)
+				}
+				return nil
+			})
+			if d.Params == nil {
+				d.Params = make(map[string]int64)
+			}
+			d.Params[key] = value
+		case field == fieldOutcome && wire == wireVarint:
+			d.Outcome = append(d.Outcome, int64(v))
+		case field == fieldOutcome && wire == wireBytes:
+			for len(data) > 0 {
+				x, n := binary.Uvarint(data)
+				if n <= 0 {
+					return errProtoTruncated
+				}
+				d.Outcome = append(d.Outcome, int64(x))
+				data = data[n:]
+			}
+		case field == fieldEntropyDigest && wire == wireBytes:
+			d.EntropyDigest = append([]byte(nil), data...)
+		case field == fieldStartedAt && wire == wireBytes:
+			d.StartedAt, err = parseProtoTimestamp(data)
+		case field == fieldFinishedAt && wire == wireBytes:
+			d.FinishedAt, err = parseProtoTimestamp(data)
+		case field == fieldAlgorithm && wire == wireBytes:
+			d.Algorithm = string(data)
+		}
+		return err
+	})
+}
+
+func appendProtoTag(b []byte, field int, wire int) []byte {
+	return appendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, wireVarint)
+	return appendUvarint(b, v)
+}
+
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	b = appendProtoTag(b, field, wireBytes)
+	b = appendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendProtoString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendProtoBytes(b, field, []byte(s))
+}
+
+// appendProtoTimestamp appends google.protobuf.Timestamp message, zero time
+// is omitted.
+func appendProtoTimestamp(b []byte, field int, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var ts []byte
+	ts = appendProtoVarint(ts, 1, uint64(t.Unix()))
+	ts = appendProtoVarint(ts, 2, uint64(t.Nanosecond()))
+	return appendProtoBytes(b, field, ts)
+}
+
+func parseProtoTimestamp(data []byte) (time.Time, error) {
+	var sec, nsec int64
+	err := walkProto(data, func(field int, wire int, v uint64, data []byte) error {
+		switch {
+		case field == 1 && wire == wireVarint:
+			sec = int64(v)
+		case field == 2 && wire == wireVarint:
+			nsec = int64(int32(v))
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+// walkProto calls fn for every field of a protobuf message. Varint and fixed
+// values are passed in v, length delimited values in data.
+func walkProto(b []byte, fn func(field int, wire int, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		b = b[n:]
+		field, wire := int(tag>>3), int(tag&7)
+		if field <= 0 || tag>>3 > math.MaxInt32 {
+			return fmt.Errorf("rng: invalid protobuf field number %d", tag>>3)
+		}
+
+		var v uint64
+		var data []byte
+		switch wire {
+		case wireVarint:
+			v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errProtoTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errProtoTruncated
+			}
+			v = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errProtoTruncated
+			}
+			v = uint64(binary.LittleEndian.Uint32(b))
+			b = b[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errProtoTruncated
+			}
+			data = b[n : n+int(l)]
+			b = b[n+int(l):]
+		default:
+			return fmt.Errorf("rng: unsupported protobuf wire type %d", wire)
+		}
+		if err := fn(field, wire, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
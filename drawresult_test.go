@@ -0,0 +1,71 @@
+package rng
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDrawResult() *DrawResult {
+	return &DrawResult{
+		ID:            "draw-1",
+		Type:          "sample",
+		Params:        map[string]int64{"n": 49, "k": 6},
+		Outcome:       []int64{3, 48, 17, 0, 22, 9},
+		EntropyDigest: []byte{0xde, 0xad, 0xbe, 0xef},
+		StartedAt:     time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		FinishedAt:    time.Date(2024, 1, 2, 3, 4, 5, 7000, time.UTC),
+	}
+}
+
+func TestDrawResultCanonical(t *testing.T) {
+	d := testDrawResult()
+	assert.Equal(t,
+		`{"id":"draw-1","type":"sample","params":{"k":6,"n":49},"outcome":[3,48,17,0,22,9],`+
+			`"entropy_digest":"3q2+7w==","started_at":"2024-01-02T03:04:05.000000006Z",`+
+			`"finished_at":"2024-01-02T03:04:05.000007Z"}`,
+		string(d.Canonical()))
+
+	// time zone must not affect canonical encoding
+	local := *d
+	local.StartedAt = d.StartedAt.In(time.FixedZone("EET", 2*3600))
+	assert.Equal(t, d.Canonical(), local.Canonical())
+	assert.Equal(t, d.Digest(), local.Digest())
+
+	empty := &DrawResult{ID: "x", Params: map[string]int64{}}
+	assert.Equal(t, `{"id":"x","type":"","outcome":[],"started_at":"0001-01-01T00:00:00Z","finished_at":"0001-01-01T00:00:00Z"}`, string(empty.Canonical()))
+
+	var decoded DrawResult
+	assert.NoError(t, json.Unmarshal(d.Canonical(), &decoded))
+	assert.Equal(t, d, &decoded)
+}
+
+func TestDrawResultProto(t *testing.T) {
+	d := testDrawResult()
+	b := d.MarshalProto()
+	assert.Equal(t, b, d.MarshalProto(), "encoding must be deterministic")
+
+	// fields 1 and 2 are length delimited strings: tag, length, bytes
+	assert.Equal(t, "0a06"+hex.EncodeToString([]byte("draw-1"))+"1206"+hex.EncodeToString([]byte("sample")), hex.EncodeToString(b[:16]))
+
+	var decoded DrawResult
+	assert.NoError(t, decoded.UnmarshalProto(b))
+	assert.Equal(t, d, &decoded)
+
+	assert.NoError(t, decoded.UnmarshalProto(nil))
+	assert.Equal(t, DrawResult{}, decoded)
+
+	assert.Error(t, decoded.UnmarshalProto(b[:len(b)-1]))
+	assert.Error(t, decoded.UnmarshalProto([]byte{0x0a, 0x05, 'a'}))
+}
+
+func TestDrawResultProtoNegative(t *testing.T) {
+	d := &DrawResult{Params: map[string]int64{"offset": -5}, Outcome: []int64{-1, 1}}
+	var decoded DrawResult
+	assert.NoError(t, decoded.UnmarshalProto(d.MarshalProto()))
+	assert.Equal(t, d.Params, decoded.Params)
+	assert.Equal(t, d.Outcome, decoded.Outcome)
+}
@@ -0,0 +1,78 @@
+package rng
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// drbgStateSize is the size of DRBG state snapshots: the AES key followed by
+// the big endian stream position.
+const drbgStateSize = sha256.Size + 8
+
+// DRBG is a deterministic random bit generator expanding a seed into an
+// endless stream of bytes. Output is the AES-256-CTR keystream with key
+// SHA256(seed) and all zero IV, so the same seed always produces the same
+// stream.
+//
+// DRBG is meant for reproducible tests and simulations, it must not be used
+// for production draws. DRBG is not safe for concurrent use.
+type DRBG struct {
+	stream cipher.Stream
+	key    [sha256.Size]byte
+	pos    uint64 // number of bytes read
+}
+
+// NewDRBG returns a DRBG seeded with seed. Seed of any length is accepted.
+func NewDRBG(seed []byte) *DRBG {
+	d := &DRBG{key: sha256.Sum256(seed)}
+	d.seek(0)
+	return d
+}
+
+// seek positions the keystream at byte pos.
+func (d *DRBG) seek(pos uint64) {
+	block, err := aes.NewCipher(d.key[:])
+	if err != nil {
+		panic(err)
+	}
+	// CTR mode increments the whole IV as a big endian counter
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], pos/aes.BlockSize)
+	d.stream = cipher.NewCTR(block, iv)
+	skip := make([]byte, pos%aes.BlockSize)
+	d.stream.XORKeyStream(skip, skip)
+	d.pos = pos
+}
+
+// Read fills p with the next bytes of the stream. It never returns an error.
+func (d *DRBG) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	d.stream.XORKeyStream(p, p)
+	d.pos += uint64(len(p))
+	return len(p), nil
+}
+
+// MarshalBinary returns a snapshot of the DRBG state: the stream key and
+// position. A DRBG restored from it with UnmarshalBinary continues the stream
+// where it was taken. The snapshot reveals the whole stream, past and future,
+// and must be protected like the seed.
+func (d *DRBG) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, drbgStateSize)
+	b = append(b, d.key[:]...)
+	return binary.BigEndian.AppendUint64(b, d.pos), nil
+}
+
+// UnmarshalBinary restores a DRBG state snapshot taken by MarshalBinary.
+func (d *DRBG) UnmarshalBinary(b []byte) error {
+	if len(b) != drbgStateSize {
+		return errors.New("rng: invalid DRBG state size")
+	}
+	copy(d.key[:], b)
+	d.seek(binary.BigEndian.Uint64(b[sha256.Size:]))
+	return nil
+}
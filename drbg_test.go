@@ -0,0 +1,70 @@
+package rng
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDRBG(t *testing.T) {
+	a := make([]byte, 100)
+	b := make([]byte, 100)
+	NewDRBG([]byte("seed")).Read(a)
+
+	// stream must not depend on read sizes
+	d := NewDRBG([]byte("seed"))
+	d.Read(b[:1])
+	d.Read(b[1:33])
+	d.Read(b[33:])
+	assert.Equal(t, a, b)
+
+	NewDRBG([]byte("other")).Read(b)
+	assert.NotEqual(t, a, b)
+
+	// AES-256-CTR keystream with key SHA256("seed"), locks down the format
+	assert.Equal(t, []byte{0x10, 0x24, 0xe0, 0x3e}, a[:4])
+}
+
+func TestDRBGState(t *testing.T) {
+	want := make([]byte, 100)
+	NewDRBG([]byte("seed")).Read(want)
+
+	for _, pos := range []int{0, 1, 16, 17, 33, 100} {
+		d := NewDRBG([]byte("seed"))
+		d.Read(make([]byte, pos))
+		state, err := d.MarshalBinary()
+		require.NoError(t, err)
+		assert.Len(t, state, 40)
+
+		var r DRBG
+		require.NoError(t, r.UnmarshalBinary(state))
+		b := make([]byte, 100-pos)
+		r.Read(b)
+		assert.Equal(t, want[pos:], b, "position %d", pos)
+	}
+
+	var r DRBG
+	assert.Error(t, r.UnmarshalBinary(make([]byte, 39)))
+}
+
+func TestMathSource(t *testing.T) {
+	r := New(NewDRBG([]byte("quick"))).MathRand()
+	for i := 0; i < 100; i++ {
+		assert.True(t, r.Int63() >= 0)
+	}
+
+	cfg := &quick.Config{Rand: New(NewDRBG(nil)).MathRand()}
+	err := quick.Check(func(n uint16) bool {
+		if n == 0 {
+			return true
+		}
+		return Intn(int(n)) < int(n)
+	}, cfg)
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() {
+		MathSource(NewDRBG(nil)).Seed(1)
+	})
+}
@@ -0,0 +1,91 @@
+package rng
+
+import "io"
+
+// Encoding selects how ReadUint64Bits turns source bytes into integers. The
+// zero value is the default encoding: bytes are little endian and the least
+// significant bits are kept. Other encodings reproduce draws of RNGs in other
+// languages byte for byte, e.g. a Java implementation reading big endian
+// integers from a byte stream.
+type Encoding struct {
+	// BigEndian interprets the bytes of a read as a big endian integer,
+	// first byte most significant.
+	BigEndian bool
+	// MSBFirst keeps the n most significant bits of the read bytes instead of
+	// the n least significant ones when n is not a multiple of 8.
+	MSBFirst bool
+}
+
+// ReadUint64BitsEncoding reads a random uint64 value in range [0, 2^n) from a
+// random source decoding the read bytes with a given encoding. It reads the
+// same (n+7)/8 bytes as ReadUint64Bits, with the zero Encoding both return the
+// same value.
+//
+// It will panic if random source returns read error.
+func ReadUint64BitsEncoding(src io.Reader, n uint, enc Encoding) uint64 {
+	if n > 64 {
+		panic("abr.Uint64Bits can not return more than 64 random bits")
+	}
+
+	bytes := (n + 7) / 8
+	b := make([]byte, 8)
+	if _, e := io.ReadFull(src, b[:bytes]); e != nil {
+		panic(e)
+	}
+	var r uint64
+	for i := uint(0); i < bytes; i++ {
+		if enc.BigEndian {
+			r = r<<8 | uint64(b[i])
+		} else {
+			r |= uint64(b[i]) << (8 * i)
+		}
+	}
+	if enc.MSBFirst {
+		// drop the least significant surplus bits
+		return r >> (8*bytes - n)
+	}
+	return r & ((1 << n) - 1)
+}
+
+// EncodedSource returns a source passing bytes of src through unchanged that
+// makes ReadUint64Bits, and all draws built on it, decode them with a given
+// encoding. Draws reading raw bytes, e.g. ReadIntnConstantTime, are not
+// affected. The encoding is lost if the returned source is wrapped in another
+// reader, wrap src instead. Generators created by New keep the encoding
+// separately from the source, so it survives middleware, scopes, checked
+// and published draws.
+func EncodedSource(src io.Reader, enc Encoding) io.Reader {
+	if e, ok := src.(*encodedSource); ok {
+		src = e.src
+	}
+	if enc == (Encoding{}) {
+		return src
+	}
+	return &encodedSource{src: src, enc: enc}
+}
+
+// sourceEncoding splits a source returned by EncodedSource into the wrapped
+// source and its encoding. Other sources have the zero Encoding.
+func sourceEncoding(src io.Reader) (io.Reader, Encoding) {
+	if e, ok := src.(*encodedSource); ok {
+		return e.src, e.enc
+	}
+	return src, Encoding{}
+}
+
+type encodedSource struct {
+	src io.Reader
+	enc Encoding
+}
+
+func (e *encodedSource) Read(p []byte) (int, error) {
+	return e.src.Read(p)
+}
+
+// WithEncoding returns a Generator reading the same source as g decoding its
+// bytes with a given encoding, see EncodedSource. The encoding applies to all
+// draws made through the generator, including Chain, Scope, Checked,
+// PublishingGenerator and IdempotentGenerator draws.
+func (g *Generator) WithEncoding(enc Encoding) *Generator {
+	return &Generator{src: g.src, enc: enc}
+}
@@ -0,0 +1,90 @@
+package rng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadUint64BitsEncoding(t *testing.T) {
+	tests := []struct {
+		enc      Encoding
+		n        uint
+		expected uint64
+	}{
+		{Encoding{}, 12, 0x201},
+		{Encoding{BigEndian: true}, 12, 0x182},
+		{Encoding{MSBFirst: true}, 12, 0x820},
+		{Encoding{BigEndian: true, MSBFirst: true}, 12, 0x018},
+		{Encoding{BigEndian: true}, 16, 0x0182},
+		{Encoding{}, 1, 1},
+		{Encoding{MSBFirst: true}, 1, 0},
+		{Encoding{BigEndian: true, MSBFirst: true}, 0, 0},
+	}
+	for _, test := range tests {
+		src := bytes.NewBuffer([]byte{0x01, 0x82})
+		assert.Equal(t, test.expected, ReadUint64BitsEncoding(src, test.n, test.enc), "%+v n=%d", test.enc, test.n)
+	}
+
+	b := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	assert.Equal(t, binary.BigEndian.Uint64(b), ReadUint64BitsEncoding(bytes.NewBuffer(b), 64, Encoding{BigEndian: true, MSBFirst: true}))
+	assert.Equal(t, ReadUint64Bits(bytes.NewBuffer(b), 64), ReadUint64BitsEncoding(bytes.NewBuffer(b), 64, Encoding{}))
+
+	assert.Panics(t, func() { ReadUint64BitsEncoding(bytes.NewBuffer(b), 65, Encoding{}) })
+	assert.Panics(t, func() { ReadUint64BitsEncoding(bytes.NewBuffer(nil), 8, Encoding{BigEndian: true}) })
+}
+
+func TestEncodedSource(t *testing.T) {
+	b := []byte{0x12, 0x34, 0x56, 0x78}
+	src := EncodedSource(bytes.NewBuffer(b), Encoding{BigEndian: true})
+	assert.Equal(t, uint64(0x1234), ReadUint64Bits(src, 16))
+	assert.Equal(t, int(binary.BigEndian.Uint16(b[2:])), ReadIntn(src, 1<<16))
+
+	// default encoding and re-encoding unwrap the source
+	buf := bytes.NewBuffer(nil)
+	assert.Equal(t, buf, EncodedSource(buf, Encoding{}))
+	assert.Equal(t, buf, EncodedSource(EncodedSource(buf, Encoding{MSBFirst: true}), Encoding{}))
+
+	g := New(bytes.NewBuffer(b)).WithEncoding(Encoding{BigEndian: true})
+	assert.Equal(t, int32(0x12345678), g.Int31n(1<<31-1))
+	le := New(bytes.NewBuffer(b))
+	assert.Equal(t, int32(0x78563412), le.Int31n(1<<31-1))
+}
+
+func TestGeneratorEncodingWrapped(t *testing.T) {
+	be := Encoding{BigEndian: true}
+	g := func() *Generator { return New(NewDRBG([]byte("seed"))).WithEncoding(be) }
+	expected := g().Intn(1000000)
+	expectedFloat := g().Float64()
+
+	// the encoding survives every source wrapper
+	chain := NewChain(g(), Observe(func(*DrawCall, []int64, error, int64, time.Duration) {}), Audit(PublisherFunc(func(*DrawResult) error { return nil }), nil))
+	assert.Equal(t, expected, chain.Intn(1000000))
+	assert.Equal(t, expectedFloat, NewChain(g(), Observe(func(*DrawCall, []int64, error, int64, time.Duration) {})).Float64())
+
+	v, err := g().Scope("round", Quota{}).Intn(1000000)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, v)
+	v, err = g().Checked().Intn(1000000)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, v)
+
+	var d *DrawResult
+	NewPublishing(g(), PublisherFunc(func(r *DrawResult) error {
+		d = r
+		return nil
+	})).Intn(1000000)
+	assert.Equal(t, []int64{int64(expected)}, d.Outcome)
+	assert.NoError(t, VerifyDrawResult(EncodedSource(NewDRBG([]byte("seed")), be), d))
+
+	v, err = NewIdempotent(g(), &MemoryDrawStore{}).Intn("id", 1000000)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, v)
+
+	assert.Equal(t, expected, New(EncodedSource(NewDRBG([]byte("seed")), be)).Intn(1000000))
+	assert.Equal(t, expected, ReadIntn(g().Source(), 1000000))
+	assert.NotEqual(t, expected, New(NewDRBG([]byte("seed"))).Intn(1000000))
+}
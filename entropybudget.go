@@ -0,0 +1,231 @@
+package rng
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultEntropySamples is the number of draws measured per spec by
+// EntropyBudget.Register. Specs drawing many values are measured with fewer
+// draws, so that at most maxEntropyValues values are drawn.
+const DefaultEntropySamples = 1000
+
+// maxEntropyValues bounds the number of values drawn to measure a spec.
+const maxEntropyValues = 1 << 16
+
+// MeasureEntropy returns the mean number of source bytes consumed by a draw
+// of a given spec over samples draws from a DRBG. Draws consume source bytes
+// independently of the source contents on average, so the estimate holds for
+// any source. It will panic if the spec is invalid or samples <= 0.
+func MeasureEntropy(spec DrawSpec, samples int) float64 {
+	run, err := specRun(spec)
+	if err != nil || samples <= 0 {
+		panic(fmt.Sprintf("invalid argument to MeasureEntropy: %v", err))
+	}
+	c := &byteCounter{src: NewDRBG([]byte("advbet/rng entropy forecast"))}
+	for i := 0; i < samples; i++ {
+		run(c)
+	}
+	return float64(c.n) / float64(samples)
+}
+
+// MeasureThroughput reads n bytes from src and returns the observed
+// throughput in bytes per second, e.g. to configure an EntropyBudget for an
+// HSM source.
+func MeasureThroughput(src io.Reader, n int) (float64, error) {
+	buf := make([]byte, 4096)
+	start := time.Now()
+	for read := 0; read < n; {
+		m := min(len(buf), n-read)
+		if _, err := io.ReadFull(src, buf[:m]); err != nil {
+			return 0, err
+		}
+		read += m
+	}
+	return float64(n) / time.Since(start).Seconds(), nil
+}
+
+// EntropyDemand is the forecast entropy consumption of a registered draw
+// spec.
+type EntropyDemand struct {
+	Name string   `json:"name"`
+	Spec DrawSpec `json:"spec"`
+	// Rate is the expected number of draws per second.
+	Rate float64 `json:"rate"`
+	// BytesPerDraw is the measured mean number of source bytes per draw.
+	BytesPerDraw float64 `json:"bytes_per_draw"`
+	// BitsPerSecond is the projected consumption, 8·BytesPerDraw·Rate.
+	BitsPerSecond float64 `json:"bits_per_second"`
+}
+
+// EntropyForecast is the projected entropy consumption of all registered
+// draw specs.
+type EntropyForecast struct {
+	// Demands lists registered specs by name.
+	Demands []EntropyDemand `json:"demands"`
+	// BitsPerSecond is the total projected consumption.
+	BitsPerSecond float64 `json:"bits_per_second"`
+	// CapacityBitsPerSecond is the configured source throughput.
+	CapacityBitsPerSecond float64 `json:"capacity_bits_per_second"`
+	// Utilization is BitsPerSecond / CapacityBitsPerSecond.
+	Utilization float64 `json:"utilization"`
+	// OverBudget is true if utilization exceeds the alert threshold.
+	OverBudget bool `json:"over_budget"`
+}
+
+// EntropyBudget forecasts entropy consumption of draw specs at their expected
+// call rates and alerts when the projection exceeds the throughput of the
+// source, so launching a new game can not stall draws in production. It is
+// safe for concurrent use.
+type EntropyBudget struct {
+	// Throughput is the sustained source throughput in bytes per second,
+	// e.g. the rate of a RateLimitedSource or MeasureThroughput of an HSM.
+	Throughput float64
+	// Threshold is the share of Throughput at which OnAlert is called, 0
+	// means 1, i.e. alerting once projected consumption exceeds throughput.
+	Threshold float64
+	// OnAlert, if set, is called with the forecast whenever a registration
+	// or rate change moves it over budget.
+	OnAlert func(EntropyForecast)
+
+	mu      sync.Mutex
+	demands map[string]EntropyDemand
+	over    bool
+}
+
+// Register adds or replaces a named draw spec called rate times per second
+// and returns the new forecast. Entropy per draw is measured with
+// MeasureEntropy. It returns an error for invalid specs, specs drawing more
+// than MaxDrawSize values, rates that are negative or infinite and a negative
+// Throughput.
+func (b *EntropyBudget) Register(name string, spec DrawSpec, rate float64) (EntropyForecast, error) {
+	if _, err := specRun(spec); err != nil {
+		return EntropyForecast{}, fmt.Errorf("rng: draw spec %q: %w", name, err)
+	}
+	size := specSize(spec)
+	if size > MaxDrawSize {
+		return EntropyForecast{}, fmt.Errorf("rng: draw spec %q: size %d exceeds %d", name, size, MaxDrawSize)
+	}
+	if err := checkRate(name, rate); err != nil {
+		return EntropyForecast{}, err
+	}
+	samples := int(min(DefaultEntropySamples, max(1, maxEntropyValues/max(1, size))))
+	bytes := MeasureEntropy(spec, samples)
+	return b.update(func() error {
+		if b.demands == nil {
+			b.demands = make(map[string]EntropyDemand)
+		}
+		b.demands[name] = EntropyDemand{
+			Name:          name,
+			Spec:          spec,
+			Rate:          rate,
+			BytesPerDraw:  bytes,
+			BitsPerSecond: 8 * bytes * rate,
+		}
+		return nil
+	})
+}
+
+// SetRate changes the call rate of a registered spec, keeping its measured
+// entropy per draw, and returns the new forecast. It returns an error for
+// unknown names, rates that are negative or infinite and a negative
+// Throughput.
+func (b *EntropyBudget) SetRate(name string, rate float64) (EntropyForecast, error) {
+	if err := checkRate(name, rate); err != nil {
+		return EntropyForecast{}, err
+	}
+	return b.update(func() error {
+		d, ok := b.demands[name]
+		if !ok {
+			return fmt.Errorf("rng: unknown draw spec %q", name)
+		}
+		d.Rate = rate
+		d.BitsPerSecond = 8 * d.BytesPerDraw * rate
+		b.demands[name] = d
+		return nil
+	})
+}
+
+// Unregister removes a draw spec and returns the new forecast.
+func (b *EntropyBudget) Unregister(name string) EntropyForecast {
+	b.mu.Lock()
+	delete(b.demands, name)
+	f := b.forecast()
+	b.over = f.OverBudget
+	b.mu.Unlock()
+	return f
+}
+
+// Forecast returns the projected consumption of registered specs.
+func (b *EntropyBudget) Forecast() EntropyForecast {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.forecast()
+}
+
+// update applies a change under the lock and alerts if it moves the forecast
+// over budget. Nothing is changed if Throughput is invalid or change fails.
+func (b *EntropyBudget) update(change func() error) (EntropyForecast, error) {
+	b.mu.Lock()
+	if !(b.Throughput >= 0) || math.IsInf(b.Throughput, 1) {
+		b.mu.Unlock()
+		return EntropyForecast{}, fmt.Errorf("rng: invalid throughput %v", b.Throughput)
+	}
+	if err := change(); err != nil {
+		b.mu.Unlock()
+		return EntropyForecast{}, err
+	}
+	f := b.forecast()
+	alert := f.OverBudget && !b.over
+	b.over = f.OverBudget
+	b.mu.Unlock()
+	if alert && b.OnAlert != nil {
+		b.OnAlert(f)
+	}
+	return f, nil
+}
+
+// checkRate returns an error unless rate is a finite number >= 0.
+func checkRate(name string, rate float64) error {
+	if !(rate >= 0) || math.IsInf(rate, 1) {
+		return fmt.Errorf("rng: draw spec %q: invalid rate %v", name, rate)
+	}
+	return nil
+}
+
+// specSize returns the number of values drawn by a valid spec.
+func specSize(s DrawSpec) int64 {
+	switch s.Type {
+	case "perm", "shuffle":
+		return s.Params["n"]
+	case "sample":
+		return min(s.Params["n"], s.Params["k"])
+	}
+	return 1
+}
+
+func (b *EntropyBudget) forecast() EntropyForecast {
+	f := EntropyForecast{Demands: []EntropyDemand{}, CapacityBitsPerSecond: 8 * b.Throughput}
+	for _, d := range b.demands {
+		f.Demands = append(f.Demands, d)
+		f.BitsPerSecond += d.BitsPerSecond
+	}
+	sort.Slice(f.Demands, func(i, j int) bool { return f.Demands[i].Name < f.Demands[j].Name })
+	threshold := b.Threshold
+	if threshold == 0 {
+		threshold = 1
+	}
+	switch {
+	case f.CapacityBitsPerSecond > 0:
+		f.Utilization = f.BitsPerSecond / f.CapacityBitsPerSecond
+		f.OverBudget = f.Utilization > threshold
+	case f.BitsPerSecond > 0:
+		// no throughput configured, any consumption is over budget
+		f.OverBudget = true
+	}
+	return f
+}
@@ -0,0 +1,115 @@
+package rng
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureEntropy(t *testing.T) {
+	assert.Equal(t, 1.0, MeasureEntropy(DrawSpec{Type: "intn", Params: map[string]int64{"n": 256}}, 100))
+	// 53 random bits are read as 7 bytes
+	assert.Equal(t, 7.0, MeasureEntropy(DrawSpec{Type: "float64"}, 100))
+	// rejection sampling reads more than the minimum on average
+	b := MeasureEntropy(DrawSpec{Type: "intn", Params: map[string]int64{"n": 129}}, 1000)
+	assert.True(t, b > 1 && b < 2.1, "%v bytes", b)
+	assert.True(t, MeasureEntropy(DrawSpec{Type: "perm", Params: map[string]int64{"n": 52}}, 100) > 51)
+
+	assert.Panics(t, func() { MeasureEntropy(DrawSpec{Type: "dice"}, 1) })
+	assert.Panics(t, func() { MeasureEntropy(DrawSpec{Type: "float64"}, 0) })
+}
+
+func TestMeasureThroughput(t *testing.T) {
+	r, err := MeasureThroughput(NewDRBG(nil), 10000)
+	require.NoError(t, err)
+	assert.True(t, r > 0)
+	_, err = MeasureThroughput(iotest.ErrReader(errors.New("hsm down")), 10)
+	assert.Error(t, err)
+}
+
+func TestEntropyBudget(t *testing.T) {
+	var alerts []EntropyForecast
+	b := &EntropyBudget{
+		Throughput: 1000, // 8000 bits per second
+		Threshold:  0.8,
+		OnAlert:    func(f EntropyForecast) { alerts = append(alerts, f) },
+	}
+
+	f, err := b.Register("roulette", DrawSpec{Type: "intn", Params: map[string]int64{"n": 256}}, 500)
+	require.NoError(t, err)
+	assert.Equal(t, 4000.0, f.BitsPerSecond)
+	assert.Equal(t, 8000.0, f.CapacityBitsPerSecond)
+	assert.Equal(t, 0.5, f.Utilization)
+	assert.False(t, f.OverBudget)
+	assert.Empty(t, alerts)
+
+	// a new game pushes consumption past the threshold
+	f, err = b.Register("crash", DrawSpec{Type: "float64"}, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 6800.0, f.BitsPerSecond)
+	assert.True(t, f.OverBudget)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, f, alerts[0])
+	assert.Equal(t, []string{"crash", "roulette"}, []string{f.Demands[0].Name, f.Demands[1].Name})
+	assert.Equal(t, 7.0, f.Demands[0].BytesPerDraw)
+
+	// staying over budget does not alert again
+	_, err = b.SetRate("crash", 60)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1)
+
+	f = b.Unregister("crash")
+	assert.False(t, f.OverBudget)
+	_, err = b.SetRate("crash", 10)
+	assert.Error(t, err)
+	f, err = b.SetRate("roulette", 900)
+	require.NoError(t, err)
+	assert.True(t, f.OverBudget)
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, f, b.Forecast())
+
+	_, err = b.Register("bad", DrawSpec{Type: "dice"}, 1)
+	assert.Error(t, err)
+	_, err = b.Register("bad", DrawSpec{Type: "float64"}, -1)
+	assert.Error(t, err)
+
+	// without throughput any consumption is over budget
+	f, err = (&EntropyBudget{}).Register("crash", DrawSpec{Type: "float64"}, 1)
+	require.NoError(t, err)
+	assert.True(t, f.OverBudget)
+}
+
+func TestEntropyBudgetInvalid(t *testing.T) {
+	b := &EntropyBudget{Throughput: 1000}
+	_, err := b.Register("crash", DrawSpec{Type: "float64"}, math.Inf(1))
+	assert.Error(t, err)
+	_, err = b.Register("crash", DrawSpec{Type: "float64"}, math.NaN())
+	assert.Error(t, err)
+	_, err = b.Register("deck", DrawSpec{Type: "perm", Params: map[string]int64{"n": 1 << 40}}, 1)
+	assert.Error(t, err)
+
+	// the largest specs are measured with fewer draws
+	f, err := b.Register("deck", DrawSpec{Type: "perm", Params: map[string]int64{"n": MaxDrawSize}}, 1)
+	require.NoError(t, err)
+	bytes := f.Demands[0].BytesPerDraw
+	assert.True(t, bytes > MaxDrawSize, "%v bytes", bytes)
+
+	// rate changes keep the measured entropy per draw
+	f, err = b.SetRate("deck", 2)
+	require.NoError(t, err)
+	assert.Equal(t, bytes, f.Demands[0].BytesPerDraw)
+	assert.Equal(t, 16*bytes, f.BitsPerSecond)
+	_, err = b.SetRate("deck", math.Inf(1))
+	assert.Error(t, err)
+
+	b.Throughput = -1
+	_, err = b.Register("crash", DrawSpec{Type: "float64"}, 1)
+	assert.Error(t, err)
+	_, err = b.SetRate("deck", 1)
+	assert.Error(t, err)
+	assert.Len(t, b.Forecast().Demands, 1)
+}
@@ -0,0 +1,53 @@
+package escrow
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealOpen(t *testing.T) {
+	lab, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	meta := Metadata{
+		KeyID:       "lab-2024",
+		GeneratorID: "table-7",
+		Algorithm:   AlgorithmDRBG,
+		CreatedAt:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Labels:      map[string]string{"env": "prod"},
+	}
+	data, err := Seal(seed, meta, lab.Recipient())
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("-----BEGIN RNG SEED ESCROW-----\n")))
+	assert.False(t, bytes.Contains(data, seed))
+
+	id, err := KeyID(data)
+	require.NoError(t, err)
+	assert.Equal(t, "lab-2024", id)
+
+	env, err := Open(data, lab)
+	require.NoError(t, err)
+	assert.Equal(t, seed, env.Seed)
+	assert.Equal(t, meta, env.Metadata)
+
+	_, err = Open(data, other)
+	assert.Error(t, err)
+
+	// tampered header must be rejected
+	tampered := bytes.Replace(data, []byte("Key-Id: lab-2024"), []byte("Key-Id: lab-2025"), 1)
+	_, err = Open(tampered, lab)
+	assert.Error(t, err)
+
+	_, err = Seal(seed, Metadata{}, lab.Recipient())
+	assert.Error(t, err)
+	_, err = Open([]byte("garbage"), lab)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// EventSeededDraw returns an outcome in [0, n) determined by public event
+// data, e.g. a block hash or match ID, and a secret salt. The value is drawn
+// from the stream of blocks
+//
+//	HMAC-SHA256(key = salt, message = "event:" hex(SHA-256(eventData)) ":" counter)
+//
+// with unbiased reduction. Publishing a commitment to the salt (see Commit)
+// before the event takes place prevents choosing a salt favouring a known
+// outcome, revealing it afterwards lets anyone check the result with
+// VerifyEventSeededDraw. It will panic if n <= 0.
+func EventSeededDraw(eventData []byte, salt []byte, n int) int {
+	h := sha256.Sum256(eventData)
+	src := &fairSource{
+		mac:    hmac.New(sha256.New, salt),
+		prefix: "event:" + hex.EncodeToString(h[:]) + ":",
+	}
+	return ReadIntn(src, n)
+}
+
+// VerifyEventSeededDraw reports whether outcome is the result of
+// EventSeededDraw for given event data, revealed salt and n.
+func VerifyEventSeededDraw(eventData []byte, salt []byte, n int, outcome int) bool {
+	if n <= 0 {
+		return false
+	}
+	return EventSeededDraw(eventData, salt, n) == outcome
+}
@@ -0,0 +1,32 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSeededDraw(t *testing.T) {
+	event := []byte("match 4711: 2-1")
+	salt := []byte("promo salt")
+
+	v := EventSeededDraw(event, salt, 1<<16)
+	assert.Equal(t, v, EventSeededDraw(event, salt, 1<<16))
+	assert.NotEqual(t, v, EventSeededDraw([]byte("match 4711: 2-2"), salt, 1<<16))
+	assert.NotEqual(t, v, EventSeededDraw(event, []byte("other salt"), 1<<16))
+
+	// n = 2^16 takes the first two bytes of the first block little endian
+	h := sha256.Sum256(event)
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte("event:" + hex.EncodeToString(h[:]) + ":0"))
+	assert.Equal(t, int(binary.LittleEndian.Uint16(mac.Sum(nil))), v)
+
+	assert.True(t, VerifyEventSeededDraw(event, salt, 1<<16, v))
+	assert.False(t, VerifyEventSeededDraw(event, salt, 1<<16, v+1))
+	assert.False(t, VerifyEventSeededDraw(event, salt, 0, 0))
+	assert.Panics(t, func() { EventSeededDraw(event, salt, 0) })
+}
@@ -0,0 +1,203 @@
+// Package experiments assigns units (users, sessions) to A/B/n experiment
+// variants.
+//
+// Assignment is either sticky, derived from a salted hash of the unit ID so
+// the same unit always sees the same variant, or a fresh draw from a random
+// source. Experiments support holdouts and mutual exclusivity groups, and
+// every assignment is emitted as an rng.DrawResult for audit. All draws use
+// the unbiased rng primitives, weights are honoured exactly.
+package experiments
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/advbet/rng"
+)
+
+// HoldoutScale is the denominator of Experiment.Holdout, holdouts are given
+// in basis points.
+const HoldoutScale = 10000
+
+// Outcome values of assignment draw results that are not variant indexes.
+const (
+	OutcomeHoldout  = -1
+	OutcomeExcluded = -2
+)
+
+// ErrUnknownExperiment is returned for experiment names not registered with
+// the Manager.
+var ErrUnknownExperiment = errors.New("experiments: unknown experiment")
+
+// Variant is an experiment arm.
+type Variant struct {
+	Name string
+	// Weight is the relative share of units assigned to the variant.
+	Weight int
+}
+
+// Experiment defines an A/B/n experiment.
+type Experiment struct {
+	Name     string
+	Variants []Variant
+	// Holdout is the share of units, in basis points, kept out of the
+	// experiment entirely.
+	Holdout int
+	// Group names a mutual exclusivity group. A unit takes part in at most
+	// one experiment of a group, the experiment is chosen uniformly. Empty
+	// group means no exclusivity.
+	Group string
+}
+
+// Assignment is the result of assigning a unit to an experiment.
+type Assignment struct {
+	Experiment string
+	// Variant is the assigned variant name, empty if the unit is held out
+	// or excluded.
+	Variant string
+	// Holdout is true if the unit is in the experiment holdout.
+	Holdout bool
+	// Excluded is true if the unit takes part in another experiment of the
+	// same exclusivity group.
+	Excluded bool
+	// Draw is the audit record of the assignment. Its Outcome holds the
+	// variant index, OutcomeHoldout or OutcomeExcluded.
+	Draw *rng.DrawResult
+}
+
+// Manager assigns units to registered experiments. It is safe for concurrent
+// use if its source and publisher are.
+type Manager struct {
+	salt        []byte
+	src         io.Reader
+	experiments map[string]*Experiment
+	groups      map[string][]string // experiment names by group
+	totals      map[string]int      // variant weight sums by experiment
+
+	// Publisher, if set, receives the draw result of every assignment.
+	Publisher rng.Publisher
+	// OnError is called with publish errors. If nil errors are ignored.
+	OnError func(error)
+}
+
+// New returns a Manager for given experiments. Sticky assignments are keyed
+// by salt, fresh draws read from src or crypto/rand.Reader if src is nil. It
+// returns an error if experiments are invalid: duplicate or empty names, no
+// variants, non positive weights or holdout out of [0, HoldoutScale].
+func New(salt []byte, src io.Reader, experiments ...Experiment) (*Manager, error) {
+	m := &Manager{
+		salt:        append([]byte(nil), salt...),
+		src:         rng.New(src).Source(),
+		experiments: make(map[string]*Experiment),
+		groups:      make(map[string][]string),
+		totals:      make(map[string]int),
+	}
+	for i := range experiments {
+		e := experiments[i]
+		if e.Name == "" {
+			return nil, fmt.Errorf("experiments: experiment %d has no name", i)
+		}
+		if _, ok := m.experiments[e.Name]; ok {
+			return nil, fmt.Errorf("experiments: duplicate experiment %q", e.Name)
+		}
+		if len(e.Variants) == 0 {
+			return nil, fmt.Errorf("experiments: experiment %q has no variants", e.Name)
+		}
+		if e.Holdout < 0 || e.Holdout > HoldoutScale {
+			return nil, fmt.Errorf("experiments: experiment %q holdout out of range", e.Name)
+		}
+		total := 0
+		for _, v := range e.Variants {
+			if v.Weight <= 0 || total > math.MaxInt32-v.Weight {
+				return nil, fmt.Errorf("experiments: experiment %q has invalid weights", e.Name)
+			}
+			total += v.Weight
+		}
+		e.Variants = append([]Variant(nil), e.Variants...)
+		m.experiments[e.Name] = &e
+		m.totals[e.Name] = total
+		if e.Group != "" {
+			m.groups[e.Group] = append(m.groups[e.Group], e.Name)
+		}
+	}
+	return m, nil
+}
+
+// Assign returns the sticky assignment of a unit. The same salt, experiment
+// definitions and unit ID always give the same assignment.
+func (m *Manager) Assign(experiment, unitID string) (Assignment, error) {
+	return m.assign(experiment, func(purpose, name string) io.Reader {
+		// length prefixes keep (purpose, name, unit) triples unambiguous
+		label := fmt.Sprintf("%s/%d/%s/%s", purpose, len(name), name, unitID)
+		return rng.FairSource(m.salt, label, 0)
+	})
+}
+
+// Draw returns a fresh random assignment, e.g. for anonymous traffic without
+// a stable unit ID. Exclusivity group membership is drawn afresh as well.
+func (m *Manager) Draw(experiment string) (Assignment, error) {
+	return m.assign(experiment, func(purpose, name string) io.Reader {
+		return m.src
+	})
+}
+
+func (m *Manager) assign(name string, source func(purpose, name string) io.Reader) (Assignment, error) {
+	e, ok := m.experiments[name]
+	if !ok {
+		return Assignment{}, ErrUnknownExperiment
+	}
+
+	a := Assignment{Experiment: name}
+	d := &rng.DrawResult{
+		ID:   rng.NewDrawID(),
+		Type: "experiment/" + name,
+		Params: map[string]int64{
+			"variants": int64(len(e.Variants)),
+			"holdout":  int64(e.Holdout),
+		},
+		StartedAt: time.Now().UTC(),
+	}
+	h := sha256.New()
+	read := func(purpose, name string) io.Reader {
+		return io.TeeReader(source(purpose, name), h)
+	}
+
+	outcome := 0
+	if members := m.groups[e.Group]; len(members) > 1 {
+		d.Params["group_size"] = int64(len(members))
+		if members[rng.ReadIntn(read("group", e.Group), len(members))] != name {
+			a.Excluded = true
+			outcome = OutcomeExcluded
+		}
+	}
+	if !a.Excluded && e.Holdout > 0 && rng.ReadIntn(read("holdout", name), HoldoutScale) < e.Holdout {
+		a.Holdout = true
+		outcome = OutcomeHoldout
+	}
+	if !a.Excluded && !a.Holdout {
+		r := rng.ReadIntn(read("variant", name), m.totals[name])
+		for i, v := range e.Variants {
+			if r < v.Weight {
+				outcome = i
+				a.Variant = v.Name
+				break
+			}
+			r -= v.Weight
+		}
+	}
+
+	d.Outcome = []int64{int64(outcome)}
+	d.EntropyDigest = h.Sum(nil)
+	d.FinishedAt = time.Now().UTC()
+	a.Draw = d
+	if m.Publisher != nil {
+		if err := m.Publisher.Publish(d); err != nil && m.OnError != nil {
+			m.OnError(err)
+		}
+	}
+	return a, nil
+}
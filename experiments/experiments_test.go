@@ -0,0 +1,112 @@
+package experiments
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newManager(t *testing.T) *Manager {
+	m, err := New([]byte("salt"), rng.NewDRBG([]byte("seed")),
+		Experiment{
+			Name:     "odds-layout",
+			Variants: []Variant{{"control", 1}, {"compact", 1}, {"wide", 2}},
+			Holdout:  1000,
+		},
+		Experiment{Name: "bonus-a", Variants: []Variant{{"on", 1}}, Group: "bonus"},
+		Experiment{Name: "bonus-b", Variants: []Variant{{"on", 1}}, Group: "bonus"},
+	)
+	require.NoError(t, err)
+	return m
+}
+
+func TestAssign(t *testing.T) {
+	m := newManager(t)
+	var published []*rng.DrawResult
+	m.Publisher = rng.PublisherFunc(func(d *rng.DrawResult) error {
+		published = append(published, d)
+		return nil
+	})
+
+	a, err := m.Assign("odds-layout", "user-1")
+	require.NoError(t, err)
+	b, err := m.Assign("odds-layout", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, a.Variant, b.Variant)
+	assert.Equal(t, a.Draw.Outcome, b.Draw.Outcome)
+	assert.Equal(t, a.Draw.EntropyDigest, b.Draw.EntropyDigest)
+	assert.NotEqual(t, a.Draw.ID, b.Draw.ID)
+	assert.Equal(t, []*rng.DrawResult{a.Draw, b.Draw}, published)
+	assert.Equal(t, "experiment/odds-layout", a.Draw.Type)
+
+	_, err = m.Assign("unknown", "user-1")
+	assert.Equal(t, ErrUnknownExperiment, err)
+}
+
+func TestAssignDistribution(t *testing.T) {
+	m := newManager(t)
+	const units = 20000
+	counts := make(map[string]int)
+	for i := 0; i < units; i++ {
+		a, err := m.Assign("odds-layout", fmt.Sprintf("user-%d", i))
+		require.NoError(t, err)
+		if a.Holdout {
+			counts["holdout"]++
+			assert.Equal(t, []int64{OutcomeHoldout}, a.Draw.Outcome)
+			continue
+		}
+		counts[a.Variant]++
+	}
+	assert.InDelta(t, 2000, counts["holdout"], 200)
+	assert.InDelta(t, 4500, counts["control"], 300)
+	assert.InDelta(t, 4500, counts["compact"], 300)
+	assert.InDelta(t, 9000, counts["wide"], 400)
+}
+
+func TestMutualExclusivity(t *testing.T) {
+	m := newManager(t)
+	inA := 0
+	for i := 0; i < 1000; i++ {
+		unit := fmt.Sprintf("user-%d", i)
+		a, err := m.Assign("bonus-a", unit)
+		require.NoError(t, err)
+		b, err := m.Assign("bonus-b", unit)
+		require.NoError(t, err)
+		// exactly one experiment of the group includes the unit
+		assert.NotEqual(t, a.Excluded, b.Excluded)
+		if !a.Excluded {
+			inA++
+			assert.Equal(t, "on", a.Variant)
+			assert.Equal(t, []int64{OutcomeExcluded}, b.Draw.Outcome)
+		}
+	}
+	assert.InDelta(t, 500, inA, 80)
+}
+
+func TestDraw(t *testing.T) {
+	m := newManager(t)
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		a, err := m.Draw("odds-layout")
+		require.NoError(t, err)
+		seen[a.Variant] = true
+	}
+	assert.Len(t, seen, 4) // three variants and holdout
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, e := range []Experiment{
+		{Variants: []Variant{{"a", 1}}},
+		{Name: "x"},
+		{Name: "x", Variants: []Variant{{"a", 0}}},
+		{Name: "x", Variants: []Variant{{"a", 1}}, Holdout: HoldoutScale + 1},
+	} {
+		_, err := New(nil, nil, e)
+		assert.Error(t, err, "%+v", e)
+	}
+	_, err := New(nil, nil, Experiment{Name: "x", Variants: []Variant{{"a", 1}}}, Experiment{Name: "x", Variants: []Variant{{"a", 1}}})
+	assert.Error(t, err)
+}
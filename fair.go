@@ -0,0 +1,54 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"strconv"
+)
+
+// FairSource returns the provably fair random stream of a single draw. Stream
+// is the concatenation of blocks
+//
+//	HMAC-SHA256(key = serverSeed, message = clientSeed ":" nonce ":" counter)
+//
+// where nonce and counter are decimal numbers and counter starts at 0. Anyone
+// knowing the revealed server seed can recompute the stream and every outcome
+// drawn from it.
+func FairSource(serverSeed []byte, clientSeed string, nonce uint64) io.Reader {
+	return &fairSource{
+		mac:    hmac.New(sha256.New, serverSeed),
+		prefix: clientSeed + ":" + strconv.FormatUint(nonce, 10) + ":",
+	}
+}
+
+type fairSource struct {
+	mac     hash.Hash
+	prefix  string
+	counter uint64
+	buf     []byte
+}
+
+func (f *fairSource) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(f.buf) == 0 {
+			f.mac.Reset()
+			io.WriteString(f.mac, f.prefix+strconv.FormatUint(f.counter, 10))
+			f.buf = f.mac.Sum(nil)
+			f.counter++
+		}
+		c := copy(p[n:], f.buf)
+		f.buf = f.buf[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// HashServerSeed returns SHA-256 of a server seed, the value published to
+// players before the draw as a commitment to the seed.
+func HashServerSeed(serverSeed []byte) []byte {
+	h := sha256.Sum256(serverSeed)
+	return h[:]
+}
@@ -0,0 +1,36 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairSource(t *testing.T) {
+	serverSeed := []byte("server seed")
+	buf := make([]byte, 40)
+	_, err := io.ReadFull(FairSource(serverSeed, "client", 7), buf)
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha256.New, serverSeed)
+	mac.Write([]byte("client:7:0"))
+	block0 := mac.Sum(nil)
+	mac.Reset()
+	mac.Write([]byte("client:7:1"))
+	block1 := mac.Sum(nil)
+	assert.Equal(t, append(block0, block1[:8]...), buf)
+
+	a := New(FairSource(serverSeed, "client", 7)).Perm(52)
+	b := New(FairSource(serverSeed, "client", 7)).Perm(52)
+	c := New(FairSource(serverSeed, "client", 8)).Perm(52)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestHashServerSeed(t *testing.T) {
+	h := sha256.Sum256([]byte("seed"))
+	assert.Equal(t, h[:], HashServerSeed([]byte("seed")))
+}
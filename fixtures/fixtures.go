@@ -0,0 +1,98 @@
+// Package fixtures generates random test data: hardware addresses, IP
+// addresses and digit strings.
+//
+// Every function has a Read variant accepting a random source, passing a
+// deterministic source makes generated data reproducible.
+package fixtures
+
+import (
+	"io"
+	"net"
+	"strings"
+
+	"github.com/advbet/rng"
+)
+
+// MAC returns a random locally administered unicast MAC-48 address.
+func MAC() net.HardwareAddr {
+	return ReadMAC(rng.DefaultSource())
+}
+
+// IP returns a random IP address within a given network. Network and host
+// addresses are not excluded.
+func IP(network *net.IPNet) net.IP {
+	return ReadIP(rng.DefaultSource(), network)
+}
+
+// CIDR returns a random IP address within a network given in CIDR notation,
+// e.g. "10.0.0.0/8" or "2001:db8::/32". It will panic if cidr is malformed.
+func CIDR(cidr string) net.IP {
+	return ReadCIDR(rng.DefaultSource(), cidr)
+}
+
+// Digits returns a copy of pattern with every '#' replaced by a random decimal
+// digit, e.g. "+370 6## #####". Other characters are copied as is.
+func Digits(pattern string) string {
+	return ReadDigits(rng.DefaultSource(), pattern)
+}
+
+// ReadMAC returns a random locally administered unicast MAC-48 address reading
+// randomness from a given source.
+func ReadMAC(src io.Reader) net.HardwareAddr {
+	mac := readBytes(src, 6)
+	// clear multicast bit, set locally administered bit
+	mac[0] = mac[0]&^0x01 | 0x02
+	return net.HardwareAddr(mac)
+}
+
+// ReadIP returns a random IP address within a given network reading
+// randomness from a given source.
+func ReadIP(src io.Reader, network *net.IPNet) net.IP {
+	base := network.IP
+	if ip4 := base.To4(); ip4 != nil && len(network.Mask) == net.IPv4len {
+		base = ip4
+	}
+	if len(base) != len(network.Mask) {
+		panic("fixtures: IP and mask length mismatch")
+	}
+
+	ip := readBytes(src, len(base))
+	for i := range ip {
+		ip[i] = base[i]&network.Mask[i] | ip[i]&^network.Mask[i]
+	}
+	return net.IP(ip)
+}
+
+// ReadCIDR returns a random IP address within a network given in CIDR notation
+// reading randomness from a given source.
+func ReadCIDR(src io.Reader, cidr string) net.IP {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ReadIP(src, network)
+}
+
+// ReadDigits returns a copy of pattern with every '#' replaced by a random
+// decimal digit reading randomness from a given source.
+func ReadDigits(src io.Reader, pattern string) string {
+	var b strings.Builder
+	b.Grow(len(pattern))
+	for _, r := range pattern {
+		if r == '#' {
+			b.WriteByte(byte('0' + rng.ReadIntn(src, 10)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// readBytes reads n bytes from src, it will panic on read error.
+func readBytes(src io.Reader, n int) []byte {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(src, b); err != nil {
+		panic(err)
+	}
+	return b
+}
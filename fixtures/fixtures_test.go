@@ -0,0 +1,52 @@
+package fixtures
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMAC(t *testing.T) {
+	mac := MAC()
+	assert.Len(t, mac, 6)
+	assert.Equal(t, byte(0x02), mac[0]&0x03)
+
+	mac = ReadMAC(bytes.NewReader([]byte{0xff, 1, 2, 3, 4, 5}))
+	assert.Equal(t, "fe:01:02:03:04:05", mac.String())
+}
+
+func TestCIDR(t *testing.T) {
+	tests := []string{
+		"10.0.0.0/8",
+		"192.168.1.0/24",
+		"192.168.1.7/32",
+		"0.0.0.0/0",
+		"2001:db8::/32",
+		"fe80::/64",
+	}
+
+	for _, cidr := range tests {
+		_, network, err := net.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			ip := CIDR(cidr)
+			assert.True(t, network.Contains(ip), "%s must contain %s", cidr, ip)
+		}
+	}
+
+	assert.Equal(t, "192.168.1.7", CIDR("192.168.1.7/32").String())
+	assert.Panics(t, func() {
+		CIDR("not a network")
+	})
+}
+
+func TestDigits(t *testing.T) {
+	s := Digits("+370 6## #####")
+	assert.Regexp(t, regexp.MustCompile(`^\+370 6[0-9]{2} [0-9]{5}$`), s)
+
+	assert.Equal(t, "ąčę-", Digits("ąčę-"))
+	assert.Equal(t, "a1b2", ReadDigits(bytes.NewReader([]byte{1, 2}), "a#b#"))
+}
@@ -0,0 +1,55 @@
+package rng
+
+import "io"
+
+// CoinFlips is a series of weighted coin flips with streak statistics.
+type CoinFlips struct {
+	// Flips holds the results, true is heads.
+	Flips []bool
+	// Heads is the number of heads.
+	Heads int
+	// LongestHeads and LongestTails are the lengths of the longest streaks
+	// of heads and tails.
+	LongestHeads int
+	LongestTails int
+	// Runs is the number of maximal streaks of equal results, e.g. HHTHH
+	// has 3 runs.
+	Runs int
+}
+
+// FlipSeries returns n coin flips landing heads with probability p, see
+// ReadFlipSeries.
+func FlipSeries(n int, p float64) CoinFlips {
+	return ReadFlipSeries(defaultSource(), n, p)
+}
+
+// ReadFlipSeries returns n coin flips landing heads with probability p reading
+// randomness from a given source. Flip probabilities are exact for the float64
+// p, see ReadBitset. It will panic if n < 0 or p is not in [0, 1].
+func ReadFlipSeries(src io.Reader, n int, p float64) CoinFlips {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		panic("invalid argument to FlipSeries")
+	}
+	words := ReadBitset(src, n, p)
+	s := CoinFlips{Flips: make([]bool, n)}
+	streak := 0
+	for i := range s.Flips {
+		heads := words[i/64]>>(i%64)&1 == 1
+		s.Flips[i] = heads
+		if i > 0 && heads == s.Flips[i-1] {
+			streak++
+		} else {
+			streak = 1
+			s.Runs++
+		}
+		if heads {
+			s.Heads++
+			if streak > s.LongestHeads {
+				s.LongestHeads = streak
+			}
+		} else if streak > s.LongestTails {
+			s.LongestTails = streak
+		}
+	}
+	return s
+}
@@ -0,0 +1,34 @@
+package rng
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFlips(t *testing.T) {
+	// bits 1, 1, 0, 1, 1, 1, 0, 0, 0, 0
+	src := bytes.NewBuffer([]byte{0x3b, 0x00})
+	s := ReadFlipSeries(src, 10, 0.5)
+	assert.Equal(t, CoinFlips{
+		Flips:        []bool{true, true, false, true, true, true, false, false, false, false},
+		Heads:        5,
+		LongestHeads: 3,
+		LongestTails: 4,
+		Runs:         4,
+	}, s)
+
+	assert.Equal(t, CoinFlips{Flips: []bool{}}, FlipSeries(0, 0.5))
+	all := FlipSeries(5, 1)
+	assert.Equal(t, 5, all.LongestHeads)
+	assert.Equal(t, 1, all.Runs)
+
+	// expected longest streak of 1000 fair flips is about log2(1000) ~ 10
+	s = ReadFlipSeries(NewDRBG([]byte("flips")), 1000, 0.5)
+	assert.True(t, s.LongestHeads >= 5 && s.LongestHeads <= 20, "longest heads %d", s.LongestHeads)
+	assert.InDelta(t, 500, s.Runs, 80)
+
+	assert.Panics(t, func() { FlipSeries(-1, 0.5) })
+	assert.Panics(t, func() { FlipSeries(1, -0.1) })
+}
@@ -0,0 +1,92 @@
+package rng
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// Interface is the set of draws used by game logic. It is implemented by
+// Generator and can be mocked in unit tests, see rngtest.Mock.
+type Interface interface {
+	Intn(n int) int
+	Float64() float64
+	Perm(n int) []int
+	Sample(n int, k int) []int
+	Shuffle(n int, swap func(i, j int))
+}
+
+// Generator draws random values from a single random source. Generator holds
+// no state of its own, it is safe for concurrent use if and only if its source
+// is. crypto/rand.Reader is safe for concurrent use, DRBG and most other
+// readers are not, use SafeGenerator to share those between goroutines.
+type Generator struct {
+	src io.Reader // without encoding, see reader
+	enc Encoding
+}
+
+var _ Interface = (*Generator)(nil)
+
+// New returns a Generator reading randomness from src. If src is nil
+// crypto/rand.Reader is used. If src was returned by EncodedSource the
+// generator keeps its encoding, see WithEncoding.
+func New(src io.Reader) *Generator {
+	if src == nil {
+		src = rand.Reader
+	}
+	src, enc := sourceEncoding(src)
+	return &Generator{src: src, enc: enc}
+}
+
+// Source returns the random source of the generator, wrapped by
+// EncodedSource if the generator has an encoding.
+func (g *Generator) Source() io.Reader {
+	return g.reader()
+}
+
+// reader returns the source draws read from, the generator source decoded
+// with the generator encoding.
+func (g *Generator) reader() io.Reader {
+	return EncodedSource(g.src, g.enc)
+}
+
+// Uint64Bits returns a random uint64 value in range [0, 2^n), see
+// ReadUint64Bits.
+func (g *Generator) Uint64Bits(n uint) uint64 {
+	return ReadUint64Bits(g.reader(), n)
+}
+
+// Intn returns a non negative int in [0, n), see ReadIntn.
+func (g *Generator) Intn(n int) int {
+	return ReadIntn(g.reader(), n)
+}
+
+// Int31n returns a non negative int32 in [0, n), see ReadInt31n.
+func (g *Generator) Int31n(n int32) int32 {
+	return ReadInt31n(g.reader(), n)
+}
+
+// Float64 returns a random number in [0.0,1.0), see ReadFloat64.
+func (g *Generator) Float64() float64 {
+	return ReadFloat64(g.reader())
+}
+
+// Perm returns a random permutation of integers [0,n), see ReadPerm.
+func (g *Generator) Perm(n int) []int {
+	return ReadPerm(g.reader(), n)
+}
+
+// Sample returns random k integers from a range [0 n), see ReadSample.
+func (g *Generator) Sample(n int, k int) []int {
+	return ReadSample(g.reader(), n, k)
+}
+
+// SampleSorted returns random k integers from a range [0 n) in ascending
+// order, see ReadSampleSorted.
+func (g *Generator) SampleSorted(n int, k int) []int {
+	return ReadSampleSorted(g.reader(), n, k)
+}
+
+// Shuffle randomizes the order of n elements, see ReadShuffle.
+func (g *Generator) Shuffle(n int, swap func(i, j int)) {
+	ReadShuffle(g.reader(), n, swap)
+}
@@ -0,0 +1,29 @@
+package rng
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	assert.Equal(t, rand.Reader, New(nil).Source())
+
+	g := New(bytes.NewBuffer([]byte{0x12, 0x34, 3, 0, 0}))
+	assert.Equal(t, uint64(0x3412), g.Uint64Bits(16))
+	assert.Equal(t, 3, g.Intn(10))
+
+	s := []string{"a", "b", "c"}
+	g.Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+	assert.Equal(t, []string{"b", "c", "a"}, s)
+
+	g = New(nil)
+	assert.Len(t, g.Perm(10), 10)
+	assert.Len(t, g.Sample(10, 3), 3)
+	f := g.Float64()
+	assert.True(t, f >= 0 && f < 1)
+}
@@ -1,8 +1,15 @@
 module github.com/advbet/rng
 
-go 1.17
+go 1.23
 
-require github.com/stretchr/testify v1.5.1
+require (
+	filippo.io/age v1.0.0
+	github.com/miekg/pkcs11 v1.1.2
+	github.com/stretchr/testify v1.5.1
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+	golang.org/x/sys v0.9.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
@@ -0,0 +1,147 @@
+package rng
+
+import (
+	"io"
+)
+
+// Edge is an undirected edge between two graph vertices. Generated edges
+// always have U < V.
+type Edge struct {
+	U, V int
+}
+
+// RandomGraph returns an edge list of an Erdős–Rényi G(n, p) random graph.
+// Each of the n*(n-1)/2 possible edges is included independently with
+// probability p. Edges are listed in lexicographic order.
+func RandomGraph(n int, p float64) []Edge {
+	return ReadRandomGraph(defaultSource(), n, p)
+}
+
+// RandomLabeledTree returns edges of a uniformly random labeled tree on n
+// vertices [0, n). Tree is built from a random Prüfer sequence.
+func RandomLabeledTree(n int) []Edge {
+	return ReadRandomLabeledTree(defaultSource(), n)
+}
+
+// RandomSpanningTree returns a uniformly random spanning tree of a complete
+// graph on n vertices as a parent slice rooted at vertex 0. parent[0] is
+// always -1.
+func RandomSpanningTree(n int) []int {
+	return ReadRandomSpanningTree(defaultSource(), n)
+}
+
+// ReadRandomGraph returns an edge list of an Erdős–Rényi G(n, p) random graph
+// reading randomness from a given source. It will panic if n < 0 or p is not
+// in [0, 1].
+func ReadRandomGraph(src io.Reader, n int, p float64) []Edge {
+	if n < 0 {
+		panic("invalid argument to RandomGraph")
+	}
+	if !(p >= 0 && p <= 1) {
+		panic("invalid probability argument to RandomGraph")
+	}
+
+	var edges []Edge
+	for u := 0; u < n; u++ {
+		for v := u + 1; v < n; v++ {
+			if ReadFloat64(src) < p {
+				edges = append(edges, Edge{u, v})
+			}
+		}
+	}
+	return edges
+}
+
+// ReadRandomLabeledTree returns edges of a uniformly random labeled tree on n
+// vertices reading randomness from a given source. It will panic if n < 0.
+func ReadRandomLabeledTree(src io.Reader, n int) []Edge {
+	if n < 0 {
+		panic("invalid argument to RandomLabeledTree")
+	}
+	if n < 2 {
+		return []Edge{}
+	}
+
+	prufer := make([]int, n-2)
+	for i := range prufer {
+		prufer[i] = ReadIntn(src, n)
+	}
+	return pruferDecode(n, prufer)
+}
+
+// ReadRandomSpanningTree returns a uniformly random spanning tree of a
+// complete graph on n vertices as a parent slice rooted at vertex 0 reading
+// randomness from a given source. It will panic if n < 0.
+func ReadRandomSpanningTree(src io.Reader, n int) []int {
+	edges := ReadRandomLabeledTree(src, n)
+
+	parent := make([]int, n)
+	if n == 0 {
+		return parent
+	}
+	adj := make([][]int, n)
+	for _, e := range edges {
+		adj[e.U] = append(adj[e.U], e.V)
+		adj[e.V] = append(adj[e.V], e.U)
+	}
+
+	parent[0] = -1
+	visited := make([]bool, n)
+	visited[0] = true
+	queue := []int{0}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range adj[u] {
+			if !visited[v] {
+				visited[v] = true
+				parent[v] = u
+				queue = append(queue, v)
+			}
+		}
+	}
+	return parent
+}
+
+// pruferDecode converts Prüfer sequence of length n-2 to a list of n-1 tree
+// edges in linear time.
+func pruferDecode(n int, prufer []int) []Edge {
+	degree := make([]int, n)
+	for i := range degree {
+		degree[i] = 1
+	}
+	for _, v := range prufer {
+		degree[v]++
+	}
+
+	edges := make([]Edge, 0, n-1)
+	ptr := 0
+	for degree[ptr] != 1 {
+		ptr++
+	}
+	leaf := ptr
+	for _, v := range prufer {
+		edges = append(edges, newEdge(leaf, v))
+		degree[v]--
+		if degree[v] == 1 && v < ptr {
+			// v became the smallest leaf
+			leaf = v
+		} else {
+			ptr++
+			for degree[ptr] != 1 {
+				ptr++
+			}
+			leaf = ptr
+		}
+	}
+	// connect last remaining leaf with the highest vertex
+	edges = append(edges, newEdge(leaf, n-1))
+	return edges
+}
+
+func newEdge(u, v int) Edge {
+	if u > v {
+		u, v = v, u
+	}
+	return Edge{u, v}
+}
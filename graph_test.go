@@ -0,0 +1,78 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomGraph(t *testing.T) {
+	assert.Empty(t, RandomGraph(10, 0))
+	assert.Len(t, RandomGraph(10, 1), 45)
+
+	for _, e := range RandomGraph(20, 0.5) {
+		assert.True(t, e.U < e.V)
+		assert.True(t, e.U >= 0 && e.V < 20)
+	}
+
+	assert.Panics(t, func() {
+		RandomGraph(-1, 0.5)
+	})
+	assert.Panics(t, func() {
+		RandomGraph(10, 1.5)
+	})
+}
+
+func TestPruferDecode(t *testing.T) {
+	// Prüfer sequence 3 3 3 4 encodes a tree with edges 0-3, 1-3, 2-3, 3-4,
+	// 4-5.
+	edges := pruferDecode(6, []int{3, 3, 3, 4})
+	assert.ElementsMatch(t, []Edge{{0, 3}, {1, 3}, {2, 3}, {3, 4}, {4, 5}}, edges)
+}
+
+func TestRandomLabeledTree(t *testing.T) {
+	assert.Empty(t, RandomLabeledTree(0))
+	assert.Empty(t, RandomLabeledTree(1))
+	assert.Equal(t, []Edge{{0, 1}}, RandomLabeledTree(2))
+
+	N := 50
+	edges := RandomLabeledTree(N)
+	assert.Len(t, edges, N-1)
+
+	// Tree with n-1 edges is connected iff union-find merges everything
+	// without cycles.
+	root := make([]int, N)
+	for i := range root {
+		root[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if root[x] != x {
+			root[x] = find(root[x])
+		}
+		return root[x]
+	}
+	for _, e := range edges {
+		a, b := find(e.U), find(e.V)
+		assert.NotEqual(t, a, b, "tree must not contain cycles")
+		root[a] = b
+	}
+}
+
+func TestRandomSpanningTree(t *testing.T) {
+	assert.Empty(t, RandomSpanningTree(0))
+	assert.Equal(t, []int{-1}, RandomSpanningTree(1))
+
+	N := 30
+	parent := RandomSpanningTree(N)
+	assert.Equal(t, -1, parent[0])
+	for v := 1; v < N; v++ {
+		// walking parents must reach the root
+		u, steps := v, 0
+		for u != 0 && steps < N {
+			u = parent[u]
+			steps++
+		}
+		assert.Equal(t, 0, u)
+	}
+}
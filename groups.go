@@ -0,0 +1,93 @@
+package rng
+
+import (
+	"io"
+)
+
+// SplitGroups randomly splits items into k groups of as equal size as
+// possible. Group sizes differ by at most one, groups receiving the extra item
+// are chosen at random. It will panic if k <= 0.
+func SplitGroups[T any](items []T, k int) [][]T {
+	return ReadSplitGroups(defaultSource(), items, k)
+}
+
+// AssignGroups randomly assigns items to groups of given sizes. It will panic
+// if sizes do not add up to the number of items.
+func AssignGroups[T any](items []T, sizes []int) [][]T {
+	return ReadAssignGroups(defaultSource(), items, sizes)
+}
+
+// InterleaveFair randomly interleaves items of all groups preserving the order
+// of items within each group, see ReadInterleaveFair.
+func InterleaveFair[T any](groups [][]T) []T {
+	return ReadInterleaveFair(defaultSource(), groups)
+}
+
+// ReadSplitGroups randomly splits items into k groups of as equal size as
+// possible reading randomness from a given source.
+func ReadSplitGroups[T any](src io.Reader, items []T, k int) [][]T {
+	if k <= 0 {
+		panic("invalid argument to SplitGroups")
+	}
+
+	sizes := make([]int, k)
+	for i := range sizes {
+		sizes[i] = len(items) / k
+	}
+	for _, i := range ReadSample(src, k, len(items)%k) {
+		sizes[i]++
+	}
+	return ReadAssignGroups(src, items, sizes)
+}
+
+// ReadAssignGroups randomly assigns items to groups of given sizes reading
+// randomness from a given source.
+func ReadAssignGroups[T any](src io.Reader, items []T, sizes []int) [][]T {
+	total := 0
+	for _, size := range sizes {
+		if size < 0 {
+			panic("invalid argument to AssignGroups, negative group size")
+		}
+		total += size
+	}
+	if total != len(items) {
+		panic("invalid argument to AssignGroups, sizes do not match number of items")
+	}
+
+	perm := ReadPerm(src, len(items))
+	groups := make([][]T, len(sizes))
+	for i, size := range sizes {
+		groups[i] = make([]T, size)
+		for j := range groups[i] {
+			groups[i][j] = items[perm[0]]
+			perm = perm[1:]
+		}
+	}
+	return groups
+}
+
+// ReadInterleaveFair randomly interleaves items of all groups preserving the
+// order of items within each group reading randomness from a given source.
+// All interleavings are equally likely, so at every position the next item is
+// taken from a group with probability proportional to its remaining items and
+// no group is favoured, e.g. when rotating promotional content of several
+// partners.
+func ReadInterleaveFair[T any](src io.Reader, groups [][]T) []T {
+	var labels []int
+	for g, items := range groups {
+		for range items {
+			labels = append(labels, g)
+		}
+	}
+	ReadShuffle(src, len(labels), func(i, j int) {
+		labels[i], labels[j] = labels[j], labels[i]
+	})
+
+	next := make([]int, len(groups))
+	out := make([]T, len(labels))
+	for i, g := range labels {
+		out[i] = groups[g][next[g]]
+		next[g]++
+	}
+	return out
+}
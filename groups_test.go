@@ -0,0 +1,74 @@
+package rng
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitGroups(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e", "f", "g"}
+	groups := SplitGroups(items, 3)
+	assert.Len(t, groups, 3)
+
+	var all []string
+	for _, g := range groups {
+		assert.True(t, len(g) == 2 || len(g) == 3)
+		all = append(all, g...)
+	}
+	assert.ElementsMatch(t, items, all)
+
+	groups = SplitGroups(items, 10)
+	assert.Len(t, groups, 10)
+
+	assert.Panics(t, func() {
+		SplitGroups(items, 0)
+	})
+}
+
+func TestAssignGroups(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6}
+	groups := AssignGroups(items, []int{1, 0, 5})
+	assert.Len(t, groups[0], 1)
+	assert.Len(t, groups[1], 0)
+	assert.Len(t, groups[2], 5)
+	assert.ElementsMatch(t, items, append(groups[0], groups[2]...))
+
+	assert.Panics(t, func() {
+		AssignGroups(items, []int{1, 2})
+	})
+	assert.Panics(t, func() {
+		AssignGroups(items, []int{7, -1})
+	})
+}
+
+func TestInterleaveFair(t *testing.T) {
+	groups := [][]string{{"a1", "a2", "a3"}, {}, {"b1", "b2"}}
+	counts := make(map[string]int)
+	src := NewDRBG([]byte("interleave"))
+	const samples = 10000
+	for i := 0; i < samples; i++ {
+		out := ReadInterleaveFair(src, groups)
+		assert.ElementsMatch(t, []string{"a1", "a2", "a3", "b1", "b2"}, out)
+		// order within groups is kept
+		var a, b []string
+		for _, s := range out {
+			if s[0] == 'a' {
+				a = append(a, s)
+			} else {
+				b = append(b, s)
+			}
+		}
+		assert.Equal(t, groups[0], a)
+		assert.Equal(t, groups[2], b)
+		counts[strings.Join(out, ",")]++
+	}
+	// all 10 interleavings are equally likely
+	assert.Len(t, counts, 10)
+	for k, c := range counts {
+		assert.InDelta(t, samples/10, c, 150, k)
+	}
+
+	assert.Empty(t, InterleaveFair[int](nil))
+}
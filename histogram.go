@@ -0,0 +1,57 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Bin returns the index i of the half-open bin [edges[i], edges[i+1]) holding
+// u, or -1 if u is NaN or outside [edges[0], edges[len(edges)-1]). Every value
+// belongs to at most one bin, a value equal to an inner edge is counted in the
+// bin to the right of it, never in both. Infinite edges are allowed, e.g.
+// {-Inf, 0, +Inf} splits values by sign.
+//
+// It will panic if there are fewer than two edges, an edge is NaN or edges are
+// not strictly increasing.
+func Bin(u float64, edges []float64) int {
+	if len(edges) < 2 {
+		panic("invalid argument to Bin")
+	}
+	for i, e := range edges {
+		if math.IsNaN(e) || (i > 0 && !(edges[i-1] < e)) {
+			panic("invalid argument to Bin")
+		}
+	}
+	if !(u >= edges[0]) || !(u < edges[len(edges)-1]) {
+		return -1
+	}
+	// first edge above u, bin starts at the edge before it
+	return sort.SearchFloat64s(edges, math.Nextafter(u, math.Inf(1))) - 1
+}
+
+// BinUniform returns the bin of a random uniform number in [0.0,1.0) among n
+// equal width bins, see ReadBinUniform.
+func BinUniform(n int) int {
+	return ReadBinUniform(defaultSource(), n)
+}
+
+// ReadBinUniform returns the bin of a random uniform number in [0.0,1.0)
+// among n equal width bins [i/n, (i+1)/n) reading randomness from a given
+// source. It consumes the same bytes as ReadFloat64 and returns the bin of
+// that value computed in exact integer arithmetic: floor(k*n/2^53) for the
+// 53-bit grid index k. Unlike int(ReadFloat64(src)*float64(n)) it never
+// rounds up into bin n and never shifts a grid value across a bin boundary, so
+// each bin receives floor(2^53/n) or ceil(2^53/n) of the 2^53 equally likely
+// values. Use ReadIntn when exactly uniform bins are needed. It will panic if
+// n <= 0.
+func ReadBinUniform(src io.Reader, n int) int {
+	if n <= 0 {
+		panic("invalid argument to BinUniform")
+	}
+	k := ReadUint64Bits(src, 53)
+	hi, lo := bits.Mul64(k, uint64(n))
+	// k < 2^53 so the product and the shifted result fit in 116 and 63 bits
+	return int(hi<<11 | lo>>53)
+}
@@ -0,0 +1,97 @@
+package rng
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBin(t *testing.T) {
+	edges := []float64{0, 0.1, 0.5, 1}
+	tests := []struct {
+		u   float64
+		bin int
+	}{
+		{0, 0},
+		{0.05, 0},
+		{math.Nextafter(0.1, 0), 0},
+		{0.1, 1},
+		{0.3, 1},
+		{0.5, 2},
+		{math.Nextafter(1, 0), 2},
+		{1, -1},
+		{-0.1, -1},
+		{math.Nextafter(0, -1), -1},
+		{math.NaN(), -1},
+		{math.Inf(1), -1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.bin, Bin(test.u, edges), "Bin(%g)", test.u)
+	}
+
+	sign := []float64{math.Inf(-1), 0, math.Inf(1)}
+	assert.Equal(t, 0, Bin(math.Inf(-1), sign))
+	assert.Equal(t, 0, Bin(-1e300, sign))
+	assert.Equal(t, 1, Bin(0, sign))
+	assert.Equal(t, 1, Bin(math.Copysign(0, -1), sign))
+	assert.Equal(t, -1, Bin(math.Inf(1), sign))
+
+	assert.Panics(t, func() { Bin(0, nil) })
+	assert.Panics(t, func() { Bin(0, []float64{0}) })
+	assert.Panics(t, func() { Bin(0, []float64{0, 0, 1}) })
+	assert.Panics(t, func() { Bin(0, []float64{1, 0}) })
+	assert.Panics(t, func() { Bin(0, []float64{0, math.NaN()}) })
+}
+
+func TestBinFloat64Histogram(t *testing.T) {
+	// Bin with equal width edges over [0, 1) gives a uniform histogram of
+	// Float64 draws, like the linear search of TestFloat64Histogram
+	edges := make([]float64, 11)
+	for i := range edges {
+		edges[i] = float64(i) / 10
+	}
+	hist := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		hist[Bin(Float64(), edges)]++
+	}
+	for _, count := range hist {
+		assert.InDelta(t, 1000, count, 150)
+	}
+}
+
+func TestBinUniform(t *testing.T) {
+	// largest grid value below 2/3, float multiplication rounds it up to 2
+	k := uint64(6004799503160661)
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, k)
+	u := ReadFloat64(bytes.NewBuffer(buf))
+	assert.True(t, 3*k < 2<<53) // exactly, u < 2/3
+	assert.Equal(t, 2, int(u*3))
+	assert.Equal(t, 1, ReadBinUniform(bytes.NewBuffer(buf), 3))
+	binary.LittleEndian.PutUint64(buf, k+1)
+	assert.Equal(t, 2, ReadBinUniform(bytes.NewBuffer(buf), 3))
+	binary.LittleEndian.PutUint64(buf, 1<<53-1)
+	assert.Equal(t, 2, ReadBinUniform(bytes.NewBuffer(buf), 3))
+
+	// same bytes as ReadFloat64, same bin as Bin with exact edges
+	src := NewDRBG([]byte("bins"))
+	buf = buf[:7]
+	edges := []float64{0, 0.25, 0.5, 0.75, 1}
+	for i := 0; i < 1000; i++ {
+		src.Read(buf)
+		assert.Equal(t, Bin(ReadFloat64(bytes.NewBuffer(buf)), edges), ReadBinUniform(bytes.NewBuffer(buf), 4))
+	}
+
+	hist := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		hist[BinUniform(10)]++
+	}
+	for _, count := range hist {
+		assert.InDelta(t, 1000, count, 150)
+	}
+
+	assert.Panics(t, func() { BinUniform(0) })
+}
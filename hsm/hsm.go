@@ -0,0 +1,272 @@
+// Package hsm provides an entropy source backed by a hardware security module.
+//
+// Source reads randomness with C_GenerateRandom from a pool of PKCS#11
+// sessions, so certified deployments can claim HSM-originated entropy end to
+// end. Open connects to a PKCS#11 module and requires cgo, the pooling and
+// health checking logic is available to any Session implementation via
+// NewSource.
+package hsm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvModule = "RNG_PKCS11_MODULE"
+	EnvSlot   = "RNG_PKCS11_SLOT"
+	EnvPIN    = "RNG_PKCS11_PIN"
+)
+
+// DefaultPoolSize is the number of sessions used when Config.PoolSize is 0.
+const DefaultPoolSize = 4
+
+// maxChunk limits the number of bytes requested by a single C_GenerateRandom
+// call, some tokens reject larger requests.
+const maxChunk = 1 << 12
+
+// ErrClosed is returned when reading from a closed Source.
+var ErrClosed = errors.New("hsm: source is closed")
+
+// Config describes a PKCS#11 token.
+type Config struct {
+	// ModulePath is the path of the PKCS#11 shared library.
+	ModulePath string
+	// Slot is the ID of the slot holding the token.
+	Slot uint
+	// PIN is the user PIN, login is skipped if it is empty.
+	PIN string
+	// PoolSize is the maximum number of open sessions.
+	PoolSize int
+}
+
+// ConfigFromEnv returns a Config read from RNG_PKCS11_MODULE, RNG_PKCS11_SLOT
+// and RNG_PKCS11_PIN environment variables.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		ModulePath: os.Getenv(EnvModule),
+		PIN:        os.Getenv(EnvPIN),
+	}
+	if cfg.ModulePath == "" {
+		return cfg, fmt.Errorf("hsm: %s is not set", EnvModule)
+	}
+	if s := os.Getenv(EnvSlot); s != "" {
+		slot, err := strconv.ParseUint(s, 10, 0)
+		if err != nil {
+			return cfg, fmt.Errorf("hsm: invalid %s: %w", EnvSlot, err)
+		}
+		cfg.Slot = uint(slot)
+	}
+	return cfg, nil
+}
+
+// Session is a single token session.
+type Session interface {
+	// GenerateRandom returns n random bytes generated by the token.
+	GenerateRandom(n int) ([]byte, error)
+	// Check returns an error if the session is no longer usable.
+	Check() error
+	Close() error
+}
+
+// Source is an io.Reader returning random bytes generated by a token. It is
+// safe for concurrent use, concurrent reads are served by different sessions.
+type Source struct {
+	open   func() (Session, error)
+	finish func() error
+	size   int
+
+	mu     sync.Mutex
+	ready  *sync.Cond // signalled when a session is returned or a slot freed
+	idle   []Session
+	active int // open sessions, idle or in use
+	closed bool
+}
+
+var _ io.Reader = (*Source)(nil)
+
+// NewSource returns a Source keeping up to size sessions created by open.
+// Sessions are opened on demand and replaced after a failure.
+func NewSource(open func() (Session, error), size int) *Source {
+	if size <= 0 {
+		size = DefaultPoolSize
+	}
+	s := &Source{open: open, size: size}
+	s.ready = sync.NewCond(&s.mu)
+	return s
+}
+
+// get returns an idle session, opens a new one or waits for a session to be
+// returned to the pool.
+func (s *Source) get() (Session, error) {
+	s.mu.Lock()
+	for {
+		if s.closed {
+			s.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if n := len(s.idle); n > 0 {
+			sess := s.idle[n-1]
+			s.idle = s.idle[:n-1]
+			s.mu.Unlock()
+			return sess, nil
+		}
+		if s.active < s.size {
+			break
+		}
+		s.ready.Wait()
+	}
+	// the slot is taken before opening, so Close does not release the
+	// token until the new session is closed again
+	s.active++
+	s.mu.Unlock()
+
+	sess, err := s.open()
+	if err != nil {
+		s.release()
+		return nil, fmt.Errorf("hsm: open session: %w", err)
+	}
+	if s.isClosed() {
+		s.discard(sess)
+		return nil, ErrClosed
+	}
+	return sess, nil
+}
+
+func (s *Source) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// put returns a session to the pool, or closes it if the source is closed.
+func (s *Source) put(sess Session) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.discard(sess)
+		return
+	}
+	s.idle = append(s.idle, sess)
+	s.ready.Signal()
+	s.mu.Unlock()
+}
+
+// discard closes a session and frees its slot.
+func (s *Source) discard(sess Session) {
+	_ = sess.Close()
+	s.release()
+}
+
+// release frees the slot of a closed session. The token is released with the
+// last slot of a closed source.
+func (s *Source) release() {
+	s.mu.Lock()
+	s.active--
+	last := s.closed && s.active == 0
+	s.ready.Signal()
+	s.mu.Unlock()
+	if last && s.finish != nil {
+		_ = s.finish()
+	}
+}
+
+// Read fills p with random bytes generated by the token. A session failing
+// C_GenerateRandom is discarded and the request is retried once on a fresh
+// session.
+func (s *Source) Read(p []byte) (int, error) {
+	n := 0
+	retried := false
+	for n < len(p) {
+		sess, err := s.get()
+		if err != nil {
+			return n, err
+		}
+		want := len(p) - n
+		if want > maxChunk {
+			want = maxChunk
+		}
+		b, err := sess.GenerateRandom(want)
+		if err == nil && len(b) != want {
+			err = fmt.Errorf("token returned %d bytes, requested %d", len(b), want)
+		}
+		if err != nil {
+			s.discard(sess)
+			if retried {
+				return n, fmt.Errorf("hsm: generate random: %w", err)
+			}
+			retried = true
+			continue
+		}
+		s.put(sess)
+		n += copy(p[n:], b)
+	}
+	return n, nil
+}
+
+// HealthCheck checks idle sessions, discarding failed ones, and returns an
+// error if no healthy session is available.
+func (s *Source) HealthCheck() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle = nil
+	s.mu.Unlock()
+
+	var healthy []Session
+	for _, sess := range idle {
+		if err := sess.Check(); err != nil {
+			s.discard(sess)
+			continue
+		}
+		healthy = append(healthy, sess)
+	}
+	if len(healthy) == 0 {
+		sess, err := s.get()
+		if err != nil {
+			return err
+		}
+		if err := sess.Check(); err != nil {
+			s.discard(sess)
+			return fmt.Errorf("hsm: health check: %w", err)
+		}
+		healthy = append(healthy, sess)
+	}
+	for _, sess := range healthy {
+		s.put(sess)
+	}
+	return nil
+}
+
+// Close closes idle sessions and releases the token. Sessions in use are
+// closed when returned to the pool and the token is released with the last of
+// them, in that case Close returns nil and the error of releasing the token is
+// dropped.
+func (s *Source) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	idle := s.idle
+	s.idle = nil
+	s.ready.Broadcast()
+	s.mu.Unlock()
+
+	for _, sess := range idle {
+		_ = sess.Close()
+	}
+	s.mu.Lock()
+	s.active -= len(idle)
+	last := s.active == 0
+	s.mu.Unlock()
+	if last && s.finish != nil {
+		return s.finish()
+	}
+	return nil
+}
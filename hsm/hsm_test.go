@@ -0,0 +1,173 @@
+package hsm
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	id     int
+	fail   bool
+	closed bool
+}
+
+func (s *fakeSession) GenerateRandom(n int) ([]byte, error) {
+	if s.fail {
+		return nil, errors.New("CKR_DEVICE_ERROR")
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(s.id)
+	}
+	return b, nil
+}
+
+func (s *fakeSession) Check() error {
+	if s.fail {
+		return errors.New("CKR_SESSION_HANDLE_INVALID")
+	}
+	return nil
+}
+
+func (s *fakeSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+type fakeToken struct {
+	mu       sync.Mutex
+	sessions []*fakeSession
+	down     bool
+}
+
+func (t *fakeToken) open() (Session, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.down {
+		return nil, errors.New("CKR_TOKEN_NOT_PRESENT")
+	}
+	s := &fakeSession{id: len(t.sessions) + 1}
+	t.sessions = append(t.sessions, s)
+	return s, nil
+}
+
+func TestSourceRead(t *testing.T) {
+	tok := &fakeToken{}
+	src := NewSource(tok.open, 2)
+
+	b := make([]byte, maxChunk+10)
+	n, err := src.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, len(b), n)
+	// idle session is reused for the second chunk
+	assert.Len(t, tok.sessions, 1)
+
+	// failed session is replaced
+	tok.sessions[0].fail = true
+	n, err = src.Read(b[:4])
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, []byte{2, 2, 2, 2}, b[:4])
+	assert.True(t, tok.sessions[0].closed)
+
+	// error is returned when retry fails too
+	tok.sessions[1].fail = true
+	tok.down = true
+	_, err = src.Read(b[:4])
+	assert.Error(t, err)
+
+	require.NoError(t, src.Close())
+	_, err = src.Read(b[:4])
+	assert.True(t, errors.Is(err, ErrClosed))
+}
+
+func TestSourceConcurrent(t *testing.T) {
+	tok := &fakeToken{}
+	src := NewSource(tok.open, 3)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := src.Read(make([]byte, 100))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, len(tok.sessions), 3)
+}
+
+func TestSourceCloseInUse(t *testing.T) {
+	tok := &fakeToken{}
+	src := NewSource(tok.open, 2)
+	finished := 0
+	src.finish = func() error {
+		finished++
+		return nil
+	}
+
+	idle, err := src.get()
+	require.NoError(t, err)
+	inUse, err := src.get()
+	require.NoError(t, err)
+	src.put(idle)
+
+	// the token is released once the session in use is returned
+	require.NoError(t, src.Close())
+	assert.True(t, tok.sessions[0].closed)
+	assert.Equal(t, 0, finished)
+	_, err = src.get()
+	assert.True(t, errors.Is(err, ErrClosed))
+	src.put(inUse)
+	assert.True(t, tok.sessions[1].closed)
+	assert.Equal(t, 1, finished)
+	require.NoError(t, src.Close())
+	assert.Equal(t, 1, finished)
+
+	// without sessions in use Close releases the token itself
+	src = NewSource(tok.open, 2)
+	src.finish = func() error { return errors.New("CKR_GENERAL_ERROR") }
+	_, err = src.Read(make([]byte, 4))
+	require.NoError(t, err)
+	assert.Error(t, src.Close())
+}
+
+func TestHealthCheck(t *testing.T) {
+	tok := &fakeToken{}
+	src := NewSource(tok.open, 2)
+
+	// opens a session if none is idle
+	require.NoError(t, src.HealthCheck())
+	require.Len(t, tok.sessions, 1)
+
+	// failed idle session is replaced
+	tok.sessions[0].fail = true
+	require.NoError(t, src.HealthCheck())
+	assert.True(t, tok.sessions[0].closed)
+	assert.Len(t, tok.sessions, 2)
+
+	tok.sessions[1].fail = true
+	tok.down = true
+	assert.Error(t, src.HealthCheck())
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvModule, "/usr/lib/softhsm/libsofthsm2.so")
+	t.Setenv(EnvSlot, "3")
+	t.Setenv(EnvPIN, "1234")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{ModulePath: "/usr/lib/softhsm/libsofthsm2.so", Slot: 3, PIN: "1234"}, cfg)
+
+	t.Setenv(EnvSlot, "x")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv(EnvModule, "")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+//go:build cgo
+
+package hsm
+
+import (
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+)
+
+// Open loads the PKCS#11 module, logs in to the token in cfg.Slot and returns
+// a Source drawing from up to cfg.PoolSize sessions.
+func Open(cfg Config) (*Source, error) {
+	ctx := pkcs11.New(cfg.ModulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("hsm: can not load PKCS#11 module %q", cfg.ModulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("hsm: initialize: %w", err)
+	}
+
+	// login state is shared by all sessions of the application, so it is
+	// done once on a session kept open for the lifetime of the source
+	login, err := ctx.OpenSession(cfg.Slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, fmt.Errorf("hsm: open session: %w", err)
+	}
+	if cfg.PIN != "" {
+		if err := ctx.Login(login, pkcs11.CKU_USER, cfg.PIN); err != nil {
+			ctx.CloseSession(login)
+			ctx.Finalize()
+			ctx.Destroy()
+			return nil, fmt.Errorf("hsm: login: %w", err)
+		}
+	}
+
+	src := NewSource(func() (Session, error) {
+		sh, err := ctx.OpenSession(cfg.Slot, pkcs11.CKF_SERIAL_SESSION)
+		if err != nil {
+			return nil, err
+		}
+		return &session{ctx: ctx, sh: sh}, nil
+	}, cfg.PoolSize)
+	src.finish = func() error {
+		if cfg.PIN != "" {
+			ctx.Logout(login)
+		}
+		ctx.CloseSession(login)
+		err := ctx.Finalize()
+		ctx.Destroy()
+		return err
+	}
+	return src, nil
+}
+
+type session struct {
+	ctx *pkcs11.Ctx
+	sh  pkcs11.SessionHandle
+}
+
+func (s *session) GenerateRandom(n int) ([]byte, error) {
+	return s.ctx.GenerateRandom(s.sh, n)
+}
+
+func (s *session) Check() error {
+	_, err := s.ctx.GetSessionInfo(s.sh)
+	return err
+}
+
+func (s *session) Close() error {
+	return s.ctx.CloseSession(s.sh)
+}
@@ -0,0 +1,39 @@
+package rng
+
+import "io"
+
+// Hypergeometric returns the number of successes among draws items drawn
+// without replacement from a population, see ReadHypergeometric.
+func Hypergeometric(population, successes, draws int) int {
+	return ReadHypergeometric(defaultSource(), population, successes, draws)
+}
+
+// ReadHypergeometric returns the number of successes among draws items drawn
+// without replacement from a population of a given size containing a given
+// number of successes, reading randomness from a given source. E.g. the
+// number of tens dealt in the next 20 cards of a shoe with 312 cards
+// remaining, 96 of them tens.
+//
+// The result is exact, draws are simulated one by one with integer
+// arithmetic. Running time is proportional to min(draws, population-draws).
+// It will panic if population < 0, successes or draws are not in
+// [0, population].
+func ReadHypergeometric(src io.Reader, population, successes, draws int) int {
+	if population < 0 || successes < 0 || successes > population || draws < 0 || draws > population {
+		panic("invalid argument to Hypergeometric")
+	}
+	if draws > population/2 {
+		// successes not drawn are drawn by the complement
+		return successes - ReadHypergeometric(src, population, successes, population-draws)
+	}
+
+	k := 0
+	for i := 0; i < draws && successes > 0; i++ {
+		if ReadIntn(src, population) < successes {
+			successes--
+			k++
+		}
+		population--
+	}
+	return k
+}
@@ -0,0 +1,58 @@
+package rng
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHypergeometric(t *testing.T) {
+	src := NewDRBG([]byte("shoe"))
+	for _, test := range []struct {
+		population, successes, draws int
+	}{
+		{312, 96, 20},
+		{52, 4, 5},
+		{52, 26, 40},
+		{10, 10, 3},
+	} {
+		N, K, n := float64(test.population), float64(test.successes), float64(test.draws)
+		mean := n * K / N
+		variance := n * K / N * (N - K) / N * (N - n) / (N - 1)
+
+		sum, sq := 0.0, 0.0
+		for i := 0; i < 5000; i++ {
+			k := ReadHypergeometric(src, test.population, test.successes, test.draws)
+			assert.True(t, k >= 0 && k <= test.draws && k <= test.successes)
+			assert.True(t, test.draws-k <= test.population-test.successes)
+			sum += float64(k)
+			sq += float64(k) * float64(k)
+		}
+		m := sum / 5000
+		assert.InDelta(t, mean, m, 4*math.Sqrt(variance/5000)+1e-9, "%+v", test)
+		assert.InDelta(t, variance, sq/5000-m*m, 0.1*variance+1e-9, "%+v", test)
+	}
+
+	assert.Equal(t, 0, ReadHypergeometric(src, 52, 0, 5))
+	assert.Equal(t, 5, ReadHypergeometric(src, 52, 52, 5))
+	assert.Equal(t, 4, ReadHypergeometric(src, 52, 4, 52))
+	assert.Equal(t, 0, ReadHypergeometric(src, 0, 0, 0))
+
+	assert.Panics(t, func() { ReadHypergeometric(src, -1, 0, 0) })
+	assert.Panics(t, func() { ReadHypergeometric(src, 10, 11, 1) })
+	assert.Panics(t, func() { ReadHypergeometric(src, 10, 5, 11) })
+	assert.Panics(t, func() { ReadHypergeometric(src, 10, -1, 1) })
+}
+
+func TestHypergeometricExact(t *testing.T) {
+	// P(k) for population 6, successes 3, draws 2 is 3/15, 9/15, 3/15
+	src := NewDRBG([]byte("exact"))
+	counts := make([]int, 3)
+	for i := 0; i < 15000; i++ {
+		counts[ReadHypergeometric(src, 6, 3, 2)]++
+	}
+	assert.InDelta(t, 3000, counts[0], 200)
+	assert.InDelta(t, 9000, counts[1], 250)
+	assert.InDelta(t, 3000, counts[2], 200)
+}
@@ -0,0 +1,195 @@
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrDrawNotFound is returned by DrawStore.LoadDraw for unknown draw IDs.
+var ErrDrawNotFound = errors.New("rng: draw not found")
+
+// ErrDrawMismatch is returned when a draw ID is reused for a draw of a
+// different type or with different parameters.
+var ErrDrawMismatch = errors.New("rng: draw ID reused with different parameters")
+
+// DrawStore persists draw results by draw ID.
+type DrawStore interface {
+	// LoadDraw returns a stored draw or ErrDrawNotFound.
+	LoadDraw(id string) (*DrawResult, error)
+	// StoreDraw atomically stores a draw unless a draw with the same ID is
+	// already stored. It returns the draw that ends up stored, which is the
+	// existing one if there was a concurrent store.
+	StoreDraw(d *DrawResult) (*DrawResult, error)
+}
+
+// IdempotentGenerator makes draws identified by unique IDs. Repeated requests
+// for the same ID return the stored outcome instead of consuming new entropy,
+// so retries in distributed servers can never draw twice.
+//
+// IdempotentGenerator is safe for concurrent use if its source and store are.
+type IdempotentGenerator struct {
+	src   io.Reader
+	store DrawStore
+
+	mu    sync.Mutex
+	locks map[string]*idLock
+}
+
+type idLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewIdempotent returns an IdempotentGenerator drawing from g and persisting
+// results in store.
+func NewIdempotent(g *Generator, store DrawStore) *IdempotentGenerator {
+	return &IdempotentGenerator{
+		src:   g.reader(),
+		store: store,
+		locks: make(map[string]*idLock),
+	}
+}
+
+// Intn returns a non negative int in [0, n) for draw id, see ReadIntn.
+func (g *IdempotentGenerator) Intn(id string, n int) (int, error) {
+	d, err := g.draw(id, "intn", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		return []int64{int64(ReadIntn(src, n))}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(d.Outcome[0]), nil
+}
+
+// Float64 returns a random number in [0.0,1.0) for draw id, see ReadFloat64.
+func (g *IdempotentGenerator) Float64(id string) (float64, error) {
+	d, err := g.draw(id, "float64", nil, func(src io.Reader) []int64 {
+		return []int64{int64(ReadUint64Bits(src, 53))}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return float64(d.Outcome[0]) / float64(1<<53), nil
+}
+
+// Perm returns a random permutation of integers [0,n) for draw id, see
+// ReadPerm.
+func (g *IdempotentGenerator) Perm(id string, n int) ([]int, error) {
+	d, err := g.draw(id, "perm", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		return intsToInt64s(ReadPerm(src, n))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return int64sToInts(d.Outcome), nil
+}
+
+// Sample returns random k integers from a range [0 n) for draw id, see
+// ReadSample.
+func (g *IdempotentGenerator) Sample(id string, n int, k int) ([]int, error) {
+	d, err := g.draw(id, "sample", map[string]int64{"n": int64(n), "k": int64(k)}, func(src io.Reader) []int64 {
+		return intsToInt64s(ReadSample(src, n, k))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return int64sToInts(d.Outcome), nil
+}
+
+// draw returns a stored draw for id or makes and stores a new one.
+func (g *IdempotentGenerator) draw(id string, typ string, params map[string]int64, fn func(src io.Reader) []int64) (*DrawResult, error) {
+	// serialize draws of the same ID within the process so concurrent
+	// retries do not consume entropy needlessly
+	unlock := g.lock(id)
+	defer unlock()
+
+	d, err := g.store.LoadDraw(id)
+	if errors.Is(err, ErrDrawNotFound) {
+		d = runDraw(g.src, typ, params, fn)
+		d.ID = id
+		d, err = g.store.StoreDraw(d)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.Type != typ || !equalParams(d.Params, params) {
+		return nil, fmt.Errorf("%w: draw %q is %s%v", ErrDrawMismatch, id, d.Type, d.Params)
+	}
+	return d, nil
+}
+
+func (g *IdempotentGenerator) lock(id string) (unlock func()) {
+	g.mu.Lock()
+	l, ok := g.locks[id]
+	if !ok {
+		l = &idLock{}
+		g.locks[id] = l
+	}
+	l.refs++
+	g.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		g.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(g.locks, id)
+		}
+		g.mu.Unlock()
+	}
+}
+
+func equalParams(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func int64sToInts(s []int64) []int {
+	out := make([]int, len(s))
+	for i, v := range s {
+		out[i] = int(v)
+	}
+	return out
+}
+
+// MemoryDrawStore is an in-memory DrawStore. It is safe for concurrent use.
+type MemoryDrawStore struct {
+	mu    sync.Mutex
+	draws map[string]*DrawResult
+}
+
+var _ DrawStore = (*MemoryDrawStore)(nil)
+
+// LoadDraw returns a stored draw or ErrDrawNotFound.
+func (s *MemoryDrawStore) LoadDraw(id string) (*DrawResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.draws[id]
+	if !ok {
+		return nil, ErrDrawNotFound
+	}
+	return d, nil
+}
+
+// StoreDraw stores a draw unless one with the same ID exists.
+func (s *MemoryDrawStore) StoreDraw(d *DrawResult) (*DrawResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.draws[d.ID]; ok {
+		return existing, nil
+	}
+	if s.draws == nil {
+		s.draws = make(map[string]*DrawResult)
+	}
+	s.draws[d.ID] = d
+	return d, nil
+}
@@ -0,0 +1,92 @@
+package rng
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingReader struct {
+	mu    sync.Mutex
+	src   *DRBG
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reads++
+	return c.src.Read(p)
+}
+
+func TestIdempotentGenerator(t *testing.T) {
+	src := &countingReader{src: NewDRBG(nil)}
+	store := &MemoryDrawStore{}
+	g := NewIdempotent(New(src), store)
+
+	a, err := g.Perm("round-1", 52)
+	require.NoError(t, err)
+	reads := src.reads
+
+	// retry returns the stored outcome without reading entropy
+	b, err := g.Perm("round-1", 52)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.Equal(t, reads, src.reads)
+
+	// a different generator sharing the store sees the same outcome
+	c, err := NewIdempotent(New(nil), store).Perm("round-1", 52)
+	require.NoError(t, err)
+	assert.Equal(t, a, c)
+
+	d, err := store.LoadDraw("round-1")
+	require.NoError(t, err)
+	assert.Equal(t, "perm", d.Type)
+
+	_, err = g.Perm("round-1", 10)
+	assert.True(t, errors.Is(err, ErrDrawMismatch))
+	_, err = g.Intn("round-1", 52)
+	assert.True(t, errors.Is(err, ErrDrawMismatch))
+
+	n, err := g.Intn("round-2", 10)
+	require.NoError(t, err)
+	m, err := g.Intn("round-2", 10)
+	require.NoError(t, err)
+	assert.Equal(t, n, m)
+
+	f1, err := g.Float64("round-3")
+	require.NoError(t, err)
+	f2, err := g.Float64("round-3")
+	require.NoError(t, err)
+	assert.Equal(t, f1, f2)
+
+	s1, err := g.Sample("round-4", 49, 6)
+	require.NoError(t, err)
+	s2, err := g.Sample("round-4", 49, 6)
+	require.NoError(t, err)
+	assert.Equal(t, s1, s2)
+}
+
+func TestIdempotentGeneratorConcurrent(t *testing.T) {
+	g := NewIdempotent(New(nil), &MemoryDrawStore{})
+
+	results := make([]int, 20)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n, err := g.Intn("same", 1<<30)
+			assert.NoError(t, err)
+			results[i] = n
+		}(i)
+	}
+	wg.Wait()
+	for _, n := range results {
+		assert.Equal(t, results[0], n)
+	}
+	assert.Empty(t, g.locks)
+}
@@ -0,0 +1,145 @@
+package rng
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"iter"
+	"math/bits"
+)
+
+// indexPermRounds is the number of Feistel rounds, as in NIST FF1.
+const indexPermRounds = 10
+
+// IndexPermutation is a keyed pseudorandom permutation of [0, n) evaluated
+// one index at a time, e.g. to scan a huge ticket space in random order
+// without materializing a Perm slice. It is a format-preserving cipher: a
+// balanced Feistel network with AES round functions over the smallest domain
+// of 2^(2h) values holding n, indexes mapped outside [0, n) are encrypted
+// again (cycle walking) until they land inside it.
+//
+// The permutation is pseudorandom, indistinguishable from a uniform one
+// without the key, but unlike Perm it is not exactly uniform, it must not be
+// used where provably unbiased outcomes are required. Memory use is constant,
+// each At or Index call takes on average less than 4 encryptions.
+// IndexPermutation is safe for concurrent use.
+type IndexPermutation struct {
+	key   []byte
+	n     uint64
+	half  uint // bits per Feistel half
+	block cipher.Block
+}
+
+// NewIndexPermutation returns a permutation of [0, n) with a random key.
+func NewIndexPermutation(n uint64) *IndexPermutation {
+	return ReadIndexPermutation(defaultSource(), n)
+}
+
+// ReadIndexPermutation returns a permutation of [0, n) with a key read from a
+// given source.
+func ReadIndexPermutation(src io.Reader, n uint64) *IndexPermutation {
+	key := make([]byte, saltSize)
+	if _, err := io.ReadFull(src, key); err != nil {
+		panic(err)
+	}
+	return NewIndexPermutationWithKey(n, key)
+}
+
+// NewIndexPermutationWithKey returns a permutation of [0, n) for a previously
+// exported key, the same key and n always give the same permutation. Keys of
+// any length are accepted.
+func NewIndexPermutationWithKey(n uint64, key []byte) *IndexPermutation {
+	k := sha256.Sum256(key)
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		panic(err)
+	}
+	half := uint(1)
+	if n > 1 {
+		half = uint(bits.Len64(n-1)+1) / 2
+	}
+	return &IndexPermutation{
+		key:   append([]byte(nil), key...),
+		n:     n,
+		half:  half,
+		block: block,
+	}
+}
+
+// Key returns a copy of the permutation key. It can be stored and later
+// passed to NewIndexPermutationWithKey to restore the permutation.
+func (p *IndexPermutation) Key() []byte {
+	return append([]byte(nil), p.key...)
+}
+
+// Len returns n, the size of the permuted range.
+func (p *IndexPermutation) Len() uint64 {
+	return p.n
+}
+
+// At returns the value at position i of the permutation. It will panic if i
+// is not in [0, n).
+func (p *IndexPermutation) At(i uint64) uint64 {
+	if i >= p.n {
+		panic("invalid argument to IndexPermutation.At")
+	}
+	x := p.encrypt(i)
+	for x >= p.n {
+		x = p.encrypt(x)
+	}
+	return x
+}
+
+// Index returns the position of value v in the permutation, the inverse of
+// At. It will panic if v is not in [0, n).
+func (p *IndexPermutation) Index(v uint64) uint64 {
+	if v >= p.n {
+		panic("invalid argument to IndexPermutation.Index")
+	}
+	x := p.decrypt(v)
+	for x >= p.n {
+		x = p.decrypt(x)
+	}
+	return x
+}
+
+// All returns all values of the permutation in order, At(0), At(1), ...
+func (p *IndexPermutation) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for i := uint64(0); i < p.n; i++ {
+			if !yield(p.At(i)) {
+				return
+			}
+		}
+	}
+}
+
+func (p *IndexPermutation) encrypt(x uint64) uint64 {
+	mask := uint64(1)<<p.half - 1
+	l, r := x>>p.half, x&mask
+	for round := 0; round < indexPermRounds; round++ {
+		l, r = r, l^p.round(round, r)
+	}
+	return l<<p.half | r
+}
+
+func (p *IndexPermutation) decrypt(x uint64) uint64 {
+	mask := uint64(1)<<p.half - 1
+	l, r := x>>p.half, x&mask
+	for round := indexPermRounds - 1; round >= 0; round-- {
+		l, r = r^p.round(round, l), l
+	}
+	return l<<p.half | r
+}
+
+// round returns the Feistel round function AES(round || x) truncated to half
+// bits.
+func (p *IndexPermutation) round(round int, x uint64) uint64 {
+	var b [aes.BlockSize]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(round))
+	binary.BigEndian.PutUint64(b[8:], x)
+	p.block.Encrypt(b[:], b[:])
+	return binary.BigEndian.Uint64(b[:8]) & (uint64(1)<<p.half - 1)
+}
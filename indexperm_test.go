@@ -0,0 +1,63 @@
+package rng
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexPermutation(t *testing.T) {
+	for _, n := range []uint64{0, 1, 2, 3, 4, 5, 17, 1000, 4096, 5000} {
+		p := ReadIndexPermutation(NewDRBG([]byte("scan")), n)
+		assert.Equal(t, n, p.Len())
+		seen := make([]bool, n)
+		i := uint64(0)
+		for v := range p.All() {
+			assert.False(t, seen[v], "n=%d value %d repeated", n, v)
+			seen[v] = true
+			assert.Equal(t, i, p.Index(v))
+			i++
+		}
+		assert.Equal(t, n, i)
+	}
+
+	// huge ranges are evaluated lazily
+	p := NewIndexPermutationWithKey(math.MaxUint64, []byte("tickets"))
+	for _, i := range []uint64{0, 1, math.MaxUint64 - 1} {
+		assert.Equal(t, i, p.Index(p.At(i)))
+	}
+	p = NewIndexPermutationWithKey(1<<40+3, []byte("tickets"))
+	v := p.At(12345)
+	assert.True(t, v < 1<<40+3)
+	assert.Equal(t, uint64(12345), p.Index(v))
+}
+
+func TestIndexPermutationKey(t *testing.T) {
+	p := NewIndexPermutation(100)
+	q := NewIndexPermutationWithKey(100, p.Key())
+	r := NewIndexPermutationWithKey(100, []byte("other"))
+	same, differ := true, false
+	for i := uint64(0); i < 100; i++ {
+		same = same && p.At(i) == q.At(i)
+		differ = differ || p.At(i) != r.At(i)
+	}
+	assert.True(t, same)
+	assert.True(t, differ)
+	assert.Len(t, p.Key(), saltSize)
+
+	// first value is spread over the range
+	first := make([]int, 10)
+	for i := 0; i < 2000; i++ {
+		first[ReadIndexPermutation(NewDRBG([]byte{byte(i), byte(i >> 8)}), 10).At(0)]++
+	}
+	for _, count := range first {
+		assert.InDelta(t, 200, count, 60)
+	}
+
+	assert.Panics(t, func() { p.At(100) })
+	assert.Panics(t, func() { p.Index(100) })
+	for range NewIndexPermutation(0).All() {
+		t.Fatal("empty permutation yielded a value")
+	}
+}
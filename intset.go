@@ -0,0 +1,44 @@
+//go:build !tinygo
+
+package rng
+
+// intSet is a set of ints used to reject duplicate draws.
+type intSet map[int]struct{}
+
+func newIntSet(size int) intSet {
+	return make(intSet, size)
+}
+
+// add inserts v into the set and reports whether it was not present before.
+func (s intSet) add(v int) bool {
+	if _, ok := s[v]; ok {
+		return false
+	}
+	s[v] = struct{}{}
+	return true
+}
+
+// swapMap holds values of permutation positions that differ from identity.
+type swapMap map[int]int
+
+func newSwapMap(size int) swapMap {
+	return make(swapMap, size)
+}
+
+// get returns the value at position i.
+func (m swapMap) get(i int) int {
+	if v, ok := m[i]; ok {
+		return v
+	}
+	return i
+}
+
+// set stores value v at position i.
+func (m swapMap) set(i, v int) {
+	m[i] = v
+}
+
+// remove resets position i to identity.
+func (m swapMap) remove(i int) {
+	delete(m, i)
+}
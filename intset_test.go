@@ -0,0 +1,31 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntSet(t *testing.T) {
+	s := newIntSet(2)
+	assert.True(t, s.add(1))
+	assert.True(t, s.add(5))
+	assert.False(t, s.add(1))
+	assert.True(t, s.add(0))
+	assert.False(t, s.add(5))
+}
+
+func TestSwapMap(t *testing.T) {
+	m := newSwapMap(2)
+	assert.Equal(t, 3, m.get(3))
+	m.set(3, 7)
+	m.set(5, 1)
+	assert.Equal(t, 7, m.get(3))
+	m.set(3, 0)
+	assert.Equal(t, 0, m.get(3))
+	m.remove(3)
+	assert.Equal(t, 3, m.get(3))
+	assert.Equal(t, 1, m.get(5))
+	m.remove(4)
+	assert.Equal(t, 4, m.get(4))
+}
@@ -0,0 +1,72 @@
+//go:build tinygo
+
+package rng
+
+// intSet is a set of ints used to reject duplicate draws. On TinyGo targets it
+// is a plain slice with linear lookups, which avoids hash map code and
+// allocations on small embedded heaps. Samples drawn there are small (a
+// handful of lottery numbers), so linear lookups are cheap.
+type intSet struct {
+	values []int
+}
+
+func newIntSet(size int) *intSet {
+	return &intSet{values: make([]int, 0, size)}
+}
+
+// add inserts v into the set and reports whether it was not present before.
+func (s *intSet) add(v int) bool {
+	for _, x := range s.values {
+		if x == v {
+			return false
+		}
+	}
+	s.values = append(s.values, v)
+	return true
+}
+
+// swapMap holds values of permutation positions that differ from identity. On
+// TinyGo targets it is a plain slice of position value pairs with linear
+// lookups, see intSet.
+type swapMap struct {
+	pos    []int
+	values []int
+}
+
+func newSwapMap(size int) *swapMap {
+	return &swapMap{pos: make([]int, 0, size), values: make([]int, 0, size)}
+}
+
+// get returns the value at position i.
+func (m *swapMap) get(i int) int {
+	for k, p := range m.pos {
+		if p == i {
+			return m.values[k]
+		}
+	}
+	return i
+}
+
+// set stores value v at position i.
+func (m *swapMap) set(i, v int) {
+	for k, p := range m.pos {
+		if p == i {
+			m.values[k] = v
+			return
+		}
+	}
+	m.pos = append(m.pos, i)
+	m.values = append(m.values, v)
+}
+
+// remove resets position i to identity.
+func (m *swapMap) remove(i int) {
+	for k, p := range m.pos {
+		if p == i {
+			last := len(m.pos) - 1
+			m.pos[k], m.values[k] = m.pos[last], m.values[last]
+			m.pos, m.values = m.pos[:last], m.values[:last]
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"sort"
+)
+
+// InverseCDF returns a value of a distribution given by its inverse
+// cumulative distribution function, see ReadInverseCDF.
+func InverseCDF(cdfInv func(u float64) float64) float64 {
+	return ReadInverseCDF(defaultSource(), cdfInv)
+}
+
+// ReadInverseCDF returns cdfInv(u) for u uniform in (0.0,1.0) reading
+// randomness from a given source, e.g. an exponential variable with
+// cdfInv = func(u float64) float64 { return -math.Log(1-u) / rate }. Zero and
+// one are never passed to cdfInv, so quantile functions of unbounded
+// distributions do not return infinities.
+func ReadInverseCDF(src io.Reader, cdfInv func(u float64) float64) float64 {
+	return cdfInv(ReadFloat64Open(src))
+}
+
+// CDFTable samples a tabulated distribution by inverting its cumulative
+// distribution function, linearly interpolated between table points. This is
+// the distribution with constant density within each interval between
+// consecutive points. CDFTable is immutable and safe for concurrent use.
+type CDFTable struct {
+	xs  []float64
+	cdf []float64
+}
+
+// InverseCDFTable returns a sampler of a distribution tabulated as points
+// (xs[i], cdf[i]), e.g. empirical stake sizes. Both slices must have the same
+// length of at least 2 and be non decreasing, cdf values are rescaled to
+// [0, 1] so cdf[0] maps to 0 and the last value to 1. It will panic if the
+// table is invalid or contains non finite values.
+func InverseCDFTable(xs, cdf []float64) *CDFTable {
+	n := len(xs)
+	if n < 2 || len(cdf) != n {
+		panic("invalid argument to InverseCDFTable, table too short")
+	}
+	for i := range xs {
+		if math.IsNaN(xs[i]) || math.IsInf(xs[i], 0) || !(cdf[i] >= 0) || math.IsInf(cdf[i], 0) {
+			panic("invalid argument to InverseCDFTable, non finite value")
+		}
+		if i > 0 && (xs[i] < xs[i-1] || cdf[i] < cdf[i-1]) {
+			panic("invalid argument to InverseCDFTable, table is not sorted")
+		}
+	}
+	lo, hi := cdf[0], cdf[n-1]
+	if hi == lo {
+		panic("invalid argument to InverseCDFTable, cdf is constant")
+	}
+
+	t := &CDFTable{
+		xs:  append([]float64(nil), xs...),
+		cdf: make([]float64, n),
+	}
+	for i, c := range cdf {
+		t.cdf[i] = (c - lo) / (hi - lo)
+	}
+	t.cdf[n-1] = 1
+	return t
+}
+
+// Quantile returns the value at cumulative probability u. It will panic if u
+// is not in [0, 1].
+func (t *CDFTable) Quantile(u float64) float64 {
+	if !(u >= 0 && u <= 1) {
+		panic("invalid argument to CDFTable.Quantile")
+	}
+	// first point reaching u, its predecessor is strictly below u
+	i := sort.SearchFloat64s(t.cdf, u)
+	if i == 0 {
+		return t.xs[0]
+	}
+	c0, c1 := t.cdf[i-1], t.cdf[i]
+	x0, x1 := t.xs[i-1], t.xs[i]
+	return x0 + (u-c0)/(c1-c0)*(x1-x0)
+}
+
+// Sample returns a random value of the tabulated distribution.
+func (t *CDFTable) Sample() float64 {
+	return t.ReadSample(defaultSource())
+}
+
+// ReadSample returns a random value of the tabulated distribution reading
+// randomness from a given source.
+func (t *CDFTable) ReadSample(src io.Reader) float64 {
+	return t.Quantile(ReadFloat64Open(src))
+}
@@ -0,0 +1,63 @@
+package rng
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverseCDF(t *testing.T) {
+	src := NewDRBG([]byte("inverse cdf"))
+	sum := 0.0
+	for i := 0; i < 20000; i++ {
+		x := ReadInverseCDF(src, func(u float64) float64 {
+			assert.True(t, u > 0 && u < 1)
+			return -math.Log(1-u) / 4
+		})
+		sum += x
+	}
+	assert.InDelta(t, 0.25, sum/20000, 0.01)
+}
+
+func TestInverseCDFTable(t *testing.T) {
+	// cdf given in percent, uniform on [0, 1) with 40% and on [1, 3) with 60%
+	tab := InverseCDFTable([]float64{0, 1, 3}, []float64{0, 40, 100})
+	assert.Equal(t, 0.0, tab.Quantile(0))
+	assert.Equal(t, 0.5, tab.Quantile(0.2))
+	assert.Equal(t, 1.0, tab.Quantile(0.4))
+	assert.InDelta(t, 2.0, tab.Quantile(0.7), 1e-12)
+	assert.Equal(t, 3.0, tab.Quantile(1))
+
+	src := NewDRBG([]byte("table"))
+	below := 0
+	for i := 0; i < 10000; i++ {
+		x := tab.ReadSample(src)
+		assert.True(t, x > 0 && x < 3)
+		if x < 1 {
+			below++
+		}
+	}
+	assert.InDelta(t, 4000, below, 200)
+
+	// flat cdf segments are never sampled, steps are atoms
+	tab = InverseCDFTable([]float64{0, 1, 2, 2, 3}, []float64{0, 0.5, 0.5, 0.9, 1})
+	atoms := 0
+	for i := 0; i < 10000; i++ {
+		x := tab.ReadSample(src)
+		assert.False(t, x > 1 && x < 2)
+		if x == 2 {
+			atoms++
+		}
+	}
+	assert.InDelta(t, 4000, atoms, 200)
+
+	assert.Panics(t, func() { InverseCDFTable([]float64{0}, []float64{1}) })
+	assert.Panics(t, func() { InverseCDFTable([]float64{0, 1}, []float64{0}) })
+	assert.Panics(t, func() { InverseCDFTable([]float64{1, 0}, []float64{0, 1}) })
+	assert.Panics(t, func() { InverseCDFTable([]float64{0, 1}, []float64{1, 0}) })
+	assert.Panics(t, func() { InverseCDFTable([]float64{0, 1}, []float64{0.5, 0.5}) })
+	assert.Panics(t, func() { InverseCDFTable([]float64{0, math.Inf(1)}, []float64{0, 1}) })
+	assert.Panics(t, func() { InverseCDFTable([]float64{0, 1}, []float64{-1, 1}) })
+	assert.Panics(t, func() { tab.Quantile(1.5) })
+}
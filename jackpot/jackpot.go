@@ -0,0 +1,257 @@
+// Package jackpot triggers progressive jackpots.
+//
+// Every stake contributes a share to the jackpot pool and triggers the
+// jackpot with probability exactly proportional to its size: a stake of s
+// minor units triggers with probability min(1, s·p) for a configured rational
+// p per minor unit, so the hit rate per unit staked does not depend on how
+// stakes are split. State is handed to a persistence hook after every
+// contribution, and the expected number of hits is tracked alongside the
+// observed one so the configured hit rate can be validated statistically.
+//
+// MustHit implements "must hit by" jackpots instead: a hidden threshold is
+// drawn at the start of every cycle and committed to with rng.Commit, the
+// jackpot is won when the pool reaches it and the threshold is revealed for
+// verification after payout.
+package jackpot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/advbet/rng"
+)
+
+// ErrPoolOverflow is returned by Contribute if the pool would exceed the
+// int64 range.
+var ErrPoolOverflow = errors.New("jackpot: pool overflows int64")
+
+// Config defines a progressive jackpot.
+type Config struct {
+	// Probability is the trigger probability per minor unit staked, e.g.
+	// 1/50000000.
+	Probability *big.Rat
+	// Contribution is the share of every stake added to the pool, e.g.
+	// 1/100. Nil means no contributions.
+	Contribution *big.Rat
+	// Seed is the pool amount in minor units after the jackpot is won.
+	Seed int64
+}
+
+// State is the persistent state of a jackpot.
+type State struct {
+	// Pool is the current pool in minor units.
+	Pool int64 `json:"pool"`
+	// Carry is the fraction of a minor unit contributed but not yet added
+	// to the pool, in units of 1/Contribution.Denom().
+	Carry int64 `json:"carry"`
+	// Staked is the total of all stakes in minor units.
+	Staked int64 `json:"staked"`
+	// Contributions is the number of stakes.
+	Contributions uint64 `json:"contributions"`
+	// Hits is the number of times the jackpot was triggered.
+	Hits uint64 `json:"hits"`
+	// Expected is the expected number of hits, the sum of trigger
+	// probabilities of all stakes, and Variance its variance.
+	Expected float64 `json:"expected"`
+	Variance float64 `json:"variance"`
+}
+
+// HitRateZ returns the deviation of observed hits from the expected number in
+// standard deviations, zero before any stake with a non zero probability.
+func (s State) HitRateZ() float64 {
+	if s.Variance == 0 {
+		return 0
+	}
+	return (float64(s.Hits) - s.Expected) / math.Sqrt(s.Variance)
+}
+
+// HitRateP returns the two-sided p-value of observed hits under the
+// configured hit rate, small values indicate a miscalibrated trigger. It uses
+// the normal approximation, which is accurate once tens of hits are expected.
+func (s State) HitRateP() float64 {
+	return math.Erfc(math.Abs(s.HitRateZ()) / math.Sqrt2)
+}
+
+// Result is the outcome of a contribution.
+type Result struct {
+	// Triggered is true if the stake won the jackpot.
+	Triggered bool
+	// Payout is the pool won in minor units, zero if not triggered.
+	Payout int64
+	// State is the jackpot state after the contribution.
+	State State
+}
+
+// Jackpot is a progressive jackpot. It is safe for concurrent use if its
+// source is, contributions are serialized.
+type Jackpot struct {
+	cfg   Config
+	src   io.Reader
+	mu    sync.Mutex
+	state State
+
+	// Persist, if set, is called with the new state after every
+	// contribution. If it returns an error the contribution is discarded,
+	// the state is left unchanged and Contribute returns the error.
+	Persist func(State) error
+}
+
+// New returns a jackpot resuming from a persisted state, e.g. State{Pool:
+// cfg.Seed} for a new one. Randomness is read from src or rng.DefaultSource()
+// if src is nil. It returns an error if the probability or contribution share
+// is not in [0, 1] or the state is invalid.
+func New(cfg Config, src io.Reader, state State) (*Jackpot, error) {
+	if cfg.Probability == nil || cfg.Probability.Sign() < 0 || cfg.Probability.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, errors.New("jackpot: probability must be in [0, 1]")
+	}
+	if !validContribution(cfg.Contribution) {
+		return nil, errors.New("jackpot: contribution must be in [0, 1] with an int64 denominator")
+	}
+	if cfg.Seed < 0 || state.Pool < 0 || state.Carry < 0 || state.Staked < 0 {
+		return nil, fmt.Errorf("jackpot: invalid state %+v", state)
+	}
+	if src == nil {
+		src = rng.DefaultSource()
+	}
+	return &Jackpot{cfg: cfg, src: src, state: state}, nil
+}
+
+// State returns the current state.
+func (j *Jackpot) State() State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// TriggerProbability returns the probability of a stake triggering the
+// jackpot, min(1, stakeMinor·p).
+func (j *Jackpot) TriggerProbability(stakeMinor int64) *big.Rat {
+	p := new(big.Rat).Mul(j.cfg.Probability, new(big.Rat).SetInt64(stakeMinor))
+	if p.Cmp(big.NewRat(1, 1)) > 0 {
+		p.SetInt64(1)
+	}
+	return p
+}
+
+// Contribute adds a stake of stakeMinor units to the jackpot and returns
+// whether it triggered. The stake's contribution is added to the pool first,
+// a triggering stake wins the whole pool and the pool is reset to the seed.
+// Source read errors are returned and leave the state unchanged. It will
+// panic if stakeMinor is negative.
+func (j *Jackpot) Contribute(stakeMinor int64) (Result, error) {
+	if stakeMinor < 0 {
+		panic("invalid argument to Contribute")
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	s := j.state
+	if s.Staked > math.MaxInt64-stakeMinor {
+		return Result{}, errors.New("jackpot: total staked overflows int64")
+	}
+	s.Staked += stakeMinor
+	s.Contributions++
+	var err error
+	if s.Pool, s.Carry, err = contribute(j.cfg.Contribution, s.Pool, s.Carry, stakeMinor); err != nil {
+		return Result{}, err
+	}
+
+	p := j.TriggerProbability(stakeMinor)
+	pf, _ := p.Float64()
+	s.Expected += pf
+	s.Variance += pf * (1 - pf)
+	r := Result{}
+	if p.Sign() > 0 {
+		u, err := readBigIntn(j.src, p.Denom())
+		if err != nil {
+			return Result{}, err
+		}
+		r.Triggered = u.Cmp(p.Num()) < 0
+	}
+	if r.Triggered {
+		r.Payout = s.Pool
+		s.Pool = j.cfg.Seed
+		s.Hits++
+	}
+
+	if j.Persist != nil {
+		if err := j.Persist(s); err != nil {
+			return Result{}, err
+		}
+	}
+	j.state = s
+	r.State = s
+	return r, nil
+}
+
+// contribute adds the contribution share c of a stake to the pool and returns
+// the new pool and carry.
+func contribute(c *big.Rat, pool, carry, stake int64) (int64, int64, error) {
+	if c == nil {
+		return pool, carry, nil
+	}
+	// pool += (stake·num + carry) / den, the remainder is carried
+	total := new(big.Int).Mul(big.NewInt(stake), c.Num())
+	total.Add(total, big.NewInt(carry))
+	add, rem := new(big.Int).QuoRem(total, c.Denom(), new(big.Int))
+	add.Add(add, big.NewInt(pool))
+	if !add.IsInt64() {
+		return 0, 0, ErrPoolOverflow
+	}
+	return add.Int64(), rem.Int64(), nil
+}
+
+// validContribution reports whether c is a valid contribution share.
+func validContribution(c *big.Rat) bool {
+	return c == nil || c.Sign() >= 0 && c.Cmp(big.NewRat(1, 1)) <= 0 && c.Denom().IsInt64()
+}
+
+// Simulate runs n contributions against a new jackpot reading randomness from
+// src and returns its final state, e.g. to validate the hit rate of a
+// configuration with State.HitRateP before deployment. Stakes are drawn by
+// stake from the same source.
+func Simulate(cfg Config, src io.Reader, stake func(src io.Reader) int64, n int) (State, error) {
+	j, err := New(cfg, src, State{Pool: cfg.Seed})
+	if err != nil {
+		return State{}, err
+	}
+	for i := 0; i < n; i++ {
+		if _, err := j.Contribute(stake(j.src)); err != nil {
+			return State{}, err
+		}
+	}
+	return j.State(), nil
+}
+
+// readBigIntn returns a uniform integer in [0, n) reading randomness from
+// src. n must be positive. Results do not depend on the platform int size,
+// values up to 2^64 are drawn as by rng.ReadUint64n.
+func readBigIntn(src io.Reader, n *big.Int) (*big.Int, error) {
+	if n.IsUint64() {
+		var r uint64
+		err := rng.New(src).Checked().Draw(func(src io.Reader) {
+			r = rng.ReadUint64n(src, n.Uint64())
+		})
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(r), nil
+	}
+	bits := n.BitLen()
+	buf := make([]byte, (bits+7)/8)
+	r := new(big.Int)
+	for {
+		if _, err := io.ReadFull(src, buf); err != nil {
+			return nil, err
+		}
+		// mask excess bits of the most significant byte
+		buf[0] &= byte(0xff >> (8*len(buf) - bits))
+		if r.SetBytes(buf).Cmp(n) < 0 {
+			return r, nil
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package jackpot
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"testing/iotest"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContribute(t *testing.T) {
+	cfg := Config{
+		Probability:  big.NewRat(1, 1000),
+		Contribution: big.NewRat(3, 100),
+		Seed:         500,
+	}
+	j, err := New(cfg, rng.NewDRBG([]byte("seed")), State{Pool: cfg.Seed})
+	require.NoError(t, err)
+
+	r, err := j.Contribute(10)
+	require.NoError(t, err)
+	require.False(t, r.Triggered)
+	// 3% of 10 units is 0.3, carried until it adds up to whole units
+	assert.Equal(t, State{Pool: 500, Carry: 30, Staked: 10, Contributions: 1, Expected: 0.01, Variance: 0.01 * 0.99}, r.State)
+	r, err = j.Contribute(30)
+	require.NoError(t, err)
+	assert.Equal(t, int64(501), r.State.Pool)
+	assert.Equal(t, int64(20), r.State.Carry)
+	assert.Equal(t, r.State, j.State())
+
+	// stakes of 1000 units and more always trigger
+	assert.Equal(t, big.NewRat(1, 1), j.TriggerProbability(5000))
+	r, err = j.Contribute(1000)
+	require.NoError(t, err)
+	assert.True(t, r.Triggered)
+	assert.Equal(t, int64(531), r.Payout)
+	assert.Equal(t, int64(500), r.State.Pool)
+	assert.Equal(t, uint64(1), r.State.Hits)
+
+	r, err = j.Contribute(0)
+	require.NoError(t, err)
+	assert.False(t, r.Triggered)
+	assert.Panics(t, func() { j.Contribute(-1) })
+}
+
+func TestReadBigIntn(t *testing.T) {
+	// ranges up to 2^64 are drawn as by rng.ReadUint64n on every platform
+	for _, n := range []uint64{1, 6, 1<<31 + 1, 1<<40 + 7, 1<<64 - 1} {
+		r, err := readBigIntn(rng.NewDRBG([]byte("big")), new(big.Int).SetUint64(n))
+		require.NoError(t, err)
+		assert.Equal(t, rng.ReadUint64n(rng.NewDRBG([]byte("big")), n), r.Uint64())
+	}
+	huge := new(big.Int).Lsh(big.NewInt(1), 100)
+	r, err := readBigIntn(rng.NewDRBG([]byte("big")), huge)
+	require.NoError(t, err)
+	assert.True(t, r.Cmp(huge) < 0)
+
+	// source errors are returned
+	_, err = readBigIntn(iotest.ErrReader(io.ErrUnexpectedEOF), big.NewInt(1000))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	_, err = readBigIntn(iotest.ErrReader(io.ErrUnexpectedEOF), huge)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	j, err := New(Config{Probability: big.NewRat(1, 3)}, iotest.ErrReader(io.ErrUnexpectedEOF), State{})
+	require.NoError(t, err)
+	_, err = j.Contribute(1)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Equal(t, State{}, j.State())
+}
+
+func TestPersist(t *testing.T) {
+	cfg := Config{Probability: big.NewRat(1, 2), Contribution: big.NewRat(1, 10)}
+	j, err := New(cfg, rng.NewDRBG([]byte("seed")), State{Pool: 100, Staked: 1000, Contributions: 10})
+	require.NoError(t, err)
+
+	var saved []State
+	j.Persist = func(s State) error {
+		saved = append(saved, s)
+		return nil
+	}
+	r, err := j.Contribute(10)
+	require.NoError(t, err)
+	assert.Equal(t, []State{r.State}, saved)
+	assert.Equal(t, int64(1010), r.State.Staked)
+
+	// failed persistence discards the contribution
+	errStore := errors.New("store down")
+	j.Persist = func(State) error { return errStore }
+	before := j.State()
+	_, err = j.Contribute(10)
+	assert.True(t, errors.Is(err, errStore))
+	assert.Equal(t, before, j.State())
+
+	// resume from persisted state
+	j2, err := New(cfg, rng.NewDRBG([]byte("seed")), saved[0])
+	require.NoError(t, err)
+	assert.Equal(t, saved[0], j2.State())
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(Config{}, nil, State{})
+	assert.Error(t, err)
+	_, err = New(Config{Probability: big.NewRat(3, 2)}, nil, State{})
+	assert.Error(t, err)
+	_, err = New(Config{Probability: big.NewRat(1, 2), Contribution: big.NewRat(-1, 2)}, nil, State{})
+	assert.Error(t, err)
+	_, err = New(Config{Probability: big.NewRat(1, 2)}, nil, State{Pool: -1})
+	assert.Error(t, err)
+
+	// huge denominators are drawn exactly
+	p := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), 100))
+	j, err := New(Config{Probability: p}, nil, State{})
+	require.NoError(t, err)
+	r, err := j.Contribute(1000)
+	require.NoError(t, err)
+	assert.False(t, r.Triggered)
+
+	j, err = New(Config{Probability: big.NewRat(1, 1), Contribution: big.NewRat(1, 1)}, nil, State{Pool: 1 << 62})
+	require.NoError(t, err)
+	_, err = j.Contribute(1 << 62)
+	assert.True(t, errors.Is(err, ErrPoolOverflow))
+}
+
+func TestSimulate(t *testing.T) {
+	cfg := Config{Probability: big.NewRat(1, 5000), Contribution: big.NewRat(1, 100), Seed: 1000}
+	stake := func(src io.Reader) int64 { return int64(1 + rng.ReadIntn(src, 100)) }
+	s, err := Simulate(cfg, rng.NewDRBG([]byte("simulate")), stake, 20000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20000), s.Contributions)
+	// about 202 expected hits
+	assert.InDelta(t, float64(s.Staked)/5000, s.Expected, 1e-6)
+	assert.True(t, s.HitRateP() > 1e-4, "z = %v", s.HitRateZ())
+
+	// a trigger firing at twice the configured rate is detected
+	s.Hits *= 2
+	assert.True(t, s.HitRateP() < 1e-6)
+	assert.Equal(t, 0.0, State{}.HitRateZ())
+}
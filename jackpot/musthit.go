@@ -0,0 +1,219 @@
+package jackpot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/advbet/rng"
+)
+
+// thresholdDomain separates threshold commitments from other committed
+// secrets.
+const thresholdDomain = "advbet/rng jackpot threshold v1"
+
+// ErrThresholdMismatch is returned by VerifyReveal if a revealed threshold
+// does not match its commitment or the payout.
+var ErrThresholdMismatch = errors.New("jackpot: revealed threshold does not match")
+
+// MustHitConfig defines a "must hit by" jackpot. At the start of every cycle
+// a hidden threshold is drawn uniformly in [Min, Max] and committed to, the
+// jackpot is won by the contribution that makes the pool reach it.
+type MustHitConfig struct {
+	// Contribution is the share of every stake added to the pool, it must
+	// be positive so the pool eventually reaches the threshold.
+	Contribution *big.Rat
+	// Seed is the pool amount in minor units at the start of a cycle.
+	Seed int64
+	// Min and Max bound the threshold, Max is the advertised "must hit by"
+	// amount. Seed < Min <= Max is required, so a new cycle never starts
+	// with the pool at its threshold.
+	Min, Max int64
+}
+
+// MustHitState is the persistent state of a must hit by jackpot. It holds the
+// hidden threshold and must be stored as securely as a server seed.
+type MustHitState struct {
+	Pool          int64  `json:"pool"`
+	Carry         int64  `json:"carry"`
+	Staked        int64  `json:"staked"`
+	Contributions uint64 `json:"contributions"`
+	// Cycle numbers cycles from 1, zero before the first cycle is started.
+	Cycle      uint64 `json:"cycle"`
+	Threshold  int64  `json:"threshold"`
+	Salt       []byte `json:"salt"`
+	Commitment []byte `json:"commitment"`
+}
+
+// Reveal discloses the threshold of a finished cycle.
+type Reveal struct {
+	Cycle      uint64 `json:"cycle"`
+	Threshold  int64  `json:"threshold"`
+	Salt       []byte `json:"salt"`
+	Commitment []byte `json:"commitment"`
+}
+
+// MustHitResult is the outcome of a contribution to a must hit by jackpot.
+type MustHitResult struct {
+	// Triggered is true if the contribution made the pool reach the
+	// threshold.
+	Triggered bool
+	// Payout is the pool won in minor units, zero if not triggered.
+	Payout int64
+	// Reveal discloses the threshold of the won cycle, nil if not
+	// triggered. A new cycle with a fresh commitment has started.
+	Reveal *Reveal
+	// Cycle and Commitment identify the current cycle, to be published
+	// before further contributions.
+	Cycle      uint64
+	Commitment []byte
+}
+
+// MustHit is a "must hit by" jackpot. It is safe for concurrent use if its
+// source is, contributions are serialized.
+type MustHit struct {
+	cfg   MustHitConfig
+	src   io.Reader
+	mu    sync.Mutex
+	state MustHitState
+
+	// Persist, if set, is called with the new state after every
+	// contribution. If it returns an error the contribution is discarded,
+	// the state is left unchanged and Contribute returns the error.
+	Persist func(MustHitState) error
+}
+
+// NewMustHit returns a must hit by jackpot resuming from a persisted state.
+// The zero state starts the first cycle, the caller should persist State()
+// before publishing its commitment. Randomness is read from src or
+// rng.DefaultSource() if src is nil. It returns an error for invalid
+// configurations or states.
+func NewMustHit(cfg MustHitConfig, src io.Reader, state MustHitState) (*MustHit, error) {
+	if !validContribution(cfg.Contribution) || cfg.Contribution == nil || cfg.Contribution.Sign() <= 0 {
+		return nil, errors.New("jackpot: contribution must be in (0, 1] with an int64 denominator")
+	}
+	if cfg.Seed < 0 || cfg.Seed >= cfg.Min || cfg.Min > cfg.Max {
+		return nil, errors.New("jackpot: Seed < Min <= Max is required")
+	}
+	if src == nil {
+		src = rng.DefaultSource()
+	}
+	m := &MustHit{cfg: cfg, src: src, state: state}
+	if state.Cycle == 0 {
+		m.state.Pool = cfg.Seed
+		if err := m.startCycle(&m.state); err != nil {
+			return nil, err
+		}
+	} else if state.Pool < 0 || state.Carry < 0 || state.Staked < 0 || state.Pool >= state.Threshold ||
+		state.Threshold < cfg.Min || state.Threshold > cfg.Max ||
+		!rng.VerifyCommitment(state.Commitment, thresholdSecret(state.Cycle, state.Threshold), state.Salt) {
+		return nil, fmt.Errorf("jackpot: invalid state of cycle %d", state.Cycle)
+	}
+	return m, nil
+}
+
+// startCycle draws the threshold of the next cycle and commits to it. It
+// returns source read errors.
+func (m *MustHit) startCycle(s *MustHitState) error {
+	span := new(big.Int).Sub(big.NewInt(m.cfg.Max), big.NewInt(m.cfg.Min))
+	span.Add(span, big.NewInt(1))
+	t, err := readBigIntn(m.src, span)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(m.src, salt); err != nil {
+		return err
+	}
+	s.Cycle++
+	s.Threshold = m.cfg.Min + t.Int64()
+	s.Salt = salt
+	s.Commitment = rng.Commit(thresholdSecret(s.Cycle, s.Threshold), s.Salt)
+	return nil
+}
+
+// State returns the current state.
+func (m *MustHit) State() MustHitState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// Commitment returns the current cycle and the commitment to its threshold.
+func (m *MustHit) Commitment() (cycle uint64, commitment []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.Cycle, m.state.Commitment
+}
+
+// Contribute adds the contribution of a stake of stakeMinor units to the pool
+// and returns whether it made the pool reach the threshold. A triggering
+// stake wins the whole pool, the threshold is revealed and a new cycle
+// starts. Source read errors are returned and leave the state unchanged. It
+// will panic if stakeMinor is negative.
+func (m *MustHit) Contribute(stakeMinor int64) (MustHitResult, error) {
+	if stakeMinor < 0 {
+		panic("invalid argument to Contribute")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.state
+	if s.Staked > math.MaxInt64-stakeMinor {
+		return MustHitResult{}, errors.New("jackpot: total staked overflows int64")
+	}
+	s.Staked += stakeMinor
+	s.Contributions++
+	var err error
+	if s.Pool, s.Carry, err = contribute(m.cfg.Contribution, s.Pool, s.Carry, stakeMinor); err != nil {
+		return MustHitResult{}, err
+	}
+	r := MustHitResult{}
+	if s.Pool >= s.Threshold {
+		r.Triggered = true
+		r.Payout = s.Pool
+		r.Reveal = &Reveal{Cycle: s.Cycle, Threshold: s.Threshold, Salt: s.Salt, Commitment: s.Commitment}
+		s.Pool = m.cfg.Seed
+		if err := m.startCycle(&s); err != nil {
+			return MustHitResult{}, err
+		}
+	}
+
+	if m.Persist != nil {
+		if err := m.Persist(s); err != nil {
+			return MustHitResult{}, err
+		}
+	}
+	m.state = s
+	r.Cycle, r.Commitment = s.Cycle, s.Commitment
+	return r, nil
+}
+
+// VerifyReveal checks a revealed threshold after payout: it must match the
+// commitment published at the start of the cycle, lie in [cfg.Min, cfg.Max]
+// and be reached by the payout. It returns an error wrapping
+// ErrThresholdMismatch otherwise.
+func VerifyReveal(cfg MustHitConfig, r Reveal, payout int64) error {
+	if !rng.VerifyCommitment(r.Commitment, thresholdSecret(r.Cycle, r.Threshold), r.Salt) {
+		return fmt.Errorf("%w: commitment of cycle %d differs", ErrThresholdMismatch, r.Cycle)
+	}
+	if r.Threshold < cfg.Min || r.Threshold > cfg.Max {
+		return fmt.Errorf("%w: threshold %d outside [%d, %d]", ErrThresholdMismatch, r.Threshold, cfg.Min, cfg.Max)
+	}
+	if payout < r.Threshold {
+		return fmt.Errorf("%w: payout %d below threshold %d", ErrThresholdMismatch, payout, r.Threshold)
+	}
+	return nil
+}
+
+// thresholdSecret returns the committed encoding of a cycle threshold: the
+// domain, a zero byte, then cycle and threshold as big endian 64-bit values.
+func thresholdSecret(cycle uint64, threshold int64) []byte {
+	b := append([]byte(thresholdDomain), 0)
+	b = binary.BigEndian.AppendUint64(b, cycle)
+	return binary.BigEndian.AppendUint64(b, uint64(threshold))
+}
@@ -0,0 +1,173 @@
+package jackpot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+	"testing/iotest"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mustHitCfg = MustHitConfig{
+	Contribution: big.NewRat(1, 10),
+	Seed:         1000,
+	Min:          1001,
+	Max:          2000,
+}
+
+func TestMustHit(t *testing.T) {
+	m, err := NewMustHit(mustHitCfg, rng.NewDRBG([]byte("seed")), MustHitState{})
+	require.NoError(t, err)
+	cycle, commitment := m.Commitment()
+	assert.Equal(t, uint64(1), cycle)
+	assert.Len(t, commitment, 32)
+	s := m.State()
+	assert.Equal(t, int64(1000), s.Pool)
+	assert.True(t, s.Threshold > 1000 && s.Threshold <= 2000)
+
+	hits := 0
+	pool := s.Pool
+	for i := 0; i < 1000; i++ {
+		r, err := m.Contribute(100)
+		require.NoError(t, err)
+		if !r.Triggered {
+			pool += 10
+			assert.Equal(t, pool, m.State().Pool)
+			assert.True(t, pool < 2000)
+			continue
+		}
+		hits++
+		require.NotNil(t, r.Reveal)
+		assert.Equal(t, pool+10, r.Payout)
+		assert.Equal(t, commitment, r.Reveal.Commitment)
+		assert.NoError(t, VerifyReveal(mustHitCfg, *r.Reveal, r.Payout))
+		// the pool crossed the threshold with this contribution
+		assert.True(t, pool < r.Reveal.Threshold)
+
+		assert.Equal(t, cycle+1, r.Cycle)
+		assert.NotEqual(t, commitment, r.Commitment)
+		cycle, commitment = r.Cycle, r.Commitment
+		pool = mustHitCfg.Seed
+	}
+	// 100 units per hit at most, 1000 contributions
+	assert.True(t, hits >= 10, "hits %d", hits)
+}
+
+func TestMustHitThresholdUniform(t *testing.T) {
+	cfg := MustHitConfig{Contribution: big.NewRat(1, 10), Min: 10, Max: 13}
+	counts := map[int64]int{}
+	src := rng.NewDRBG([]byte("uniform"))
+	for i := 0; i < 4000; i++ {
+		m, err := NewMustHit(cfg, src, MustHitState{})
+		require.NoError(t, err)
+		counts[m.State().Threshold]++
+	}
+	require.Len(t, counts, 4)
+	for th, n := range counts {
+		assert.InDelta(t, 1000, n, 120, "threshold %d", th)
+	}
+}
+
+func TestVerifyReveal(t *testing.T) {
+	m, err := NewMustHit(mustHitCfg, rng.NewDRBG([]byte("seed")), MustHitState{})
+	require.NoError(t, err)
+	s := m.State()
+	r := Reveal{Cycle: s.Cycle, Threshold: s.Threshold, Salt: s.Salt, Commitment: s.Commitment}
+	assert.NoError(t, VerifyReveal(mustHitCfg, r, s.Threshold))
+
+	bad := r
+	bad.Threshold++
+	assert.True(t, errors.Is(VerifyReveal(mustHitCfg, bad, s.Threshold+1), ErrThresholdMismatch))
+	bad = r
+	bad.Cycle++
+	assert.True(t, errors.Is(VerifyReveal(mustHitCfg, bad, s.Threshold), ErrThresholdMismatch))
+	assert.True(t, errors.Is(VerifyReveal(mustHitCfg, r, s.Threshold-1), ErrThresholdMismatch))
+	narrow := mustHitCfg
+	narrow.Max = s.Threshold - 1
+	narrow.Min = narrow.Max
+	assert.True(t, errors.Is(VerifyReveal(narrow, r, s.Threshold), ErrThresholdMismatch))
+}
+
+func TestMustHitPersist(t *testing.T) {
+	m, err := NewMustHit(mustHitCfg, rng.NewDRBG([]byte("seed")), MustHitState{})
+	require.NoError(t, err)
+	var saved MustHitState
+	m.Persist = func(s MustHitState) error {
+		saved = s
+		return nil
+	}
+	_, err = m.Contribute(50)
+	require.NoError(t, err)
+	assert.Equal(t, m.State(), saved)
+
+	// resume from persisted state
+	m2, err := NewMustHit(mustHitCfg, rng.NewDRBG([]byte("other")), saved)
+	require.NoError(t, err)
+	assert.Equal(t, saved, m2.State())
+
+	// tampered thresholds are rejected
+	saved.Threshold++
+	_, err = NewMustHit(mustHitCfg, nil, saved)
+	assert.Error(t, err)
+
+	errStore := errors.New("store down")
+	m.Persist = func(MustHitState) error { return errStore }
+	before := m.State()
+	_, err = m.Contribute(50)
+	assert.True(t, errors.Is(err, errStore))
+	assert.Equal(t, before, m.State())
+
+	// resumed thresholds must lie in [Min, Max] even if committed to
+	outside := m.State()
+	outside.Threshold = mustHitCfg.Max + 1
+	outside.Commitment = rng.Commit(thresholdSecret(outside.Cycle, outside.Threshold), outside.Salt)
+	_, err = NewMustHit(mustHitCfg, nil, outside)
+	assert.Error(t, err)
+
+	half := big.NewRat(1, 2)
+	_, err = NewMustHit(MustHitConfig{Contribution: half, Seed: 10, Min: 5, Max: 20}, nil, MustHitState{})
+	assert.Error(t, err)
+	_, err = NewMustHit(MustHitConfig{Contribution: half, Min: 20, Max: 10}, nil, MustHitState{})
+	assert.Error(t, err)
+	// a threshold equal to the seed would trigger with a zero stake
+	_, err = NewMustHit(MustHitConfig{Contribution: half, Seed: 100, Min: 100, Max: 101}, nil, MustHitState{})
+	assert.Error(t, err)
+	_, err = NewMustHit(MustHitConfig{Min: 10, Max: 20}, nil, MustHitState{})
+	assert.Error(t, err)
+	_, err = NewMustHit(MustHitConfig{Contribution: new(big.Rat), Min: 10, Max: 20}, nil, MustHitState{})
+	assert.Error(t, err)
+}
+
+func TestMustHitResumeNearSeed(t *testing.T) {
+	// with the seed just below Min every fresh cycle can be resumed and
+	// zero stakes never trigger
+	cfg := MustHitConfig{Contribution: big.NewRat(1, 2), Seed: 99, Min: 100, Max: 101}
+	for i := 0; i < 20; i++ {
+		m, err := NewMustHit(cfg, rng.NewDRBG([]byte(fmt.Sprint(i))), MustHitState{})
+		require.NoError(t, err)
+		r, err := m.Contribute(0)
+		require.NoError(t, err)
+		assert.False(t, r.Triggered)
+		_, err = NewMustHit(cfg, nil, m.State())
+		assert.NoError(t, err)
+	}
+}
+
+func TestMustHitSourceError(t *testing.T) {
+	_, err := NewMustHit(mustHitCfg, iotest.ErrReader(io.ErrUnexpectedEOF), MustHitState{})
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	// the source fails when the next cycle starts
+	src := io.MultiReader(io.LimitReader(rng.NewDRBG([]byte("seed")), 34), iotest.ErrReader(io.ErrUnexpectedEOF))
+	m, err := NewMustHit(mustHitCfg, src, MustHitState{})
+	require.NoError(t, err)
+	before := m.State()
+	_, err = m.Contribute(100000)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Equal(t, before, m.State())
+}
@@ -0,0 +1,124 @@
+// Package kms provides entropy sources backed by managed cloud key management
+// services.
+//
+// Sources request random bytes in batches of up to MaxBatch bytes, the limit
+// of both AWS KMS GenerateRandom and GCP Cloud KMS GenerateRandomBytes, and
+// serve small reads from a local buffer to stay within API quotas. Clients are
+// small adapters around the vendor SDKs, keeping this module free of their
+// dependencies. A source is selected per generator with rng.New(src).
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// MaxBatch is the maximum number of bytes requested by a single API call.
+const MaxBatch = 1024
+
+// AWSClient is the subset of AWS KMS used by AWS source. It is implemented by
+// an adapter calling kms.Client.GenerateRandom with NumberOfBytes set and
+// returning the Plaintext field of the output.
+type AWSClient interface {
+	GenerateRandom(ctx context.Context, numberOfBytes int32) ([]byte, error)
+}
+
+// GCPClient is the subset of GCP Cloud KMS used by GCP source. It is
+// implemented by an adapter calling KeyManagementClient.GenerateRandomBytes
+// with Location, LengthBytes and ProtectionLevel HSM set and returning the Data
+// field of the response.
+type GCPClient interface {
+	GenerateRandomBytes(ctx context.Context, location string, lengthBytes int32) ([]byte, error)
+}
+
+// Options configure a Source.
+type Options struct {
+	// Batch is the number of bytes requested by a single API call, values
+	// outside (0, MaxBatch] select MaxBatch.
+	Batch int
+	// Timeout limits the duration of a single API call, 0 means no limit.
+	Timeout time.Duration
+}
+
+// Source is an io.Reader returning random bytes generated by a cloud KMS. It
+// is safe for concurrent use.
+type Source struct {
+	fetch   func(ctx context.Context, n int32) ([]byte, error)
+	batch   int
+	timeout time.Duration
+
+	mu  sync.Mutex
+	buf []byte // fetched bytes not returned yet
+}
+
+var _ io.Reader = (*Source)(nil)
+
+// NewAWS returns a source backed by AWS KMS GenerateRandom.
+func NewAWS(client AWSClient, opts Options) *Source {
+	return newSource(client.GenerateRandom, opts)
+}
+
+// NewGCP returns a source backed by GCP Cloud KMS GenerateRandomBytes in a
+// given location, e.g. "projects/p/locations/europe-west1".
+func NewGCP(client GCPClient, location string, opts Options) *Source {
+	return newSource(func(ctx context.Context, n int32) ([]byte, error) {
+		return client.GenerateRandomBytes(ctx, location, n)
+	}, opts)
+}
+
+func newSource(fetch func(ctx context.Context, n int32) ([]byte, error), opts Options) *Source {
+	if opts.Batch <= 0 || opts.Batch > MaxBatch {
+		opts.Batch = MaxBatch
+	}
+	return &Source{
+		fetch:   fetch,
+		batch:   opts.Batch,
+		timeout: opts.Timeout,
+	}
+}
+
+// Read fills p with random bytes. Buffered bytes are returned first, each byte
+// is returned only once.
+func (s *Source) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			if err := s.refill(); err != nil {
+				return n, err
+			}
+		}
+		c := copy(p[n:], s.buf)
+		// wipe returned bytes from the buffer
+		for i := range s.buf[:c] {
+			s.buf[i] = 0
+		}
+		s.buf = s.buf[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (s *Source) refill() error {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	b, err := s.fetch(ctx, int32(s.batch))
+	if err != nil {
+		return fmt.Errorf("kms: generate random: %w", err)
+	}
+	if len(b) != s.batch {
+		return errors.New("kms: generate random returned unexpected number of bytes")
+	}
+	s.buf = b
+	return nil
+}
@@ -0,0 +1,79 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAWS struct {
+	calls []int32
+	err   error
+}
+
+func (c *fakeAWS) GenerateRandom(ctx context.Context, n int32) ([]byte, error) {
+	c.calls = append(c.calls, n)
+	if c.err != nil {
+		return nil, c.err
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(len(c.calls))
+	}
+	return b, nil
+}
+
+type fakeGCP struct {
+	location string
+	deadline bool
+}
+
+func (c *fakeGCP) GenerateRandomBytes(ctx context.Context, location string, n int32) ([]byte, error) {
+	c.location = location
+	_, c.deadline = ctx.Deadline()
+	return make([]byte, n), nil
+}
+
+func TestAWS(t *testing.T) {
+	client := &fakeAWS{}
+	src := NewAWS(client, Options{Batch: 4})
+
+	b := make([]byte, 3)
+	_, err := src.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 1, 1}, b)
+
+	// remaining buffered byte is used before a new batch
+	b = make([]byte, 6)
+	_, err = src.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 2, 2, 2, 3}, b)
+	assert.Equal(t, []int32{4, 4, 4}, client.calls)
+
+	client.err = errors.New("ThrottlingException")
+	_, err = src.Read(make([]byte, 8))
+	assert.Error(t, err)
+}
+
+func TestBatchLimit(t *testing.T) {
+	client := &fakeAWS{}
+	src := NewAWS(client, Options{Batch: 5000})
+	_, err := src.Read(make([]byte, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []int32{MaxBatch}, client.calls)
+}
+
+func TestGCP(t *testing.T) {
+	client := &fakeGCP{}
+	src := NewGCP(client, "projects/p/locations/europe-west1", Options{Timeout: time.Second})
+
+	g := rng.New(src)
+	assert.Equal(t, 0, g.Intn(10))
+	assert.Equal(t, "projects/p/locations/europe-west1", client.location)
+	assert.True(t, client.deadline)
+}
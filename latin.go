@@ -0,0 +1,109 @@
+package rng
+
+import (
+	"io"
+)
+
+// RandomLatinSquare returns a random n×n Latin square over symbols [0, n).
+// Square is indexed as square[row][column].
+//
+// Square is obtained by running a Jacobson–Matthews Markov chain, which mixes
+// over the whole space of Latin squares, starting from a cyclic square.
+func RandomLatinSquare(n int) [][]int {
+	return ReadRandomLatinSquare(defaultSource(), n)
+}
+
+// ReadRandomLatinSquare returns a random n×n Latin square over symbols [0, n)
+// reading randomness from a given source. It will panic if n < 0.
+func ReadRandomLatinSquare(src io.Reader, n int) [][]int {
+	if n < 0 {
+		panic("invalid argument to RandomLatinSquare")
+	}
+
+	// incidence cube, cube[r][c][s] is 1 if symbol s is in cell (r, c),
+	// improper states have a single cell set to -1
+	cube := make([]int8, n*n*n)
+	at := func(r, c, s int) *int8 {
+		return &cube[(r*n+c)*n+s]
+	}
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			*at(r, c, (r+c)%n) = 1
+		}
+	}
+
+	if n > 1 {
+		// number of proper moves to make, n^3 is the usual heuristic
+		// for good mixing
+		moves := n * n * n
+		improper := false
+		var ir, ic, is int // coordinates of the -1 cell if improper
+
+		for moves > 0 || improper {
+			var r, c, s, r2, c2, s2 int
+			if !improper {
+				// pick a uniformly random zero cell of the cube
+				r, c = ReadIntn(src, n), ReadIntn(src, n)
+				cur := 0
+				for *at(r, c, cur) != 1 {
+					cur++
+				}
+				s = ReadIntn(src, n-1)
+				if s >= cur {
+					s++
+				}
+				r2 = lineFind(n, func(i int) int8 { return *at(i, c, s) }, 0)
+				c2 = lineFind(n, func(i int) int8 { return *at(r, i, s) }, 0)
+				s2 = lineFind(n, func(i int) int8 { return *at(r, c, i) }, 0)
+				moves--
+			} else {
+				// improper cell lines contain exactly two ones, pick
+				// one of them at random
+				r, c, s = ir, ic, is
+				r2 = lineFind(n, func(i int) int8 { return *at(i, c, s) }, ReadIntn(src, 2))
+				c2 = lineFind(n, func(i int) int8 { return *at(r, i, s) }, ReadIntn(src, 2))
+				s2 = lineFind(n, func(i int) int8 { return *at(r, c, i) }, ReadIntn(src, 2))
+			}
+
+			*at(r, c, s)++
+			*at(r, c2, s2)++
+			*at(r2, c, s2)++
+			*at(r2, c2, s)++
+			*at(r, c, s2)--
+			*at(r, c2, s)--
+			*at(r2, c, s)--
+			*at(r2, c2, s2)--
+
+			improper = *at(r2, c2, s2) < 0
+			ir, ic, is = r2, c2, s2
+		}
+	}
+
+	square := make([][]int, n)
+	for r := range square {
+		square[r] = make([]int, n)
+		for c := range square[r] {
+			for s := 0; s < n; s++ {
+				if *at(r, c, s) == 1 {
+					square[r][c] = s
+					break
+				}
+			}
+		}
+	}
+	return square
+}
+
+// lineFind returns index of the k-th (zero based) cell with value 1 in a cube
+// line of length n accessed via get.
+func lineFind(n int, get func(int) int8, k int) int {
+	for i := 0; i < n; i++ {
+		if get(i) == 1 {
+			if k == 0 {
+				return i
+			}
+			k--
+		}
+	}
+	panic("rng: inconsistent Latin square incidence cube")
+}
@@ -0,0 +1,55 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func isLatinSquare(square [][]int) bool {
+	n := len(square)
+	for i := 0; i < n; i++ {
+		row := make(map[int]bool)
+		col := make(map[int]bool)
+		for j := 0; j < n; j++ {
+			if square[i][j] < 0 || square[i][j] >= n || square[j][i] < 0 || square[j][i] >= n {
+				return false
+			}
+			row[square[i][j]] = true
+			col[square[j][i]] = true
+		}
+		if len(row) != n || len(col) != n {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRandomLatinSquare(t *testing.T) {
+	assert.Empty(t, RandomLatinSquare(0))
+	assert.Equal(t, [][]int{{0}}, RandomLatinSquare(1))
+
+	for n := 2; n <= 12; n++ {
+		square := RandomLatinSquare(n)
+		assert.Len(t, square, n)
+		assert.True(t, isLatinSquare(square), "%v is not a Latin square", square)
+	}
+
+	assert.Panics(t, func() {
+		RandomLatinSquare(-1)
+	})
+}
+
+func TestRandomLatinSquareCoverage(t *testing.T) {
+	// There are exactly 12 Latin squares of order 3, chain must be able to
+	// reach all of them.
+	seen := make(map[[9]int]bool)
+	for i := 0; i < 1000 && len(seen) < 12; i++ {
+		var key [9]int
+		for r, row := range RandomLatinSquare(3) {
+			copy(key[r*3:], row)
+		}
+		seen[key] = true
+	}
+	assert.Len(t, seen, 12)
+}
@@ -0,0 +1,102 @@
+// Package loadgen generates synthetic traffic for load tests from a single
+// seedable source, e.g. rng.NewDRBG(seed) for reproducible runs.
+package loadgen
+
+import (
+	"io"
+	"math"
+
+	"github.com/advbet/rng"
+)
+
+// Correlated generates N streams of values sharing a common shock. Every step
+// draws a common standard normal Z and independent standard normals E_i and
+// returns
+//
+//	X_i = sqrt(rho) * Z + sqrt(1 - rho) * E_i
+//
+// so each X_i is standard normal and any two streams have correlation rho,
+// e.g. betting activity across markets surging together on a goal.
+//
+// Correlated is not safe for concurrent use.
+type Correlated struct {
+	src       io.Reader
+	n         int
+	common    float64 // sqrt(rho)
+	own       float64 // sqrt(1 - rho)
+	spare     float64
+	haveSpare bool
+}
+
+// NewCorrelated returns a generator of n streams with pairwise correlation rho
+// reading randomness from src. It will panic if n <= 0 or rho is not in
+// [0, 1].
+func NewCorrelated(src io.Reader, n int, rho float64) *Correlated {
+	if n <= 0 || !(rho >= 0 && rho <= 1) {
+		panic("invalid argument to NewCorrelated")
+	}
+	return &Correlated{
+		src:    src,
+		n:      n,
+		common: math.Sqrt(rho),
+		own:    math.Sqrt(1 - rho),
+	}
+}
+
+// normal returns a standard normal value using the Box–Muller transform.
+func (c *Correlated) normal() float64 {
+	if c.haveSpare {
+		c.haveSpare = false
+		return c.spare
+	}
+	u1 := rng.ReadFloat64Open(c.src)
+	u2 := rng.ReadFloat64(c.src)
+	r := math.Sqrt(-2 * math.Log(u1))
+	c.spare = r * math.Sin(2*math.Pi*u2)
+	c.haveSpare = true
+	return r * math.Cos(2*math.Pi*u2)
+}
+
+// Next returns the next standard normal value of every stream.
+func (c *Correlated) Next() []float64 {
+	z := c.normal()
+	x := make([]float64, c.n)
+	for i := range x {
+		x[i] = c.common*z + c.own*c.normal()
+	}
+	return x
+}
+
+// NextUniform returns the next value of every stream transformed to a uniform
+// value in (0, 1) by the normal CDF, keeping the dependence between streams.
+func (c *Correlated) NextUniform() []float64 {
+	x := c.Next()
+	for i := range x {
+		x[i] = 0.5 * math.Erfc(-x[i]/math.Sqrt2)
+	}
+	return x
+}
+
+// NextCounts returns the next event count of every stream, e.g. bets placed
+// per market in a second. Stream i is Poisson with mean
+//
+//	rates[i] * exp(volatility * X_i - volatility^2 / 2)
+//
+// so the long run mean is rates[i] while surges are correlated across
+// streams. It will panic if len(rates) differs from the number of streams or
+// a rate or volatility is negative.
+func (c *Correlated) NextCounts(rates []float64, volatility float64) []int {
+	if len(rates) != c.n || volatility < 0 {
+		panic("invalid argument to NextCounts")
+	}
+	x := c.Next()
+	counts := make([]int, c.n)
+	for i, rate := range rates {
+		if rate < 0 {
+			panic("invalid argument to NextCounts")
+		}
+		mean := rate * math.Exp(volatility*x[i]-volatility*volatility/2)
+		counts[i] = rng.ReadPoisson(c.src, mean)
+	}
+	return counts
+}
@@ -0,0 +1,63 @@
+package loadgen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func correlation(a, b []float64) float64 {
+	n := float64(len(a))
+	var sa, sb, saa, sbb, sab float64
+	for i := range a {
+		sa += a[i]
+		sb += b[i]
+		saa += a[i] * a[i]
+		sbb += b[i] * b[i]
+		sab += a[i] * b[i]
+	}
+	cov := sab/n - sa/n*sb/n
+	return cov / math.Sqrt((saa/n-sa/n*sa/n)*(sbb/n-sb/n*sb/n))
+}
+
+func TestCorrelated(t *testing.T) {
+	for _, rho := range []float64{0, 0.3, 0.9} {
+		c := NewCorrelated(rng.NewDRBG([]byte("load")), 3, rho)
+		const steps = 20000
+		streams := make([][]float64, 3)
+		for s := 0; s < steps; s++ {
+			for i, x := range c.Next() {
+				streams[i] = append(streams[i], x)
+			}
+		}
+		assert.InDelta(t, rho, correlation(streams[0], streams[1]), 0.03, "rho = %g", rho)
+		assert.InDelta(t, rho, correlation(streams[1], streams[2]), 0.03, "rho = %g", rho)
+	}
+}
+
+func TestCorrelatedReproducible(t *testing.T) {
+	a := NewCorrelated(rng.NewDRBG([]byte("seed")), 2, 0.5)
+	b := NewCorrelated(rng.NewDRBG([]byte("seed")), 2, 0.5)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, a.NextUniform(), b.NextUniform())
+	}
+}
+
+func TestNextCounts(t *testing.T) {
+	c := NewCorrelated(rng.NewDRBG([]byte("counts")), 2, 0.8)
+	const steps = 5000
+	sums := make([]float64, 2)
+	for s := 0; s < steps; s++ {
+		for i, k := range c.NextCounts([]float64{3, 1200}, 0.2) {
+			sums[i] += float64(k)
+		}
+	}
+	assert.InDelta(t, 3, sums[0]/steps, 0.15)
+	assert.InDelta(t, 1200, sums[1]/steps, 20)
+
+	assert.Panics(t, func() { c.NextCounts([]float64{1}, 0.2) })
+	assert.Panics(t, func() { c.NextCounts([]float64{1, -1}, 0.2) })
+	assert.Panics(t, func() { NewCorrelated(nil, 2, 1.5) })
+}
@@ -0,0 +1,146 @@
+package loadgen
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/advbet/rng"
+)
+
+// Skew is a key popularity distribution of a Workload. Keys are ranks, key 0
+// is the most popular one.
+type Skew struct {
+	kind   int
+	s      float64
+	hotSet float64
+	hotOps float64
+}
+
+const (
+	skewUniform = iota
+	skewZipf
+	skewHotspot
+)
+
+// Uniform returns a skew where all keys are equally popular.
+func Uniform() Skew {
+	return Skew{kind: skewUniform}
+}
+
+// Zipf returns a skew where key k is requested with probability proportional
+// to 1/(k+1)^s. YCSB uses s = 0.99.
+func Zipf(s float64) Skew {
+	return Skew{kind: skewZipf, s: s}
+}
+
+// Hotspot returns a skew where hotOps share of requests go to the first
+// hotSet share of keys, uniform within both the hot and the cold set, e.g.
+// Hotspot(0.2, 0.8) for the 80/20 rule.
+func Hotspot(hotSet, hotOps float64) Skew {
+	return Skew{kind: skewHotspot, hotSet: hotSet, hotOps: hotOps}
+}
+
+// Workload generates a stream of requests for keys [0, keys) with Poisson
+// arrivals at a target rate, a building block for benchmark harnesses.
+//
+// Workload is not safe for concurrent use.
+type Workload struct {
+	src  io.Reader
+	keys int
+	rate float64
+	skew Skew
+	cdf  []float64 // zipf cumulative probabilities
+	hot  int       // hotspot hot set size
+	at   float64   // arrival time of the last request in seconds
+}
+
+// NewWorkload returns a workload of keys with a given skew and a mean rate of
+// requests per second reading randomness from src. Zipf skew precomputes a
+// table of keys float64 values. It will panic if keys <= 0, rate is not
+// positive and finite, Zipf exponent is negative or Hotspot shares are not in
+// [0, 1].
+func NewWorkload(src io.Reader, keys int, skew Skew, rate float64) *Workload {
+	if keys <= 0 || !(rate > 0) || math.IsInf(rate, 1) {
+		panic("invalid argument to NewWorkload")
+	}
+	w := &Workload{src: src, keys: keys, rate: rate, skew: skew}
+	switch skew.kind {
+	case skewZipf:
+		if !(skew.s >= 0) || math.IsInf(skew.s, 1) {
+			panic("invalid argument to NewWorkload, bad Zipf exponent")
+		}
+		w.cdf = make([]float64, keys)
+		sum := 0.0
+		for k := range w.cdf {
+			sum += math.Pow(float64(k+1), -skew.s)
+			w.cdf[k] = sum
+		}
+		for k := range w.cdf {
+			w.cdf[k] /= sum
+		}
+	case skewHotspot:
+		if !(skew.hotSet >= 0 && skew.hotSet <= 1) || !(skew.hotOps >= 0 && skew.hotOps <= 1) {
+			panic("invalid argument to NewWorkload, bad Hotspot shares")
+		}
+		w.hot = int(math.Round(skew.hotSet * float64(keys)))
+	}
+	return w
+}
+
+// Key returns the next requested key.
+func (w *Workload) Key() int {
+	switch w.skew.kind {
+	case skewZipf:
+		u := rng.ReadFloat64(w.src)
+		k := sort.SearchFloat64s(w.cdf, u)
+		// u equal to a cumulative value belongs to the next key
+		if k < len(w.cdf) && w.cdf[k] == u {
+			k++
+		}
+		return min(k, w.keys-1)
+	case skewHotspot:
+		hot := w.hot > 0 && (w.hot == w.keys || rng.ReadFloat64(w.src) < w.skew.hotOps)
+		if hot {
+			return rng.ReadIntn(w.src, w.hot)
+		}
+		return w.hot + rng.ReadIntn(w.src, w.keys-w.hot)
+	default:
+		return rng.ReadIntn(w.src, w.keys)
+	}
+}
+
+// Next returns the arrival time of the next request, measured from the start
+// of the workload, and its key. Gaps between arrivals are exponential with
+// mean 1/rate.
+func (w *Workload) Next() (time.Duration, int) {
+	w.at += -math.Log(rng.ReadFloat64Open(w.src)) / w.rate
+	return time.Duration(w.at * float64(time.Second)), w.Key()
+}
+
+// Run calls fn with the key of every request at its arrival time until ctx is
+// done and returns the context error. Arrivals are scheduled from the time Run
+// is called, if fn falls behind requests are issued without waiting so the
+// target rate is kept on average (open loop).
+func (w *Workload) Run(ctx context.Context, fn func(key int)) error {
+	start := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	for {
+		at, key := w.Next()
+		if d := time.Until(start.Add(at)); d > 0 {
+			timer.Reset(d)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		fn(key)
+	}
+}
@@ -0,0 +1,96 @@
+package loadgen
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func keyCounts(w *Workload, keys, n int) []int {
+	counts := make([]int, keys)
+	for i := 0; i < n; i++ {
+		counts[w.Key()]++
+	}
+	return counts
+}
+
+func TestWorkloadUniform(t *testing.T) {
+	w := NewWorkload(rng.NewDRBG([]byte("uniform")), 10, Uniform(), 100)
+	for _, c := range keyCounts(w, 10, 10000) {
+		assert.InDelta(t, 1000, c, 150)
+	}
+}
+
+func TestWorkloadZipf(t *testing.T) {
+	w := NewWorkload(rng.NewDRBG([]byte("zipf")), 100, Zipf(1), 100)
+	counts := keyCounts(w, 100, 100000)
+	h := 0.0
+	for k := 1; k <= 100; k++ {
+		h += 1 / float64(k)
+	}
+	for _, k := range []int{0, 1, 9, 99} {
+		want := 100000 / float64(k+1) / h
+		assert.InDelta(t, want, counts[k], 5*math.Sqrt(want)+1, "key %d", k)
+	}
+
+	// exponent 0 is uniform
+	w = NewWorkload(rng.NewDRBG([]byte("zipf")), 4, Zipf(0), 100)
+	for _, c := range keyCounts(w, 4, 8000) {
+		assert.InDelta(t, 2000, c, 200)
+	}
+}
+
+func TestWorkloadHotspot(t *testing.T) {
+	w := NewWorkload(rng.NewDRBG([]byte("hotspot")), 100, Hotspot(0.2, 0.8), 100)
+	counts := keyCounts(w, 100, 50000)
+	hot := 0
+	for k, c := range counts {
+		if k < 20 {
+			hot += c
+		}
+	}
+	assert.InDelta(t, 40000, hot, 500)
+
+	// all keys hot or no keys hot
+	w = NewWorkload(rng.NewDRBG(nil), 10, Hotspot(1, 0.5), 100)
+	keyCounts(w, 10, 100)
+	w = NewWorkload(rng.NewDRBG(nil), 10, Hotspot(0, 0.5), 100)
+	keyCounts(w, 10, 100)
+}
+
+func TestWorkloadNext(t *testing.T) {
+	w := NewWorkload(rng.NewDRBG([]byte("arrivals")), 10, Uniform(), 50)
+	var last time.Duration
+	for i := 0; i < 5000; i++ {
+		at, key := w.Next()
+		assert.True(t, at >= last)
+		assert.True(t, key >= 0 && key < 10)
+		last = at
+	}
+	// 5000 arrivals at 50 per second take about 100 seconds
+	assert.InDelta(t, 100, last.Seconds(), 5)
+}
+
+func TestWorkloadRun(t *testing.T) {
+	w := NewWorkload(rng.NewDRBG([]byte("run")), 10, Uniform(), 1000)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	n := 0
+	err := w.Run(ctx, func(key int) { n++ })
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, n > 50 && n < 400, "%d requests", n)
+}
+
+func TestNewWorkloadInvalid(t *testing.T) {
+	src := rng.NewDRBG(nil)
+	assert.Panics(t, func() { NewWorkload(src, 0, Uniform(), 1) })
+	assert.Panics(t, func() { NewWorkload(src, 1, Uniform(), 0) })
+	assert.Panics(t, func() { NewWorkload(src, 1, Uniform(), math.Inf(1)) })
+	assert.Panics(t, func() { NewWorkload(src, 1, Zipf(-1), 1) })
+	assert.Panics(t, func() { NewWorkload(src, 1, Hotspot(1.5, 0.5), 1) })
+	assert.Panics(t, func() { NewWorkload(src, 1, Hotspot(0.5, math.NaN()), 1) })
+}
@@ -0,0 +1,98 @@
+package rng
+
+import (
+	"cmp"
+	"io"
+	"slices"
+)
+
+// MapKey returns a uniformly random key of a map. It will panic if the map is
+// empty.
+//
+// Go map iteration order is unspecified but not random, so picking the first
+// key of a range loop must not be used as a random selection.
+func MapKey[K comparable, V any](m map[K]V) K {
+	return ReadMapKey(defaultSource(), m)
+}
+
+// WeightedMapKey returns a random key of a map with probability of each key
+// proportional to its weight. Keys with zero weight are never returned. It will
+// panic if all weights are zero or if the sum of weights overflows uint64.
+func WeightedMapKey[K comparable](weights map[K]uint64) K {
+	return ReadWeightedMapKey(defaultSource(), weights)
+}
+
+// ReadMapKey returns a uniformly random key of a map reading randomness from a
+// given source.
+//
+// Selection is unbiased regardless of map iteration order, but because the
+// order changes between runs the returned key is not reproducible from a
+// deterministic source.
+func ReadMapKey[K comparable, V any](src io.Reader, m map[K]V) K {
+	if len(m) == 0 {
+		panic("invalid argument to MapKey, map is empty")
+	}
+
+	i := ReadIntn(src, len(m))
+	for k := range m {
+		if i == 0 {
+			return k
+		}
+		i--
+	}
+	panic("unreachable")
+}
+
+// ReadWeightedMapKey returns a random key of a map with probability of each
+// key proportional to its weight reading randomness from a given source.
+//
+// Like ReadMapKey, selection is unbiased regardless of map iteration order,
+// but the returned key is not reproducible from a deterministic source, see
+// ReadSortedWeightedMapKey for ordered key types.
+func ReadWeightedMapKey[K comparable](src io.Reader, weights map[K]uint64) K {
+	keys := make([]K, 0, len(weights))
+	for k := range weights {
+		keys = append(keys, k)
+	}
+	return readWeightedKey(src, weights, keys)
+}
+
+// ReadSortedWeightedMapKey returns a random key of a map with probability of
+// each key proportional to its weight reading randomness from a given source.
+// Keys are considered in ascending order, so the same source always returns
+// the same key. It will panic if all weights are zero or if the sum of
+// weights overflows uint64.
+func ReadSortedWeightedMapKey[K cmp.Ordered](src io.Reader, weights map[K]uint64) K {
+	keys := make([]K, 0, len(weights))
+	for k := range weights {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return readWeightedKey(src, weights, keys)
+}
+
+// readWeightedKey returns a random key of weights considering keys in the
+// given order.
+func readWeightedKey[K comparable](src io.Reader, weights map[K]uint64, keys []K) K {
+	var total uint64
+	for _, k := range keys {
+		w := weights[k]
+		if total+w < total {
+			panic("invalid argument to WeightedMapKey, weights sum overflows uint64")
+		}
+		total += w
+	}
+	if total == 0 {
+		panic("invalid argument to WeightedMapKey, weights sum is zero")
+	}
+
+	r := readUint64n(src, total)
+	for _, k := range keys {
+		w := weights[k]
+		if r < w {
+			return k
+		}
+		r -= w
+	}
+	panic("unreachable")
+}
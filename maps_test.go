@@ -0,0 +1,55 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapKey(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	seen := make(map[string]bool)
+	for i := 0; i < 1000 && len(seen) < len(m); i++ {
+		k := MapKey(m)
+		assert.Contains(t, m, k)
+		seen[k] = true
+	}
+	assert.Len(t, seen, len(m))
+
+	assert.Panics(t, func() {
+		MapKey(map[int]int{})
+	})
+}
+
+func TestWeightedMapKey(t *testing.T) {
+	weights := map[string]uint64{"never": 0, "a": 1, "b": 1 << 40}
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, "never", WeightedMapKey(weights))
+	}
+	assert.Equal(t, "a", WeightedMapKey(map[string]uint64{"a": 1, "b": 0}))
+	assert.Equal(t, 7, WeightedMapKey(map[int]uint64{7: 1<<64 - 1}))
+
+	assert.Panics(t, func() {
+		WeightedMapKey(map[string]uint64{"a": 0})
+	})
+	assert.Panics(t, func() {
+		WeightedMapKey(map[string]uint64{"a": 1 << 63, "b": 1 << 63})
+	})
+}
+
+func TestReadSortedWeightedMapKey(t *testing.T) {
+	weights := map[string]uint64{"a": 1, "b": 2, "c": 3, "d": 4, "never": 0}
+	var first []string
+	for i := 0; i < 50; i++ {
+		first = append(first, ReadSortedWeightedMapKey(NewDRBG([]byte{byte(i)}), weights))
+	}
+	// the same sources return the same keys regardless of iteration order
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, first[i], ReadSortedWeightedMapKey(NewDRBG([]byte{byte(i)}), weights))
+	}
+	assert.NotContains(t, first, "never")
+
+	assert.Panics(t, func() {
+		ReadSortedWeightedMapKey(NewDRBG(nil), map[int]uint64{1: 0})
+	})
+}
@@ -0,0 +1,89 @@
+package rng
+
+import (
+	"io"
+	"sort"
+)
+
+// MarkovSampler walks a Markov chain with integer transition weights, e.g.
+// synthetic user journeys through pages of a site. Draws are exact, the
+// probability of moving from state i to state j is exactly
+// transitions[i][j] / sum(transitions[i]). MarkovSampler is immutable and safe
+// for concurrent use.
+type MarkovSampler struct {
+	// cum[i][j] is the sum of transitions[i][0..j]
+	cum [][]uint64
+}
+
+// NewMarkovSampler returns a sampler for a square transition weight matrix,
+// transitions[i][j] is the weight of moving from state i to state j. Absorbing
+// states have a non zero weight only for themselves. It will panic if the
+// matrix is not square, a row has zero sum or a row sum overflows uint64.
+func NewMarkovSampler(transitions [][]uint64) *MarkovSampler {
+	n := len(transitions)
+	cum := make([][]uint64, n)
+	for i, row := range transitions {
+		if len(row) != n {
+			panic("invalid argument to NewMarkovSampler, matrix is not square")
+		}
+		cum[i] = make([]uint64, n)
+		var total uint64
+		for j, w := range row {
+			if total+w < total {
+				panic("invalid argument to NewMarkovSampler, weights sum overflows uint64")
+			}
+			total += w
+			cum[i][j] = total
+		}
+		if total == 0 {
+			panic("invalid argument to NewMarkovSampler, weights sum is zero")
+		}
+	}
+	return &MarkovSampler{cum: cum}
+}
+
+// States returns the number of states of the chain.
+func (m *MarkovSampler) States() int {
+	return len(m.cum)
+}
+
+// Next returns a random successor of state.
+func (m *MarkovSampler) Next(state int) int {
+	return m.ReadNext(defaultSource(), state)
+}
+
+// ReadNext returns a random successor of state reading randomness from a
+// given source. It will panic if state is out of range.
+func (m *MarkovSampler) ReadNext(src io.Reader, state int) int {
+	if state < 0 || state >= len(m.cum) {
+		panic("invalid argument to MarkovSampler.Next")
+	}
+	row := m.cum[state]
+	r := readUint64n(src, row[len(row)-1])
+	// first state whose cumulative weight exceeds r, zero weight states are
+	// never selected
+	return sort.Search(len(row), func(j int) bool { return row[j] > r })
+}
+
+// Walk returns a random walk of steps transitions starting at start.
+func (m *MarkovSampler) Walk(start, steps int) []int {
+	return m.ReadWalk(defaultSource(), start, steps)
+}
+
+// ReadWalk returns a random walk of steps transitions starting at start
+// reading randomness from a given source. The returned slice holds start
+// followed by steps visited states. It will panic if start is out of range or
+// steps < 0.
+func (m *MarkovSampler) ReadWalk(src io.Reader, start, steps int) []int {
+	if steps < 0 || start < 0 || start >= len(m.cum) {
+		panic("invalid argument to MarkovSampler.Walk")
+	}
+	walk := make([]int, 0, steps+1)
+	walk = append(walk, start)
+	state := start
+	for i := 0; i < steps; i++ {
+		state = m.ReadNext(src, state)
+		walk = append(walk, state)
+	}
+	return walk
+}
@@ -0,0 +1,45 @@
+package rng
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkovSampler(t *testing.T) {
+	// home -> {home 0, match 3, checkout 1}, match -> {home 1, match 1,
+	// checkout 2}, checkout is absorbing
+	m := NewMarkovSampler([][]uint64{
+		{0, 3, 1},
+		{1, 1, 2},
+		{0, 0, 1},
+	})
+	assert.Equal(t, 3, m.States())
+
+	src := bytes.NewBuffer([]byte{0, 1, 3})
+	assert.Equal(t, []int{0, 1, 1, 2, 2}, m.ReadWalk(src, 0, 4))
+
+	g := NewDRBG([]byte("markov"))
+	const samples = 40000
+	counts := make([]int, 3)
+	for i := 0; i < samples; i++ {
+		counts[m.ReadNext(g, 1)]++
+	}
+	for j, p := range []float64{0.25, 0.25, 0.5} {
+		assert.InDelta(t, p*samples, counts[j], 5*math.Sqrt(samples*p*(1-p)))
+	}
+
+	assert.Equal(t, []int{2, 2, 2}, m.Walk(2, 2))
+	assert.Panics(t, func() { m.Next(3) })
+	assert.Panics(t, func() { m.Walk(0, -1) })
+	assert.Panics(t, func() { m.Walk(3, 0) })
+	assert.Panics(t, func() { m.Walk(-1, 0) })
+}
+
+func TestNewMarkovSamplerPanics(t *testing.T) {
+	assert.Panics(t, func() { NewMarkovSampler([][]uint64{{1, 1}}) })
+	assert.Panics(t, func() { NewMarkovSampler([][]uint64{{1, 0}, {0, 0}}) })
+	assert.Panics(t, func() { NewMarkovSampler([][]uint64{{math.MaxUint64, 1}, {1, 1}}) })
+}
@@ -0,0 +1,35 @@
+package rng
+
+import (
+	"io"
+	mrand "math/rand"
+)
+
+// MathSource returns a math/rand.Source64 reading randomness from src. It
+// allows passing this package's sources to APIs expecting *math/rand.Rand,
+// e.g. testing/quick.Config.Rand. Calling Seed on the returned source panics.
+func MathSource(src io.Reader) mrand.Source64 {
+	return mathSource{src: src}
+}
+
+// MathRand returns a *math/rand.Rand reading randomness from the generator
+// source. Like any *math/rand.Rand it is not safe for concurrent use.
+func (g *Generator) MathRand() *mrand.Rand {
+	return mrand.New(MathSource(g.reader()))
+}
+
+type mathSource struct {
+	src io.Reader
+}
+
+func (s mathSource) Int63() int64 {
+	return int64(ReadUint64Bits(s.src, 63))
+}
+
+func (s mathSource) Uint64() uint64 {
+	return ReadUint64Bits(s.src, 64)
+}
+
+func (s mathSource) Seed(int64) {
+	panic("rng: MathSource can not be seeded, use NewDRBG for reproducible sources")
+}
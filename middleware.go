@@ -0,0 +1,177 @@
+package rng
+
+import (
+	"io"
+	"time"
+)
+
+// DrawCall is a single draw passing through a middleware chain.
+type DrawCall struct {
+	// Type names the draw function, e.g. "intn", "perm" or "sample".
+	Type string
+	// Params holds draw arguments by name, e.g. {"n": 49, "k": 6}.
+	Params map[string]int64
+	// Run performs the draw reading randomness from src and returns its
+	// outcome encoded as in DrawResult.Outcome.
+	Run func(src io.Reader) []int64
+}
+
+// DrawHandler executes draw calls reading randomness from src.
+type DrawHandler interface {
+	HandleDraw(src io.Reader, call *DrawCall) ([]int64, error)
+}
+
+// DrawHandlerFunc adapts an ordinary function to the DrawHandler interface.
+type DrawHandlerFunc func(src io.Reader, call *DrawCall) ([]int64, error)
+
+// HandleDraw calls f(src, call).
+func (f DrawHandlerFunc) HandleDraw(src io.Reader, call *DrawCall) ([]int64, error) {
+	return f(src, call)
+}
+
+// Middleware wraps a DrawHandler adding a cross-cutting concern such as
+// logging, metrics, audit, quotas or health tests, the way http middleware
+// wraps an http.Handler. A middleware may replace the source passed on, e.g.
+// to count or test consumed bytes, inspect the outcome or reject the draw by
+// returning an error without calling next.
+type Middleware func(next DrawHandler) DrawHandler
+
+// Chain is a Generator whose draws pass through a middleware chain. Draws are
+// made exactly as by the wrapped Generator, so for the same source outcomes
+// are identical.
+//
+// Chain is safe for concurrent use if the source and all middleware are.
+type Chain struct {
+	g       *Generator
+	handler DrawHandler
+}
+
+var _ Interface = (*Chain)(nil)
+
+// NewChain returns a Chain drawing from g through middleware mw. The first
+// middleware is the outermost one, it sees the call first and the outcome
+// last.
+func NewChain(g *Generator, mw ...Middleware) *Chain {
+	// middleware may wrap the source, the generator encoding is applied to
+	// whatever source reaches the draw
+	var h DrawHandler = DrawHandlerFunc(func(src io.Reader, call *DrawCall) ([]int64, error) {
+		return call.Run(EncodedSource(src, g.enc)), nil
+	})
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return &Chain{g: g, handler: h}
+}
+
+// Draw passes a call through the chain and returns its outcome or the error
+// returned by a middleware.
+func (c *Chain) Draw(call *DrawCall) ([]int64, error) {
+	return c.handler.HandleDraw(c.g.src, call)
+}
+
+// mustDraw is Draw for Interface methods, which have no error result. Like
+// source read errors, middleware errors panic.
+func (c *Chain) mustDraw(typ string, params map[string]int64, run func(src io.Reader) []int64) []int64 {
+	outcome, err := c.Draw(&DrawCall{Type: typ, Params: params, Run: run})
+	if err != nil {
+		panic(err)
+	}
+	return outcome
+}
+
+// Intn returns a non negative int in [0, n), see ReadIntn. It will panic if
+// a middleware rejects the draw.
+func (c *Chain) Intn(n int) int {
+	outcome := c.mustDraw("intn", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		return []int64{int64(ReadIntn(src, n))}
+	})
+	return int(outcome[0])
+}
+
+// Float64 returns a random number in [0.0,1.0), see ReadFloat64. The outcome
+// seen by middleware is the raw 53-bit integer.
+func (c *Chain) Float64() float64 {
+	outcome := c.mustDraw("float64", nil, func(src io.Reader) []int64 {
+		return []int64{int64(ReadUint64Bits(src, 53))}
+	})
+	return float64(outcome[0]) / float64(1<<53)
+}
+
+// Perm returns a random permutation of integers [0,n), see ReadPerm.
+func (c *Chain) Perm(n int) []int {
+	return int64sToInts(c.mustDraw("perm", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		return intsToInt64s(ReadPerm(src, n))
+	}))
+}
+
+// Sample returns random k integers from a range [0 n), see ReadSample.
+func (c *Chain) Sample(n int, k int) []int {
+	return int64sToInts(c.mustDraw("sample", map[string]int64{"n": int64(n), "k": int64(k)}, func(src io.Reader) []int64 {
+		return intsToInt64s(ReadSample(src, n, k))
+	}))
+}
+
+// Shuffle randomizes the order of n elements, see ReadShuffle. The outcome
+// seen by middleware lists swap indexes j for i = n-1 down to 1.
+func (c *Chain) Shuffle(n int, swap func(i, j int)) {
+	js := c.mustDraw("shuffle", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		var js []int64
+		ReadShuffle(src, n, func(i, j int) {
+			js = append(js, int64(j))
+		})
+		return js
+	})
+	for k, i := 0, n-1; i > 0; k, i = k+1, i-1 {
+		swap(i, int(js[k]))
+	}
+}
+
+// Audit returns middleware publishing a DrawResult of every successful draw,
+// the chain equivalent of PublishingGenerator. Publish errors are passed to
+// onError if not nil, the outcome is returned regardless.
+func Audit(pub Publisher, onError func(error)) Middleware {
+	return func(next DrawHandler) DrawHandler {
+		return DrawHandlerFunc(func(src io.Reader, call *DrawCall) ([]int64, error) {
+			var err error
+			d := runDraw(src, call.Type, call.Params, func(src io.Reader) []int64 {
+				var outcome []int64
+				outcome, err = next.HandleDraw(src, call)
+				return outcome
+			})
+			if err != nil {
+				return nil, err
+			}
+			if perr := pub.Publish(d); perr != nil && onError != nil {
+				onError(perr)
+			}
+			return d.Outcome, nil
+		})
+	}
+}
+
+// Observe returns middleware calling fn after every draw with the call, its
+// outcome or error, the number of source bytes consumed and the duration,
+// e.g. for logging or metrics.
+func Observe(fn func(call *DrawCall, outcome []int64, err error, bytes int64, elapsed time.Duration)) Middleware {
+	return func(next DrawHandler) DrawHandler {
+		return DrawHandlerFunc(func(src io.Reader, call *DrawCall) ([]int64, error) {
+			cr := &byteCounter{src: src}
+			start := time.Now()
+			outcome, err := next.HandleDraw(cr, call)
+			fn(call, outcome, err, cr.n, time.Since(start))
+			return outcome, err
+		})
+	}
+}
+
+// byteCounter counts bytes read from src.
+type byteCounter struct {
+	src io.Reader
+	n   int64
+}
+
+func (r *byteCounter) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	r.n += int64(n)
+	return n, err
+}
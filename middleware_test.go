@@ -0,0 +1,107 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next DrawHandler) DrawHandler {
+			return DrawHandlerFunc(func(src io.Reader, call *DrawCall) ([]int64, error) {
+				order = append(order, name+" "+call.Type)
+				outcome, err := next.HandleDraw(src, call)
+				order = append(order, name+" done")
+				return outcome, err
+			})
+		}
+	}
+
+	c := NewChain(New(NewDRBG([]byte("chain"))), trace("outer"), trace("inner"))
+	g := New(NewDRBG([]byte("chain")))
+
+	assert.Equal(t, g.Intn(100), c.Intn(100))
+	assert.Equal(t, []string{"outer intn", "inner intn", "inner done", "outer done"}, order)
+	assert.Equal(t, g.Float64(), c.Float64())
+	assert.Equal(t, g.Perm(10), c.Perm(10))
+	assert.Equal(t, g.Sample(100, 5), c.Sample(100, 5))
+
+	a, b := []int{0, 1, 2, 3, 4}, []int{0, 1, 2, 3, 4}
+	g.Shuffle(len(a), func(i, j int) { a[i], a[j] = a[j], a[i] })
+	c.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+	assert.Equal(t, a, b)
+
+}
+
+func TestChainReject(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	draws := 0
+	quota := func(next DrawHandler) DrawHandler {
+		return DrawHandlerFunc(func(src io.Reader, call *DrawCall) ([]int64, error) {
+			if draws == 2 {
+				return nil, errQuota
+			}
+			draws++
+			return next.HandleDraw(src, call)
+		})
+	}
+	c := NewChain(New(NewDRBG(nil)), quota)
+	c.Intn(10)
+	_, err := c.Draw(&DrawCall{Type: "intn", Run: func(src io.Reader) []int64 {
+		return []int64{int64(ReadIntn(src, 10))}
+	}})
+	require.NoError(t, err)
+
+	_, err = c.Draw(&DrawCall{Type: "intn", Run: func(src io.Reader) []int64 { return nil }})
+	assert.Equal(t, errQuota, err)
+	assert.PanicsWithValue(t, errQuota, func() { c.Perm(5) })
+}
+
+func TestAudit(t *testing.T) {
+	var published []*DrawResult
+	pub := PublisherFunc(func(d *DrawResult) error {
+		published = append(published, d)
+		return errors.New("bus down")
+	})
+	var errs []error
+	c := NewChain(New(NewDRBG([]byte("audit"))), Audit(pub, func(err error) { errs = append(errs, err) }))
+
+	s := c.Sample(49, 6)
+	require.Len(t, published, 1)
+	d := published[0]
+	assert.Equal(t, "sample", d.Type)
+	assert.Equal(t, map[string]int64{"n": 49, "k": 6}, d.Params)
+	assert.Equal(t, intsToInt64s(s), d.Outcome)
+	assert.Len(t, d.EntropyDigest, 32)
+	assert.Len(t, errs, 1)
+
+	// same outcome and digest as PublishingGenerator
+	var want *DrawResult
+	NewPublishing(New(NewDRBG([]byte("audit"))), PublisherFunc(func(d *DrawResult) error {
+		want = d
+		return nil
+	})).Sample(49, 6)
+	assert.Equal(t, want.Outcome, d.Outcome)
+	assert.Equal(t, want.EntropyDigest, d.EntropyDigest)
+}
+
+func TestObserve(t *testing.T) {
+	var calls []string
+	var consumed int64
+	c := NewChain(New(NewDRBG(nil)), Observe(func(call *DrawCall, outcome []int64, err error, bytes int64, elapsed time.Duration) {
+		calls = append(calls, call.Type)
+		consumed += bytes
+		assert.NoError(t, err)
+		assert.True(t, elapsed >= 0)
+	}))
+	c.Intn(1000)
+	c.Float64()
+	assert.Equal(t, []string{"intn", "float64"}, calls)
+	assert.True(t, consumed >= 2+7)
+}
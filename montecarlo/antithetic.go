@@ -0,0 +1,71 @@
+package montecarlo
+
+import (
+	"io"
+
+	"github.com/advbet/rng"
+)
+
+// AntitheticFloat64 returns an antithetic pair u, 1-u with u uniform in
+// [0.0,1.0) read from src. Both values are exact multiples of 2^-53, the
+// second is in (0.0,1.0].
+func AntitheticFloat64(src io.Reader) (float64, float64) {
+	u := rng.ReadFloat64(src)
+	return u, 1 - u
+}
+
+// Antithetic is a source running a simulation twice: once with bytes from
+// the underlying source and once, after Mirror, with the bitwise complement of
+// the same bytes. Uniforms derived from complemented bytes are reflected
+// (u becomes about 1-u), so outputs that are monotone in the uniforms, such
+// as inverse CDF draws, are negatively correlated and averaging the two runs
+// reduces variance. Draws using rejection may consume different amounts of
+// entropy in the two runs, mirrored reads past the recorded bytes come from
+// the underlying source.
+//
+// Antithetic is not safe for concurrent use.
+type Antithetic struct {
+	src    io.Reader
+	rec    []byte
+	pos    int
+	mirror bool
+}
+
+// NewAntithetic returns an Antithetic source reading from src.
+func NewAntithetic(src io.Reader) *Antithetic {
+	return &Antithetic{src: src}
+}
+
+// Read fills p with recorded source bytes in the primary run or complemented
+// recorded bytes in the mirrored run.
+func (a *Antithetic) Read(p []byte) (int, error) {
+	if !a.mirror {
+		n, err := io.ReadFull(a.src, p)
+		a.rec = append(a.rec, p[:n]...)
+		return n, err
+	}
+	n := copy(p, a.rec[a.pos:])
+	for i := range p[:n] {
+		p[i] = ^p[i]
+	}
+	a.pos += n
+	if n < len(p) {
+		m, err := io.ReadFull(a.src, p[n:])
+		return n + m, err
+	}
+	return n, nil
+}
+
+// Mirror starts the mirrored run replaying complemented bytes recorded since
+// the last call to Next.
+func (a *Antithetic) Mirror() {
+	a.mirror = true
+	a.pos = 0
+}
+
+// Next starts a new primary run, discarding recorded bytes.
+func (a *Antithetic) Next() {
+	a.mirror = false
+	a.rec = a.rec[:0]
+	a.pos = 0
+}
@@ -0,0 +1,56 @@
+package montecarlo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAntitheticFloat64(t *testing.T) {
+	u, v := AntitheticFloat64(bytes.NewBuffer([]byte{0, 0, 0, 0, 0, 0, 0x10, 0}))
+	assert.Equal(t, 0.5, u)
+	assert.Equal(t, 0.5, v)
+	u, v = AntitheticFloat64(bytes.NewBuffer(make([]byte, 8)))
+	assert.Equal(t, 0.0, u)
+	assert.Equal(t, 1.0, v)
+}
+
+func TestAntithetic(t *testing.T) {
+	a := NewAntithetic(bytes.NewBuffer([]byte{0x0f, 0xf0, 0x33}))
+	assert.Equal(t, []byte{0x0f, 0xf0}, read(a, 2))
+	a.Mirror()
+	assert.Equal(t, []byte{0xf0}, read(a, 1))
+	// past the recording bytes come from the source
+	assert.Equal(t, []byte{0x0f, 0x33}, read(a, 2))
+	a.Next()
+	assert.Empty(t, a.rec)
+
+	// antithetic runs reduce the variance of a monotone estimator
+	src := NewAntithetic(rng.NewDRBG([]byte("antithetic")))
+	const pairs = 2000
+	var plain, anti []float64
+	for i := 0; i < pairs; i++ {
+		src.Next()
+		x := rng.ReadFloat64(src)
+		src.Mirror()
+		y := rng.ReadFloat64(src)
+		plain = append(plain, x*x)
+		anti = append(anti, (x*x+y*y)/2)
+	}
+	assert.Less(t, variance(anti), variance(plain)/4)
+}
+
+func variance(xs []float64) float64 {
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	v := 0.0
+	for _, x := range xs {
+		v += (x - mean) * (x - mean)
+	}
+	return v / float64(len(xs))
+}
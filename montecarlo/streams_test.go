@@ -0,0 +1,59 @@
+package montecarlo
+
+import (
+	"io"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func read(r io.Reader, n int) []byte {
+	b := make([]byte, n)
+	_, _ = r.Read(b)
+	return b
+}
+
+func TestStreams(t *testing.T) {
+	s := NewStreams([]byte("seed"))
+	deck := s.Stream("deck")
+	assert.Equal(t, "deck", deck.Name())
+	assert.Same(t, deck, s.Stream("deck"))
+
+	first := read(deck, 40)
+	assert.Equal(t, int64(40), deck.Save())
+	// named streams are independent of each other and of creation order
+	assert.NotEqual(t, first, read(s.Stream("players"), 40))
+	assert.Equal(t, first, read(NewStreams([]byte("seed")).Stream("deck"), 40))
+
+	// rewind to any position, including inside an AES block
+	deck.Rewind(17)
+	assert.Equal(t, first[17:], read(deck, 23))
+	pos := deck.Save()
+	more := read(deck, 10)
+	deck.Rewind(pos)
+	assert.Equal(t, more, read(deck, 10))
+
+	s.RewindAll()
+	assert.Equal(t, first, read(deck, 40))
+	assert.Panics(t, func() { deck.Rewind(-1) })
+}
+
+func TestCommonRandomNumbers(t *testing.T) {
+	// two configurations differing only in payout see identical outcomes
+	s := NewStreams([]byte("crn"))
+	run := func(payout int) int {
+		g := rng.New(s.Stream("spins"))
+		total := 0
+		for i := 0; i < 100; i++ {
+			if g.Intn(37) == 0 {
+				total += payout
+			}
+		}
+		return total
+	}
+	a := run(35)
+	s.RewindAll()
+	b := run(36)
+	assert.Equal(t, a/35, b/36)
+}
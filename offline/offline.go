@@ -0,0 +1,185 @@
+// Package offline provides entropy pre-generated into files for air-gapped
+// draw ceremonies.
+//
+// Prefetch writes entropy read from a source, e.g. an HSM, to a file whose
+// SHA-256 is sealed ahead of time. On the offline machine Open returns a
+// Source consuming the file front to back. The position of the first unused
+// byte is persisted in a sidecar file after every read, so a restarted
+// ceremony never reuses entropy, and draws fail with ErrExhausted rather than
+// wrapping around when the file is used up. Seal and Unseal protect entropy
+// files and DRBG state snapshots in storage and transport with
+// XChaCha20-Poly1305.
+package offline
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PositionSuffix is appended to the entropy file path to name the file
+// holding the read position.
+const PositionSuffix = ".pos"
+
+var (
+	// ErrExhausted is returned by Source.Read when fewer bytes remain than
+	// requested. No bytes are consumed by a failed read.
+	ErrExhausted = errors.New("offline: entropy file exhausted")
+	// ErrClosed is returned when reading from a closed Source.
+	ErrClosed = errors.New("offline: source is closed")
+)
+
+// Prefetch copies n bytes of entropy from src to w and returns their
+// SHA-256, the seal of the entropy file.
+func Prefetch(w io.Writer, src io.Reader, n int64) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("offline: negative size %d", n)
+	}
+	h := sha256.New()
+	if _, err := io.CopyN(io.MultiWriter(w, h), src, n); err != nil {
+		return nil, fmt.Errorf("offline: prefetching entropy: %w", err)
+	}
+	return h.Sum(nil), nil
+}
+
+// Source is an io.Reader consuming entropy from a file. It is safe for
+// concurrent use.
+type Source struct {
+	mu      sync.Mutex
+	f       *os.File
+	posPath string
+	size    int64
+	pos     int64
+}
+
+// Open opens an entropy file written by Prefetch, resuming at the position
+// persisted by an earlier Source or at the start of the file.
+func Open(path string) (*Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	s := &Source{f: f, posPath: path + PositionSuffix, size: fi.Size()}
+	b, err := os.ReadFile(s.posPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		f.Close()
+		return nil, err
+	default:
+		s.pos, err = strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+		if err != nil || s.pos < 0 || s.pos > s.size {
+			f.Close()
+			return nil, fmt.Errorf("offline: invalid position file %s", s.posPath)
+		}
+	}
+	return s, nil
+}
+
+// Read fills p with the next len(p) unused bytes of the file. The new
+// position is persisted before the bytes are returned. It returns
+// ErrExhausted if fewer than len(p) bytes remain.
+func (s *Source) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return 0, ErrClosed
+	}
+	if int64(len(p)) > s.size-s.pos {
+		return 0, fmt.Errorf("%w: %d bytes requested, %d remain", ErrExhausted, len(p), s.size-s.pos)
+	}
+	if _, err := s.f.ReadAt(p, s.pos); err != nil {
+		return 0, err
+	}
+	if err := s.persist(s.pos + int64(len(p))); err != nil {
+		return 0, err
+	}
+	s.pos += int64(len(p))
+	return len(p), nil
+}
+
+// persist atomically replaces the position file. The temporary file is synced
+// before the rename and the directory after it, so a crash can neither leave
+// an empty position file nor roll the position back.
+func (s *Source) persist(pos int64) error {
+	tmp := s.posPath + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strconv.FormatInt(pos, 10) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.posPath); err != nil {
+		return err
+	}
+	dir, err := os.Open(filepath.Dir(s.posPath))
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
+
+// Position returns the offset of the first unused byte.
+func (s *Source) Position() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pos
+}
+
+// Remaining returns the number of unused bytes.
+func (s *Source) Remaining() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size - s.pos
+}
+
+// Digest returns SHA-256 of the whole file, to be compared with the seal
+// returned by Prefetch before the ceremony starts.
+func (s *Source) Digest() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil, ErrClosed
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(s.f, 0, s.size)); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Close closes the entropy file.
+func (s *Source) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return ErrClosed
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
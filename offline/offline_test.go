@@ -0,0 +1,105 @@
+package offline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prefetch(t *testing.T, n int64) (string, []byte) {
+	path := filepath.Join(t.TempDir(), "entropy.bin")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	digest, err := Prefetch(f, rng.NewDRBG([]byte("seed")), n)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return path, digest
+}
+
+func TestPrefetch(t *testing.T) {
+	var buf bytes.Buffer
+	digest, err := Prefetch(&buf, rng.NewDRBG([]byte("seed")), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 100, buf.Len())
+	sum := sha256.Sum256(buf.Bytes())
+	assert.Equal(t, sum[:], digest)
+
+	_, err = Prefetch(&buf, bytes.NewReader(make([]byte, 10)), 11)
+	assert.Error(t, err)
+	_, err = Prefetch(&buf, bytes.NewReader(nil), -1)
+	assert.Error(t, err)
+}
+
+func TestSource(t *testing.T) {
+	path, digest := prefetch(t, 64)
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	d, err := s.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest, d)
+
+	b := make([]byte, 10)
+	n, err := s.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.Equal(t, want[:10], b)
+	assert.Equal(t, int64(10), s.Position())
+	assert.Equal(t, int64(54), s.Remaining())
+	require.NoError(t, s.Close())
+	_, err = s.Read(b)
+	assert.True(t, errors.Is(err, ErrClosed))
+
+	// reopening resumes at the persisted position
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, int64(10), s.Position())
+	n, err = s.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, want[10:20], b[:n])
+
+	// a read past the end consumes nothing
+	_, err = s.Read(make([]byte, 45))
+	assert.True(t, errors.Is(err, ErrExhausted))
+	assert.Equal(t, int64(44), s.Remaining())
+	n, err = s.Read(make([]byte, 44))
+	assert.NoError(t, err)
+	assert.Equal(t, 44, n)
+	_, err = s.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, ErrExhausted))
+}
+
+func TestSourceDraws(t *testing.T) {
+	path, _ := prefetch(t, 16)
+	s, err := Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	g := rng.New(s)
+	g.Uint64Bits(64)
+	g.Uint64Bits(64)
+	assert.Equal(t, int64(0), s.Remaining())
+	assert.Panics(t, func() { g.Uint64Bits(64) })
+}
+
+func TestOpenInvalidPosition(t *testing.T) {
+	path, _ := prefetch(t, 16)
+	require.NoError(t, os.WriteFile(path+PositionSuffix, []byte("17\n"), 0o600))
+	_, err := Open(path)
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(path+PositionSuffix, []byte("x"), 0o600))
+	_, err = Open(path)
+	assert.Error(t, err)
+	_, err = Open(filepath.Join(t.TempDir(), "missing.bin"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/advbet/rng"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Kinds of sealed materials.
+const (
+	KindEntropy   = "entropy"
+	KindDRBGState = "drbg-state"
+)
+
+// sealMagic starts every sealed file, the last byte is the format version.
+const sealMagic = "RNGSEAL\x01"
+
+// sealChunk is the plaintext size of a sealed chunk. Every chunk is
+// authenticated on its own, so large entropy files are sealed and unsealed
+// without holding them in memory.
+const sealChunk = 1 << 16
+
+var (
+	// ErrUnknownKey is returned by Unseal if none of the given keys has the
+	// key ID recorded in the sealed file.
+	ErrUnknownKey = errors.New("offline: unknown sealing key")
+	// ErrCorrupt is returned by Unseal for sealed files that fail
+	// authentication: modified, truncated or sealed with another key.
+	ErrCorrupt = errors.New("offline: sealed file is corrupt")
+)
+
+// Key is a XChaCha20-Poly1305 sealing key.
+type Key struct {
+	// ID names the key in sealed files so the holder of the matching key
+	// can be found, e.g. "ceremony-2026-10/vault-a".
+	ID string
+	// Secret is the chacha20poly1305.KeySize byte key.
+	Secret []byte
+}
+
+func (k Key) aead() (cipher.AEAD, error) {
+	if k.ID == "" || len(k.ID) > 255 {
+		return nil, errors.New("offline: key ID must be 1 to 255 bytes")
+	}
+	return chacha20poly1305.NewX(k.Secret)
+}
+
+// Seal encrypts and authenticates materials of a given kind read from r and
+// writes the sealed file to w. The file header records the format version,
+// kind and key ID in plaintext, all of it is authenticated. Chunks are
+// sealed with XChaCha20-Poly1305 under a random nonce prefix and the chunk
+// number, the final chunk is marked so truncation is detected.
+func Seal(w io.Writer, r io.Reader, kind string, key Key) error {
+	aead, err := key.aead()
+	if err != nil {
+		return err
+	}
+	if kind == "" || len(kind) > 255 {
+		return errors.New("offline: kind must be 1 to 255 bytes")
+	}
+	header := sealHeader(kind, key.ID)
+	prefix := make([]byte, chacha20poly1305.NonceSizeX-8)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return err
+	}
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	br := bufio.NewReaderSize(r, sealChunk)
+	buf := make([]byte, sealChunk)
+	for i := uint64(0); ; i++ {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		final := n < sealChunk
+		if !final {
+			_, err := br.Peek(1)
+			final = err == io.EOF
+		}
+		ct := aead.Seal(nil, chunkNonce(prefix, i), buf[:n], chunkAD(header, i, final))
+		if _, err := w.Write(ct); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// Unseal verifies and decrypts a sealed file of a given kind read from r with
+// the key recorded in its header and writes the plaintext to w. Chunks are
+// written as soon as they are authenticated, on error the output must be
+// discarded. It returns ErrUnknownKey if no key matches and ErrCorrupt if
+// authentication fails.
+func Unseal(w io.Writer, r io.Reader, kind string, keys ...Key) error {
+	br := bufio.NewReaderSize(r, sealChunk+chacha20poly1305.Overhead)
+	header, fileKind, keyID, err := readSealHeader(br)
+	if err != nil {
+		return err
+	}
+	if fileKind != kind {
+		return fmt.Errorf("offline: sealed file holds %q, not %q", fileKind, kind)
+	}
+	var aead cipher.AEAD
+	for _, k := range keys {
+		if k.ID == keyID {
+			if aead, err = k.aead(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if aead == nil {
+		return fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	prefix := header[len(header)-(chacha20poly1305.NonceSizeX-8):]
+
+	buf := make([]byte, sealChunk+chacha20poly1305.Overhead)
+	for i := uint64(0); ; i++ {
+		n, err := io.ReadFull(br, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		final := n < len(buf)
+		if !final {
+			_, err := br.Peek(1)
+			final = err == io.EOF
+		}
+		pt, err := aead.Open(nil, chunkNonce(prefix, i), buf[:n], chunkAD(header, i, final))
+		if err != nil {
+			return ErrCorrupt
+		}
+		if _, err := w.Write(pt); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// SealedKeyID returns the kind and key ID recorded in a sealed file header
+// without decrypting it.
+func SealedKeyID(r io.Reader) (kind, keyID string, err error) {
+	_, kind, keyID, err = readSealHeader(bufio.NewReader(r))
+	return kind, keyID, err
+}
+
+// SealDRBG writes a sealed state snapshot of a DRBG, see
+// rng.DRBG.MarshalBinary.
+func SealDRBG(w io.Writer, d *rng.DRBG, key Key) error {
+	state, err := d.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return Seal(w, bytes.NewReader(state), KindDRBGState, key)
+}
+
+// UnsealDRBG restores a DRBG from a sealed state snapshot.
+func UnsealDRBG(r io.Reader, keys ...Key) (*rng.DRBG, error) {
+	var state bytes.Buffer
+	if err := Unseal(&state, r, KindDRBGState, keys...); err != nil {
+		return nil, err
+	}
+	d := &rng.DRBG{}
+	if err := d.UnmarshalBinary(state.Bytes()); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// sealHeader returns the header up to the nonce prefix: magic, then kind and
+// key ID each preceded by its length byte.
+func sealHeader(kind, keyID string) []byte {
+	h := []byte(sealMagic)
+	h = append(h, byte(len(kind)))
+	h = append(h, kind...)
+	h = append(h, byte(len(keyID)))
+	return append(h, keyID...)
+}
+
+func readSealHeader(r io.Reader) (header []byte, kind, keyID string, err error) {
+	magic := make([]byte, len(sealMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != sealMagic {
+		return nil, "", "", fmt.Errorf("%w: not a sealed file", ErrCorrupt)
+	}
+	readString := func() (string, error) {
+		var n [1]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return "", err
+		}
+		b := make([]byte, n[0])
+		_, err := io.ReadFull(r, b)
+		return string(b), err
+	}
+	if kind, err = readString(); err != nil {
+		return nil, "", "", fmt.Errorf("%w: truncated header", ErrCorrupt)
+	}
+	if keyID, err = readString(); err != nil {
+		return nil, "", "", fmt.Errorf("%w: truncated header", ErrCorrupt)
+	}
+	header = sealHeader(kind, keyID)
+	prefix := make([]byte, chacha20poly1305.NonceSizeX-8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, "", "", fmt.Errorf("%w: truncated header", ErrCorrupt)
+	}
+	return append(header, prefix...), kind, keyID, nil
+}
+
+func chunkNonce(prefix []byte, i uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(nil), prefix...), i)
+}
+
+// chunkAD binds a chunk to the file header, its position and whether it is
+// the last one.
+func chunkAD(header []byte, i uint64, final bool) []byte {
+	ad := binary.BigEndian.AppendUint64(append([]byte(nil), header...), i)
+	if final {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
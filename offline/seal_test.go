@@ -0,0 +1,91 @@
+package offline
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = Key{ID: "vault-a", Secret: bytes.Repeat([]byte{7}, 32)}
+
+func TestSeal(t *testing.T) {
+	for _, size := range []int{0, 1, sealChunk - 1, sealChunk, sealChunk + 1, 3 * sealChunk} {
+		plain := make([]byte, size)
+		rng.NewDRBG([]byte("seed")).Read(plain)
+
+		var sealed bytes.Buffer
+		require.NoError(t, Seal(&sealed, bytes.NewReader(plain), KindEntropy, testKey))
+		if size >= 32 {
+			assert.False(t, bytes.Contains(sealed.Bytes(), plain[:32]))
+		}
+
+		kind, keyID, err := SealedKeyID(bytes.NewReader(sealed.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, KindEntropy, kind)
+		assert.Equal(t, "vault-a", keyID)
+
+		var out bytes.Buffer
+		other := Key{ID: "vault-b", Secret: make([]byte, 32)}
+		require.NoError(t, Unseal(&out, bytes.NewReader(sealed.Bytes()), KindEntropy, other, testKey), "size %d", size)
+		assert.True(t, bytes.Equal(plain, out.Bytes()), "size %d", size)
+
+		// truncation at and between chunk boundaries
+		for _, cut := range []int{sealed.Len() - 1, sealed.Len() - (sealChunk + 16)} {
+			if cut <= 0 {
+				continue
+			}
+			err := Unseal(&bytes.Buffer{}, bytes.NewReader(sealed.Bytes()[:cut]), KindEntropy, testKey)
+			assert.True(t, errors.Is(err, ErrCorrupt), "size %d cut %d", size, cut)
+		}
+	}
+}
+
+func TestUnsealErrors(t *testing.T) {
+	var sealed bytes.Buffer
+	require.NoError(t, Seal(&sealed, bytes.NewReader([]byte("entropy")), KindEntropy, testKey))
+	b := sealed.Bytes()
+
+	unseal := func(b []byte, kind string, keys ...Key) error {
+		return Unseal(&bytes.Buffer{}, bytes.NewReader(b), kind, keys...)
+	}
+	assert.NoError(t, unseal(b, KindEntropy, testKey))
+	assert.True(t, errors.Is(unseal(b, KindEntropy, Key{ID: "other", Secret: testKey.Secret}), ErrUnknownKey))
+	assert.True(t, errors.Is(unseal(b, KindEntropy, Key{ID: "vault-a", Secret: make([]byte, 32)}), ErrCorrupt))
+	assert.Error(t, unseal(b, KindDRBGState, testKey))
+	assert.True(t, errors.Is(unseal(b[:10], KindEntropy, testKey), ErrCorrupt))
+	assert.True(t, errors.Is(unseal([]byte("plain entropy"), KindEntropy, testKey), ErrCorrupt))
+
+	// header fields are authenticated
+	for _, i := range []int{len(sealMagic) + 1, len(b) - 1} {
+		c := append([]byte(nil), b...)
+		c[i] ^= 1
+		assert.Error(t, unseal(c, KindEntropy, testKey), "byte %d", i)
+	}
+
+	assert.Error(t, Seal(&bytes.Buffer{}, bytes.NewReader(nil), KindEntropy, Key{ID: "k", Secret: make([]byte, 16)}))
+	assert.Error(t, Seal(&bytes.Buffer{}, bytes.NewReader(nil), KindEntropy, Key{Secret: make([]byte, 32)}))
+	assert.Error(t, Seal(&bytes.Buffer{}, bytes.NewReader(nil), "", testKey))
+}
+
+func TestSealDRBG(t *testing.T) {
+	d := rng.NewDRBG([]byte("seed"))
+	d.Read(make([]byte, 21))
+
+	var sealed bytes.Buffer
+	require.NoError(t, SealDRBG(&sealed, d, testKey))
+	r, err := UnsealDRBG(bytes.NewReader(sealed.Bytes()), testKey)
+	require.NoError(t, err)
+
+	want := make([]byte, 50)
+	got := make([]byte, 50)
+	d.Read(want)
+	r.Read(got)
+	assert.Equal(t, want, got)
+
+	_, err = UnsealDRBG(bytes.NewReader(sealed.Bytes()))
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+}
@@ -0,0 +1,39 @@
+package rng
+
+import (
+	"io"
+	"sort"
+)
+
+// OrderByRandomKey returns a copy of items in random order, see
+// ReadOrderByRandomKey.
+func OrderByRandomKey[T any](items []T) []T {
+	return ReadOrderByRandomKey(defaultSource(), items)
+}
+
+// ReadOrderByRandomKey returns a copy of items in random order reading
+// randomness from a given source. Each item is assigned a random uint64 key in
+// input order and items are sorted by key with a stable sort, so equal keys
+// (probability about n²/2^65) keep their input order.
+//
+// Unlike Shuffle the order is defined by independent per item keys: with a
+// seeded source (e.g. DRBG) two datasets of the same length get matching keys
+// position by position, and keys compose with other orderings such as the
+// exponential keys of WeightedPerm.
+func ReadOrderByRandomKey[T any](src io.Reader, items []T) []T {
+	type keyed struct {
+		key  uint64
+		item T
+	}
+	ks := make([]keyed, len(items))
+	for i, it := range items {
+		ks[i] = keyed{key: ReadUint64Bits(src, 64), item: it}
+	}
+	sort.SliceStable(ks, func(i, j int) bool { return ks[i].key < ks[j].key })
+
+	out := make([]T, len(ks))
+	for i, k := range ks {
+		out[i] = k.item
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package rng
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderByRandomKey(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	a := ReadOrderByRandomKey(NewDRBG([]byte("join")), items)
+	b := ReadOrderByRandomKey(NewDRBG([]byte("join")), []int{0, 1, 2, 3, 4})
+	assert.ElementsMatch(t, items, a)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, items, "input is not modified")
+	// same seed and length orders positions identically
+	for i, j := range b {
+		assert.Equal(t, items[j], a[i])
+	}
+
+	// equal keys keep input order
+	zeros := bytes.NewBuffer(make([]byte, 8*len(items)))
+	assert.Equal(t, items, ReadOrderByRandomKey(zeros, items))
+
+	assert.Empty(t, ReadOrderByRandomKey(NewDRBG(nil), []int(nil)))
+
+	g := NewDRBG([]byte("uniform"))
+	counts := make(map[[3]int]int)
+	for i := 0; i < 6000; i++ {
+		p := ReadOrderByRandomKey(g, []int{0, 1, 2})
+		counts[[3]int{p[0], p[1], p[2]}]++
+	}
+	assert.Len(t, counts, 6)
+	for _, c := range counts {
+		assert.InDelta(t, 1000, c, 150)
+	}
+}
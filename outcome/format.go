@@ -0,0 +1,168 @@
+package outcome
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax is returned when parsing text that is not in canonical form.
+var ErrSyntax = errors.New("outcome: text is not in canonical form")
+
+// Formatter converts draw outcomes, e.g. rng.DrawResult.Outcome, to canonical
+// text and back. Canonical text is plain ASCII independent of locale, numbers
+// have no grouping separators and ordering is numeric, so audit records
+// rendered by different services compare byte-identically. Parse only accepts
+// canonical text, Format(Parse(s)) == s for every s it accepts.
+type Formatter interface {
+	Format(outcome []int64) (string, error)
+	Parse(s string) ([]int64, error)
+}
+
+var (
+	_ Formatter = Numbers{}
+	_ Formatter = Cards{}
+	_ Formatter = Roulette{}
+)
+
+// Numbers formats lottery style numbers as decimals joined by "-", e.g.
+// "03-07-12-25-33-41".
+type Numbers struct {
+	// Width zero pads numbers to at least Width digits.
+	Width int
+	// Sorted formats numbers in ascending order regardless of draw order.
+	// Parse rejects unsorted text.
+	Sorted bool
+}
+
+// Format returns canonical text of non negative numbers.
+func (f Numbers) Format(outcome []int64) (string, error) {
+	nums := append([]int64(nil), outcome...)
+	if f.Sorted {
+		sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	}
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		if n < 0 {
+			return "", fmt.Errorf("outcome: negative number %d", n)
+		}
+		parts[i] = fmt.Sprintf("%0*d", f.Width, n)
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// Parse returns numbers of canonical text.
+func (f Numbers) Parse(s string) ([]int64, error) {
+	if s == "" {
+		return []int64{}, nil
+	}
+	parts := strings.Split(s, "-")
+	nums := make([]int64, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || fmt.Sprintf("%0*d", f.Width, n) != p {
+			return nil, fmt.Errorf("%w: %q", ErrSyntax, p)
+		}
+		if f.Sorted && i > 0 && n < nums[i-1] {
+			return nil, fmt.Errorf("%w: numbers are not sorted", ErrSyntax)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+const (
+	cardRanks = "A23456789TJQK"
+	cardSuits = "CDHS"
+)
+
+// Cards formats card indexes of a 52 card deck as rank and suit letters
+// joined by spaces in draw order, e.g. "AS TD 2H". Card i has rank
+// "A23456789TJQK"[i%13] and suit "CDHS"[i/13], so index 0 is the ace of clubs
+// and 51 the king of spades.
+type Cards struct{}
+
+// Format returns canonical text of card indexes in [0, 52).
+func (Cards) Format(outcome []int64) (string, error) {
+	parts := make([]string, len(outcome))
+	for i, c := range outcome {
+		if c < 0 || c >= 52 {
+			return "", fmt.Errorf("outcome: card index %d out of range [0, 52)", c)
+		}
+		parts[i] = string([]byte{cardRanks[c%13], cardSuits[c/13]})
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// Parse returns card indexes of canonical text.
+func (Cards) Parse(s string) ([]int64, error) {
+	if s == "" {
+		return []int64{}, nil
+	}
+	parts := strings.Split(s, " ")
+	cards := make([]int64, len(parts))
+	for i, p := range parts {
+		var rank, suit int
+		if len(p) == 2 {
+			rank = strings.IndexByte(cardRanks, p[0])
+			suit = strings.IndexByte(cardSuits, p[1])
+		}
+		if len(p) != 2 || rank < 0 || suit < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrSyntax, p)
+		}
+		cards[i] = int64(suit*13 + rank)
+	}
+	return cards, nil
+}
+
+// Roulette formats roulette pockets as decimals joined by ",", e.g.
+// "17,0,32". European wheels have pockets 0 to 36, American wheels also have
+// "00" stored as index 37.
+type Roulette struct {
+	American bool
+}
+
+func (f Roulette) pockets() int64 {
+	if f.American {
+		return 38
+	}
+	return 37
+}
+
+// Format returns canonical text of pocket indexes.
+func (f Roulette) Format(outcome []int64) (string, error) {
+	parts := make([]string, len(outcome))
+	for i, p := range outcome {
+		if p < 0 || p >= f.pockets() {
+			return "", fmt.Errorf("outcome: pocket %d out of range [0, %d)", p, f.pockets())
+		}
+		if p == 37 {
+			parts[i] = "00"
+		} else {
+			parts[i] = strconv.FormatInt(p, 10)
+		}
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Parse returns pocket indexes of canonical text.
+func (f Roulette) Parse(s string) ([]int64, error) {
+	if s == "" {
+		return []int64{}, nil
+	}
+	parts := strings.Split(s, ",")
+	pockets := make([]int64, len(parts))
+	for i, p := range parts {
+		switch n, err := strconv.ParseInt(p, 10, 64); {
+		case p == "00" && f.American:
+			pockets[i] = 37
+		case err != nil || n < 0 || n >= 37 || strconv.FormatInt(n, 10) != p:
+			return nil, fmt.Errorf("%w: %q", ErrSyntax, p)
+		default:
+			pockets[i] = n
+		}
+	}
+	return pockets, nil
+}
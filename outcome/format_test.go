@@ -0,0 +1,91 @@
+package outcome
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumbers(t *testing.T) {
+	f := Numbers{Width: 2, Sorted: true}
+	s, err := f.Format([]int64{41, 7, 12, 3, 33, 25})
+	require.NoError(t, err)
+	assert.Equal(t, "03-07-12-25-33-41", s)
+
+	s, err = Numbers{}.Format([]int64{1000000, 5})
+	require.NoError(t, err)
+	assert.Equal(t, "1000000-5", s)
+
+	_, err = f.Format([]int64{-1})
+	assert.Error(t, err)
+
+	for _, bad := range []string{"3-07", "07-03", "+7", "07--12", "1,000", "٣"} {
+		_, err := f.Parse(bad)
+		assert.True(t, errors.Is(err, ErrSyntax), bad)
+	}
+}
+
+func TestCards(t *testing.T) {
+	s, err := Cards{}.Format([]int64{51, 0, 22, 9})
+	require.NoError(t, err)
+	assert.Equal(t, "KS AC TD TC", s)
+
+	_, err = Cards{}.Format([]int64{52})
+	assert.Error(t, err)
+
+	for _, bad := range []string{"as", "10S", "AS  KD", "AX", "ZS"} {
+		_, err := Cards{}.Parse(bad)
+		assert.True(t, errors.Is(err, ErrSyntax), bad)
+	}
+}
+
+func TestRoulette(t *testing.T) {
+	s, err := Roulette{American: true}.Format([]int64{17, 0, 37})
+	require.NoError(t, err)
+	assert.Equal(t, "17,0,00", s)
+
+	_, err = Roulette{}.Format([]int64{37})
+	assert.Error(t, err)
+
+	for _, bad := range []string{"00", "07", "37", "-1", "1, 2", " 1"} {
+		_, err := Roulette{}.Parse(bad)
+		assert.True(t, errors.Is(err, ErrSyntax), bad)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	g := rng.New(rng.NewDRBG([]byte("round trip")))
+	for name, test := range map[string]struct {
+		f       Formatter
+		outcome []int64
+	}{
+		"lottery":  {Numbers{Width: 2, Sorted: true}, toInt64s(g.Sample(49, 6))},
+		"numbers":  {Numbers{}, toInt64s(g.Perm(20))},
+		"cards":    {Cards{}, toInt64s(g.Perm(52))},
+		"european": {Roulette{}, toInt64s([]int{g.Intn(37), g.Intn(37), g.Intn(37)})},
+		"american": {Roulette{American: true}, []int64{37, 0, int64(g.Intn(38))}},
+		"empty":    {Cards{}, []int64{}},
+	} {
+		s, err := test.f.Format(test.outcome)
+		require.NoError(t, err, name)
+		parsed, err := test.f.Parse(s)
+		require.NoError(t, err, name)
+		again, err := test.f.Format(parsed)
+		require.NoError(t, err, name)
+		assert.Equal(t, s, again, name)
+		if _, ok := test.f.(Numbers); !ok || !test.f.(Numbers).Sorted {
+			assert.Equal(t, test.outcome, parsed, name)
+		}
+	}
+}
+
+func toInt64s(s []int) []int64 {
+	out := make([]int64, len(s))
+	for i, v := range s {
+		out[i] = int64(v)
+	}
+	return out
+}
@@ -0,0 +1,266 @@
+// Package outcome maps declarative outcome spaces to draws, so game configs
+// rather than code changes define new draw types.
+//
+// A Space is a uniform integer range, a weighted table of labelled entries or
+// a composite of other spaces, optionally drawn repeatedly. Spaces are usually
+// loaded from JSON (see Load):
+//
+//	{
+//	  "name": "bonus round",
+//	  "type": "composite",
+//	  "parts": [
+//	    {"name": "dice", "type": "range", "min": 1, "max": 6, "count": 2},
+//	    {"name": "prize", "type": "table", "entries": [
+//	      {"label": "none", "weight": 90},
+//	      {"label": "cash", "value": 100, "weight": 9},
+//	      {"label": "jackpot", "value": 10000, "weight": 1}
+//	    ]}
+//	  ]
+//	}
+//
+// Draw validates a space and draws it from an rng.Interface, returning a tree
+// of results mirroring the space.
+package outcome
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/advbet/rng"
+)
+
+// Supported Space.Type values.
+const (
+	// TypeRange draws a uniform integer in [Min, Max].
+	TypeRange = "range"
+	// TypeTable draws an entry with probability proportional to its weight.
+	TypeTable = "table"
+	// TypeComposite draws all parts in declaration order.
+	TypeComposite = "composite"
+)
+
+// Limits enforced by Validate so configs can not request unbounded work.
+const (
+	MaxDepth = 8
+	MaxCount = 1 << 16
+	// MaxDraws bounds the total number of range and table draws of a
+	// space, counts of nested spaces multiply.
+	MaxDraws = 1 << 20
+)
+
+// Space is an outcome space.
+type Space struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+	// Min and Max are the inclusive bounds of a range.
+	Min int64 `json:"min,omitempty"`
+	Max int64 `json:"max,omitempty"`
+	// Entries are the rows of a table.
+	Entries []Entry `json:"entries,omitempty"`
+	// Parts are the spaces of a composite.
+	Parts []Space `json:"parts,omitempty"`
+	// Count is the number of independent draws of the space, 0 means 1.
+	// Results of repeated draws are returned as parts.
+	Count int `json:"count,omitempty"`
+}
+
+// Entry is a row of a weighted table.
+type Entry struct {
+	Label string `json:"label"`
+	Value int64  `json:"value,omitempty"`
+	// Weight is the relative probability of the entry. Zero weight entries
+	// are never drawn.
+	Weight int `json:"weight"`
+}
+
+// Result is a drawn outcome.
+type Result struct {
+	Name string `json:"name,omitempty"`
+	// Value is the drawn number of a range, the value of a drawn table entry
+	// or the sum of part values of composite and repeated draws.
+	Value int64 `json:"value"`
+	// Label is the label of a drawn table entry.
+	Label string `json:"label,omitempty"`
+	// Index is the index of a drawn table entry.
+	Index int `json:"index,omitempty"`
+	// Parts hold results of composite parts or repeated draws.
+	Parts []Result `json:"parts,omitempty"`
+}
+
+// Load decodes a JSON encoded space and validates it. Unknown fields are
+// rejected.
+func Load(r io.Reader) (*Space, error) {
+	var s Space
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("outcome: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks that the space can be drawn: types are known, ranges are
+// not empty and no wider than math.MaxInt, tables have a positive total weight
+// below math.MaxInt32, composites have parts, result values can not overflow
+// int64 and limits are respected.
+func (s *Space) Validate() error {
+	_, err := s.validate(s.label(""), 1)
+	return err
+}
+
+// bounds are the least and greatest result value of a space and the number of
+// range and table draws it makes.
+type bounds struct {
+	lo, hi int64
+	draws  int64
+}
+
+func (s *Space) validate(path string, depth int) (bounds, error) {
+	if depth > MaxDepth {
+		return bounds{}, fmt.Errorf("outcome: %s: nested deeper than %d", path, MaxDepth)
+	}
+	if s.Count < 0 || s.Count > MaxCount {
+		return bounds{}, fmt.Errorf("outcome: %s: count out of range [0, %d]", path, MaxCount)
+	}
+	var b bounds
+	switch s.Type {
+	case TypeRange:
+		// width is computed modulo 2^64 so any pair of int64 bounds works
+		if s.Min > s.Max || uint64(s.Max)-uint64(s.Min) >= math.MaxInt {
+			return bounds{}, fmt.Errorf("outcome: %s: invalid range [%d, %d]", path, s.Min, s.Max)
+		}
+		b = bounds{lo: s.Min, hi: s.Max, draws: 1}
+	case TypeTable:
+		if _, err := s.total(); err != nil {
+			return bounds{}, fmt.Errorf("outcome: %s: %w", path, err)
+		}
+		b = bounds{lo: math.MaxInt64, hi: math.MinInt64, draws: 1}
+		for _, e := range s.Entries {
+			if e.Weight > 0 {
+				b.lo, b.hi = min(b.lo, e.Value), max(b.hi, e.Value)
+			}
+		}
+	case TypeComposite:
+		if len(s.Parts) == 0 {
+			return bounds{}, fmt.Errorf("outcome: %s: composite has no parts", path)
+		}
+		for i := range s.Parts {
+			p := &s.Parts[i]
+			pb, err := p.validate(path+"/"+p.label(strconv.Itoa(i)), depth+1)
+			if err != nil {
+				return bounds{}, err
+			}
+			var ok1, ok2 bool
+			b.lo, ok1 = addInt64(b.lo, pb.lo)
+			b.hi, ok2 = addInt64(b.hi, pb.hi)
+			if !ok1 || !ok2 {
+				return bounds{}, fmt.Errorf("outcome: %s: sum of values overflows int64", path)
+			}
+			if b.draws += pb.draws; b.draws > MaxDraws {
+				return bounds{}, fmt.Errorf("outcome: %s: more than %d draws", path, MaxDraws)
+			}
+		}
+	default:
+		return bounds{}, fmt.Errorf("outcome: %s: unknown type %q", path, s.Type)
+	}
+	if n := int64(s.Count); n > 1 {
+		// draws are at most MaxDraws, so draws*n can not overflow
+		if b.lo < math.MinInt64/n || b.hi > math.MaxInt64/n {
+			return bounds{}, fmt.Errorf("outcome: %s: sum of values overflows int64", path)
+		}
+		b.lo, b.hi, b.draws = b.lo*n, b.hi*n, b.draws*n
+		if b.draws > MaxDraws {
+			return bounds{}, fmt.Errorf("outcome: %s: more than %d draws", path, MaxDraws)
+		}
+	}
+	return b, nil
+}
+
+// addInt64 returns a + b and whether the sum did not overflow.
+func addInt64(a, b int64) (int64, bool) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// total returns the sum of table weights.
+func (s *Space) total() (int, error) {
+	total := 0
+	for _, e := range s.Entries {
+		if e.Weight < 0 || total > math.MaxInt32-e.Weight {
+			return 0, fmt.Errorf("invalid weight of entry %q", e.Label)
+		}
+		total += e.Weight
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("table has no entries with positive weight")
+	}
+	return total, nil
+}
+
+// label returns the name of the space for error messages.
+func (s *Space) label(def string) string {
+	if s.Name != "" {
+		return strconv.Quote(s.Name)
+	}
+	if def == "" {
+		return "space"
+	}
+	return def
+}
+
+// Draw validates the space and draws an outcome from g.
+func (s *Space) Draw(g rng.Interface) (*Result, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	r := s.draw(g)
+	return &r, nil
+}
+
+// draw draws a validated space.
+func (s *Space) draw(g rng.Interface) Result {
+	if s.Count == 0 {
+		return s.drawOnce(g)
+	}
+	res := Result{Name: s.Name, Parts: make([]Result, s.Count)}
+	for i := range res.Parts {
+		res.Parts[i] = s.drawOnce(g)
+		res.Parts[i].Name = ""
+		res.Value += res.Parts[i].Value
+	}
+	return res
+}
+
+func (s *Space) drawOnce(g rng.Interface) Result {
+	res := Result{Name: s.Name}
+	switch s.Type {
+	case TypeRange:
+		width := uint64(s.Max) - uint64(s.Min)
+		res.Value = int64(uint64(s.Min) + uint64(g.Intn(int(width)+1)))
+	case TypeTable:
+		total, _ := s.total()
+		r := g.Intn(total)
+		for i, e := range s.Entries {
+			if r < e.Weight {
+				res.Index, res.Label, res.Value = i, e.Label, e.Value
+				break
+			}
+			r -= e.Weight
+		}
+	case TypeComposite:
+		res.Parts = make([]Result, len(s.Parts))
+		for i := range s.Parts {
+			res.Parts[i] = s.Parts[i].draw(g)
+			res.Value += res.Parts[i].Value
+		}
+	}
+	return res
+}
@@ -0,0 +1,128 @@
+package outcome
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bonusRound = `{
+  "name": "bonus round",
+  "type": "composite",
+  "parts": [
+    {"name": "dice", "type": "range", "min": 1, "max": 6, "count": 2},
+    {"name": "prize", "type": "table", "entries": [
+      {"label": "none", "weight": 90},
+      {"label": "cash", "value": 100, "weight": 9},
+      {"label": "jackpot", "value": 10000, "weight": 1}
+    ]}
+  ]
+}`
+
+func TestLoadDraw(t *testing.T) {
+	s, err := Load(strings.NewReader(bonusRound))
+	require.NoError(t, err)
+
+	g := rng.New(rng.NewDRBG([]byte("bonus")))
+	for i := 0; i < 1000; i++ {
+		res, err := s.Draw(g)
+		require.NoError(t, err)
+		assert.Equal(t, "bonus round", res.Name)
+		require.Len(t, res.Parts, 2)
+
+		dice := res.Parts[0]
+		assert.Equal(t, "dice", dice.Name)
+		require.Len(t, dice.Parts, 2)
+		for _, d := range dice.Parts {
+			assert.True(t, d.Value >= 1 && d.Value <= 6)
+		}
+		assert.Equal(t, dice.Parts[0].Value+dice.Parts[1].Value, dice.Value)
+
+		prize := res.Parts[1]
+		assert.Equal(t, s.Parts[1].Entries[prize.Index].Label, prize.Label)
+		assert.Equal(t, s.Parts[1].Entries[prize.Index].Value, prize.Value)
+		assert.Equal(t, dice.Value+prize.Value, res.Value)
+	}
+}
+
+func TestDrawDistribution(t *testing.T) {
+	s := Space{Type: TypeTable, Entries: []Entry{
+		{Label: "a", Weight: 1},
+		{Label: "never", Weight: 0},
+		{Label: "b", Weight: 3},
+	}}
+	g := rng.New(rng.NewDRBG([]byte("table")))
+	counts := make(map[string]int)
+	for i := 0; i < 40000; i++ {
+		res, err := s.Draw(g)
+		require.NoError(t, err)
+		counts[res.Label]++
+	}
+	assert.Zero(t, counts["never"])
+	assert.InDelta(t, 10000, counts["a"], 400)
+	assert.InDelta(t, 30000, counts["b"], 400)
+}
+
+func TestDrawRangeBounds(t *testing.T) {
+	g := rng.New(rng.NewDRBG([]byte("range")))
+
+	s := Space{Type: TypeRange, Min: -3, Max: -3}
+	res, err := s.Draw(g)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), res.Value)
+
+	s = Space{Type: TypeRange, Min: math.MinInt / 2, Max: math.MaxInt/2 - 1}
+	for i := 0; i < 100; i++ {
+		res, err = s.Draw(g)
+		require.NoError(t, err)
+		assert.True(t, res.Value >= s.Min && res.Value <= s.Max)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	deep := Space{Type: TypeRange, Max: 1}
+	for i := 0; i < MaxDepth; i++ {
+		deep = Space{Type: TypeComposite, Parts: []Space{deep}}
+	}
+	repeated := Space{Type: TypeRange, Max: 1, Count: MaxCount}
+	for i := 1; i < MaxDepth; i++ {
+		repeated = Space{Type: TypeComposite, Parts: []Space{repeated}, Count: MaxCount}
+	}
+	big := Space{Type: TypeTable, Entries: []Entry{{Label: "a", Value: math.MaxInt64, Weight: 1}}}
+
+	for name, s := range map[string]Space{
+		"unknown type":  {Type: "dice"},
+		"empty range":   {Type: TypeRange, Min: 2, Max: 1},
+		"wide range":    {Type: TypeRange, Min: -1 << 63, Max: 1<<63 - 1},
+		"no weight":     {Type: TypeTable, Entries: []Entry{{Label: "a"}}},
+		"neg weight":    {Type: TypeTable, Entries: []Entry{{Label: "a", Weight: -1}, {Label: "b", Weight: 2}}},
+		"no parts":      {Type: TypeComposite},
+		"bad part":      {Type: TypeComposite, Parts: []Space{{Type: TypeRange}, {Name: "x"}}},
+		"count":         {Type: TypeRange, Count: MaxCount + 1},
+		"nested deeply": deep,
+		"nested counts": repeated,
+		"draws":         {Type: TypeComposite, Parts: []Space{{Type: TypeRange, Max: 1, Count: MaxCount}}, Count: MaxDraws/MaxCount + 1},
+		"repeat value":  {Type: TypeTable, Entries: big.Entries, Count: 2},
+		"sum value":     {Type: TypeComposite, Parts: []Space{big, {Type: TypeRange, Min: 1, Max: 1}}},
+		"negative sum":  {Type: TypeRange, Min: math.MinInt64 / 2, Max: 0, Count: 3},
+	} {
+		assert.Error(t, s.Validate(), name)
+		_, err := s.Draw(rng.New(nil))
+		assert.Error(t, err, name)
+	}
+
+	// zero weight entries are never drawn and do not overflow
+	big.Entries = append(big.Entries, Entry{Label: "b", Value: math.MinInt64})
+	assert.NoError(t, (&Space{Type: TypeComposite, Parts: []Space{big, {Type: TypeRange, Min: -1, Max: -1}}}).Validate())
+	assert.NoError(t, (&Space{Type: TypeRange, Max: 1, Count: MaxCount}).Validate())
+
+	err := (&Space{Type: TypeComposite, Parts: []Space{{Type: TypeRange}, {Name: "x"}}}).Validate()
+	assert.EqualError(t, err, `outcome: space/"x": unknown type ""`)
+
+	_, err = Load(strings.NewReader(`{"type": "range", "maximum": 3}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package rng
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrPoolEmpty is returned by OutcomePool.Next when no precomputed outcome is
+// available.
+var ErrPoolEmpty = errors.New("rng: outcome pool is empty")
+
+// Outcome pool audit events.
+const (
+	PoolFill  = "fill"
+	PoolServe = "serve"
+)
+
+// PoolRecord is an audit record of an outcome entering or leaving an
+// OutcomePool.
+type PoolRecord struct {
+	// Event is PoolFill or PoolServe.
+	Event  string `json:"event"`
+	DrawID string `json:"draw_id"`
+	// Digest is SHA-256 of the canonical draw result, equal in the fill and
+	// serve records of a draw.
+	Digest []byte    `json:"digest"`
+	At     time.Time `json:"at"`
+	// Size is the number of outcomes in the pool after the event.
+	Size int `json:"size"`
+}
+
+// OutcomePool precomputes outcomes of a single draw spec during idle time and
+// serves them in O(1) at request time, for games whose latency budget can not
+// fit a slow source, e.g. an HSM round trip, at spin time.
+//
+// Outcomes are served strictly in the order they were drawn, so no outcome
+// can be selected for a request. Pooled outcomes are held encrypted with
+// AES-256-GCM under a random key of the pool, which never leaves memory, and
+// every outcome drawn and served is reported to Audit with the digest of its
+// draw result, so auditors can match served outcomes to their precomputation.
+//
+// OutcomePool is safe for concurrent use, fills are serialized and do not
+// block Next.
+type OutcomePool struct {
+	src      *Checked
+	spec     DrawSpec
+	run      func(src io.Reader) []int64
+	capacity int
+	aead     cipher.AEAD
+
+	fillMu sync.Mutex
+	mu     sync.Mutex
+	queue  []sealedOutcome // queue[head:] are pooled
+	head   int
+	nonce  uint64
+
+	// Audit, if set, receives a record for every outcome added to and
+	// served from the pool. Fill records are reported while the fill is in
+	// progress, so they arrive in draw order, but neither Fill nor Next hold
+	// the lock guarding pooled outcomes, so Audit may call Len and Next.
+	// Audit must not call Fill.
+	Audit func(PoolRecord)
+}
+
+// NewOutcomePool returns an empty pool of draws of a given spec drawing
+// randomness from g and holding up to capacity outcomes. Spec ID is ignored,
+// every pooled draw gets a random ID. It will panic if the spec is invalid
+// or capacity <= 0.
+func NewOutcomePool(g *Generator, spec DrawSpec, capacity int) *OutcomePool {
+	run, err := specRun(spec)
+	if err != nil || capacity <= 0 {
+		panic(fmt.Sprintf("invalid argument to NewOutcomePool: %v", err))
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		panic(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &OutcomePool{src: g.Checked(), spec: spec, run: run, capacity: capacity, aead: aead}
+}
+
+// Len returns the number of pooled outcomes.
+func (p *OutcomePool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue) - p.head
+}
+
+// Fill draws outcomes until the pool holds capacity outcomes and returns the
+// number drawn. It is meant to run during idle time, e.g. from a ticker. It
+// stops at the first source read error and returns it as *SourceError, the
+// outcomes drawn before the error stay pooled.
+func (p *OutcomePool) Fill() (int, error) {
+	p.fillMu.Lock()
+	defer p.fillMu.Unlock()
+	n := 0
+	for p.Len() < p.capacity {
+		var d *DrawResult
+		err := p.src.Draw(func(src io.Reader) {
+			d = runDraw(src, p.spec.Type, p.spec.Params, p.run)
+		})
+		if err != nil {
+			return n, err
+		}
+		canonical := d.Canonical()
+		digest := sha256.Sum256(canonical)
+
+		p.mu.Lock()
+		p.nonce++
+		sealed := p.aead.Seal(nil, poolNonce(p.nonce), canonical, nil)
+		p.queue = append(p.queue, sealedOutcome{nonce: p.nonce, sealed: sealed})
+		size := len(p.queue) - p.head
+		p.mu.Unlock()
+
+		n++
+		p.audit(PoolRecord{Event: PoolFill, DrawID: d.ID, Digest: digest[:], At: d.FinishedAt, Size: size})
+	}
+	return n, nil
+}
+
+// Next returns the oldest pooled outcome. It never reads from the source, it
+// returns ErrPoolEmpty if the pool is exhausted.
+func (p *OutcomePool) Next() (*DrawResult, error) {
+	p.mu.Lock()
+	if p.head == len(p.queue) {
+		p.mu.Unlock()
+		return nil, ErrPoolEmpty
+	}
+	e := p.queue[p.head]
+	p.queue[p.head] = sealedOutcome{}
+	p.head++
+	// reuse the backing array once the served prefix dominates
+	if p.head == len(p.queue) {
+		p.queue, p.head = p.queue[:0], 0
+	} else if p.head > 64 && p.head > len(p.queue)/2 {
+		p.queue, p.head = append(p.queue[:0], p.queue[p.head:]...), 0
+	}
+	size := len(p.queue) - p.head
+	p.mu.Unlock()
+
+	canonical, err := p.aead.Open(nil, poolNonce(e.nonce), e.sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("rng: opening pooled outcome: %w", err)
+	}
+	d := &DrawResult{}
+	if err := json.Unmarshal(canonical, d); err != nil {
+		return nil, fmt.Errorf("rng: decoding pooled outcome: %w", err)
+	}
+	digest := sha256.Sum256(canonical)
+	p.audit(PoolRecord{Event: PoolServe, DrawID: d.ID, Digest: digest[:], At: time.Now().UTC(), Size: size})
+	return d, nil
+}
+
+func (p *OutcomePool) audit(r PoolRecord) {
+	if p.Audit != nil {
+		p.Audit(r)
+	}
+}
+
+// sealedOutcome is an encrypted canonical draw result.
+type sealedOutcome struct {
+	nonce  uint64
+	sealed []byte
+}
+
+// poolNonce returns the GCM nonce of the n-th pooled outcome, nonces never
+// repeat under a pool key.
+func poolNonce(n uint64) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint64(b[4:], n)
+	return b
+}
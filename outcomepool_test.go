@@ -0,0 +1,127 @@
+package rng
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutcomePool(t *testing.T) {
+	var records []PoolRecord
+	p := NewOutcomePool(New(NewDRBG([]byte("seed"))), DrawSpec{Type: "intn", Params: map[string]int64{"n": 37}}, 100)
+	p.Audit = func(r PoolRecord) { records = append(records, r) }
+
+	_, err := p.Next()
+	assert.True(t, errors.Is(err, ErrPoolEmpty))
+	n, err := p.Fill()
+	require.NoError(t, err)
+	assert.Equal(t, 100, n)
+	assert.Equal(t, 100, p.Len())
+	n, err = p.Fill()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	require.Len(t, records, 100)
+
+	// outcomes are served in draw order and match the fill records
+	for i := 0; i < 60; i++ {
+		d, err := p.Next()
+		require.NoError(t, err)
+		assert.Equal(t, "intn", d.Type)
+		assert.Equal(t, "intn/v1", d.Algorithm)
+		require.Len(t, d.Outcome, 1)
+		assert.True(t, d.Outcome[0] >= 0 && d.Outcome[0] < 37)
+
+		fill, serve := records[i], records[len(records)-1]
+		assert.Equal(t, PoolFill, fill.Event)
+		assert.Equal(t, PoolServe, serve.Event)
+		assert.Equal(t, fill.DrawID, d.ID)
+		assert.Equal(t, fill.Digest, serve.Digest)
+		digest := d.Digest()
+		assert.Equal(t, digest[:], serve.Digest)
+		assert.Equal(t, 99-i, serve.Size)
+	}
+	assert.Equal(t, 40, p.Len())
+	n, err = p.Fill()
+	require.NoError(t, err)
+	assert.Equal(t, 60, n)
+	for i := 0; i < 100; i++ {
+		_, err := p.Next()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, p.Len())
+
+	assert.Panics(t, func() { NewOutcomePool(New(nil), DrawSpec{Type: "dice"}, 1) })
+	assert.Panics(t, func() { NewOutcomePool(New(nil), DrawSpec{Type: "float64"}, 0) })
+}
+
+func TestOutcomePoolReproducible(t *testing.T) {
+	spec := DrawSpec{Type: "sample", Params: map[string]int64{"n": 49, "k": 6}}
+	p := NewOutcomePool(New(NewDRBG([]byte("seed"))), spec, 10)
+	_, err := p.Fill()
+	require.NoError(t, err)
+	src := NewDRBG([]byte("seed"))
+	for i := 0; i < 10; i++ {
+		d, err := p.Next()
+		require.NoError(t, err)
+		assert.NoError(t, VerifyDrawResult(src, d))
+	}
+
+	// pooled draws keep the generator encoding
+	be := Encoding{BigEndian: true}
+	p = NewOutcomePool(New(NewDRBG([]byte("seed"))).WithEncoding(be), DrawSpec{Type: "intn", Params: map[string]int64{"n": 1000000}}, 1)
+	_, err = p.Fill()
+	require.NoError(t, err)
+	d, err := p.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []int64{int64(New(NewDRBG([]byte("seed"))).WithEncoding(be).Intn(1000000))}, d.Outcome)
+}
+
+func TestOutcomePoolSourceError(t *testing.T) {
+	src := io.MultiReader(bytes.NewReader([]byte{1, 2}), iotest.ErrReader(io.ErrUnexpectedEOF))
+	p := NewOutcomePool(New(src), DrawSpec{Type: "intn", Params: map[string]int64{"n": 6}}, 5)
+	n, err := p.Fill()
+	assert.Equal(t, 2, n)
+	var se *SourceError
+	assert.True(t, errors.As(err, &se))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Equal(t, 2, p.Len())
+}
+
+func TestOutcomePoolConcurrent(t *testing.T) {
+	p := NewOutcomePool(New(nil), DrawSpec{Type: "float64"}, 50)
+	_, err := p.Fill()
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ids := map[string]bool{}
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := p.Fill()
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				d, err := p.Next()
+				if errors.Is(err, ErrPoolEmpty) {
+					continue
+				}
+				assert.NoError(t, err)
+				mu.Lock()
+				assert.False(t, ids[d.ID])
+				ids[d.ID] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.True(t, p.Len() <= 50)
+}
@@ -0,0 +1,194 @@
+// Package pairing pairs tournament players randomly subject to constraints,
+// e.g. for poker or esports rounds.
+//
+// Pair finds a pairing by randomized backtracking: players are taken in
+// random order and matched with random allowed opponents, dead ends are
+// undone and retried. In Swiss mode players are taken in descending score
+// order and opponents with the closest score are tried first, so players
+// meet others with similar scores. Pairings are random among equally good
+// choices but not uniform over all valid pairings.
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/advbet/rng"
+)
+
+// DefaultMaxSteps is the search step limit used when Constraints.MaxSteps is
+// 0.
+const DefaultMaxSteps = 1000000
+
+// ErrNoPairing is returned if no pairing satisfies the constraints within the
+// step limit.
+var ErrNoPairing = errors.New("pairing: no valid pairing")
+
+// Player is a tournament player.
+type Player struct {
+	ID string
+	// Score is the tournament score, e.g. match points.
+	Score int
+	// Opponents lists IDs of players met in earlier rounds.
+	Opponents []string
+	// HadBye is true if the player already had a bye.
+	HadBye bool
+}
+
+// Constraints restrict valid pairings.
+type Constraints struct {
+	// AllowRematches allows players to meet again, rematches are
+	// forbidden by default.
+	AllowRematches bool
+	// MaxScoreDiff, if positive, is the largest score difference allowed
+	// between paired players.
+	MaxScoreDiff int
+	// Swiss pairs players with similar scores first.
+	Swiss bool
+	// Allowed, if set, reports whether two players can be paired, e.g. to
+	// keep team mates apart.
+	Allowed func(a, b string) bool
+	// MaxSteps limits the number of pairings tried by the search.
+	MaxSteps int
+}
+
+// Match is a pairing of two players. B is empty if A has a bye.
+type Match struct {
+	A, B string
+}
+
+// Pair returns a random pairing of players, see ReadPair.
+func Pair(players []Player, c Constraints) ([]Match, error) {
+	return ReadPair(rng.DefaultSource(), players, c)
+}
+
+// ReadPair returns a random pairing of players satisfying the constraints
+// reading randomness from a given source. With an odd number of players one
+// player gets a bye, chosen among players without an earlier bye, the lowest
+// scored first in Swiss mode. It returns ErrNoPairing if no valid pairing is
+// found and an error for duplicate player IDs.
+func ReadPair(src io.Reader, players []Player, c Constraints) ([]Match, error) {
+	seen := make(map[string]bool, len(players))
+	for _, p := range players {
+		if seen[p.ID] {
+			return nil, fmt.Errorf("pairing: duplicate player %q", p.ID)
+		}
+		seen[p.ID] = true
+	}
+	s := &search{c: c, steps: c.MaxSteps}
+	if s.steps <= 0 {
+		s.steps = DefaultMaxSteps
+	}
+
+	// random order, stable sort keeps it among equal scores
+	s.players = make([]*Player, len(players))
+	for i, j := range rng.ReadPerm(src, len(players)) {
+		s.players[i] = &players[j]
+	}
+	if c.Swiss {
+		sort.SliceStable(s.players, func(i, j int) bool { return s.players[i].Score > s.players[j].Score })
+	}
+	s.met = make(map[[2]string]bool)
+	for _, p := range players {
+		for _, o := range p.Opponents {
+			s.met[[2]string{p.ID, o}] = true
+			s.met[[2]string{o, p.ID}] = true
+		}
+	}
+	s.paired = make([]bool, len(players))
+	// candidate opponents of every player in the order they are tried
+	s.candidates = make([][]int, len(players))
+	for i, p := range s.players {
+		for j, q := range s.players {
+			if i != j && s.allowed(p, q) {
+				s.candidates[i] = append(s.candidates[i], j)
+			}
+		}
+		if c.Swiss {
+			sort.SliceStable(s.candidates[i], func(a, b int) bool {
+				return abs(p.Score-s.players[s.candidates[i][a]].Score) < abs(p.Score-s.players[s.candidates[i][b]].Score)
+			})
+		}
+	}
+
+	if len(players)%2 == 1 {
+		// bye candidates from the end of the order, lowest scores first
+		for i := len(s.players) - 1; i >= 0; i-- {
+			if s.players[i].HadBye {
+				continue
+			}
+			s.paired[i] = true
+			s.matches = append(s.matches, Match{A: s.players[i].ID})
+			if s.solve() {
+				// the bye is listed last
+				return append(s.matches[1:], s.matches[0]), nil
+			}
+			s.matches = s.matches[:0]
+			s.paired[i] = false
+		}
+		return nil, ErrNoPairing
+	}
+	if !s.solve() {
+		return nil, ErrNoPairing
+	}
+	return s.matches, nil
+}
+
+type search struct {
+	c          Constraints
+	players    []*Player
+	candidates [][]int
+	met        map[[2]string]bool
+	paired     []bool
+	matches    []Match
+	steps      int
+}
+
+func (s *search) allowed(p, q *Player) bool {
+	if !s.c.AllowRematches && s.met[[2]string{p.ID, q.ID}] {
+		return false
+	}
+	if s.c.MaxScoreDiff > 0 && abs(p.Score-q.Score) > s.c.MaxScoreDiff {
+		return false
+	}
+	return s.c.Allowed == nil || s.c.Allowed(p.ID, q.ID)
+}
+
+// solve pairs the first unpaired player with each candidate in turn and
+// recurses, undoing dead ends.
+func (s *search) solve() bool {
+	i := 0
+	for i < len(s.paired) && s.paired[i] {
+		i++
+	}
+	if i == len(s.paired) {
+		return true
+	}
+	s.paired[i] = true
+	for _, j := range s.candidates[i] {
+		if s.paired[j] {
+			continue
+		}
+		if s.steps--; s.steps < 0 {
+			break
+		}
+		s.paired[j] = true
+		s.matches = append(s.matches, Match{A: s.players[i].ID, B: s.players[j].ID})
+		if s.solve() {
+			return true
+		}
+		s.matches = s.matches[:len(s.matches)-1]
+		s.paired[j] = false
+	}
+	s.paired[i] = false
+	return false
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
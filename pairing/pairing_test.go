@@ -0,0 +1,156 @@
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func players(n int) []Player {
+	ps := make([]Player, n)
+	for i := range ps {
+		ps[i] = Player{ID: fmt.Sprintf("p%d", i), Score: i / 2}
+	}
+	return ps
+}
+
+// checkPairing verifies that every player is in exactly one match.
+func checkPairing(t *testing.T, ps []Player, ms []Match) {
+	t.Helper()
+	seen := map[string]int{}
+	for _, m := range ms {
+		seen[m.A]++
+		if m.B != "" {
+			seen[m.B]++
+		}
+	}
+	require.Len(t, seen, len(ps))
+	for _, p := range ps {
+		assert.Equal(t, 1, seen[p.ID], p.ID)
+	}
+}
+
+func TestPair(t *testing.T) {
+	ps := players(8)
+	ms, err := ReadPair(rng.NewDRBG([]byte("seed")), ps, Constraints{})
+	require.NoError(t, err)
+	assert.Len(t, ms, 4)
+	checkPairing(t, ps, ms)
+
+	// unconstrained pairings are uniform over all 3 pairings of 4 players
+	counts := map[string]int{}
+	src := rng.NewDRBG([]byte("uniform"))
+	for i := 0; i < 3000; i++ {
+		ms, err := ReadPair(src, players(4), Constraints{})
+		require.NoError(t, err)
+		partner := ""
+		for _, m := range ms {
+			if m.A == "p0" {
+				partner = m.B
+			}
+			if m.B == "p0" {
+				partner = m.A
+			}
+		}
+		counts[partner]++
+	}
+	assert.Len(t, counts, 3)
+	for p, n := range counts {
+		assert.InDelta(t, 1000, n, 120, p)
+	}
+
+	_, err = Pair([]Player{{ID: "a"}, {ID: "a"}}, Constraints{})
+	assert.Error(t, err)
+	ms, err = Pair(nil, Constraints{})
+	assert.NoError(t, err)
+	assert.Empty(t, ms)
+}
+
+func TestPairNoRematch(t *testing.T) {
+	// round robin of 4 players, the only pairing left is p0-p3 and p1-p2
+	ps := players(4)
+	ps[0].Opponents = []string{"p1", "p2"}
+	ps[3].Opponents = []string{"p1", "p2"}
+	for i := 0; i < 20; i++ {
+		ms, err := Pair(ps, Constraints{})
+		require.NoError(t, err)
+		checkPairing(t, ps, ms)
+		for _, m := range ms {
+			assert.Contains(t, [][2]string{{"p0", "p3"}, {"p3", "p0"}, {"p1", "p2"}, {"p2", "p1"}}, [2]string{m.A, m.B})
+		}
+	}
+
+	// opponents recorded on one side only also count
+	ps = players(2)
+	ps[1].Opponents = []string{"p0"}
+	_, err := Pair(ps, Constraints{})
+	assert.True(t, errors.Is(err, ErrNoPairing))
+	_, err = Pair(ps, Constraints{AllowRematches: true})
+	assert.NoError(t, err)
+}
+
+func TestPairSwiss(t *testing.T) {
+	ps := players(8) // scores 0 0 1 1 2 2 3 3
+	for i := 0; i < 20; i++ {
+		ms, err := Pair(ps, Constraints{Swiss: true})
+		require.NoError(t, err)
+		checkPairing(t, ps, ms)
+		for _, m := range ms {
+			var a, b int
+			fmt.Sscanf(m.A, "p%d", &a)
+			fmt.Sscanf(m.B, "p%d", &b)
+			assert.Equal(t, a/2, b/2, "%v", m)
+		}
+	}
+
+	// rematches within score groups push players to neighbouring groups
+	ps[0].Opponents = []string{"p1"}
+	ms, err := Pair(ps, Constraints{Swiss: true, MaxScoreDiff: 1})
+	require.NoError(t, err)
+	checkPairing(t, ps, ms)
+	_, err = Pair(ps[:2], Constraints{Swiss: true})
+	assert.True(t, errors.Is(err, ErrNoPairing))
+}
+
+func TestPairBye(t *testing.T) {
+	ps := players(5) // scores 0 0 1 1 2
+	ps[0].HadBye = true
+	for i := 0; i < 20; i++ {
+		ms, err := Pair(ps, Constraints{Swiss: true})
+		require.NoError(t, err)
+		checkPairing(t, ps, ms)
+		assert.Equal(t, Match{A: "p1"}, ms[2])
+	}
+
+	for i := range ps {
+		ps[i].HadBye = true
+	}
+	_, err := Pair(ps, Constraints{})
+	assert.True(t, errors.Is(err, ErrNoPairing))
+}
+
+func TestPairAllowed(t *testing.T) {
+	ps := players(6)
+	team := map[string]int{"p0": 0, "p1": 0, "p2": 0, "p3": 1, "p4": 1, "p5": 1}
+	c := Constraints{Allowed: func(a, b string) bool { return team[a] != team[b] }}
+	for i := 0; i < 20; i++ {
+		ms, err := Pair(ps, c)
+		require.NoError(t, err)
+		for _, m := range ms {
+			assert.NotEqual(t, team[m.A], team[m.B])
+		}
+	}
+
+	// an odd team size makes pairing impossible, the search gives up after
+	// MaxSteps tries
+	team["p6"], team["p7"] = 0, 1
+	ps = append(players(8), Player{ID: "p8"}, Player{ID: "p9"})
+	team["p8"], team["p9"] = 0, 0
+	c.MaxSteps = 1000
+	_, err := Pair(ps, c)
+	assert.True(t, errors.Is(err, ErrNoPairing))
+}
@@ -0,0 +1,56 @@
+package rng
+
+import (
+	"io"
+	"strings"
+)
+
+// patternClasses maps pattern characters to the sets they are replaced with.
+var patternClasses = map[byte]string{
+	'A': "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	'a': "abcdefghijklmnopqrstuvwxyz",
+	'9': "0123456789",
+	'?': "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+}
+
+// FromPattern returns a random string matching a character class pattern, see
+// ReadFromPattern.
+func FromPattern(pattern string) string {
+	return ReadFromPattern(defaultSource(), pattern)
+}
+
+// ReadFromPattern returns a random string matching a character class pattern
+// reading randomness from a given source. Every class character is replaced
+// with a uniformly selected member of its class:
+//
+//	A  uppercase letter A-Z
+//	a  lowercase letter a-z
+//	9  digit 0-9
+//	?  letter or digit
+//
+// Other characters are copied literally, a backslash copies the following
+// character literally, e.g. "AAA-999-aa?" may produce "KQZ-071-xe4" and
+// "\\A9" may produce "A3". It will panic if the pattern ends with an unpaired
+// backslash.
+func ReadFromPattern(src io.Reader, pattern string) string {
+	var b strings.Builder
+	b.Grow(len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == '\\' {
+			i++
+			if i == len(pattern) {
+				panic("invalid argument to FromPattern")
+			}
+			b.WriteByte(pattern[i])
+			continue
+		}
+		class, ok := patternClasses[c]
+		if !ok {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte(class[ReadIntn(src, len(class))])
+	}
+	return b.String()
+}
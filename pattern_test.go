@@ -0,0 +1,23 @@
+package rng
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFromPattern(t *testing.T) {
+	src := bytes.NewBuffer([]byte{0, 25, 1, 9, 0, 5, 61})
+	assert.Equal(t, "AZ-b9-0\\A5-9", ReadFromPattern(src, `AA-a9-9\\\A9-?`))
+
+	re := regexp.MustCompile(`^[A-Z]{3}-[0-9]{3}-[a-z]{2}[A-Za-z0-9]$`)
+	g := NewDRBG([]byte("seed"))
+	for i := 0; i < 100; i++ {
+		assert.Regexp(t, re, ReadFromPattern(g, "AAA-999-aa?"))
+	}
+
+	assert.Equal(t, "", FromPattern(""))
+	assert.Panics(t, func() { FromPattern(`A\`) })
+}
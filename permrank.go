@@ -0,0 +1,63 @@
+package rng
+
+import "math/big"
+
+// PermRank returns the lexicographic rank of permutation p of [0, n) in
+// [0, n!), computed from its Lehmer code. A shuffled deck can be stored or
+// committed to as this single number and regenerated exactly with PermUnrank,
+// 52 cards fit in 226 bits. It will panic if p is not a permutation of
+// [0, len(p)).
+func PermRank(p []int) *big.Int {
+	n := len(p)
+	seen := make([]bool, n)
+	for _, v := range p {
+		if v < 0 || v >= n || seen[v] {
+			panic("invalid argument to PermRank")
+		}
+		seen[v] = true
+	}
+
+	rank := new(big.Int)
+	for i, v := range p {
+		// Lehmer code digit: later elements smaller than v
+		c := 0
+		for _, w := range p[i+1:] {
+			if w < v {
+				c++
+			}
+		}
+		rank.Mul(rank, big.NewInt(int64(n-i)))
+		rank.Add(rank, big.NewInt(int64(c)))
+	}
+	return rank
+}
+
+// PermUnrank returns the permutation of [0, n) with lexicographic rank r, the
+// inverse of PermRank. It will panic if n < 0 or r is not in [0, n!).
+func PermUnrank(n int, r *big.Int) []int {
+	if n < 0 || r.Sign() < 0 {
+		panic("invalid argument to PermUnrank")
+	}
+
+	// Lehmer code digits in factorial base, least significant first
+	code := make([]int, n)
+	q, m := new(big.Int).Set(r), new(big.Int)
+	for i := n - 1; i >= 0; i-- {
+		q.DivMod(q, big.NewInt(int64(n-i)), m)
+		code[i] = int(m.Int64())
+	}
+	if q.Sign() != 0 {
+		panic("invalid argument to PermUnrank")
+	}
+
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	p := make([]int, n)
+	for i, c := range code {
+		p[i] = remaining[c]
+		remaining = append(remaining[:c], remaining[c+1:]...)
+	}
+	return p
+}
@@ -0,0 +1,31 @@
+package rng
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermRank(t *testing.T) {
+	all := [][]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	for r, p := range all {
+		assert.Equal(t, int64(r), PermRank(p).Int64())
+		assert.Equal(t, p, PermUnrank(3, big.NewInt(int64(r))))
+	}
+	assert.Equal(t, int64(0), PermRank(nil).Int64())
+	assert.Equal(t, []int{}, PermUnrank(0, big.NewInt(0)))
+
+	g := NewDRBG([]byte("deck"))
+	for i := 0; i < 20; i++ {
+		deck := ReadPerm(g, 52)
+		r := PermRank(deck)
+		assert.True(t, r.BitLen() <= 226)
+		assert.Equal(t, deck, PermUnrank(52, r))
+	}
+
+	assert.Panics(t, func() { PermRank([]int{0, 0}) })
+	assert.Panics(t, func() { PermRank([]int{1, 2}) })
+	assert.Panics(t, func() { PermUnrank(3, big.NewInt(6)) })
+	assert.Panics(t, func() { PermUnrank(3, big.NewInt(-1)) })
+}
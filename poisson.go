@@ -0,0 +1,94 @@
+package rng
+
+import (
+	"io"
+	"iter"
+	"math"
+	"time"
+)
+
+// PoissonProcess returns an endless sequence of inter-arrival times of a
+// Poisson process with rate events per second, see ReadPoissonProcess.
+func PoissonProcess(rate float64) iter.Seq[time.Duration] {
+	return ReadPoissonProcess(defaultSource(), rate)
+}
+
+// ReadPoissonProcess returns an endless sequence of inter-arrival times of a
+// Poisson process with rate events per second reading randomness from a given
+// source. Inter-arrival times are exponentially distributed with mean 1/rate
+// and rounded to nanoseconds, times beyond the Duration range saturate at its
+// maximum. It will panic if rate is not finite or the mean 1/rate exceeds the
+// Duration range.
+func ReadPoissonProcess(src io.Reader, rate float64) iter.Seq[time.Duration] {
+	if !validRate(rate) {
+		panic("invalid argument to PoissonProcess")
+	}
+	return func(yield func(time.Duration) bool) {
+		for {
+			if !yield(seconds(readExp(src) / rate)) {
+				return
+			}
+		}
+	}
+}
+
+// NonHomogeneousPoissonProcess returns an endless sequence of inter-arrival
+// times of a Poisson process with time varying rate, see
+// ReadNonHomogeneousPoissonProcess.
+func NonHomogeneousPoissonProcess(rate func(t time.Duration) float64, maxRate float64) iter.Seq[time.Duration] {
+	return ReadNonHomogeneousPoissonProcess(defaultSource(), rate, maxRate)
+}
+
+// ReadNonHomogeneousPoissonProcess returns an endless sequence of
+// inter-arrival times of a Poisson process whose rate at time t since the
+// start is rate(t) events per second, e.g. bursty traffic peaking at kick-off.
+// It reads randomness from a given source and uses thinning: candidate events
+// of a process with maxRate are kept with probability rate(t)/maxRate. Times
+// beyond the Duration range saturate at its maximum. It will panic if maxRate
+// is not finite or 1/maxRate exceeds the Duration range and the sequence
+// panics if rate(t) is outside [0, maxRate].
+func ReadNonHomogeneousPoissonProcess(src io.Reader, rate func(t time.Duration) float64, maxRate float64) iter.Seq[time.Duration] {
+	if !validRate(maxRate) {
+		panic("invalid argument to NonHomogeneousPoissonProcess")
+	}
+	return func(yield func(time.Duration) bool) {
+		t, last := 0.0, 0.0 // seconds since the start
+		for {
+			t += readExp(src) / maxRate
+			r := rate(seconds(t))
+			if !(r >= 0 && r <= maxRate) {
+				panic("rng: NonHomogeneousPoissonProcess rate out of [0, maxRate]")
+			}
+			if ReadFloat64(src)*maxRate >= r {
+				continue
+			}
+			if !yield(seconds(t - last)) {
+				return
+			}
+			last = t
+		}
+	}
+}
+
+// readExp returns an exponentially distributed value with mean 1.
+func readExp(src io.Reader) float64 {
+	return -math.Log(ReadFloat64Open(src))
+}
+
+// maxSeconds is the Duration range in seconds.
+const maxSeconds = float64(math.MaxInt64) / float64(time.Second)
+
+// validRate reports whether rate is finite with a mean inter-arrival time
+// 1/rate within the Duration range.
+func validRate(rate float64) bool {
+	return rate > 0 && !math.IsInf(rate, 0) && 1/rate < maxSeconds
+}
+
+// seconds converts non negative seconds to a Duration rounded to nanoseconds,
+// saturating at the maximum Duration.
+func seconds(s float64) time.Duration {
+	if s >= maxSeconds {
+		return math.MaxInt64
+	}
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
@@ -0,0 +1,69 @@
+package rng
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoissonProcess(t *testing.T) {
+	const events = 20000
+	var total time.Duration
+	n := 0
+	for d := range ReadPoissonProcess(NewDRBG([]byte("poisson")), 50) {
+		assert.True(t, d >= 0)
+		total += d
+		n++
+		if n == events {
+			break
+		}
+	}
+	// mean inter-arrival time is 20ms
+	assert.InDelta(t, 20*time.Millisecond, total/events, float64(time.Millisecond))
+
+	assert.Panics(t, func() { PoissonProcess(0) })
+	assert.Panics(t, func() { PoissonProcess(1e-11) })
+
+	// gaps of slow processes saturate instead of overflowing
+	n = 0
+	for d := range ReadPoissonProcess(NewDRBG([]byte("slow")), 2e-10) {
+		assert.True(t, d > 0, "%v", d)
+		if n++; n == 100 {
+			break
+		}
+	}
+	assert.Equal(t, time.Duration(math.MaxInt64), seconds(1e20))
+}
+
+func TestNonHomogeneousPoissonProcess(t *testing.T) {
+	// 10 events per second in the first second, 100 afterwards
+	rate := func(t time.Duration) float64 {
+		if t < time.Second {
+			return 10
+		}
+		return 100
+	}
+	var at time.Duration
+	first, second := 0, 0
+	for d := range ReadNonHomogeneousPoissonProcess(NewDRBG([]byte("nhpp")), rate, 100) {
+		at += d
+		if at >= 101*time.Second {
+			break
+		}
+		if at < time.Second {
+			first++
+		} else {
+			second++
+		}
+	}
+	assert.InDelta(t, 10, first, 12)
+	assert.InDelta(t, 10000, second, 500)
+
+	assert.Panics(t, func() { NonHomogeneousPoissonProcess(rate, 0) })
+	assert.Panics(t, func() {
+		for range NonHomogeneousPoissonProcess(rate, 50) {
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrNotApproved is returned by Failover reads when the primary source failed,
+// the grace buffer can not serve the read and switching to the fallback
+// source was not (yet) approved by an operator.
+var ErrNotApproved = errors.New("policy: fallback source not approved")
+
+// State is the source currently serving Failover reads.
+type State int
+
+// Failover states.
+const (
+	// StatePrimary serves reads from the primary source.
+	StatePrimary State = iota
+	// StateGrace serves reads from the grace buffer while waiting for
+	// operator approval.
+	StateGrace
+	// StateDenied means the operator denied the fallback, reads fail until
+	// the primary source recovers.
+	StateDenied
+	// StateFallback serves reads from the fallback source until Restore is
+	// called.
+	StateFallback
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePrimary:
+		return "primary"
+	case StateGrace:
+		return "grace"
+	case StateDenied:
+		return "denied"
+	case StateFallback:
+		return "fallback"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Approver asks an operator whether to switch to the fallback source after
+// the primary failed with cause. It may block until the operator answers,
+// reads are bridged by the grace buffer meanwhile.
+type Approver func(cause error) bool
+
+// ApprovalRequest is sent by a ChannelApprover. The operator answers by
+// sending a single value to Reply.
+type ApprovalRequest struct {
+	Cause error
+	Reply chan<- bool
+}
+
+// ChannelApprover returns an Approver sending requests to ch, e.g. consumed
+// by an admin endpoint or chat integration.
+func ChannelApprover(ch chan<- ApprovalRequest) Approver {
+	return func(cause error) bool {
+		reply := make(chan bool, 1)
+		ch <- ApprovalRequest{Cause: cause, Reply: reply}
+		return <-reply
+	}
+}
+
+// Failover reads from a primary certified source and only switches to a
+// fallback source after explicit operator approval. A grace buffer of entropy
+// pre-fetched from the primary bridges short outages: when the primary fails
+// reads are served from the buffer while the approval is pending, and the
+// primary is retried on every read. Once approved, the fallback serves reads
+// until Restore is called.
+//
+// Failover is safe for concurrent use.
+type Failover struct {
+	mu        sync.Mutex
+	primary   io.Reader
+	fallback  io.Reader
+	approve   Approver
+	grace     []byte
+	graceSize int
+	state     State
+	gen       int // incremented when a pending approval becomes obsolete
+
+	// OnStateChange, if set, is called with the new state and the primary
+	// failure causing it (nil when returning to the primary). It is called
+	// with the Failover lock held and must not read from it.
+	OnStateChange func(s State, cause error)
+}
+
+// NewFailover returns a Failover reading from primary and falling back to
+// fallback when approve returns true. It fills a grace buffer of graceSize
+// bytes from primary and returns an error if that read fails.
+func NewFailover(primary, fallback io.Reader, approve Approver, graceSize int) (*Failover, error) {
+	if graceSize < 0 {
+		return nil, errors.New("policy: negative grace buffer size")
+	}
+	grace := make([]byte, graceSize)
+	if _, err := io.ReadFull(primary, grace); err != nil {
+		return nil, fmt.Errorf("policy: filling grace buffer: %w", err)
+	}
+	return &Failover{
+		primary:   primary,
+		fallback:  fallback,
+		approve:   approve,
+		grace:     grace,
+		graceSize: graceSize,
+	}, nil
+}
+
+// State returns the source currently serving reads.
+func (f *Failover) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// Buffered returns the number of bytes in the grace buffer.
+func (f *Failover) Buffered() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.grace)
+}
+
+// Read fills p from the current source. Reads are never served partially
+// from the grace buffer, a read larger than the remaining buffer fails with
+// ErrNotApproved.
+func (f *Failover) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.state == StateFallback {
+		return f.fallback.Read(p)
+	}
+
+	n, err := io.ReadFull(f.primary, p)
+	if err == nil {
+		if f.state != StatePrimary {
+			f.setState(StatePrimary, nil)
+		}
+		f.refill()
+		return n, nil
+	}
+
+	if f.state == StatePrimary {
+		f.setState(StateGrace, err)
+		go f.requestApproval(f.gen, err)
+	}
+	if f.state == StateGrace && len(p) <= len(f.grace) {
+		n = copy(p, f.grace[len(f.grace)-len(p):])
+		f.grace = f.grace[:len(f.grace)-n]
+		return n, nil
+	}
+	return 0, fmt.Errorf("%w: primary source failed: %v", ErrNotApproved, err)
+}
+
+// refill tops up the grace buffer from the primary source. Failures are
+// ignored, they surface on the next read.
+func (f *Failover) refill() {
+	missing := f.graceSize - len(f.grace)
+	if missing == 0 {
+		return
+	}
+	buf := make([]byte, missing)
+	if _, err := io.ReadFull(f.primary, buf); err == nil {
+		f.grace = append(f.grace, buf...)
+	}
+}
+
+func (f *Failover) requestApproval(gen int, cause error) {
+	ok := f.approve != nil && f.approve(cause)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if gen != f.gen || f.state != StateGrace {
+		// primary recovered or state was reset meanwhile
+		return
+	}
+	if ok {
+		f.setState(StateFallback, cause)
+	} else {
+		f.setState(StateDenied, cause)
+	}
+}
+
+// Restore switches reads back to the primary source, e.g. after the operator
+// confirmed it was repaired. A pending approval request is ignored.
+func (f *Failover) Restore() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state != StatePrimary {
+		f.setState(StatePrimary, nil)
+	}
+}
+
+func (f *Failover) setState(s State, cause error) {
+	f.state = s
+	if s == StatePrimary || s == StateGrace {
+		f.gen++
+	}
+	if f.OnStateChange != nil {
+		f.OnStateChange(s, cause)
+	}
+}
@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchReader reads from src unless failing is set.
+type switchReader struct {
+	mu      sync.Mutex
+	src     io.Reader
+	failing bool
+}
+
+func (r *switchReader) setFailing(f bool) {
+	r.mu.Lock()
+	r.failing = f
+	r.mu.Unlock()
+}
+
+func (r *switchReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failing {
+		return 0, errors.New("hsm offline")
+	}
+	return r.src.Read(p)
+}
+
+func TestFailoverApproved(t *testing.T) {
+	primary := &switchReader{src: rng.NewDRBG([]byte("primary"))}
+	fallback := rng.NewDRBG([]byte("fallback"))
+	requests := make(chan ApprovalRequest)
+	var states []State
+	f, err := NewFailover(primary, fallback, ChannelApprover(requests), 64)
+	require.NoError(t, err)
+	f.OnStateChange = func(s State, cause error) { states = append(states, s) }
+	assert.Equal(t, 64, f.Buffered())
+
+	buf := make([]byte, 32)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, StatePrimary, f.State())
+
+	// outage is bridged by the grace buffer while approval is pending
+	primary.setFailing(true)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, StateGrace, f.State())
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.Buffered())
+	_, err = f.Read(buf)
+	assert.True(t, errors.Is(err, ErrNotApproved))
+
+	req := <-requests
+	assert.EqualError(t, req.Cause, "hsm offline")
+	req.Reply <- true
+	assert.Eventually(t, func() bool { return f.State() == StateFallback }, time.Second, time.Millisecond)
+
+	want := make([]byte, 32)
+	rng.NewDRBG([]byte("fallback")).Read(want)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, want, buf)
+
+	// fallback is kept even when the primary recovers until restored
+	primary.setFailing(false)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, StateFallback, f.State())
+	f.Restore()
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 64, f.Buffered())
+	assert.Equal(t, []State{StateGrace, StateFallback, StatePrimary}, states)
+}
+
+func TestFailoverDenied(t *testing.T) {
+	primary := &switchReader{src: rng.NewDRBG(nil)}
+	denied := make(chan struct{})
+	f, err := NewFailover(primary, rng.NewDRBG(nil), func(cause error) bool {
+		defer close(denied)
+		return false
+	}, 0)
+	require.NoError(t, err)
+
+	primary.setFailing(true)
+	buf := make([]byte, 8)
+	_, err = f.Read(buf)
+	assert.True(t, errors.Is(err, ErrNotApproved))
+	<-denied
+	assert.Eventually(t, func() bool { return f.State() == StateDenied }, time.Second, time.Millisecond)
+	_, err = f.Read(buf)
+	assert.True(t, errors.Is(err, ErrNotApproved))
+
+	// primary recovery ends the outage without operator action
+	primary.setFailing(false)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, StatePrimary, f.State())
+}
+
+func TestFailoverRecoveredBeforeApproval(t *testing.T) {
+	primary := &switchReader{src: rng.NewDRBG(nil)}
+	requests := make(chan ApprovalRequest, 1)
+	f, err := NewFailover(primary, rng.NewDRBG(nil), ChannelApprover(requests), 16)
+	require.NoError(t, err)
+
+	primary.setFailing(true)
+	buf := make([]byte, 8)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	primary.setFailing(false)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+
+	// late approval of an obsolete request is ignored
+	(<-requests).Reply <- true
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, StatePrimary, f.State())
+	assert.Equal(t, 16, f.Buffered())
+}
+
+func TestNewFailoverErrors(t *testing.T) {
+	_, err := NewFailover(&switchReader{failing: true}, nil, nil, 8)
+	assert.Error(t, err)
+	_, err = NewFailover(rng.NewDRBG(nil), nil, nil, -1)
+	assert.Error(t, err)
+	assert.Equal(t, "fallback", StateFallback.String())
+	assert.Equal(t, "State(7)", State(7).String())
+}
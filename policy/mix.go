@@ -0,0 +1,180 @@
+package policy
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrHealth is wrapped by errors returned when a source fails a health test.
+var ErrHealth = errors.New("policy: health test failed")
+
+// healthReader runs continuous repetition count and adaptive proportion tests
+// on bytes read from a source. Once a test fails all reads return an error.
+type healthReader struct {
+	name string
+	src  io.Reader
+	h    Health
+
+	last   byte
+	repeat int // length of the current run of last
+	first  byte
+	count  int // occurrences of first in the current window
+	seen   int // bytes seen in the current window
+	err    error
+}
+
+func newHealthReader(name string, src io.Reader, h Health) *healthReader {
+	return &healthReader{name: name, src: src, h: h}
+}
+
+func (r *healthReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := io.ReadFull(r.src, p)
+	if err != nil {
+		return n, fmt.Errorf("policy: source %q: %w", r.name, err)
+	}
+	for _, b := range p {
+		if r.repeat > 0 && b == r.last {
+			r.repeat++
+		} else {
+			r.last, r.repeat = b, 1
+		}
+		if r.repeat >= r.h.RepetitionCutoff {
+			r.err = fmt.Errorf("%w: source %q repetition count", ErrHealth, r.name)
+		}
+
+		if r.seen == 0 {
+			r.first, r.count = b, 0
+		}
+		if b == r.first {
+			r.count++
+		}
+		r.seen++
+		if r.count >= r.h.AdaptiveCutoff {
+			r.err = fmt.Errorf("%w: source %q adaptive proportion", ErrHealth, r.name)
+		}
+		if r.seen == r.h.AdaptiveWindow {
+			r.seen = 0
+		}
+	}
+	if r.err != nil {
+		// do not leak output that failed the test
+		for i := range p {
+			p[i] = 0
+		}
+		return 0, r.err
+	}
+	return n, nil
+}
+
+// xorReader combines equal length reads from all readers with XOR.
+type xorReader struct {
+	mu      sync.Mutex
+	readers []io.Reader
+	buf     []byte
+}
+
+func (r *xorReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cap(r.buf) < len(p) {
+		r.buf = make([]byte, len(p))
+	}
+	buf := r.buf[:len(p)]
+	for i := range p {
+		p[i] = 0
+	}
+	for _, src := range r.readers {
+		if _, err := io.ReadFull(src, buf); err != nil {
+			return 0, err
+		}
+		for i := range p {
+			p[i] ^= buf[i]
+		}
+	}
+	return len(p), nil
+}
+
+// hashReader returns SHA-256 blocks of a counter followed by a block read from
+// every reader.
+type hashReader struct {
+	mu      sync.Mutex
+	readers []io.Reader
+	ctr     uint64
+	buf     [sha256.Size]byte
+}
+
+func (r *hashReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for n < len(p) {
+		h := sha256.New()
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], r.ctr)
+		r.ctr++
+		h.Write(ctr[:])
+		for _, src := range r.readers {
+			if _, err := io.ReadFull(src, r.buf[:]); err != nil {
+				return n, err
+			}
+			h.Write(r.buf[:])
+		}
+		n += copy(p[n:], h.Sum(nil))
+	}
+	return n, nil
+}
+
+// reseeder expands combined entropy with AES-256-CTR keyed from the source and
+// replaces the key when it is older than interval.
+type reseeder struct {
+	src      io.Reader
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	stream   cipher.Stream
+	seededAt time.Time
+}
+
+func newReseeder(src io.Reader, interval time.Duration) *reseeder {
+	return &reseeder{src: src, interval: interval, now: time.Now}
+}
+
+func (r *reseeder) reseed() error {
+	var seed [32 + aes.BlockSize]byte
+	if _, err := io.ReadFull(r.src, seed[:]); err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(seed[:32])
+	if err != nil {
+		return err
+	}
+	r.stream = cipher.NewCTR(block, seed[32:])
+	r.seededAt = r.now()
+	return nil
+}
+
+func (r *reseeder) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stream == nil || r.now().Sub(r.seededAt) >= r.interval {
+		if err := r.reseed(); err != nil {
+			return 0, err
+		}
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	r.stream.XORKeyStream(p, p)
+	return len(p), nil
+}
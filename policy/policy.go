@@ -0,0 +1,199 @@
+// Package policy builds entropy sources from a declarative mixing policy.
+//
+// A Policy declares which sources must be present, how their output is
+// combined, how often the output generator is reseeded and health test
+// thresholds. Keeping it in one reviewed YAML document (see Load) replaces
+// scattered constructor calls in compliance sensitive deployments:
+//
+//	sources:
+//	  - name: os
+//	    required: true
+//	  - name: hsm
+//	    required: true
+//	combine: hash
+//	reseed_interval: 10m
+//	health:
+//	  repetition_cutoff: 6
+//	  adaptive_cutoff: 62
+//	  adaptive_window: 512
+//
+// Build validates the policy against the sources available at startup and
+// returns the combined io.Reader.
+//
+// Failover guards switching from a primary certified source to a fallback
+// with explicit operator approval.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported Policy.Combine values.
+const (
+	// CombineXOR returns XOR of equal length reads from all sources.
+	CombineXOR = "xor"
+	// CombineHash returns SHA-256 of concatenated reads from all sources.
+	CombineHash = "hash"
+)
+
+// Default health test thresholds, NIST SP 800-90B cutoffs for byte samples
+// with assessed min-entropy of 4 bits per byte and false positive rate 2^-20.
+const (
+	DefaultRepetitionCutoff = 6
+	DefaultAdaptiveCutoff   = 62
+	DefaultAdaptiveWindow   = 512
+)
+
+// Policy is an entropy mixing policy.
+type Policy struct {
+	// Sources lists sources combined in declaration order.
+	Sources []SourceSpec `yaml:"sources"`
+	// Combine selects the combining function, CombineHash if empty.
+	Combine string `yaml:"combine"`
+	// ReseedInterval is the maximum age of the output generator key. If 0
+	// combined output is returned directly without an output generator.
+	ReseedInterval time.Duration `yaml:"reseed_interval"`
+	// Health configures tests run on the output of every source.
+	Health Health `yaml:"health"`
+}
+
+// SourceSpec declares a single source.
+type SourceSpec struct {
+	Name string `yaml:"name"`
+	// Required sources must be present at startup, optional missing sources
+	// are skipped.
+	Required bool `yaml:"required"`
+}
+
+// Health configures SP 800-90B repetition count and adaptive proportion tests.
+// Zero values select defaults.
+type Health struct {
+	// RepetitionCutoff is the number of identical consecutive bytes that
+	// fails the repetition count test.
+	RepetitionCutoff int `yaml:"repetition_cutoff"`
+	// AdaptiveCutoff is the number of occurrences of the first byte of a
+	// window that fails the adaptive proportion test.
+	AdaptiveCutoff int `yaml:"adaptive_cutoff"`
+	// AdaptiveWindow is the adaptive proportion test window in bytes.
+	AdaptiveWindow int `yaml:"adaptive_window"`
+}
+
+func (h Health) withDefaults() Health {
+	if h.RepetitionCutoff == 0 {
+		h.RepetitionCutoff = DefaultRepetitionCutoff
+	}
+	if h.AdaptiveCutoff == 0 {
+		h.AdaptiveCutoff = DefaultAdaptiveCutoff
+	}
+	if h.AdaptiveWindow == 0 {
+		h.AdaptiveWindow = DefaultAdaptiveWindow
+	}
+	return h
+}
+
+// Load parses and validates a YAML encoded policy. Unknown fields are
+// rejected.
+func Load(r io.Reader) (Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("policy: %w", err)
+	}
+	return p, p.Validate()
+}
+
+// Validate checks the policy for consistency, it reports all problems found.
+func (p Policy) Validate() error {
+	var errs []string
+	if len(p.Sources) == 0 {
+		errs = append(errs, "no sources declared")
+	}
+	required := 0
+	seen := make(map[string]bool)
+	for i, s := range p.Sources {
+		switch {
+		case s.Name == "":
+			errs = append(errs, fmt.Sprintf("source %d has no name", i))
+		case seen[s.Name]:
+			errs = append(errs, fmt.Sprintf("source %q declared twice", s.Name))
+		}
+		seen[s.Name] = true
+		if s.Required {
+			required++
+		}
+	}
+	if len(p.Sources) > 0 && required == 0 {
+		errs = append(errs, "at least one source must be required")
+	}
+	switch p.Combine {
+	case "", CombineXOR, CombineHash:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown combine %q", p.Combine))
+	}
+	if p.ReseedInterval < 0 {
+		errs = append(errs, "negative reseed interval")
+	}
+	h := p.Health.withDefaults()
+	if h.RepetitionCutoff < 2 {
+		errs = append(errs, "repetition cutoff must be at least 2")
+	}
+	if h.AdaptiveWindow < 2 {
+		errs = append(errs, "adaptive window must be at least 2")
+	}
+	if h.AdaptiveCutoff < 2 || h.AdaptiveCutoff > h.AdaptiveWindow {
+		errs = append(errs, "adaptive cutoff must be in [2, adaptive window]")
+	}
+	if len(errs) > 0 {
+		return errors.New("policy: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Build validates the policy and returns a reader combining given sources by
+// name. It returns an error if a required source is missing or a source not
+// declared by the policy is given.
+func (p Policy) Build(sources map[string]io.Reader) (io.Reader, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	declared := make(map[string]bool)
+	var readers []io.Reader
+	var missing []string
+	for _, s := range p.Sources {
+		declared[s.Name] = true
+		src, ok := sources[s.Name]
+		if !ok || src == nil {
+			if s.Required {
+				missing = append(missing, s.Name)
+			}
+			continue
+		}
+		readers = append(readers, newHealthReader(s.Name, src, p.Health.withDefaults()))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("policy: required sources missing: %s", strings.Join(missing, ", "))
+	}
+	for name := range sources {
+		if !declared[name] {
+			return nil, fmt.Errorf("policy: source %q is not declared", name)
+		}
+	}
+
+	var out io.Reader
+	if p.Combine == CombineXOR {
+		out = &xorReader{readers: readers}
+	} else {
+		out = &hashReader{readers: readers}
+	}
+	if p.ReseedInterval > 0 {
+		out = newReseeder(out, p.ReseedInterval)
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+sources:
+  - name: os
+    required: true
+  - name: hsm
+    required: false
+combine: xor
+reseed_interval: 10m
+health:
+  repetition_cutoff: 8
+`
+
+func TestLoad(t *testing.T) {
+	p, err := Load(strings.NewReader(testPolicy))
+	require.NoError(t, err)
+	assert.Equal(t, Policy{
+		Sources:        []SourceSpec{{Name: "os", Required: true}, {Name: "hsm"}},
+		Combine:        CombineXOR,
+		ReseedInterval: 10 * time.Minute,
+		Health:         Health{RepetitionCutoff: 8},
+	}, p)
+
+	_, err = Load(strings.NewReader("sources: []\ncombine: xor\nunknown: 1\n"))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	err := Policy{
+		Sources: []SourceSpec{{Name: "a"}, {Name: "a"}, {}},
+		Combine: "and",
+		Health:  Health{AdaptiveCutoff: 1000},
+	}.Validate()
+	require.Error(t, err)
+	for _, msg := range []string{
+		`source "a" declared twice`,
+		"source 2 has no name",
+		"at least one source must be required",
+		`unknown combine "and"`,
+		"adaptive cutoff",
+	} {
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	p := Policy{Sources: []SourceSpec{{Name: "os", Required: true}, {Name: "hsm"}}}
+
+	_, err := p.Build(map[string]io.Reader{"hsm": rand.Reader})
+	assert.EqualError(t, err, "policy: required sources missing: os")
+	_, err = p.Build(map[string]io.Reader{"os": rand.Reader, "kms": rand.Reader})
+	assert.EqualError(t, err, `policy: source "kms" is not declared`)
+
+	src, err := p.Build(map[string]io.Reader{"os": rand.Reader})
+	require.NoError(t, err)
+	g := rng.New(src)
+	assert.Len(t, g.Perm(52), 52)
+}
+
+func TestCombineXOR(t *testing.T) {
+	p := Policy{Sources: []SourceSpec{{Name: "a", Required: true}, {Name: "b", Required: true}}, Combine: CombineXOR}
+	src, err := p.Build(map[string]io.Reader{
+		"a": bytes.NewReader([]byte{0x0f, 0x10, 0x20}),
+		"b": bytes.NewReader([]byte{0xf0, 0x01, 0x02}),
+	})
+	require.NoError(t, err)
+	b := make([]byte, 3)
+	_, err = src.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0x11, 0x22}, b)
+}
+
+func TestHealthFailure(t *testing.T) {
+	p := Policy{Sources: []SourceSpec{{Name: "stuck", Required: true}}}
+	src, err := p.Build(map[string]io.Reader{"stuck": bytes.NewReader(make([]byte, 1024))})
+	require.NoError(t, err)
+	_, err = src.Read(make([]byte, 8))
+	assert.True(t, errors.Is(err, ErrHealth))
+	// failure is permanent
+	_, err = src.Read(make([]byte, 8))
+	assert.True(t, errors.Is(err, ErrHealth))
+
+	// adaptive proportion test catches a biased source with short runs
+	biased := bytes.Repeat([]byte{7, 7, 1, 7, 2, 7, 3, 7}, 64)
+	r := newHealthReader("biased", bytes.NewReader(biased), Health{}.withDefaults())
+	_, err = r.Read(make([]byte, 512))
+	assert.EqualError(t, err, `policy: health test failed: source "biased" adaptive proportion`)
+}
+
+func TestReseed(t *testing.T) {
+	now := time.Unix(0, 0)
+	counter := 0
+	src := readerFunc(func(p []byte) (int, error) {
+		counter++
+		return rand.Read(p)
+	})
+	r := newReseeder(src, time.Minute)
+	r.now = func() time.Time { return now }
+
+	b := make([]byte, 64)
+	_, _ = r.Read(b)
+	_, _ = r.Read(b)
+	assert.Equal(t, 1, counter)
+	now = now.Add(time.Minute)
+	_, _ = r.Read(b)
+	assert.Equal(t, 2, counter)
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
@@ -0,0 +1,334 @@
+// Package probe periodically samples entropy sources and tracks their
+// latency, error rate and byte statistics, so a degrading source (e.g. an HSM
+// slowing down) is noticed before draws start failing.
+//
+// A Prober implements expvar.Var, publish it to expose statistics of all
+// sources:
+//
+//	p := probe.New(time.Minute, probe.Target{Name: "hsm", Source: hsmSource})
+//	expvar.Publish("rng_sources", p)
+//	go p.Run(ctx)
+//
+// HealthCheck reports sources failing configured thresholds, OnResult can
+// feed other metrics systems.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults used for zero Target and Prober fields.
+const (
+	DefaultSize    = 64
+	DefaultTimeout = 5 * time.Second
+	DefaultWindow  = 100
+)
+
+var (
+	// ErrTimeout is recorded when a read does not finish within the
+	// timeout.
+	ErrTimeout = errors.New("probe: read timed out")
+	// ErrConstant is recorded when all bytes of a sample are equal, the
+	// typical output of a stuck source.
+	ErrConstant = errors.New("probe: constant output")
+)
+
+// Target is a probed source.
+type Target struct {
+	Name   string
+	Source io.Reader
+	// Size is the number of bytes read per probe, DefaultSize if 0.
+	Size int
+}
+
+// Result is the outcome of a single probe.
+type Result struct {
+	Name    string
+	Time    time.Time
+	Latency time.Duration
+	Err     error
+}
+
+// Stats are statistics of a single source.
+type Stats struct {
+	// Probes and Errors count all probes and failed probes.
+	Probes uint64 `json:"probes"`
+	Errors uint64 `json:"errors"`
+	// ErrorRate is the share of failed probes among the last Window ones.
+	ErrorRate float64 `json:"error_rate"`
+	// LastProbe, LastLatency and LastError describe the latest probe.
+	LastProbe   time.Time     `json:"last_probe"`
+	LastLatency time.Duration `json:"last_latency"`
+	LastError   string        `json:"last_error,omitempty"`
+	// MeanLatency is the mean latency of successful probes.
+	MeanLatency time.Duration `json:"mean_latency"`
+	// Bytes is the number of sampled bytes, MeanByte their mean value
+	// (127.5 expected) and OnesRatio the share of set bits (0.5 expected).
+	Bytes     uint64  `json:"bytes"`
+	MeanByte  float64 `json:"mean_byte"`
+	OnesRatio float64 `json:"ones_ratio"`
+}
+
+// Prober samples targets periodically. Fields must not be changed after Run
+// is called. Prober is safe for concurrent use.
+type Prober struct {
+	// Interval is the time between probes of every target.
+	Interval time.Duration
+	// Timeout limits the duration of a single read, DefaultTimeout if 0.
+	// Reads can not be interrupted, a target is not probed again until a
+	// timed out read returns.
+	Timeout time.Duration
+	// Window is the number of latest probes ErrorRate is computed over,
+	// DefaultWindow if 0.
+	Window int
+	// MaxLatency and MaxErrorRate are HealthCheck thresholds, zero values
+	// disable the check.
+	MaxLatency   time.Duration
+	MaxErrorRate float64
+	// OnResult, if set, is called with the result of every probe.
+	OnResult func(Result)
+
+	targets []Target
+	mu      sync.Mutex
+	state   map[string]*state
+}
+
+type state struct {
+	stats   Stats
+	busy    bool
+	recent  []bool // failure flags of latest probes, ring buffer
+	next    int
+	sum     uint64 // sum of sampled byte values
+	ones    uint64 // number of set bits in sampled bytes
+	latency time.Duration
+	ok      uint64 // successful probes
+}
+
+// New returns a Prober sampling targets every interval. It will panic if
+// interval <= 0 or target names are empty or not unique.
+func New(interval time.Duration, targets ...Target) *Prober {
+	if interval <= 0 {
+		panic("invalid argument to probe.New, interval must be positive")
+	}
+	p := &Prober{
+		Interval: interval,
+		targets:  append([]Target(nil), targets...),
+		state:    make(map[string]*state),
+	}
+	for _, t := range targets {
+		if _, ok := p.state[t.Name]; t.Name == "" || ok {
+			panic("invalid argument to probe.New, duplicate or empty target name")
+		}
+		p.state[t.Name] = &state{}
+	}
+	return p
+}
+
+// Run probes all targets immediately and then every Interval until ctx is
+// done. It returns the context error.
+func (p *Prober) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		p.Probe()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe samples every target once and waits for the reads to finish or time
+// out. Targets still busy with a timed out read are skipped.
+func (p *Prober) Probe() {
+	var wg sync.WaitGroup
+	for _, t := range p.targets {
+		p.mu.Lock()
+		st := p.state[t.Name]
+		busy := st.busy
+		st.busy = true
+		p.mu.Unlock()
+		if busy {
+			continue
+		}
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			p.probe(t)
+		}(t)
+	}
+	wg.Wait()
+}
+
+func (p *Prober) probe(t Target) {
+	size := t.Size
+	if size <= 0 {
+		size = DefaultSize
+	}
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	type read struct {
+		buf     []byte
+		latency time.Duration
+		err     error
+	}
+	done := make(chan read, 1)
+	start := time.Now()
+	go func() {
+		buf := make([]byte, size)
+		_, err := io.ReadFull(t.Source, buf)
+		done <- read{buf: buf, latency: time.Since(start), err: err}
+	}()
+
+	res := Result{Name: t.Name, Time: start}
+	var buf []byte
+	select {
+	case r := <-done:
+		res.Latency, res.Err, buf = r.latency, r.err, r.buf
+		if r.err == nil && constant(r.buf) {
+			res.Err = ErrConstant
+		}
+		p.record(res, buf, true)
+	case <-time.After(timeout):
+		res.Latency, res.Err = timeout, ErrTimeout
+		p.record(res, nil, false)
+		go func() {
+			// keep the target busy until the read returns
+			<-done
+			p.mu.Lock()
+			p.state[t.Name].busy = false
+			p.mu.Unlock()
+		}()
+	}
+	if p.OnResult != nil {
+		p.OnResult(res)
+	}
+}
+
+// record updates target statistics with a probe result. If finished the
+// target is no longer busy.
+func (p *Prober) record(res Result, buf []byte, finished bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st := p.state[res.Name]
+	if finished {
+		st.busy = false
+	}
+	window := p.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if len(st.recent) < window {
+		st.recent = append(st.recent, res.Err != nil)
+	} else {
+		st.recent[st.next%window] = res.Err != nil
+	}
+	st.next++
+	failed := 0
+	for _, f := range st.recent {
+		if f {
+			failed++
+		}
+	}
+
+	s := &st.stats
+	s.Probes++
+	s.ErrorRate = float64(failed) / float64(len(st.recent))
+	s.LastProbe = res.Time
+	s.LastLatency = res.Latency
+	s.LastError = ""
+	if res.Err != nil {
+		s.Errors++
+		s.LastError = res.Err.Error()
+		return
+	}
+
+	st.ok++
+	st.latency += res.Latency
+	s.MeanLatency = st.latency / time.Duration(st.ok)
+	for _, b := range buf {
+		st.sum += uint64(b)
+		for ; b != 0; b &= b - 1 {
+			st.ones++
+		}
+	}
+	s.Bytes += uint64(len(buf))
+	s.MeanByte = float64(st.sum) / float64(s.Bytes)
+	s.OnesRatio = float64(st.ones) / float64(8*s.Bytes)
+}
+
+func constant(b []byte) bool {
+	for _, c := range b[1:] {
+		if c != b[0] {
+			return false
+		}
+	}
+	return len(b) > 1
+}
+
+// Stats returns statistics of a target.
+func (p *Prober) Stats(name string) (Stats, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st, ok := p.state[name]
+	if !ok {
+		return Stats{}, false
+	}
+	return st.stats, true
+}
+
+// Snapshot returns statistics of all targets by name.
+func (p *Prober) Snapshot() map[string]Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := make(map[string]Stats, len(p.state))
+	for name, st := range p.state {
+		m[name] = st.stats
+	}
+	return m
+}
+
+// String returns a JSON encoded Snapshot, implementing expvar.Var.
+func (p *Prober) String() string {
+	b, err := json.Marshal(p.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// HealthCheck returns an error listing targets whose last probe failed,
+// whose last latency exceeds MaxLatency or whose error rate exceeds
+// MaxErrorRate. Targets not probed yet are considered healthy.
+func (p *Prober) HealthCheck() error {
+	var problems []string
+	for name, s := range p.Snapshot() {
+		switch {
+		case s.Probes == 0:
+		case s.LastError != "":
+			problems = append(problems, fmt.Sprintf("%s: %s", name, s.LastError))
+		case p.MaxLatency > 0 && s.LastLatency > p.MaxLatency:
+			problems = append(problems, fmt.Sprintf("%s: latency %v above %v", name, s.LastLatency, p.MaxLatency))
+		case p.MaxErrorRate > 0 && s.ErrorRate > p.MaxErrorRate:
+			problems = append(problems, fmt.Sprintf("%s: error rate %.3f above %.3f", name, s.ErrorRate, p.MaxErrorRate))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("probe: unhealthy sources: %s", strings.Join(problems, "; "))
+}
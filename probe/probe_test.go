@@ -0,0 +1,169 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ expvar.Var = (*Prober)(nil)
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("token removed")
+}
+
+// blockingReader blocks reads from src until release is closed.
+type blockingReader struct {
+	src     io.Reader
+	release chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	<-r.release
+	return r.src.Read(p)
+}
+
+func TestProbe(t *testing.T) {
+	var mu sync.Mutex
+	var results []Result
+	p := New(time.Minute,
+		Target{Name: "drbg", Source: rng.NewDRBG([]byte("probe")), Size: 4096},
+		Target{Name: "broken", Source: errReader{}},
+		Target{Name: "stuck", Source: bytes.NewReader(make([]byte, 1<<20))},
+	)
+	p.OnResult = func(r Result) {
+		mu.Lock()
+		results = append(results, r)
+		mu.Unlock()
+	}
+	for i := 0; i < 10; i++ {
+		p.Probe()
+	}
+	assert.Len(t, results, 30)
+
+	s, ok := p.Stats("drbg")
+	require.True(t, ok)
+	assert.Equal(t, uint64(10), s.Probes)
+	assert.Zero(t, s.Errors)
+	assert.Zero(t, s.ErrorRate)
+	assert.Equal(t, uint64(40960), s.Bytes)
+	assert.InDelta(t, 127.5, s.MeanByte, 1)
+	assert.InDelta(t, 0.5, s.OnesRatio, 0.005)
+	assert.Empty(t, s.LastError)
+
+	s, _ = p.Stats("broken")
+	assert.Equal(t, uint64(10), s.Errors)
+	assert.Equal(t, 1.0, s.ErrorRate)
+	assert.Equal(t, "token removed", s.LastError)
+
+	s, _ = p.Stats("stuck")
+	assert.Equal(t, ErrConstant.Error(), s.LastError)
+	assert.Zero(t, s.Bytes)
+
+	_, ok = p.Stats("missing")
+	assert.False(t, ok)
+
+	err := p.HealthCheck()
+	assert.EqualError(t, err, "probe: unhealthy sources: broken: token removed; stuck: probe: constant output")
+
+	var snapshot map[string]Stats
+	require.NoError(t, json.Unmarshal([]byte(p.String()), &snapshot))
+	assert.Len(t, snapshot, 3)
+	assert.Equal(t, uint64(10), snapshot["drbg"].Probes)
+}
+
+func TestProbeTimeout(t *testing.T) {
+	r := &blockingReader{src: rng.NewDRBG(nil), release: make(chan struct{})}
+	p := New(time.Minute, Target{Name: "slow", Source: r})
+	p.Timeout = 10 * time.Millisecond
+	p.Probe()
+	s, _ := p.Stats("slow")
+	assert.Equal(t, ErrTimeout.Error(), s.LastError)
+
+	// busy target is skipped until the read returns
+	p.Probe()
+	s, _ = p.Stats("slow")
+	assert.Equal(t, uint64(1), s.Probes)
+
+	close(r.release)
+	assert.Eventually(t, func() bool {
+		p.Probe()
+		s, _ = p.Stats("slow")
+		return s.Probes == 2
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, s.LastError)
+	assert.Equal(t, 0.5, s.ErrorRate)
+}
+
+func TestHealthCheckThresholds(t *testing.T) {
+	src := &flakyReader{src: rng.NewDRBG(nil)}
+	p := New(time.Minute, Target{Name: "flaky", Source: src})
+	p.Window = 4
+	assert.NoError(t, p.HealthCheck(), "not probed yet")
+
+	for _, fail := range []bool{true, false, true, false} {
+		src.fail = fail
+		p.Probe()
+	}
+	s, _ := p.Stats("flaky")
+	assert.Equal(t, 0.5, s.ErrorRate)
+	assert.NoError(t, p.HealthCheck())
+
+	p.MaxErrorRate = 0.25
+	assert.EqualError(t, p.HealthCheck(), "probe: unhealthy sources: flaky: error rate 0.500 above 0.250")
+
+	p.MaxErrorRate = 0
+	p.MaxLatency = time.Nanosecond
+	src.delay = time.Millisecond
+	p.Probe()
+	assert.Error(t, p.HealthCheck())
+
+	// window keeps only the latest probes
+	src.delay = 0
+	for i := 0; i < 4; i++ {
+		p.Probe()
+	}
+	s, _ = p.Stats("flaky")
+	assert.Zero(t, s.ErrorRate)
+	assert.Equal(t, uint64(2), s.Errors)
+}
+
+type flakyReader struct {
+	src   io.Reader
+	fail  bool
+	delay time.Duration
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	if r.fail {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return r.src.Read(p)
+}
+
+func TestRun(t *testing.T) {
+	p := New(5*time.Millisecond, Target{Name: "drbg", Source: rng.NewDRBG(nil)})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, p.Run(ctx))
+	s, _ := p.Stats("drbg")
+	assert.True(t, s.Probes >= 2)
+
+	assert.Panics(t, func() { New(time.Second, Target{Name: "a"}, Target{Name: "a"}) })
+	assert.Panics(t, func() { New(time.Second, Target{}) })
+	assert.Panics(t, func() { New(0, Target{Name: "a"}) })
+	assert.Panics(t, func() { New(-time.Second) })
+}
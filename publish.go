@@ -0,0 +1,165 @@
+package rng
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"time"
+)
+
+// Publisher receives a DrawResult for every draw made by a
+// PublishingGenerator. Implementations for message buses live in the publish
+// subpackage.
+type Publisher interface {
+	Publish(d *DrawResult) error
+}
+
+// PublisherFunc adapts an ordinary function to the Publisher interface.
+type PublisherFunc func(d *DrawResult) error
+
+// Publish calls f(d).
+func (f PublisherFunc) Publish(d *DrawResult) error {
+	return f(d)
+}
+
+// PublishingGenerator is a Generator decorator emitting a DrawResult for each
+// draw to a Publisher. Draws are made exactly as by the wrapped Generator, so
+// for the same source outcomes are identical.
+//
+// PublishingGenerator is safe for concurrent use if the source and publisher
+// are.
+type PublishingGenerator struct {
+	g   *Generator
+	pub Publisher
+
+	// OnError is called with publish errors. Draw outcomes are returned
+	// even if publishing fails. If nil errors are ignored.
+	OnError func(error)
+}
+
+var _ Interface = (*PublishingGenerator)(nil)
+
+// NewPublishing returns a PublishingGenerator drawing from g and publishing
+// results to pub.
+func NewPublishing(g *Generator, pub Publisher) *PublishingGenerator {
+	return &PublishingGenerator{g: g, pub: pub}
+}
+
+// Intn returns a non negative int in [0, n), see ReadIntn.
+func (p *PublishingGenerator) Intn(n int) int {
+	var r int
+	p.draw("intn", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		r = ReadIntn(src, n)
+		return []int64{int64(r)}
+	})
+	return r
+}
+
+// Float64 returns a random number in [0.0,1.0), see ReadFloat64. Published
+// outcome is the raw 53-bit integer.
+func (p *PublishingGenerator) Float64() float64 {
+	var r uint64
+	p.draw("float64", nil, func(src io.Reader) []int64 {
+		r = ReadUint64Bits(src, 53)
+		return []int64{int64(r)}
+	})
+	return float64(r) / float64(1<<53)
+}
+
+// Perm returns a random permutation of integers [0,n), see ReadPerm.
+func (p *PublishingGenerator) Perm(n int) []int {
+	var r []int
+	p.draw("perm", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		r = ReadPerm(src, n)
+		return intsToInt64s(r)
+	})
+	return r
+}
+
+// Sample returns random k integers from a range [0 n), see ReadSample.
+func (p *PublishingGenerator) Sample(n int, k int) []int {
+	var r []int
+	p.draw("sample", map[string]int64{"n": int64(n), "k": int64(k)}, func(src io.Reader) []int64 {
+		r = ReadSample(src, n, k)
+		return intsToInt64s(r)
+	})
+	return r
+}
+
+// Shuffle randomizes the order of n elements, see ReadShuffle. Published
+// outcome lists swap indexes j for i = n-1 down to 1.
+func (p *PublishingGenerator) Shuffle(n int, swap func(i, j int)) {
+	var js []int64
+	p.draw("shuffle", map[string]int64{"n": int64(n)}, func(src io.Reader) []int64 {
+		ReadShuffle(src, n, func(i, j int) {
+			js = append(js, int64(j))
+		})
+		return js
+	})
+	for k, i := 0, n-1; i > 0; k, i = k+1, i-1 {
+		swap(i, int(js[k]))
+	}
+}
+
+// draw runs fn reading from a digesting source and publishes the result.
+func (p *PublishingGenerator) draw(typ string, params map[string]int64, fn func(src io.Reader) []int64) {
+	d := runDraw(p.g.reader(), typ, params, fn)
+	if err := p.pub.Publish(d); err != nil && p.OnError != nil {
+		p.OnError(err)
+	}
+}
+
+// runDraw runs fn reading from src and returns a DrawResult with a fresh ID,
+// timestamps and digest of consumed entropy. fn must implement the current
+// algorithm of registered types, its ID is recorded in the result. The
+// encoding of src, see EncodedSource, is kept.
+func runDraw(src io.Reader, typ string, params map[string]int64, fn func(src io.Reader) []int64) *DrawResult {
+	d := &DrawResult{
+		ID:        NewDrawID(),
+		Type:      typ,
+		Params:    params,
+		StartedAt: time.Now().UTC(),
+	}
+	if a, ok := CurrentAlgorithm(typ); ok {
+		d.Algorithm = a.ID
+	}
+	src, enc := sourceEncoding(src)
+	dr := &digestReader{src: src, h: sha256.New()}
+	d.Outcome = fn(EncodedSource(dr, enc))
+	d.EntropyDigest = dr.h.Sum(nil)
+	d.FinishedAt = time.Now().UTC()
+	return d
+}
+
+// NewDrawID returns a random 128-bit hex encoded draw ID, as used by
+// DrawResult values of this package. IDs are read from crypto/rand directly so
+// they never consume entropy of the draw source.
+func NewDrawID() string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// digestReader hashes all bytes read from src.
+type digestReader struct {
+	src io.Reader
+	h   hash.Hash
+}
+
+func (r *digestReader) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	r.h.Write(p[:n])
+	return n, err
+}
+
+func intsToInt64s(s []int) []int64 {
+	out := make([]int64, len(s))
+	for i, v := range s {
+		out[i] = int64(v)
+	}
+	return out
+}
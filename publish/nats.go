@@ -0,0 +1,143 @@
+package publish
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/advbet/rng"
+)
+
+// dialTimeout limits connecting to and handshaking with a NATS server.
+const dialTimeout = 10 * time.Second
+
+// NATS publishes draw results to a NATS subject using the core NATS text
+// protocol. Only the features needed for publishing are implemented: no TLS,
+// no authentication and no reconnects. NATS is safe for concurrent use.
+type NATS struct {
+	subject string
+	conn    net.Conn
+
+	mu  sync.Mutex // guards w and err
+	w   *bufio.Writer
+	err error // first server or connection error, closes the publisher
+}
+
+var _ rng.Publisher = (*NATS)(nil)
+
+// DialNATS connects to a NATS server at addr (host:port) and returns a
+// publisher sending draw results to subject.
+func DialNATS(addr, subject string) (*NATS, error) {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("publish: invalid NATS subject %q", subject)
+	}
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &NATS{
+		subject: subject,
+		conn:    conn,
+		w:       bufio.NewWriter(conn),
+	}
+	r := bufio.NewReader(conn)
+	if err := n.handshake(r); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go n.readLoop(r)
+	return n, nil
+}
+
+// handshake reads server INFO, sends CONNECT and waits for PONG to make sure
+// the server accepted the connection.
+func (n *NATS) handshake(r *bufio.Reader) error {
+	n.conn.SetDeadline(time.Now().Add(dialTimeout))
+	defer n.conn.SetDeadline(time.Time{})
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("publish: unexpected NATS greeting %q", strings.TrimSpace(line))
+	}
+	if _, err := n.conn.Write([]byte("CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"advbet/rng\"}\r\nPING\r\n")); err != nil {
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("publish: NATS server error: %s", line)
+		}
+	}
+}
+
+// readLoop answers server PINGs and records server errors.
+func (n *NATS) readLoop(r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			n.fail(err)
+			return
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PING":
+			n.mu.Lock()
+			n.w.WriteString("PONG\r\n")
+			n.w.Flush()
+			n.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			n.fail(fmt.Errorf("publish: NATS server error: %s", line))
+			return
+		}
+	}
+}
+
+func (n *NATS) fail(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err == nil {
+		n.err = err
+	}
+}
+
+// Publish sends canonical draw result to the subject.
+func (n *NATS) Publish(d *rng.DrawResult) error {
+	payload := d.Canonical()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	fmt.Fprintf(n.w, "PUB %s %d\r\n", n.subject, len(payload))
+	n.w.Write(payload)
+	n.w.WriteString("\r\n")
+	if err := n.w.Flush(); err != nil {
+		n.err = err
+		return err
+	}
+	return nil
+}
+
+// Close closes the server connection.
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	if n.err == nil {
+		n.err = errors.New("publish: NATS publisher closed")
+	}
+	n.mu.Unlock()
+	return n.conn.Close()
+}
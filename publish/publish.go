@@ -0,0 +1,38 @@
+// Package publish provides rng.Publisher implementations emitting draw results
+// to message buses.
+//
+// Messages carry the canonical JSON encoding of rng.DrawResult (see
+// DrawResult.Canonical) and are keyed by draw ID where the bus supports keys.
+package publish
+
+import (
+	"context"
+
+	"github.com/advbet/rng"
+)
+
+// KafkaProducer is the subset of a Kafka client used by Kafka publisher. It is
+// implemented by small adapters around any Kafka client library (sarama,
+// franz-go, kafka-go), keeping this module free of their dependencies.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Kafka publishes draw results to a Kafka topic.
+type Kafka struct {
+	producer KafkaProducer
+	topic    string
+}
+
+var _ rng.Publisher = (*Kafka)(nil)
+
+// NewKafka returns a Kafka publisher producing to topic.
+func NewKafka(producer KafkaProducer, topic string) *Kafka {
+	return &Kafka{producer: producer, topic: topic}
+}
+
+// Publish produces a message keyed by draw ID with canonical draw result as
+// value.
+func (k *Kafka) Publish(d *rng.DrawResult) error {
+	return k.producer.Produce(context.Background(), k.topic, []byte(d.ID), d.Canonical())
+}
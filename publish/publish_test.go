@@ -0,0 +1,108 @@
+package publish
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+type producerFunc func(ctx context.Context, topic string, key, value []byte) error
+
+func (f producerFunc) Produce(ctx context.Context, topic string, key, value []byte) error {
+	return f(ctx, topic, key, value)
+}
+
+func TestKafka(t *testing.T) {
+	d := &rng.DrawResult{ID: "draw-1", Type: "intn", Outcome: []int64{3}}
+	var got []string
+	k := NewKafka(producerFunc(func(ctx context.Context, topic string, key, value []byte) error {
+		got = append(got, topic, string(key), string(value))
+		return nil
+	}), "draws")
+
+	assert.NoError(t, k.Publish(d))
+	assert.Equal(t, []string{"draws", "draw-1", string(d.Canonical())}, got)
+}
+
+// fakeNATS accepts a single connection, performs the handshake and sends
+// received PUB payloads to the returned channel.
+func fakeNATS(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("can not listen on localhost:", err)
+	}
+	msgs := make(chan string, 10)
+	go func() {
+		defer l.Close()
+		defer close(msgs)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"fake\"}\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch f := strings.Fields(line); f[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+				// server initiated ping must be answered
+				fmt.Fprint(conn, "PING\r\n")
+			case "PUB":
+				var size int
+				fmt.Sscan(f[2], &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				msgs <- f[1] + " " + string(payload[:size])
+			case "PONG":
+				msgs <- "PONG"
+			}
+		}
+	}()
+	return l.Addr().String(), msgs
+}
+
+func TestNATS(t *testing.T) {
+	addr, msgs := fakeNATS(t)
+	n, err := DialNATS(addr, "rng.draws")
+	assert.NoError(t, err)
+	defer n.Close()
+
+	select {
+	case m := <-msgs:
+		assert.Equal(t, "PONG", m)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server PING was not answered")
+	}
+
+	d := &rng.DrawResult{ID: "draw-1", Type: "intn", Outcome: []int64{3}}
+	assert.NoError(t, n.Publish(d))
+	select {
+	case m := <-msgs:
+		assert.Equal(t, "rng.draws "+string(d.Canonical()), m)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	assert.NoError(t, n.Close())
+	assert.Error(t, n.Publish(d))
+}
+
+func TestDialNATSInvalidSubject(t *testing.T) {
+	_, err := DialNATS("127.0.0.1:1", "bad subject")
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package rng
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishingGenerator(t *testing.T) {
+	var published []*DrawResult
+	p := NewPublishing(New(NewDRBG([]byte("pub"))), PublisherFunc(func(d *DrawResult) error {
+		published = append(published, d)
+		return nil
+	}))
+	g := New(NewDRBG([]byte("pub")))
+
+	assert.Equal(t, g.Intn(100), p.Intn(100))
+	assert.Equal(t, g.Float64(), p.Float64())
+	assert.Equal(t, g.Perm(5), p.Perm(5))
+	assert.Equal(t, g.Sample(49, 6), p.Sample(49, 6))
+
+	sa := []int{0, 1, 2, 3}
+	sb := []int{0, 1, 2, 3}
+	g.Shuffle(len(sa), func(i, j int) { sa[i], sa[j] = sa[j], sa[i] })
+	p.Shuffle(len(sb), func(i, j int) { sb[i], sb[j] = sb[j], sb[i] })
+	assert.Equal(t, sa, sb)
+
+	assert.Len(t, published, 5)
+	assert.Equal(t, "intn", published[0].Type)
+	assert.Equal(t, map[string]int64{"n": 100}, published[0].Params)
+	assert.Len(t, published[0].Outcome, 1)
+	assert.Equal(t, "sample", published[3].Type)
+	assert.Len(t, published[3].Outcome, 6)
+	assert.Len(t, published[4].Outcome, 3)
+
+	ids := make(map[string]bool)
+	for _, d := range published {
+		ids[d.ID] = true
+		assert.Len(t, d.EntropyDigest, sha256.Size)
+		assert.False(t, d.FinishedAt.Before(d.StartedAt))
+	}
+	assert.Len(t, ids, 5)
+
+	// intn(100) reads a single byte, digest must be its hash
+	b := make([]byte, 1)
+	NewDRBG([]byte("pub")).Read(b)
+	digest := sha256.Sum256(b)
+	assert.Equal(t, digest[:], published[0].EntropyDigest)
+}
+
+func TestPublishingGeneratorError(t *testing.T) {
+	var errs []error
+	p := NewPublishing(New(nil), PublisherFunc(func(d *DrawResult) error {
+		return errors.New("bus down")
+	}))
+	n := p.Intn(10)
+	assert.True(t, n >= 0 && n < 10)
+
+	p.OnError = func(err error) {
+		errs = append(errs, err)
+	}
+	p.Intn(10)
+	assert.Len(t, errs, 1)
+}
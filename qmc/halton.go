@@ -0,0 +1,109 @@
+package qmc
+
+import (
+	"io"
+	"math"
+
+	"github.com/advbet/rng"
+)
+
+// MaxHaltonDim is the maximum dimension of Halton sequences.
+const MaxHaltonDim = 64
+
+// Halton generates points of a Halton sequence with random digit
+// permutations. Halton is not safe for concurrent use.
+type Halton struct {
+	bases []uint64
+	// perms[j][d] permutes digit d of dimension j, digits past the last
+	// table are not used
+	perms [][][]int
+	index uint64
+}
+
+// NewHalton returns a Halton sequence of dim dimensions scrambled with
+// randomness from rng.DefaultSource(), see ReadHalton.
+func NewHalton(dim int) *Halton {
+	return ReadHalton(rng.DefaultSource(), dim)
+}
+
+// ReadHalton returns a Halton sequence of dim dimensions scrambled with
+// randomness read from a given source. Dimension j uses the j-th prime as
+// base and every digit position gets an independent random permutation of the
+// base digits, including zero, which removes the correlation between high
+// dimensions of the plain sequence. Digits are generated up to 2^-53
+// precision. If src is nil the sequence is not scrambled. It will panic if dim
+// is not in [1, MaxHaltonDim].
+func ReadHalton(src io.Reader, dim int) *Halton {
+	if dim < 1 || dim > MaxHaltonDim {
+		panic("invalid argument to NewHalton")
+	}
+	h := &Halton{
+		bases: primes(dim),
+		perms: make([][][]int, dim),
+	}
+	for j, b := range h.bases {
+		digits := int(math.Ceil(53 / math.Log2(float64(b))))
+		h.perms[j] = make([][]int, digits)
+		for d := range h.perms[j] {
+			if src == nil {
+				h.perms[j][d] = identity(int(b))
+			} else {
+				h.perms[j][d] = rng.ReadPerm(src, int(b))
+			}
+		}
+	}
+	return h
+}
+
+// Dim returns the number of dimensions.
+func (h *Halton) Dim() int {
+	return len(h.bases)
+}
+
+// Next returns the next point in [0, 1)^dim.
+func (h *Halton) Next() []float64 {
+	p := make([]float64, len(h.bases))
+	for j, b := range h.bases {
+		n := h.index
+		scale := 1 / float64(b)
+		x := 0.0
+		for _, perm := range h.perms[j] {
+			x += float64(perm[n%b]) * scale
+			n /= b
+			scale /= float64(b)
+		}
+		// guard against rounding up to 1 when all digits are b-1
+		p[j] = math.Min(x, math.Nextafter(1, 0))
+	}
+	h.index++
+	return p
+}
+
+func identity(n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	return p
+}
+
+// primes returns the first n primes.
+func primes(n int) []uint64 {
+	ps := make([]uint64, 0, n)
+	for c := uint64(2); len(ps) < n; c++ {
+		prime := true
+		for _, p := range ps {
+			if p*p > c {
+				break
+			}
+			if c%p == 0 {
+				prime = false
+				break
+			}
+		}
+		if prime {
+			ps = append(ps, c)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,48 @@
+package qmc
+
+import (
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaltonUnscrambled(t *testing.T) {
+	h := ReadHalton(nil, 2)
+	assert.Equal(t, 2, h.Dim())
+	want := [][]float64{{0, 0}, {0.5, 1.0 / 3}, {0.25, 2.0 / 3}, {0.75, 1.0 / 9}}
+	for _, w := range want {
+		p := h.Next()
+		assert.InDelta(t, w[0], p[0], 1e-12)
+		assert.InDelta(t, w[1], p[1], 1e-12)
+	}
+}
+
+func TestHaltonScrambled(t *testing.T) {
+	h := ReadHalton(rng.NewDRBG([]byte("halton")), MaxHaltonDim)
+	// first 3^4 points of the base 3 dimension are stratified in 81 cells
+	seen := make(map[int]bool)
+	for i := 0; i < 81; i++ {
+		p := h.Next()
+		for _, x := range p {
+			assert.True(t, x >= 0 && x < 1)
+		}
+		cell := int(p[1] * 81)
+		assert.False(t, seen[cell], "cell %d", cell)
+		seen[cell] = true
+	}
+
+	assert.Equal(t, []uint64{2, 3, 5, 7, 11, 13}, primes(6))
+	assert.Panics(t, func() { NewHalton(MaxHaltonDim + 1) })
+}
+
+func TestHaltonIntegration(t *testing.T) {
+	h := NewHalton(3)
+	sum := 0.0
+	const n = 5000
+	for i := 0; i < n; i++ {
+		p := h.Next()
+		sum += p[0] * p[1] * p[2]
+	}
+	assert.InDelta(t, 0.125, sum/n, 2e-3)
+}
@@ -0,0 +1,140 @@
+// Package qmc generates randomized quasi-random (low-discrepancy) point
+// sequences for variance reduction in Monte Carlo simulations.
+//
+// Sobol and Halton sequences cover the unit hypercube more evenly than
+// independent random points. Scrambling randomizes them with an rng source,
+// so estimates stay unbiased and their error can be assessed from independent
+// replications, each scrambled with fresh randomness.
+package qmc
+
+import (
+	"io"
+	"math/bits"
+
+	"github.com/advbet/rng"
+)
+
+// sobolPoly holds primitive polynomial degree s, coefficients a and initial
+// direction numbers m of Sobol dimensions 2 and up (Joe and Kuo,
+// new-joe-kuo-6.21201). Dimension 1 is the van der Corput sequence.
+var sobolPoly = []struct {
+	s int
+	a uint
+	m []uint64
+}{
+	{1, 0, []uint64{1}},
+	{2, 1, []uint64{1, 3}},
+	{3, 1, []uint64{1, 3, 1}},
+	{3, 2, []uint64{1, 1, 1}},
+	{4, 1, []uint64{1, 1, 3, 3}},
+	{4, 4, []uint64{1, 3, 5, 13}},
+	{5, 2, []uint64{1, 1, 5, 5, 17}},
+	{5, 4, []uint64{1, 1, 5, 5, 5}},
+	{5, 7, []uint64{1, 1, 7, 11, 19}},
+	{5, 11, []uint64{1, 1, 5, 1, 1}},
+	{5, 13, []uint64{1, 1, 1, 3, 11}},
+	{5, 14, []uint64{1, 3, 5, 5, 31}},
+	{6, 1, []uint64{1, 3, 3, 9, 7, 49}},
+	{6, 13, []uint64{1, 1, 1, 15, 21, 21}},
+	{6, 16, []uint64{1, 3, 1, 13, 27, 49}},
+}
+
+// MaxSobolDim is the maximum dimension of Sobol sequences.
+const MaxSobolDim = 16
+
+// Sobol generates points of a scrambled Sobol sequence. Sobol is not safe for
+// concurrent use.
+type Sobol struct {
+	v     [][64]uint64 // direction numbers v[dim][bit], most significant first
+	x     []uint64     // current point
+	index uint64
+}
+
+// NewSobol returns a Sobol sequence of dim dimensions scrambled with
+// randomness from rng.DefaultSource(), see ReadSobol.
+func NewSobol(dim int) *Sobol {
+	return ReadSobol(rng.DefaultSource(), dim)
+}
+
+// ReadSobol returns a Sobol sequence of dim dimensions scrambled with
+// randomness read from a given source. Scrambling is a random linear matrix
+// scramble followed by a random digital shift (Matoušek 1998), it preserves
+// the net structure of the sequence. If src is nil the sequence is not
+// scrambled. It will panic if dim is not in [1, MaxSobolDim].
+func ReadSobol(src io.Reader, dim int) *Sobol {
+	if dim < 1 || dim > MaxSobolDim {
+		panic("invalid argument to NewSobol")
+	}
+	s := &Sobol{
+		v: make([][64]uint64, dim),
+		x: make([]uint64, dim),
+	}
+	for j := range s.v {
+		m := make([]uint64, 64)
+		if j == 0 {
+			for k := range m {
+				m[k] = 1
+			}
+		} else {
+			p := sobolPoly[j-1]
+			copy(m, p.m)
+			for k := p.s; k < 64; k++ {
+				m[k] = m[k-p.s] ^ m[k-p.s]<<uint(p.s)
+				for i := 1; i < p.s; i++ {
+					if p.a>>uint(p.s-1-i)&1 == 1 {
+						m[k] ^= m[k-i] << uint(i)
+					}
+				}
+			}
+		}
+		for k := range m {
+			s.v[j][k] = m[k] << uint(63-k)
+		}
+	}
+	if src != nil {
+		for j := range s.v {
+			scramble(src, &s.v[j])
+			s.x[j] = rng.ReadUint64Bits(src, 64)
+		}
+	}
+	return s
+}
+
+// scramble multiplies direction numbers by a random lower triangular binary
+// matrix with unit diagonal. Row i of the matrix maps to output bit 63-i and
+// may only mix in more significant input bits.
+func scramble(src io.Reader, v *[64]uint64) {
+	var rows [64]uint64
+	for i := range rows {
+		bit := uint64(1) << uint(63-i)
+		// random bits strictly above bit i plus the diagonal
+		rows[i] = rng.ReadUint64Bits(src, uint(i))<<uint(64-i) | bit
+	}
+	for k := range v {
+		var y uint64
+		for i, row := range rows {
+			y |= uint64(bits.OnesCount64(row&v[k])&1) << uint(63-i)
+		}
+		v[k] = y
+	}
+}
+
+// Dim returns the number of dimensions.
+func (s *Sobol) Dim() int {
+	return len(s.x)
+}
+
+// Next returns the next point in [0, 1)^dim. Points are generated in Gray code
+// order, every aligned block of 2^k points forms a net.
+func (s *Sobol) Next() []float64 {
+	p := make([]float64, len(s.x))
+	for j, x := range s.x {
+		p[j] = float64(x>>11) / (1 << 53)
+	}
+	c := bits.TrailingZeros64(^s.index)
+	for j := range s.x {
+		s.x[j] ^= s.v[j][c]
+	}
+	s.index++
+	return p
+}
@@ -0,0 +1,74 @@
+package qmc
+
+import (
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSobolUnscrambled(t *testing.T) {
+	s := ReadSobol(nil, 2)
+	assert.Equal(t, 2, s.Dim())
+	assert.Equal(t, []float64{0, 0}, s.Next())
+	assert.Equal(t, []float64{0.5, 0.5}, s.Next())
+	assert.Equal(t, []float64{0.75, 0.25}, s.Next())
+	assert.Equal(t, []float64{0.25, 0.75}, s.Next())
+}
+
+// checkStratified checks that each dimension of the first 2^k points has
+// exactly one point in each interval [i/2^k, (i+1)/2^k).
+func checkStratified(t *testing.T, s *Sobol, k uint) {
+	t.Helper()
+	n := 1 << k
+	seen := make([][]bool, s.Dim())
+	for j := range seen {
+		seen[j] = make([]bool, n)
+	}
+	for i := 0; i < n; i++ {
+		for j, x := range s.Next() {
+			cell := int(x * float64(n))
+			assert.False(t, seen[j][cell], "dimension %d cell %d", j, cell)
+			seen[j][cell] = true
+		}
+	}
+}
+
+func TestSobolStratified(t *testing.T) {
+	checkStratified(t, ReadSobol(nil, MaxSobolDim), 10)
+	checkStratified(t, ReadSobol(rng.NewDRBG([]byte("sobol")), MaxSobolDim), 10)
+}
+
+func TestSobolNet(t *testing.T) {
+	// first 2^k points of dimensions 1 and 2 form a (0, k, 2)-net: every
+	// elementary box of area 2^-k holds one point
+	const k = 8
+	s := ReadSobol(rng.NewDRBG([]byte("net")), 2)
+	var pts [][]float64
+	for i := 0; i < 1<<k; i++ {
+		pts = append(pts, s.Next())
+	}
+	for a := 0; a <= k; a++ {
+		seen := make(map[[2]int]bool)
+		for _, p := range pts {
+			box := [2]int{int(p[0] * float64(int(1)<<a)), int(p[1] * float64(int(1)<<(k-a)))}
+			assert.False(t, seen[box], "a = %d box %v", a, box)
+			seen[box] = true
+		}
+	}
+}
+
+func TestSobolIntegration(t *testing.T) {
+	// integral of x*y*z over the unit cube is 1/8
+	s := NewSobol(3)
+	sum := 0.0
+	const n = 1 << 12
+	for i := 0; i < n; i++ {
+		p := s.Next()
+		sum += p[0] * p[1] * p[2]
+	}
+	assert.InDelta(t, 0.125, sum/n, 1e-3)
+
+	assert.Panics(t, func() { NewSobol(0) })
+	assert.Panics(t, func() { NewSobol(MaxSobolDim + 1) })
+}
@@ -1,35 +1,96 @@
 package rng
 
-import "crypto/rand"
-
 // Uint64Bits generates a random uint64 value in range [0, 2^n). In other words
 // returned uint64 will have n least significant bits set to random values,
 // other bits will be set to 0.
 //
 // It will panic if there is error reading from crypto/rand source.
 func Uint64Bits(n uint) (r uint64) {
-	return ReadUint64Bits(rand.Reader, n)
+	return ReadUint64Bits(defaultSource(), n)
 }
 
 // Intn returns a non negative int in [0, n).
 // It will panic if n <= 0.
 func Intn(n int) int {
-	return ReadIntn(rand.Reader, n)
+	return ReadIntn(defaultSource(), n)
+}
+
+// Int31n returns a non negative int32 in [0, n).
+// It will panic if n <= 0.
+func Int31n(n int32) int32 {
+	return ReadInt31n(defaultSource(), n)
+}
+
+// IntnInclusive returns a non negative int in [0, n].
+// It will panic if n < 0.
+func IntnInclusive(n int) int {
+	return ReadIntnInclusive(defaultSource(), n)
 }
 
 // Float64 returns a random number in [0.0,1.0)
 func Float64() float64 {
-	return ReadFloat64(rand.Reader)
+	return ReadFloat64(defaultSource())
+}
+
+// Float64Open returns a random number in (0.0,1.0)
+func Float64Open() float64 {
+	return ReadFloat64Open(defaultSource())
+}
+
+// Float64Closed returns a random number in [0.0,1.0]
+func Float64Closed() float64 {
+	return ReadFloat64Closed(defaultSource())
+}
+
+// Float64Fixed returns a random number in [min, max] with a given number of
+// decimal places, see ReadFloat64Fixed.
+func Float64Fixed(min, max float64, decimals int) float64 {
+	return ReadFloat64Fixed(defaultSource(), min, max, decimals)
 }
 
 // Perm returns, as a slice of n ints, a random permutation of the integers
 // [0,n).
 func Perm(n int) []int {
-	return ReadPerm(rand.Reader, n)
+	return ReadPerm(defaultSource(), n)
+}
+
+// PermPrefix returns the first k elements of a uniformly random permutation of
+// the integers [0,n), see ReadPermPrefix.
+func PermPrefix(n, k int) []int {
+	return ReadPermPrefix(defaultSource(), n, k)
+}
+
+// Cycle returns a uniformly random cyclic permutation of the integers [0,n),
+// see ReadCycle.
+func Cycle(n int) []int {
+	return ReadCycle(defaultSource(), n)
 }
 
 // Sample returns random k integers from a range [0 n). If k > n then only n
 // integers are returned.
 func Sample(n int, k int) []int {
-	return ReadSample(rand.Reader, n, k)
+	return ReadSample(defaultSource(), n, k)
+}
+
+// SampleSorted returns random k integers from a range [0 n) sorted in
+// ascending order, see ReadSampleSorted.
+func SampleSorted(n int, k int) []int {
+	return ReadSampleSorted(defaultSource(), n, k)
+}
+
+// Shuffle pseudo-randomizes the order of elements. n is the number of
+// elements, swap swaps the elements with indexes i and j.
+func Shuffle(n int, swap func(i, j int)) {
+	ReadShuffle(defaultSource(), n, swap)
+}
+
+// ShuffleString returns s with its runes in random order, see
+// ReadShuffleString.
+func ShuffleString(s string) string {
+	return ReadShuffleString(defaultSource(), s)
+}
+
+// ShuffleBytes randomizes the order of bytes in b in place.
+func ShuffleBytes(b []byte) {
+	ReadShuffleBytes(defaultSource(), b)
 }
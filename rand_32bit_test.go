@@ -0,0 +1,35 @@
+//go:build 386 || arm || mips || mipsle
+
+package rng
+
+import (
+	"bytes"
+	"math"
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests for platforms where int is 32 bits wide.
+
+func TestIntSize32(t *testing.T) {
+	assert.Equal(t, 32, bits.UintSize)
+}
+
+func TestIntn32(t *testing.T) {
+	r := Intn(math.MaxInt32)
+	assert.True(t, r >= 0 && r < math.MaxInt32)
+	r = IntnInclusive(math.MaxInt32)
+	assert.True(t, r >= 0)
+
+	// reduction reads 4 bytes for n just above 2^24, as on 64-bit platforms
+	src := bytes.NewBuffer([]byte{1, 0, 0, 1, 2, 0, 0, 0})
+	assert.Equal(t, 0, ReadIntn(src, 1<<24+1))
+	assert.Equal(t, 2, ReadIntn(src, 1<<24+1))
+
+	// Int31n and Intn consume the same bytes
+	a := ReadIntn(NewDRBG([]byte("32")), 1<<30+3)
+	b := ReadInt31n(NewDRBG([]byte("32")), 1<<30+3)
+	assert.Equal(t, a, int(b))
+}
@@ -2,10 +2,12 @@ package rng
 
 import (
 	"bytes"
-	"crypto/rand"
 	"fmt"
 	"math"
+	"math/bits"
+	"sort"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -24,25 +26,19 @@ func TestInt64Overflow(t *testing.T) {
 }
 
 func TestUint64BitsSourceError(t *testing.T) {
-	origRand := rand.Reader
-	defer func() {
-		rand.Reader = origRand
-	}()
+	defer SetDefaultSource(DefaultSource())
 
-	// This test replaces random source with source that would return error
-	// on read. We test if error is converted to panic.
+	// This test replaces default random source with source that would
+	// return error on read. We test if error is converted to panic.
 
-	rand.Reader = bytes.NewBuffer([]byte{})
+	SetDefaultSource(bytes.NewBuffer([]byte{}))
 	assert.Panics(t, func() {
 		Uint64Bits(8)
 	})
 }
 
 func TestUint64BitsRead(t *testing.T) {
-	origRand := rand.Reader
-	defer func() {
-		rand.Reader = origRand
-	}()
+	defer SetDefaultSource(DefaultSource())
 
 	// Test if Uint64Bits read from random source and read as little bytes
 	// as possible. In this test we replace random source with fixed length
@@ -67,17 +63,14 @@ func TestUint64BitsRead(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		rand.Reader = bytes.NewBuffer(test.source)
+		SetDefaultSource(bytes.NewBuffer(test.source))
 		n := Uint64Bits(test.bits)
 		assert.Equal(t, test.value, n)
 	}
 }
 
 func TestUint64BitsMask(t *testing.T) {
-	origRand := rand.Reader
-	defer func() {
-		rand.Reader = origRand
-	}()
+	defer SetDefaultSource(DefaultSource())
 
 	// This test checks if Uint64Bits masks extra bits if nuber of bits does
 	// not make last byte full.
@@ -104,7 +97,7 @@ func TestUint64BitsMask(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		rand.Reader = bytes.NewBuffer(source)
+		SetDefaultSource(bytes.NewBuffer(source))
 		n := Uint64Bits(test.bits)
 		assert.Equal(t, test.value, n)
 	}
@@ -153,7 +146,7 @@ func TestIntnPanics(t *testing.T) {
 func TestIntn(t *testing.T) {
 	assert.Equal(t, 0, Intn(1))
 
-	for i := uint(1); i <= 62; i++ {
+	for i := uint(1); i <= bits.UintSize-2; i++ {
 		max := (1 << i) + 1
 		n := Intn(max)
 		assert.True(t, n < max, fmt.Sprintf("Intn(%d) = %d, must be < %d", max, n, max))
@@ -161,6 +154,35 @@ func TestIntn(t *testing.T) {
 	}
 }
 
+func TestInt31n(t *testing.T) {
+	assert.Panics(t, func() { Int31n(0) })
+	assert.Panics(t, func() { Int31n(-1) })
+	assert.Equal(t, int32(0), Int31n(1))
+
+	for _, n := range []int32{2, 1000, math.MaxInt32} {
+		r := Int31n(n)
+		assert.True(t, r >= 0 && r < n, "Int31n(%d) = %d", n, r)
+	}
+
+	// 0xfffe is above the rejection limit 65000 for n = 1000
+	src := bytes.NewBuffer([]byte{0xfe, 0xff, 0xe8, 0x03})
+	assert.Equal(t, int32(0), ReadInt31n(src, 1000))
+	src = bytes.NewBuffer([]byte{0xfe, 0xff, 0xff, 0x7f})
+	assert.Equal(t, int32(math.MaxInt32-1), ReadInt31n(src, math.MaxInt32))
+}
+
+func TestReadUint64n(t *testing.T) {
+	assert.Panics(t, func() { ReadUint64n(NewDRBG(nil), 0) })
+	assert.Equal(t, uint64(0), ReadUint64n(NewDRBG(nil), 1))
+	assert.Equal(t, uint64(ReadIntn(NewDRBG([]byte("n")), 1000)), ReadUint64n(NewDRBG([]byte("n")), 1000))
+
+	src := bytes.NewBuffer([]byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	assert.Equal(t, uint64(math.MaxUint64-1), ReadUint64n(src, math.MaxUint64))
+	// ranges above 32-bit int are drawn the same on every platform
+	src = bytes.NewBuffer([]byte{0x05, 0x00, 0x00, 0x00, 0x01})
+	assert.Equal(t, uint64(1<<32+5), ReadUint64n(src, 1<<33))
+}
+
 func TestFloat64(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		r := Float64()
@@ -214,6 +236,70 @@ func TestSample(t *testing.T) {
 	assert.Len(t, Sample(2, 10), 2)
 }
 
+func TestSampleVersion(t *testing.T) {
+	// SampleV3 consumes exactly one ReadIntn(n-i) per sampled integer
+	for _, k := range []int{0, 1, 6, 30, 49} {
+		src := NewDRBG([]byte("v3"))
+		sample := ReadSampleVersion(src, SampleV3, 49, k)
+
+		expected := NewDRBG([]byte("v3"))
+		m := make([]int, 49)
+		for i := range m {
+			m[i] = i
+		}
+		for i := 0; i < k; i++ {
+			j := i + ReadIntn(expected, 49-i)
+			m[i], m[j] = m[j], m[i]
+		}
+		assert.Equal(t, m[:k], sample, "k=%d", k)
+		// both streams are at the same position
+		assert.Equal(t, ReadUint64Bits(expected, 64), ReadUint64Bits(src, 64))
+	}
+
+	assert.Equal(t, ReadSample(NewDRBG([]byte("x")), 100, 5), ReadSampleVersion(NewDRBG([]byte("x")), SampleVersion, 100, 5))
+	// SampleV1 takes a prefix of the full permutation
+	assert.Equal(t, ReadPerm(NewDRBG([]byte("x")), 10)[:8], ReadSampleVersion(NewDRBG([]byte("x")), SampleV1, 10, 8))
+	// SampleV2 and SampleV3 agree on the shared partial Fisher–Yates branch,
+	// SampleV1 and SampleV2 on the duplicate rejection branch
+	assert.Equal(t, ReadSampleVersion(NewDRBG([]byte("x")), SampleV2, 10, 8), ReadSampleVersion(NewDRBG([]byte("x")), SampleV3, 10, 8))
+	assert.Equal(t, ReadSampleVersion(NewDRBG([]byte("x")), SampleV1, 100, 5), ReadSampleVersion(NewDRBG([]byte("x")), SampleV2, 100, 5))
+	assert.Len(t, ReadSampleVersion(NewDRBG([]byte("x")), SampleV1, 100, 5), 5)
+	assert.Len(t, ReadSampleVersion(NewDRBG([]byte("x")), SampleV1, 5, 100), 5)
+	assert.Len(t, ReadSampleVersion(NewDRBG([]byte("x")), SampleV2, 5, 100), 5)
+
+	assert.Panics(t, func() { ReadSampleVersion(NewDRBG(nil), "sample/v0", 10, 2) })
+	assert.Panics(t, func() { ReadSampleVersion(NewDRBG(nil), SampleV3, -1, 0) })
+	assert.Panics(t, func() { ReadSampleVersion(NewDRBG(nil), SampleV1, 10, -1) })
+}
+
+func TestSampleSorted(t *testing.T) {
+	for _, k := range []int{0, 3, 8, 12} {
+		s := ReadSample(NewDRBG([]byte("sorted")), 10, k)
+		sorted := ReadSampleSorted(NewDRBG([]byte("sorted")), 10, k)
+		assert.True(t, sort.IntsAreSorted(sorted))
+		assert.ElementsMatch(t, s, sorted)
+	}
+	assert.Len(t, SampleSorted(49, 6), 6)
+}
+
+func TestSampleDrawOrder(t *testing.T) {
+	// every version returns integers in uniformly random order on both the
+	// duplicate rejection (k=2) and the permutation (k=8) branch, the first
+	// drawn integer is uniform over the range
+	src := NewDRBG([]byte("order"))
+	for _, version := range []string{SampleV1, SampleV2, SampleV3} {
+		for _, k := range []int{2, 8} {
+			first := make([]int, 10)
+			for i := 0; i < 10000; i++ {
+				first[ReadSampleVersion(src, version, 10, k)[0]]++
+			}
+			for _, count := range first {
+				assert.InDelta(t, 1000, count, 150, "%s k=%d", version, k)
+			}
+		}
+	}
+}
+
 func TestIntnFrequencyMonobit(t *testing.T) {
 	if !cfg.long {
 		t.Skip("skipping, run with --long to enable long RNG tests")
@@ -348,3 +434,174 @@ func TestFloat64Histogram(t *testing.T) {
 	}
 	assert.Equal(t, N, n)
 }
+
+func TestIntnInclusive(t *testing.T) {
+	assert.Equal(t, 0, IntnInclusive(0))
+
+	seen := make(map[int]bool)
+	for i := 0; i < 1000 && len(seen) < 3; i++ {
+		n := IntnInclusive(2)
+		assert.True(t, n >= 0 && n <= 2, fmt.Sprintf("IntnInclusive(2) = %d, must be in [0, 2]", n))
+		seen[n] = true
+	}
+	assert.Len(t, seen, 3)
+
+	assert.True(t, IntnInclusive(math.MaxInt) >= 0)
+	assert.Panics(t, func() {
+		IntnInclusive(-1)
+	})
+}
+
+func TestFloat64Open(t *testing.T) {
+	// first 7 bytes are all zero and must be rejected
+	src := bytes.NewBuffer([]byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	assert.Equal(t, 1.0/float64(1<<53), ReadFloat64Open(src))
+
+	for i := 0; i < 10; i++ {
+		r := Float64Open()
+		assert.True(t, r > 0.0 && r < 1.0, fmt.Sprintf("Float64Open() = %g, must be in (0.0, 1.0)", r))
+	}
+}
+
+func TestFloat64Closed(t *testing.T) {
+	// 2^53 is the largest accepted value
+	src := bytes.NewBuffer([]byte{0, 0, 0, 0, 0, 0, 0x20, 0})
+	assert.Equal(t, 1.0, ReadFloat64Closed(src))
+
+	for i := 0; i < 10; i++ {
+		r := Float64Closed()
+		assert.True(t, r >= 0.0 && r <= 1.0, fmt.Sprintf("Float64Closed() = %g, must be in [0.0, 1.0]", r))
+	}
+}
+
+func TestFloat64Fixed(t *testing.T) {
+	// [1.10, 1.20] has 11 grid values, draw index 0 and 10
+	assert.Equal(t, 1.1, ReadFloat64Fixed(bytes.NewBuffer([]byte{0}), 1.1, 1.2, 2))
+	assert.Equal(t, 1.2, ReadFloat64Fixed(bytes.NewBuffer([]byte{10}), 1.1, 1.2, 2))
+	// bounds are rounded inwards to the grid
+	assert.Equal(t, 0.2, ReadFloat64Fixed(bytes.NewBuffer([]byte{0}), 0.15, 0.35, 1))
+	assert.Equal(t, -3.0, ReadFloat64Fixed(bytes.NewBuffer([]byte{0}), -3, 3, 0))
+
+	counts := make(map[float64]int)
+	g := NewDRBG([]byte("seed"))
+	for i := 0; i < 11000; i++ {
+		counts[ReadFloat64Fixed(g, 1.1, 1.2, 2)]++
+	}
+	assert.Len(t, counts, 11)
+	for v, c := range counts {
+		assert.InDelta(t, 1000, c, 150, "value %g", v)
+	}
+
+	for _, args := range [][3]float64{
+		{1, 2, -1},
+		{1, 2, 16},
+		{1.01, 1.09, 1},
+		{math.NaN(), 1, 2},
+		{0, math.Inf(1), 2},
+		{0, 1e10, 10},
+	} {
+		assert.Panics(t, func() { Float64Fixed(args[0], args[1], int(args[2])) }, "%v", args)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	s := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, s)
+
+	Shuffle(0, func(i, j int) {
+		t.Error("swap must not be called for empty input")
+	})
+	assert.Panics(t, func() {
+		Shuffle(-1, func(i, j int) {})
+	})
+}
+
+func TestShuffleString(t *testing.T) {
+	// swaps (2, 0) then (1, 1)
+	src := bytes.NewBuffer([]byte{0, 1})
+	assert.Equal(t, "€ßa", ReadShuffleString(src, "aß€"))
+
+	s := ShuffleString("ąčęėįšųūž")
+	assert.True(t, utf8.ValidString(s))
+	assert.ElementsMatch(t, []rune("ąčęėįšųūž"), []rune(s))
+	assert.Equal(t, "", ShuffleString(""))
+
+	b := []byte("abc")
+	ReadShuffleBytes(bytes.NewBuffer([]byte{0, 1}), b)
+	assert.Equal(t, []byte("cba"), b)
+	ShuffleBytes(nil)
+}
+
+func TestCycle(t *testing.T) {
+	assert.Empty(t, Cycle(0))
+	assert.Equal(t, []int{0}, Cycle(1))
+	assert.Equal(t, []int{1, 0}, Cycle(2))
+
+	for n := 3; n < 20; n++ {
+		p := Cycle(n)
+		// walking successors must visit every element once
+		visited := make(map[int]bool)
+		for i := 0; !visited[i]; i = p[i] {
+			assert.NotEqual(t, i, p[i], "cycle must not have fixed points")
+			visited[i] = true
+		}
+		assert.Len(t, visited, n)
+	}
+}
+
+func TestCycleUniform(t *testing.T) {
+	// all (3-1)! = 2 cycles of length 3 must be possible
+	seen := make(map[[3]int]bool)
+	for i := 0; i < 1000 && len(seen) < 2; i++ {
+		p := Cycle(3)
+		seen[[3]int{p[0], p[1], p[2]}] = true
+	}
+	assert.Len(t, seen, 2)
+}
+
+func TestPermPrefix(t *testing.T) {
+	assert.Empty(t, PermPrefix(0, 5))
+	assert.Empty(t, PermPrefix(10, 0))
+	assert.Len(t, PermPrefix(3, 5), 3)
+
+	tests := []struct {
+		n int
+		k int
+	}{
+		{10, 3},  // sparse map
+		{10, 8},  // dense array
+		{10, 10}, // full permutation
+		// 1 << 62 on 64-bit platforms
+		{math.MaxInt/2 + 1, 1000},
+	}
+	for _, test := range tests {
+		p := PermPrefix(test.n, test.k)
+		assert.Len(t, p, test.k)
+		unique := make(map[int]bool)
+		for _, v := range p {
+			assert.True(t, v >= 0 && v < test.n)
+			unique[v] = true
+		}
+		assert.Len(t, unique, test.k)
+	}
+
+	assert.Panics(t, func() {
+		PermPrefix(-1, 1)
+	})
+	assert.Panics(t, func() {
+		PermPrefix(1, -1)
+	})
+}
+
+func TestPermPrefixConsistent(t *testing.T) {
+	// sparse and dense implementations must consume entropy identically
+	// and return the same values
+	for n := 1; n < 12; n++ {
+		sparse := ReadPermPrefix(NewDRBG([]byte{byte(n)}), n, n/2)
+		dense := ReadPermPrefix(NewDRBG([]byte{byte(n)}), n, n)
+		assert.Equal(t, dense[:n/2], sparse)
+	}
+}
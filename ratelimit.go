@@ -0,0 +1,102 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitedSource is a source wrapper limiting the rate of bytes read from
+// an inner source using a token bucket. Reads block until enough tokens are
+// available. Bucket capacity is one second worth of bytes, so short bursts up
+// to bytesPerSec are served without delay.
+//
+// RateLimitedSource is safe for concurrent use if the inner source is.
+type RateLimitedSource struct {
+	src    io.Reader
+	bucket *tokenBucket
+}
+
+// NewRateLimitedSource returns a source reading from src at most bytesPerSec
+// bytes per second on average. It will panic if bytesPerSec < 1 or is
+// infinite.
+func NewRateLimitedSource(src io.Reader, bytesPerSec float64) *RateLimitedSource {
+	if !(bytesPerSec >= 1) || math.IsInf(bytesPerSec, 1) {
+		panic("invalid argument to NewRateLimitedSource")
+	}
+	return &RateLimitedSource{src: src, bucket: newTokenBucket(bytesPerSec)}
+}
+
+// Read reads from the inner source, waiting for rate limit tokens first. Large
+// reads are split into chunks not exceeding the bucket capacity.
+func (r *RateLimitedSource) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		chunk := len(p) - n
+		if float64(chunk) > r.bucket.rate {
+			chunk = int(r.bucket.rate)
+		}
+		r.bucket.wait(float64(chunk))
+		c, err := r.src.Read(p[n : n+chunk])
+		n += c
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// tokenBucket limits the average rate of events, e.g. bytes read or draws.
+// Bucket capacity is one second worth of tokens.
+type tokenBucket struct {
+	rate float64 // tokens per second
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// replaced in tests
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		tokens: rate,
+		last:   time.Now(),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+}
+
+// wait blocks until n tokens are available and takes them.
+func (b *tokenBucket) wait(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	b.tokens -= n
+	if b.tokens < 0 {
+		// sleeping under the lock queues concurrent waiters fairly
+		b.sleep(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+}
+
+// WithByteRateLimit returns a Generator sharing the source of g but reading
+// at most bytesPerSec source bytes per second, see NewRateLimitedSource. The
+// limit applies to entropy consumed, not to the number of draws: a Perm(52)
+// consumes far more of it than an Intn(6), see Quota.MaxDrawsPerSec to limit
+// the draw rate of a Scope. Each returned generator has its own limit, so
+// separate scopes (e.g. game tables) sharing one entropy device can not starve
+// each other. It will panic if bytesPerSec < 1 or is infinite.
+func (g *Generator) WithByteRateLimit(bytesPerSec float64) *Generator {
+	return &Generator{src: NewRateLimitedSource(g.src, bytesPerSec), enc: g.enc}
+}
@@ -0,0 +1,56 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedSource(t *testing.T) {
+	clock := time.Unix(0, 0)
+	var slept time.Duration
+
+	r := NewRateLimitedSource(NewDRBG(nil), 100)
+	r.bucket.last = clock
+	r.bucket.now = func() time.Time { return clock }
+	r.bucket.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+
+	// initial burst of one second worth of bytes is free
+	_, err := io.ReadFull(r, make([]byte, 100))
+	assert.NoError(t, err)
+	assert.Zero(t, slept)
+
+	// next 50 bytes must wait half a second
+	_, err = io.ReadFull(r, make([]byte, 50))
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, slept)
+
+	// idle time refills the bucket up to its capacity
+	clock = clock.Add(10 * time.Second)
+	slept = 0
+	_, err = io.ReadFull(r, make([]byte, 350))
+	assert.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, slept)
+
+	// reads are not split beyond their length with huge rates
+	_, err = io.ReadFull(NewRateLimitedSource(NewDRBG(nil), 1e20), make([]byte, 10))
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() {
+		NewRateLimitedSource(NewDRBG(nil), 0)
+	})
+	assert.Panics(t, func() {
+		NewRateLimitedSource(NewDRBG(nil), math.Inf(1))
+	})
+}
+
+func TestGeneratorWithByteRateLimit(t *testing.T) {
+	g := New(nil).WithByteRateLimit(1 << 20)
+	assert.Len(t, g.Perm(100), 100)
+}
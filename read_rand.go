@@ -2,14 +2,20 @@ package rng
 
 import (
 	"io"
+	"math"
+	"sort"
 )
 
 // ReadUint64Bits reads a random uint64 value in range [0, 2^n) from a random
 // source. In other words returned uint64 will have n least significant bits set
 // to random values, other bits will be set to 0.
 //
-// It will panic if random source returns read error.
+// Bytes are interpreted as a little endian integer unless src was returned by
+// EncodedSource. It will panic if random source returns read error.
 func ReadUint64Bits(src io.Reader, n uint) (r uint64) {
+	if e, ok := src.(*encodedSource); ok {
+		return ReadUint64BitsEncoding(e.src, n, e.enc)
+	}
 	if n > 64 {
 		panic("abr.Uint64Bits can not return more than 64 random bits")
 	}
@@ -33,14 +39,20 @@ func ReadIntn(src io.Reader, n int) int {
 		panic("invalid argument to Intn")
 	}
 
-	N := uint64(n)
+	return int(readUint64n(src, uint64(n)))
+}
+
+// readUint64n returns a uint64 in [0, N) reading randomness from a given
+// source. N must be positive. All arithmetic is done in uint64, so the bytes
+// consumed and the result do not depend on the size of int.
+func readUint64n(src io.Reader, N uint64) uint64 {
 	// minimum number of random bits that will be read be read from entropy
 	// source, it is always a multiple of 8 because reads have byte
 	// granularity
 	bits := minBytes(N-1) * 8
 	// if N is a power of two single read is always sufficient
 	if N&(N-1) == 0 {
-		return int(ReadUint64Bits(src, bits) & (N - 1))
+		return ReadUint64Bits(src, bits) & (N - 1)
 	}
 
 	// call Uint64Bits(bits) will always return values in range [0; M)
@@ -72,17 +84,105 @@ func ReadIntn(src io.Reader, n int) int {
 	for {
 		r := ReadUint64Bits(src, bits)
 		if r < limit {
-			return int(r % N)
+			return r % N
 		}
 	}
 }
 
+// ReadInt31n returns a non negative int32 in [0, n) reading randomness from a
+// given source. Unlike ReadIntn its range does not depend on the size of int,
+// so results are identical on 32-bit and 64-bit platforms for any n. It will
+// panic if n <= 0.
+func ReadInt31n(src io.Reader, n int32) int32 {
+	if n <= 0 {
+		panic("invalid argument to Int31n")
+	}
+
+	return int32(readUint64n(src, uint64(n)))
+}
+
+// ReadUint64n returns a uint64 in [0, n) reading randomness from a given
+// source. Like ReadInt31n its results are identical on 32-bit and 64-bit
+// platforms, and for n that fit an int it consumes the same entropy and
+// returns the same value as ReadIntn. It will panic if n == 0.
+func ReadUint64n(src io.Reader, n uint64) uint64 {
+	if n == 0 {
+		panic("invalid argument to Uint64n")
+	}
+
+	return readUint64n(src, n)
+}
+
+// ReadIntnInclusive returns a non negative int in [0, n] reading randomness
+// from a given source. It will panic if n < 0.
+func ReadIntnInclusive(src io.Reader, n int) int {
+	if n < 0 {
+		panic("invalid argument to IntnInclusive")
+	}
+	// uint64(n)+1 can not overflow because n is a non negative int
+	return int(readUint64n(src, uint64(n)+1))
+}
+
 // ReadFloat64 returns a random number in [0.0,1.0) reading randomness from a
 // given source.
 func ReadFloat64(src io.Reader) float64 {
 	return float64(ReadUint64Bits(src, 53)) / float64(1<<53)
 }
 
+// ReadFloat64Open returns a random number in (0.0,1.0) reading randomness from
+// a given source. Values are uniform over the same grid of multiples of 2^-53
+// as ReadFloat64 with zero excluded.
+func ReadFloat64Open(src io.Reader) float64 {
+	for {
+		if r := ReadUint64Bits(src, 53); r != 0 {
+			return float64(r) / float64(1<<53)
+		}
+	}
+}
+
+// ReadFloat64Closed returns a random number in [0.0,1.0] reading randomness
+// from a given source. Values are uniform over multiples of 2^-53 including
+// both 0.0 and 1.0.
+func ReadFloat64Closed(src io.Reader) float64 {
+	return float64(readUint64n(src, 1<<53+1)) / float64(1<<53)
+}
+
+// ReadFloat64Fixed returns a random number in [min, max] with a given number of
+// decimal places reading randomness from a given source, e.g. odds in [1.01,
+// 10.00] with 2 decimals. All values of the grid k/10^decimals within the
+// range are equally likely, including both bounds when they lie on the grid.
+// An integer number of grid steps is drawn and scaled, avoiding the bias of
+// rounding a continuous draw where grid endpoints get half the probability.
+//
+// Bounds are snapped to the grid first, so 1.1 with 2 decimals is treated as
+// 1.10 despite its binary representation. It will panic if decimals is not in
+// [0, 15], bounds are not finite, the range contains no grid value or more
+// than 2^53 of them.
+func ReadFloat64Fixed(src io.Reader, min, max float64, decimals int) float64 {
+	if decimals < 0 || decimals > 15 || math.IsNaN(min) || math.IsNaN(max) ||
+		math.IsInf(min, 0) || math.IsInf(max, 0) {
+		panic("invalid argument to Float64Fixed")
+	}
+	scale := math.Pow10(decimals)
+	lo := math.Ceil(snapToGrid(min * scale))
+	hi := math.Floor(snapToGrid(max * scale))
+	if lo > hi || hi-lo >= 1<<53 || math.Abs(lo) > 1<<53 || math.Abs(hi) > 1<<53 {
+		panic("invalid argument to Float64Fixed")
+	}
+	k := readUint64n(src, uint64(hi-lo)+1)
+	return (lo + float64(k)) / scale
+}
+
+// snapToGrid rounds x to the nearest integer if it is within floating point
+// error of it.
+func snapToGrid(x float64) float64 {
+	r := math.Round(x)
+	if math.Abs(x-r) <= 1e-9*math.Max(1, math.Abs(x)) {
+		return r
+	}
+	return x
+}
+
 // ReadPerm returns, as a slice of n ints, a random permutation of the integers
 // [0,n) reading randomness from a given source.
 func ReadPerm(src io.Reader, n int) []int {
@@ -95,28 +195,183 @@ func ReadPerm(src io.Reader, n int) []int {
 	return m
 }
 
+// ReadPermPrefix returns the first k elements of a uniformly random
+// permutation of the integers [0,n) reading randomness from a given source. If
+// k > n then all n elements are returned.
+//
+// It runs the first k steps of a forward Fisher–Yates shuffle in O(k) time and
+// memory, only the swapped positions are stored sparsely, so it is suitable
+// for huge n. It will panic if n < 0 or k < 0.
+func ReadPermPrefix(src io.Reader, n, k int) []int {
+	if n < 0 || k < 0 {
+		panic("invalid argument to PermPrefix")
+	}
+	if k > n {
+		k = n
+	}
+
+	prefix := make([]int, k)
+	if k > n/2 {
+		// dense array is smaller than a map of up to 2k entries
+		m := make([]int, n)
+		for i := range m {
+			m[i] = i
+		}
+		for i := range prefix {
+			j := i + ReadIntn(src, n-i)
+			m[i], m[j] = m[j], m[i]
+			prefix[i] = m[i]
+		}
+		return prefix
+	}
+
+	// swapped holds values of positions that differ from identity
+	swapped := newSwapMap(2 * k)
+	for i := range prefix {
+		j := i + ReadIntn(src, n-i)
+		prefix[i] = swapped.get(j)
+		swapped.set(j, swapped.get(i))
+		// position i is never read again
+		swapped.remove(i)
+	}
+	return prefix
+}
+
+// ReadCycle returns a uniformly random cyclic permutation of the integers
+// [0,n) using Sattolo's algorithm reading randomness from a given source.
+// Following p[i] from any i visits all n elements before returning to i, e.g.
+// p[i] is the opponent of player i in a round-robin pairing.
+//
+// Unlike Perm, Cycle never returns fixed points (p[i] == i) for n > 1 and only
+// (n-1)! of the n! permutations are possible, so it must not be used where a
+// uniform permutation is required.
+func ReadCycle(src io.Reader, n int) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := ReadIntn(src, i) // j < i, unlike Fisher–Yates
+		m[i], m[j] = m[j], m[i]
+	}
+	return m
+}
+
+// ReadShuffle randomizes the order of n elements using Fisher–Yates algorithm
+// reading randomness from a given source. swap swaps the elements with indexes
+// i and j. It will panic if n < 0.
+func ReadShuffle(src io.Reader, n int, swap func(i, j int)) {
+	if n < 0 {
+		panic("invalid argument to Shuffle")
+	}
+	for i := n - 1; i > 0; i-- {
+		swap(i, ReadIntn(src, i+1))
+	}
+}
+
+// ReadShuffleString returns s with its runes in random order reading
+// randomness from a given source. Multi-byte UTF-8 sequences are kept intact,
+// invalid bytes are replaced with utf8.RuneError. Combining characters are
+// separate runes and may be moved away from their base character.
+func ReadShuffleString(src io.Reader, s string) string {
+	r := []rune(s)
+	ReadShuffle(src, len(r), func(i, j int) {
+		r[i], r[j] = r[j], r[i]
+	})
+	return string(r)
+}
+
+// ReadShuffleBytes randomizes the order of bytes in b in place reading
+// randomness from a given source.
+func ReadShuffleBytes(src io.Reader, b []byte) {
+	ReadShuffle(src, len(b), func(i, j int) {
+		b[i], b[j] = b[j], b[i]
+	})
+}
+
+// Sample algorithm versions accepted by ReadSampleVersion. A draw recorded
+// with its version can be reproduced from the same source stream even after
+// the default algorithm of ReadSample changes.
+const (
+	// SampleV1 is the original algorithm: the first k elements of ReadPerm(src,
+	// n) if k > n/2, otherwise ReadIntn(src, n) repeated until k distinct
+	// integers are drawn.
+	SampleV1 = "sample/v1"
+	// SampleV2 is SampleV1 with the ReadPerm branch replaced by the first k
+	// steps of a forward Fisher–Yates shuffle, see ReadPermPrefix. Entropy
+	// consumption depends on the branch and on duplicates drawn.
+	SampleV2 = "sample/v2"
+	// SampleV3 always runs the first k steps of a forward Fisher–Yates
+	// shuffle, see ReadPermPrefix. Step i reads exactly ReadIntn(src, n-i),
+	// so a sample consumes the same entropy as k sequential ReadIntn calls
+	// with ranges n, n-1, ..., n-k+1, independent of how the permutation is
+	// stored internally.
+	SampleV3 = "sample/v3"
+	// SampleVersion is the version used by ReadSample.
+	SampleVersion = SampleV3
+)
+
 // ReadSample returns random k integers from a range [0 n). If k > n then only n
-// integers are returned.
+// integers are returned. Integers are returned in draw order, which is a
+// uniformly random order, e.g. the order balls leave a lottery machine. Use
+// ReadSampleSorted for display.
 //
-// This function consumes entropy from a given entroy source src.
+// This function consumes entropy from a given entroy source src as described
+// by SampleVersion.
 func ReadSample(src io.Reader, n int, k int) []int {
+	return ReadSampleVersion(src, SampleVersion, n, k)
+}
+
+// ReadSampleVersion returns random k integers from a range [0 n) using a given
+// algorithm version, SampleV1, SampleV2 or SampleV3, reading randomness from a
+// given source. It will panic if the version is unknown or n or k are
+// negative.
+func ReadSampleVersion(src io.Reader, version string, n int, k int) []int {
+	if n < 0 || k < 0 {
+		panic("invalid argument to Sample")
+	}
 	if k > n {
 		k = n
 	}
 
-	if k > n/2 {
-		return ReadPerm(src, n)[0:k]
+	switch version {
+	case SampleV1:
+		if k > n/2 {
+			return ReadPerm(src, n)[0:k]
+		}
+		return readSampleRejection(src, n, k)
+	case SampleV2:
+		if k > n/2 {
+			return ReadPermPrefix(src, n, k)
+		}
+		return readSampleRejection(src, n, k)
+	case SampleV3:
+		return ReadPermPrefix(src, n, k)
 	}
+	panic("invalid argument to Sample, unknown version " + version)
+}
 
+// readSampleRejection draws k distinct integers from [0 n) rejecting
+// duplicates.
+func readSampleRejection(src io.Reader, n int, k int) []int {
 	sample := make([]int, 0, k)
-	cache := make(map[int]struct{})
+	seen := newIntSet(k)
 	for len(sample) < k {
 		r := ReadIntn(src, n)
-		if _, ok := cache[r]; ok {
+		if !seen.add(r) {
 			continue
 		}
-		cache[r] = struct{}{}
 		sample = append(sample, r)
 	}
 	return sample
 }
+
+// ReadSampleSorted returns random k integers from a range [0 n) sorted in
+// ascending order reading randomness from a given source, e.g. numbers of a
+// lottery ticket. It consumes the same entropy and selects the same integers
+// as ReadSample, only the draw order is lost.
+func ReadSampleSorted(src io.Reader, n int, k int) []int {
+	s := ReadSample(src, n, k)
+	sort.Ints(s)
+	return s
+}
@@ -0,0 +1,99 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrTooManyRejections is returned by Rejection when MaxTries proposals in a
+// row were rejected.
+var ErrTooManyRejections = errors.New("rng: too many rejected proposals")
+
+// RejectionSample returns a value drawn by rejection sampling, see
+// ReadRejectionSample.
+func RejectionSample(proposal func() float64, accept func(x float64) float64) float64 {
+	return ReadRejectionSample(defaultSource(), proposal, accept)
+}
+
+// ReadRejectionSample draws proposals x until one is accepted with
+// probability accept(x), the uniform deciding acceptance is read from a given
+// source. To sample density f with proposal density g and envelope
+// f(x) <= M*g(x), accept must return f(x)/(M*g(x)).
+//
+// It will panic if accept returns a value outside [0, 1], which means the
+// envelope does not cover the target density.
+func ReadRejectionSample(src io.Reader, proposal func() float64, accept func(x float64) float64) float64 {
+	for {
+		x := proposal()
+		if acceptProposal(src, accept(x)) {
+			return x
+		}
+	}
+}
+
+// Rejection is an envelope rejection sampler for values of type T counting
+// proposals and acceptances. Proposals and acceptance uniforms read from the
+// same source, so a seeded source reproduces the whole sequence.
+//
+// Counters are updated atomically, Rejection is safe for concurrent use if
+// the source and proposal function are.
+type Rejection[T any] struct {
+	// Proposal draws a candidate value.
+	Proposal func(src io.Reader) T
+	// Accept returns the acceptance probability of a candidate in [0, 1].
+	Accept func(x T) float64
+	// MaxTries limits consecutive rejections of a single sample. If 0
+	// sampling retries until a proposal is accepted.
+	MaxTries int
+
+	proposals atomic.Uint64
+	accepted  atomic.Uint64
+}
+
+// Sample draws a value reading randomness from the default source.
+func (r *Rejection[T]) Sample() (T, error) {
+	return r.ReadSample(defaultSource())
+}
+
+// ReadSample draws a value reading randomness from a given source. It returns
+// ErrTooManyRejections if MaxTries proposals were rejected and will panic if
+// Accept returns a value outside [0, 1].
+func (r *Rejection[T]) ReadSample(src io.Reader) (T, error) {
+	for tries := 0; r.MaxTries == 0 || tries < r.MaxTries; tries++ {
+		x := r.Proposal(src)
+		r.proposals.Add(1)
+		if acceptProposal(src, r.Accept(x)) {
+			r.accepted.Add(1)
+			return x, nil
+		}
+	}
+	var zero T
+	return zero, ErrTooManyRejections
+}
+
+// Stats returns the total number of proposals drawn and accepted.
+func (r *Rejection[T]) Stats() (proposals, accepted uint64) {
+	// read accepted first so it never exceeds proposals
+	accepted = r.accepted.Load()
+	return r.proposals.Load(), accepted
+}
+
+// AcceptanceRate returns the share of accepted proposals, the inverse of the
+// effective envelope constant M. It is 0 before the first proposal.
+func (r *Rejection[T]) AcceptanceRate() float64 {
+	proposals, accepted := r.Stats()
+	if proposals == 0 {
+		return 0
+	}
+	return float64(accepted) / float64(proposals)
+}
+
+// acceptProposal returns true with probability p.
+func acceptProposal(src io.Reader, p float64) bool {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid acceptance probability in rejection sampling")
+	}
+	// ReadFloat64 is in [0, 1), p = 1 always accepts and p = 0 never does
+	return ReadFloat64(src) < p
+}
@@ -0,0 +1,66 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectionSample(t *testing.T) {
+	src := NewDRBG([]byte("rejection"))
+	// triangular density 2x on [0, 1) from uniform proposals, M = 2
+	sum := 0.0
+	for i := 0; i < 20000; i++ {
+		x := ReadRejectionSample(src, func() float64 { return ReadFloat64(src) }, func(x float64) float64 {
+			return x
+		})
+		sum += x
+	}
+	assert.InDelta(t, 2.0/3, sum/20000, 0.01)
+
+	assert.Panics(t, func() {
+		ReadRejectionSample(src, func() float64 { return 1 }, func(x float64) float64 { return 1.5 })
+	})
+	assert.Panics(t, func() {
+		ReadRejectionSample(src, func() float64 { return 1 }, func(x float64) float64 { return math.NaN() })
+	})
+}
+
+func TestRejection(t *testing.T) {
+	// standard half-normal from Exp(1) proposals, M = sqrt(2e/pi)
+	r := &Rejection[float64]{
+		Proposal: func(src io.Reader) float64 { return -math.Log(ReadFloat64Open(src)) },
+		Accept:   func(x float64) float64 { return math.Exp(-(x - 1) * (x - 1) / 2) },
+	}
+	src := NewDRBG([]byte("half-normal"))
+	sum := 0.0
+	for i := 0; i < 20000; i++ {
+		x, err := r.ReadSample(src)
+		require.NoError(t, err)
+		sum += x
+	}
+	assert.InDelta(t, math.Sqrt(2/math.Pi), sum/20000, 0.02)
+
+	proposals, accepted := r.Stats()
+	assert.Equal(t, uint64(20000), accepted)
+	assert.True(t, proposals > accepted)
+	assert.InDelta(t, math.Sqrt(math.Pi/(2*math.E)), r.AcceptanceRate(), 0.01)
+}
+
+func TestRejectionMaxTries(t *testing.T) {
+	r := &Rejection[int]{
+		Proposal: func(src io.Reader) int { return ReadIntn(src, 10) },
+		Accept:   func(x int) float64 { return 0 },
+		MaxTries: 5,
+	}
+	assert.Zero(t, r.AcceptanceRate())
+	x, err := r.ReadSample(NewDRBG(nil))
+	assert.Equal(t, ErrTooManyRejections, err)
+	assert.Zero(t, x)
+	proposals, accepted := r.Stats()
+	assert.Equal(t, uint64(5), proposals)
+	assert.Zero(t, accepted)
+}
@@ -0,0 +1,130 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/advbet/rng"
+)
+
+// Divergence describes the first draw where a live run differs from the
+// recorded transcript.
+type Divergence struct {
+	// Index is the index of the diverging draw.
+	Index int
+	// Field is the first differing part of the draw: "method", "args",
+	// "bytes", "result" or "extra" for draws past the end of the
+	// transcript.
+	Field string
+	// Recorded is the transcript entry, zero if Field is "extra".
+	Recorded Entry
+	// Live is the entry of the live draw.
+	Live Entry
+	// Offset is the index of the first differing byte if Field is "bytes".
+	Offset int
+}
+
+func (d *Divergence) Error() string {
+	switch d.Field {
+	case "extra":
+		return fmt.Sprintf("replay: draw %d %s%v is not in the transcript", d.Index, d.Live.Method, d.Live.Args)
+	case "bytes":
+		return fmt.Sprintf("replay: draw %d %s%v consumed different bytes at offset %d: recorded %x, live %x",
+			d.Index, d.Live.Method, d.Live.Args, d.Offset, d.Recorded.Bytes, d.Live.Bytes)
+	case "result":
+		return fmt.Sprintf("replay: draw %d %s%v returned %s, recorded %s",
+			d.Index, d.Live.Method, d.Live.Args, d.Live.Result, d.Recorded.Result)
+	default:
+		return fmt.Sprintf("replay: draw %d called %s%v, recorded %s%v",
+			d.Index, d.Live.Method, d.Live.Args, d.Recorded.Method, d.Recorded.Args)
+	}
+}
+
+// Checker is an rng.Interface running live draws while replaying a recorded
+// transcript. Draws always return live results, the first divergence from
+// the transcript is kept and passed to OnDivergence. It is safe for
+// concurrent use, draws are serialized.
+type Checker struct {
+	drawer
+	transcript []Entry
+	first      *Divergence
+
+	// OnDivergence, if set, is called once with the first divergence.
+	OnDivergence func(d *Divergence)
+}
+
+var _ rng.Interface = (*Checker)(nil)
+
+// NewChecker returns a Checker drawing from src and comparing draws against
+// transcript. If src is nil crypto/rand.Reader is used.
+func NewChecker(src io.Reader, transcript []Entry) *Checker {
+	c := &Checker{transcript: transcript}
+	c.src = rng.New(src).Source()
+	c.on = c.check
+	return c
+}
+
+func (c *Checker) check(live Entry) {
+	if c.first != nil {
+		return
+	}
+	d := compare(c.transcript, live)
+	if d == nil {
+		return
+	}
+	c.first = d
+	if c.OnDivergence != nil {
+		c.OnDivergence(d)
+	}
+}
+
+func compare(transcript []Entry, live Entry) *Divergence {
+	if live.Index >= len(transcript) {
+		return &Divergence{Index: live.Index, Field: "extra", Live: live}
+	}
+	rec := transcript[live.Index]
+	d := &Divergence{Index: live.Index, Recorded: rec, Live: live}
+	switch {
+	case rec.Method != live.Method:
+		d.Field = "method"
+	case !reflect.DeepEqual(normalize(rec.Args), normalize(live.Args)):
+		d.Field = "args"
+	case !bytes.Equal(rec.Bytes, live.Bytes):
+		d.Field = "bytes"
+		for d.Offset < len(rec.Bytes) && d.Offset < len(live.Bytes) && rec.Bytes[d.Offset] == live.Bytes[d.Offset] {
+			d.Offset++
+		}
+	case !bytes.Equal(rec.Result, live.Result):
+		d.Field = "result"
+	default:
+		return nil
+	}
+	return d
+}
+
+func normalize(args []int) []int {
+	if args == nil {
+		return []int{}
+	}
+	return args
+}
+
+// Divergence returns the first divergence or nil if all draws so far match
+// the transcript.
+func (c *Checker) Divergence() *Divergence {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.first
+}
+
+// Remaining returns the number of recorded draws not replayed yet.
+func (c *Checker) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n >= len(c.transcript) {
+		return 0
+	}
+	return len(c.transcript) - c.n
+}
@@ -0,0 +1,69 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func record(seed string) []Entry {
+	r := NewRecorder(rng.NewDRBG([]byte(seed)))
+	r.Intn(100)
+	r.Perm(5)
+	r.Float64()
+	return r.Entries()
+}
+
+func TestCheckerMatch(t *testing.T) {
+	c := NewChecker(rng.NewDRBG([]byte("seed")), record("seed"))
+	c.Intn(100)
+	c.Perm(5)
+	assert.Equal(t, 1, c.Remaining())
+	c.Float64()
+	assert.Nil(t, c.Divergence())
+	assert.Equal(t, 0, c.Remaining())
+
+	c.Intn(2)
+	d := c.Divergence()
+	require.NotNil(t, d)
+	assert.Equal(t, "extra", d.Field)
+	assert.Equal(t, 3, d.Index)
+}
+
+func TestCheckerArgs(t *testing.T) {
+	c := NewChecker(rng.NewDRBG([]byte("seed")), record("seed"))
+	c.Intn(100)
+	c.Perm(6)
+	d := c.Divergence()
+	require.NotNil(t, d)
+	assert.Equal(t, 1, d.Index)
+	assert.Equal(t, "args", d.Field)
+	assert.EqualError(t, d, "replay: draw 1 called Perm[6], recorded Perm[5]")
+}
+
+func TestCheckerBytes(t *testing.T) {
+	var got *Divergence
+	c := NewChecker(rng.NewDRBG([]byte("other")), record("seed"))
+	c.OnDivergence = func(d *Divergence) { got = d }
+	c.Intn(100)
+	c.Perm(5)
+
+	require.NotNil(t, got)
+	assert.Equal(t, got, c.Divergence())
+	assert.Equal(t, 0, got.Index)
+	assert.Equal(t, "bytes", got.Field)
+	assert.Equal(t, 0, got.Offset)
+	assert.Contains(t, got.Error(), "replay: draw 0 Intn[100] consumed different bytes at offset 0")
+}
+
+func TestCheckerResult(t *testing.T) {
+	transcript := record("seed")
+	transcript[0].Result = []byte("101")
+	c := NewChecker(rng.NewDRBG([]byte("seed")), transcript)
+	c.Intn(100)
+	d := c.Divergence()
+	require.NotNil(t, d)
+	assert.Equal(t, "result", d.Field)
+}
@@ -0,0 +1,164 @@
+// Package replay records draw transcripts and detects where a live run
+// diverges from a recorded one.
+//
+// A Recorder logs every draw: method, arguments, entropy bytes consumed and
+// result. A Checker runs the same draws live while replaying a recorded
+// transcript and reports the first divergence, the call index together with
+// recorded and live parameters and bytes, which makes "the replay doesn't
+// match" incidents practical to debug.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/advbet/rng"
+)
+
+// Entry is a single recorded draw.
+type Entry struct {
+	Index  int             `json:"i"`
+	Method string          `json:"method"`
+	Args   []int           `json:"args"`
+	Bytes  []byte          `json:"bytes"`
+	Result json.RawMessage `json:"result"`
+}
+
+// WriteTranscript writes entries as JSON lines.
+func WriteTranscript(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadTranscript reads JSON lines written by WriteTranscript.
+func ReadTranscript(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<24)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("replay: line %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// captureReader copies bytes read from src.
+type captureReader struct {
+	src io.Reader
+	buf []byte
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.src.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	return n, err
+}
+
+// drawer runs draws against a source and captures their entries.
+type drawer struct {
+	mu  sync.Mutex
+	src io.Reader
+	n   int
+	on  func(e Entry)
+}
+
+func (d *drawer) draw(method string, args []int, fn func(src io.Reader) interface{}) interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	capture := &captureReader{src: d.src}
+	result := fn(capture)
+	raw, err := json.Marshal(result)
+	if err != nil {
+		panic(err)
+	}
+	e := Entry{
+		Index:  d.n,
+		Method: method,
+		Args:   args,
+		Bytes:  capture.buf,
+		Result: raw,
+	}
+	d.n++
+	d.on(e)
+	return result
+}
+
+func (d *drawer) Intn(n int) int {
+	return d.draw("Intn", []int{n}, func(src io.Reader) interface{} {
+		return rng.ReadIntn(src, n)
+	}).(int)
+}
+
+func (d *drawer) Float64() float64 {
+	return d.draw("Float64", []int{}, func(src io.Reader) interface{} {
+		return rng.ReadFloat64(src)
+	}).(float64)
+}
+
+func (d *drawer) Perm(n int) []int {
+	return d.draw("Perm", []int{n}, func(src io.Reader) interface{} {
+		return rng.ReadPerm(src, n)
+	}).([]int)
+}
+
+func (d *drawer) Sample(n, k int) []int {
+	return d.draw("Sample", []int{n, k}, func(src io.Reader) interface{} {
+		return rng.ReadSample(src, n, k)
+	}).([]int)
+}
+
+// Shuffle records swapped index pairs as its result. swap is called after
+// the draw is recorded without holding the drawer lock, so it may draw again.
+func (d *drawer) Shuffle(n int, swap func(i, j int)) {
+	swaps := d.draw("Shuffle", []int{n}, func(src io.Reader) interface{} {
+		swaps := []int{}
+		rng.ReadShuffle(src, n, func(i, j int) {
+			swaps = append(swaps, i, j)
+		})
+		return swaps
+	}).([]int)
+	for k := 0; k < len(swaps); k += 2 {
+		swap(swaps[k], swaps[k+1])
+	}
+}
+
+// Recorder is an rng.Interface recording every draw. It is safe for
+// concurrent use, draws are serialized.
+type Recorder struct {
+	drawer
+	entries []Entry
+}
+
+var _ rng.Interface = (*Recorder)(nil)
+
+// NewRecorder returns a Recorder drawing from src. If src is nil
+// crypto/rand.Reader is used.
+func NewRecorder(src io.Reader) *Recorder {
+	r := &Recorder{}
+	r.src = rng.New(src).Source()
+	r.on = func(e Entry) { r.entries = append(r.entries, e) }
+	return r
+}
+
+// Entries returns draws recorded so far.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
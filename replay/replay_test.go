@@ -0,0 +1,54 @@
+package replay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(bytes.NewBuffer([]byte{0x05, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}))
+	assert.Equal(t, 5, r.Intn(10))
+	r.Shuffle(2, func(i, j int) {})
+
+	entries := r.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{Index: 0, Method: "Intn", Args: []int{10}, Bytes: []byte{0x05}, Result: []byte("5")}, entries[0])
+	assert.Equal(t, Entry{Index: 1, Method: "Shuffle", Args: []int{2}, Bytes: []byte{0x01}, Result: []byte("[1,1]")}, entries[1])
+}
+
+func TestRecorderShuffleReentrant(t *testing.T) {
+	r := NewRecorder(rng.NewDRBG([]byte("seed")))
+	s := []int{0, 1, 2, 3}
+	var draws []int
+	// swap may draw from the same recorder
+	r.Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+		draws = append(draws, r.Intn(6))
+	})
+	assert.Len(t, draws, 3)
+	entries := r.Entries()
+	require.Len(t, entries, 4)
+	assert.Equal(t, "Shuffle", entries[0].Method)
+	assert.Equal(t, "Intn", entries[3].Method)
+}
+
+func TestTranscriptRoundTrip(t *testing.T) {
+	r := NewRecorder(rng.NewDRBG([]byte("seed")))
+	r.Intn(100)
+	r.Float64()
+	r.Perm(5)
+	r.Sample(10, 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTranscript(&buf, r.Entries()))
+	entries, err := ReadTranscript(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, r.Entries(), entries)
+
+	_, err = ReadTranscript(bytes.NewBufferString("{\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package rngtest
+
+import (
+	"testing"
+)
+
+// Significance is the overall probability of a uniformity check failing for
+// an unbiased draw function. Checks running several tests divide it among them
+// (Bonferroni correction).
+const Significance = 0.001
+
+// CheckUniform calls draw samples times and verifies with a chi-square test
+// that results are uniformly distributed over [0, n). Values out of range fail
+// the check immediately. It returns the test p-value.
+//
+// Each of n values should be expected at least 5 times for the test to be
+// meaningful, i.e. samples >= 5*n.
+func CheckUniform(t testing.TB, draw func() int, n, samples int) float64 {
+	t.Helper()
+	if n < 2 || samples <= 0 {
+		panic("invalid argument to CheckUniform")
+	}
+
+	counts := make([]int, n)
+	for i := 0; i < samples; i++ {
+		v := draw()
+		if v < 0 || v >= n {
+			t.Errorf("rngtest: draw returned %d, must be in [0, %d)", v, n)
+			return 0
+		}
+		counts[v]++
+	}
+
+	p := chiSquareP(chiSquare(counts, samples), n-1)
+	if p < Significance {
+		t.Errorf("rngtest: draws are not uniform over [0, %d), p = %g (< %g), counts %v", n, p, Significance, counts)
+	}
+	return p
+}
+
+// CheckPermutationUniformity calls perm samples times and verifies that every
+// value is equally likely to appear at every position. It runs a chi-square
+// test per position with Bonferroni corrected significance. Results that are
+// not permutations of [0, n) fail the check immediately. It returns the lowest
+// p-value of all positions.
+func CheckPermutationUniformity(t testing.TB, perm func() []int, n, samples int) float64 {
+	t.Helper()
+	if n < 2 || samples <= 0 {
+		panic("invalid argument to CheckPermutationUniformity")
+	}
+
+	counts := make([][]int, n) // counts[position][value]
+	for i := range counts {
+		counts[i] = make([]int, n)
+	}
+	seen := make([]int, n)
+	for i := 0; i < samples; i++ {
+		p := perm()
+		if !isPerm(p, n, seen, i+1) {
+			t.Errorf("rngtest: %v is not a permutation of [0, %d)", p, n)
+			return 0
+		}
+		for pos, v := range p {
+			counts[pos][v]++
+		}
+	}
+
+	alpha := Significance / float64(n)
+	minP := 1.0
+	for pos := range counts {
+		p := chiSquareP(chiSquare(counts[pos], samples), n-1)
+		if p < minP {
+			minP = p
+		}
+		if p < alpha {
+			t.Errorf("rngtest: values at position %d are not uniform, p = %g (< %g), counts %v", pos, p, alpha, counts[pos])
+		}
+	}
+	return minP
+}
+
+// isPerm checks if p is a permutation of [0, n). seen is a scratch slice of
+// length n, mark must be different for each call.
+func isPerm(p []int, n int, seen []int, mark int) bool {
+	if len(p) != n {
+		return false
+	}
+	for _, v := range p {
+		if v < 0 || v >= n || seen[v] == mark {
+			return false
+		}
+		seen[v] = mark
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package rngtest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChiSquareP(t *testing.T) {
+	// reference values from chi-square distribution tables
+	tests := []struct {
+		x  float64
+		df int
+		p  float64
+	}{
+		{0, 3, 1},
+		{3.841, 1, 0.05},
+		{6.635, 1, 0.01},
+		{18.307, 10, 0.05},
+		{23.209, 10, 0.01},
+		{124.342, 100, 0.05},
+		{2, 2, math.Exp(-1)},
+	}
+
+	for _, test := range tests {
+		assert.InDelta(t, test.p, chiSquareP(test.x, test.df), 1e-4, "x = %g, df = %d", test.x, test.df)
+	}
+}
+
+func TestCheckUniform(t *testing.T) {
+	g := FromFuzz([]byte("uniform"))
+	p := CheckUniform(t, func() int { return g.Intn(10) }, 10, 10000)
+	assert.True(t, p >= Significance)
+
+	// naive modulo reduction of a byte is biased for n = 100
+	mt := &testing.T{}
+	CheckUniform(mt, func() int { return int(g.Uint64Bits(8)) % 100 }, 100, 100000)
+	assert.True(t, mt.Failed())
+
+	mt = &testing.T{}
+	CheckUniform(mt, func() int { return 10 }, 10, 1)
+	assert.True(t, mt.Failed())
+}
+
+func TestCheckPermutationUniformity(t *testing.T) {
+	g := FromFuzz([]byte("perm"))
+	p := CheckPermutationUniformity(t, func() []int { return g.Perm(5) }, 5, 5000)
+	assert.True(t, p >= Significance/5)
+
+	// naive shuffle swapping with any position is biased
+	mt := &testing.T{}
+	CheckPermutationUniformity(mt, func() []int {
+		p := []int{0, 1, 2, 3, 4}
+		for i := range p {
+			j := g.Intn(len(p))
+			p[i], p[j] = p[j], p[i]
+		}
+		return p
+	}, 5, 50000)
+	assert.True(t, mt.Failed())
+
+	mt = &testing.T{}
+	CheckPermutationUniformity(mt, func() []int { return []int{0, 0, 1} }, 3, 1)
+	assert.True(t, mt.Failed())
+
+}
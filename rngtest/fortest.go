@@ -0,0 +1,35 @@
+package rngtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/advbet/rng"
+)
+
+// SeedEnv is the environment variable mixed into ForTest seeds. Setting it to
+// a new value explores different draws, setting it to a value logged by a
+// failed test reproduces that run.
+const SeedEnv = "RNGTEST_SEED"
+
+// ForTest returns a deterministic Generator seeded with the test name and the
+// value of SeedEnv. Randomized tests using it draw the same values on every
+// run, and failed tests log the seed to rerun them with:
+//
+//	func TestSettle(t *testing.T) {
+//		g := rngtest.ForTest(t)
+//		...
+//	}
+func ForTest(t testing.TB) *rng.Generator {
+	t.Helper()
+	seed, salt := t.Name(), os.Getenv(SeedEnv)
+	if salt != "" {
+		seed = salt + "\x00" + seed
+	}
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("rngtest: draws seeded with test name %q and %s=%q", t.Name(), SeedEnv, salt)
+		}
+	})
+	return FromFuzz([]byte(seed))
+}
@@ -0,0 +1,47 @@
+package rngtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForTest(t *testing.T) {
+	t.Setenv(SeedEnv, "")
+	a := ForTest(t).Perm(20)
+	assert.Equal(t, FromFuzz([]byte(t.Name())).Perm(20), a)
+	t.Run("sub", func(t *testing.T) {
+		assert.NotEqual(t, a, ForTest(t).Perm(20))
+	})
+
+	t.Setenv(SeedEnv, "42")
+	b := ForTest(t).Perm(20)
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, b, ForTest(t).Perm(20))
+}
+
+func TestForTestLogsSeed(t *testing.T) {
+	t.Setenv(SeedEnv, "42")
+	rec := &logRecorder{TB: t}
+	ForTest(rec)
+	rec.failed = true
+	for _, f := range rec.cleanups {
+		f()
+	}
+	assert.Equal(t, []string{`rngtest: draws seeded with test name "TestForTestLogsSeed" and RNGTEST_SEED="42"`}, rec.logs)
+}
+
+// logRecorder captures logs and cleanups of a test that is reported failed.
+type logRecorder struct {
+	testing.TB
+	failed   bool
+	logs     []string
+	cleanups []func()
+}
+
+func (r *logRecorder) Failed() bool     { return r.failed }
+func (r *logRecorder) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+func (r *logRecorder) Logf(format string, args ...interface{}) {
+	r.logs = append(r.logs, fmt.Sprintf(format, args...))
+}
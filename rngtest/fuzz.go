@@ -0,0 +1,25 @@
+package rngtest
+
+import (
+	"testing/quick"
+
+	"github.com/advbet/rng"
+)
+
+// FromFuzz returns a deterministic Generator seeded with fuzz input data. The
+// same input always produces the same draws, so crashes found by the fuzzer
+// are reproducible:
+//
+//	f.Fuzz(func(t *testing.T, seed []byte) {
+//		g := rngtest.FromFuzz(seed)
+//		...
+//	})
+func FromFuzz(data []byte) *rng.Generator {
+	return rng.New(rng.NewDRBG(data))
+}
+
+// QuickConfig returns a testing/quick configuration drawing values from a
+// DRBG seeded with seed.
+func QuickConfig(seed []byte) *quick.Config {
+	return &quick.Config{Rand: FromFuzz(seed).MathRand()}
+}
@@ -0,0 +1,38 @@
+package rngtest
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromFuzz(t *testing.T) {
+	a := FromFuzz([]byte{1, 2, 3}).Perm(20)
+	b := FromFuzz([]byte{1, 2, 3}).Perm(20)
+	assert.Equal(t, a, b)
+}
+
+func TestQuickConfig(t *testing.T) {
+	var first, second []int
+	collect := func(dst *[]int) func(int) bool {
+		return func(n int) bool {
+			*dst = append(*dst, n)
+			return true
+		}
+	}
+	assert.NoError(t, quick.Check(collect(&first), QuickConfig([]byte("seed"))))
+	assert.NoError(t, quick.Check(collect(&second), QuickConfig([]byte("seed"))))
+	assert.Equal(t, first, second)
+}
+
+func FuzzFromFuzz(f *testing.F) {
+	f.Add([]byte("seed"))
+	f.Fuzz(func(t *testing.T, seed []byte) {
+		g := FromFuzz(seed)
+		n := g.Intn(100)
+		if n < 0 || n >= 100 {
+			t.Fatalf("Intn(100) = %d", n)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package rngtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/advbet/rng"
+)
+
+// UpdateGoldenEnv is the environment variable that makes CheckGolden rewrite
+// golden files instead of comparing against them when set to "1".
+const UpdateGoldenEnv = "RNGTEST_UPDATE_GOLDEN"
+
+// Op is a single draw call of a transcript script. Supported operations are
+// "uint64bits" (N bits), "intn" (N), "float64", "perm" (N), "sample" (N, K)
+// and "shuffle" (shuffles [0, N) and records the result). Algorithm selects a
+// version of "sample", e.g. rng.SampleV1, the current one if empty.
+type Op struct {
+	Op        string `json:"op"`
+	Algorithm string `json:"algorithm,omitempty"`
+	N         int    `json:"n,omitempty"`
+	K         int    `json:"k,omitempty"`
+}
+
+// transcriptLine is one line of a canonical transcript. Field order is part of
+// the format.
+type transcriptLine struct {
+	Index     int         `json:"i"`
+	Op        string      `json:"op"`
+	Algorithm string      `json:"algorithm,omitempty"`
+	N         int         `json:"n,omitempty"`
+	K         int         `json:"k,omitempty"`
+	Result    interface{} `json:"result"`
+}
+
+// ParseScript parses a JSON array of draw operations.
+func ParseScript(data []byte) ([]Op, error) {
+	var script []Op
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&script); err != nil {
+		return nil, fmt.Errorf("rngtest: parsing script: %w", err)
+	}
+	return script, nil
+}
+
+// Transcript executes script against a DRBG seeded with seed and returns a
+// canonical transcript: one JSON object per line holding operation index,
+// arguments and result. The same seed and script always produce byte
+// identical transcripts unless draw algorithms change.
+func Transcript(seed []byte, script []Op) ([]byte, error) {
+	g := rng.New(rng.NewDRBG(seed))
+	var buf bytes.Buffer
+	for i, op := range script {
+		result, err := execOp(g, op)
+		if err != nil {
+			return nil, fmt.Errorf("rngtest: operation %d: %w", i, err)
+		}
+		line, err := json.Marshal(transcriptLine{
+			Index:     i,
+			Op:        op.Op,
+			Algorithm: op.Algorithm,
+			N:         op.N,
+			K:         op.K,
+			Result:    result,
+		})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// CheckGolden reads a JSON script from scriptPath, produces its transcript for
+// seed and compares it with the golden file. If environment variable
+// RNGTEST_UPDATE_GOLDEN is set to 1 the golden file is rewritten instead.
+func CheckGolden(t testing.TB, seed []byte, scriptPath, goldenPath string) {
+	t.Helper()
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, err := ParseScript(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := Transcript(seed, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		if err := os.WriteFile(goldenPath, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("rngtest: %v (set %s=1 to create it)", err, UpdateGoldenEnv)
+	}
+	if diff := firstDiff(expected, actual); diff != "" {
+		t.Errorf("rngtest: transcript differs from golden file %s\n%s", goldenPath, diff)
+	}
+}
+
+// execOp executes a single operation converting draw panics to errors.
+func execOp(g *rng.Generator, op Op) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %v", op.Op, r)
+		}
+	}()
+
+	if op.Algorithm != "" && op.Op != "sample" {
+		return nil, fmt.Errorf("%s: algorithm %q not supported", op.Op, op.Algorithm)
+	}
+	switch op.Op {
+	case "uint64bits":
+		if op.N < 0 {
+			return nil, fmt.Errorf("uint64bits: negative number of bits")
+		}
+		return g.Uint64Bits(uint(op.N)), nil
+	case "intn":
+		return g.Intn(op.N), nil
+	case "float64":
+		return g.Float64(), nil
+	case "perm":
+		return g.Perm(op.N), nil
+	case "sample":
+		if op.Algorithm != "" {
+			return rng.ReadSampleVersion(g.Source(), op.Algorithm, op.N, op.K), nil
+		}
+		return g.Sample(op.N, op.K), nil
+	case "shuffle":
+		s := make([]int, op.N)
+		for i := range s {
+			s[i] = i
+		}
+		g.Shuffle(len(s), func(i, j int) {
+			s[i], s[j] = s[j], s[i]
+		})
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// firstDiff returns a description of the first differing line of two
+// transcripts or an empty string if they are equal.
+func firstDiff(expected, actual []byte) string {
+	if bytes.Equal(expected, actual) {
+		return ""
+	}
+	e := bytes.Split(expected, []byte("\n"))
+	a := bytes.Split(actual, []byte("\n"))
+	for i := 0; i < len(e) || i < len(a); i++ {
+		var el, al []byte
+		if i < len(e) {
+			el = e[i]
+		}
+		if i < len(a) {
+			al = a[i]
+		}
+		if !bytes.Equal(el, al) {
+			return fmt.Sprintf("line %d:\n-%s\n+%s", i+1, el, al)
+		}
+	}
+	return ""
+}
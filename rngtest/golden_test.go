@@ -0,0 +1,47 @@
+package rngtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckGolden(t *testing.T) {
+	// Golden file locks down draw algorithms, it must only be updated if
+	// outcomes for a given seed are intentionally changed.
+	CheckGolden(t, []byte("golden"), "testdata/script.json", "testdata/script.golden")
+}
+
+func TestTranscript(t *testing.T) {
+	script, err := ParseScript([]byte(`[{"op": "intn", "n": 6}, {"op": "perm", "n": 3}]`))
+	assert.NoError(t, err)
+
+	a, err := Transcript([]byte("seed"), script)
+	assert.NoError(t, err)
+	b, err := Transcript([]byte("seed"), script)
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.Regexp(t, `^{"i":0,"op":"intn","n":6,"result":[0-5]}\n{"i":1,"op":"perm","n":3,"result":\[[0-2],[0-2],[0-2]\]}\n$`, string(a))
+
+	_, err = Transcript(nil, []Op{{Op: "intn", N: 0}})
+	assert.Error(t, err)
+	_, err = Transcript(nil, []Op{{Op: "dice"}})
+	assert.Error(t, err)
+
+	versioned, err := Transcript([]byte("seed"), []Op{{Op: "sample", Algorithm: "sample/v1", N: 10, K: 8}})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^{"i":0,"op":"sample","algorithm":"sample/v1","n":10,"k":8,"result":\[`, string(versioned))
+	_, err = Transcript(nil, []Op{{Op: "sample", Algorithm: "sample/v0", N: 10, K: 2}})
+	assert.Error(t, err)
+	_, err = Transcript(nil, []Op{{Op: "intn", Algorithm: "intn/v1", N: 6}})
+	assert.Error(t, err)
+
+	_, err = ParseScript([]byte(`[{"op": "intn", "max": 6}]`))
+	assert.Error(t, err)
+}
+
+func TestFirstDiff(t *testing.T) {
+	assert.Equal(t, "", firstDiff([]byte("a\nb\n"), []byte("a\nb\n")))
+	assert.Equal(t, "line 2:\n-b\n+c", firstDiff([]byte("a\nb\n"), []byte("a\nc\n")))
+	assert.Equal(t, "line 3:\n-\n+d", firstDiff([]byte("a\nb\n"), []byte("a\nb\nd\n")))
+}
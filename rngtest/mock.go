@@ -0,0 +1,184 @@
+package rngtest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/advbet/rng"
+)
+
+// Call is a single recorded call to a Mock method.
+type Call struct {
+	Method string
+	Args   []int
+}
+
+func (c Call) String() string {
+	return fmt.Sprintf("%s%v", c.Method, c.Args)
+}
+
+// Mock is an rng.Interface implementation returning scripted values. Each
+// method consumes the next value from its script and panics if the script is
+// exhausted or the value is out of range for the call. Shuffle is driven by
+// Ints, consuming one value per Fisher–Yates step exactly like
+// rng.ReadShuffle.
+//
+// All calls are recorded and can be checked with AssertCalls. Mock is safe for
+// concurrent use.
+type Mock struct {
+	Ints    []int
+	Floats  []float64
+	Perms   [][]int
+	Samples [][]int
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ rng.Interface = (*Mock)(nil)
+
+// Intn returns the next scripted int.
+func (m *Mock) Intn(n int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("Intn", n)
+	return m.nextInt(n)
+}
+
+// Float64 returns the next scripted float.
+func (m *Mock) Float64() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("Float64")
+	if len(m.Floats) == 0 {
+		panic("rngtest: Mock.Floats script exhausted")
+	}
+	f := m.Floats[0]
+	m.Floats = m.Floats[1:]
+	if f < 0 || f >= 1 {
+		panic(fmt.Sprintf("rngtest: scripted Float64 value %g is out of range [0, 1)", f))
+	}
+	return f
+}
+
+// Perm returns the next scripted permutation.
+func (m *Mock) Perm(n int) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("Perm", n)
+	if len(m.Perms) == 0 {
+		panic("rngtest: Mock.Perms script exhausted")
+	}
+	p := m.Perms[0]
+	m.Perms = m.Perms[1:]
+	if len(p) != n {
+		panic(fmt.Sprintf("rngtest: scripted Perm(%d) value has length %d", n, len(p)))
+	}
+	return append([]int(nil), p...)
+}
+
+// Sample returns the next scripted sample.
+func (m *Mock) Sample(n int, k int) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.record("Sample", n, k)
+	if len(m.Samples) == 0 {
+		panic("rngtest: Mock.Samples script exhausted")
+	}
+	s := m.Samples[0]
+	m.Samples = m.Samples[1:]
+	if k > n {
+		k = n
+	}
+	if len(s) != k {
+		panic(fmt.Sprintf("rngtest: scripted Sample(%d, %d) value has length %d", n, k, len(s)))
+	}
+	return append([]int(nil), s...)
+}
+
+// Shuffle shuffles n elements using scripted ints as Fisher–Yates swap
+// indexes.
+func (m *Mock) Shuffle(n int, swap func(i, j int)) {
+	m.mu.Lock()
+	m.record("Shuffle", n)
+	js := make([]int, 0, n)
+	for i := n - 1; i > 0; i-- {
+		js = append(js, m.nextInt(i+1))
+	}
+	m.mu.Unlock()
+
+	// swap is called without holding the lock, it may use the mock
+	for k, i := 0, n-1; i > 0; k, i = k+1, i-1 {
+		swap(i, js[k])
+	}
+}
+
+// Calls returns all calls made so far.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// AssertCalls checks that exactly the given calls were made in order. It
+// reports a test error and returns false otherwise.
+func (m *Mock) AssertCalls(t testing.TB, calls ...Call) bool {
+	t.Helper()
+	actual := m.Calls()
+	if len(actual) == 0 && len(calls) == 0 {
+		return true
+	}
+	if !reflect.DeepEqual(normalizeCalls(actual), normalizeCalls(calls)) {
+		t.Errorf("rngtest: unexpected calls\nexpected: %v\nactual:   %v", calls, actual)
+		return false
+	}
+	return true
+}
+
+// AssertExhausted checks that all scripted values were consumed. It reports a
+// test error and returns false otherwise.
+func (m *Mock) AssertExhausted(t testing.TB) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Ints)+len(m.Floats)+len(m.Perms)+len(m.Samples) != 0 {
+		t.Errorf("rngtest: unused scripted values: %d ints, %d floats, %d perms, %d samples",
+			len(m.Ints), len(m.Floats), len(m.Perms), len(m.Samples))
+		return false
+	}
+	return true
+}
+
+// record appends a call to the call log, m.mu must be held.
+func (m *Mock) record(method string, args ...int) {
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// nextInt pops next scripted int and validates it is in [0, n), m.mu must be
+// held.
+func (m *Mock) nextInt(n int) int {
+	if len(m.Ints) == 0 {
+		panic("rngtest: Mock.Ints script exhausted")
+	}
+	v := m.Ints[0]
+	m.Ints = m.Ints[1:]
+	if v < 0 || v >= n {
+		panic(fmt.Sprintf("rngtest: scripted Intn(%d) value %d is out of range", n, v))
+	}
+	return v
+}
+
+// normalizeCalls replaces nil argument slices with empty ones so calls can be
+// compared with reflect.DeepEqual.
+func normalizeCalls(calls []Call) []Call {
+	out := make([]Call, len(calls))
+	for i, c := range calls {
+		if c.Args == nil {
+			c.Args = []int{}
+		}
+		out[i] = c
+	}
+	return out
+}
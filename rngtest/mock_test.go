@@ -0,0 +1,77 @@
+package rngtest
+
+import (
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+// rollDice is an example of game logic written against rng.Interface.
+func rollDice(r rng.Interface, n int) int {
+	sum := 0
+	for i := 0; i < n; i++ {
+		sum += r.Intn(6) + 1
+	}
+	return sum
+}
+
+func TestMock(t *testing.T) {
+	m := &Mock{
+		Ints:    []int{5, 0, 2, 0},
+		Floats:  []float64{0.25},
+		Perms:   [][]int{{1, 0, 2}},
+		Samples: [][]int{{7, 3}},
+	}
+
+	assert.Equal(t, 7, rollDice(m, 2))
+	assert.Equal(t, 0.25, m.Float64())
+	assert.Equal(t, []int{1, 0, 2}, m.Perm(3))
+	assert.Equal(t, []int{7, 3}, m.Sample(10, 2))
+
+	s := []string{"a", "b", "c"}
+	m.Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+	// same swaps as rng.ReadShuffle would do for Intn draws 2, 0
+	expected := []string{"a", "b", "c"}
+	rng.ReadShuffle(SequenceSource(2, 0), len(expected), func(i, j int) {
+		expected[i], expected[j] = expected[j], expected[i]
+	})
+	assert.Equal(t, expected, s)
+
+	m.AssertCalls(t,
+		Call{"Intn", []int{6}},
+		Call{"Intn", []int{6}},
+		Call{"Float64", nil},
+		Call{"Perm", []int{3}},
+		Call{"Sample", []int{10, 2}},
+		Call{"Shuffle", []int{3}},
+	)
+	m.AssertExhausted(t)
+}
+
+func TestMockPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		(&Mock{}).Intn(3)
+	})
+	assert.Panics(t, func() {
+		(&Mock{Ints: []int{3}}).Intn(3)
+	})
+	assert.Panics(t, func() {
+		(&Mock{Floats: []float64{1}}).Float64()
+	})
+	assert.Panics(t, func() {
+		(&Mock{Perms: [][]int{{0}}}).Perm(2)
+	})
+}
+
+func TestMockAssertions(t *testing.T) {
+	m := &Mock{Ints: []int{1, 2}}
+	m.Intn(2)
+
+	mt := &testing.T{}
+	assert.False(t, m.AssertCalls(mt, Call{"Intn", []int{3}}))
+	assert.False(t, m.AssertExhausted(mt))
+	assert.True(t, m.AssertCalls(t, Call{"Intn", []int{2}}))
+}
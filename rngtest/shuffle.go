@@ -0,0 +1,84 @@
+package rngtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ShuffleReport holds position–value frequencies of repeated shuffles.
+type ShuffleReport struct {
+	N      int
+	Trials int
+	// Counts[position][value] is the number of trials placing value at
+	// position.
+	Counts [][]int
+	// ChiSquare is Pearson's statistic of all cells against the uniform
+	// expectation Trials/N, with (N-1)^2 degrees of freedom as row and column
+	// sums are fixed.
+	ChiSquare float64
+	// P is the test p-value, low values indicate a biased shuffle.
+	P float64
+}
+
+// ShuffleBias calls perm(n) trials times and returns the position–value
+// frequency matrix with a chi-square uniformity test, e.g. to certify a
+// custom shuffle in-house. It returns an error if perm returns something other
+// than a permutation of [0, n). Each cell should be expected at least 5 times
+// for the test to be meaningful, i.e. trials >= 5*n.
+func ShuffleBias(perm func(n int) []int, n, trials int) (*ShuffleReport, error) {
+	if n < 2 || trials <= 0 {
+		panic("invalid argument to ShuffleBias")
+	}
+
+	r := &ShuffleReport{N: n, Trials: trials, Counts: make([][]int, n)}
+	for i := range r.Counts {
+		r.Counts[i] = make([]int, n)
+	}
+	seen := make([]int, n)
+	for i := 0; i < trials; i++ {
+		p := perm(n)
+		if !isPerm(p, n, seen, i+1) {
+			return nil, fmt.Errorf("rngtest: trial %d: %v is not a permutation of [0, %d)", i, p, n)
+		}
+		for pos, v := range p {
+			r.Counts[pos][v]++
+		}
+	}
+
+	for _, row := range r.Counts {
+		r.ChiSquare += chiSquare(row, trials)
+	}
+	r.P = chiSquareP(r.ChiSquare, (n-1)*(n-1))
+	return r, nil
+}
+
+// WriteCSV writes the frequency matrix as CSV with a header row of values and
+// one row per position, ready for spreadsheet heat maps:
+//
+//	position,0,1,2
+//	0,334,331,335
+//	...
+func (r *ShuffleReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	row := make([]string, r.N+1)
+	row[0] = "position"
+	for v := 0; v < r.N; v++ {
+		row[v+1] = strconv.Itoa(v)
+	}
+	if err := cw.Write(row); err != nil {
+		return err
+	}
+	for pos, counts := range r.Counts {
+		row[0] = strconv.Itoa(pos)
+		for v, c := range counts {
+			row[v+1] = strconv.Itoa(c)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
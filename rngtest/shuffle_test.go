@@ -0,0 +1,44 @@
+package rngtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffleBias(t *testing.T) {
+	g := rng.New(rng.NewDRBG([]byte("shuffle")))
+	r, err := ShuffleBias(g.Perm, 5, 5000)
+	require.NoError(t, err)
+	assert.Len(t, r.Counts, 5)
+	assert.Greater(t, r.P, Significance)
+
+	// naive swap-with-any shuffle is biased
+	naive := func(n int) []int {
+		p := make([]int, n)
+		for i := range p {
+			p[i] = i
+		}
+		for i := range p {
+			j := g.Intn(n)
+			p[i], p[j] = p[j], p[i]
+		}
+		return p
+	}
+	r, err = ShuffleBias(naive, 5, 20000)
+	require.NoError(t, err)
+	assert.Less(t, r.P, Significance)
+
+	_, err = ShuffleBias(func(n int) []int { return make([]int, n) }, 3, 10)
+	assert.Error(t, err)
+}
+
+func TestShuffleReportCSV(t *testing.T) {
+	r := &ShuffleReport{N: 2, Trials: 3, Counts: [][]int{{1, 2}, {2, 1}}}
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteCSV(&buf))
+	assert.Equal(t, "position,0,1\n0,1,2\n1,2,1\n", buf.String())
+}
@@ -0,0 +1,88 @@
+// Package rngtest provides helpers for testing code that uses the rng package.
+//
+// Instead of replacing the package default source with rng.SetDefaultSource
+// tests should pass one of the deterministic sources defined here to the Read
+// variants of rng functions or to rng.New.
+package rngtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"sync/atomic"
+)
+
+// FixedSource returns a source producing bytes given as a hex string and then
+// io.EOF. Whitespace in the hex string is ignored. It will panic if the string
+// is not valid hex.
+func FixedSource(hexBytes string) io.Reader {
+	b, err := hex.DecodeString(string(bytes.Join(bytes.Fields([]byte(hexBytes)), nil)))
+	if err != nil {
+		panic(err)
+	}
+	return bytes.NewReader(b)
+}
+
+// SequenceSource returns a source that serves exactly one of the given values
+// per Read call. Value is written in little endian byte order, truncated to
+// the length of the read buffer (or zero padded if the buffer is longer than
+// 8 bytes). io.EOF is returned once all values are consumed.
+//
+// Because rng draws read all the bytes they need in a single call, each value
+// is used as the raw random number of one draw, e.g. rng.ReadIntn(src, 6)
+// returns 4 for a value of 4 and rng.ReadUint64Bits(src, 64) returns the value
+// as is.
+func SequenceSource(values ...uint64) io.Reader {
+	return &sequenceSource{values: values}
+}
+
+type sequenceSource struct {
+	values []uint64
+}
+
+func (s *sequenceSource) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(s.values) == 0 {
+		return 0, io.EOF
+	}
+	v := s.values[0]
+	s.values = s.values[1:]
+	for i := range p {
+		p[i] = byte(v)
+		v >>= 8
+	}
+	return len(p), nil
+}
+
+// Counter is a source wrapper counting bytes and read calls passed through to
+// an inner source. It is safe for concurrent use if the inner source is.
+type Counter struct {
+	inner io.Reader
+	bytes int64
+	reads int64
+}
+
+// CountingSource wraps inner source with a Counter.
+func CountingSource(inner io.Reader) *Counter {
+	return &Counter{inner: inner}
+}
+
+// Read reads from the inner source and updates counters.
+func (c *Counter) Read(p []byte) (int, error) {
+	n, err := c.inner.Read(p)
+	atomic.AddInt64(&c.bytes, int64(n))
+	atomic.AddInt64(&c.reads, 1)
+	return n, err
+}
+
+// Bytes returns the number of bytes read so far.
+func (c *Counter) Bytes() int64 {
+	return atomic.LoadInt64(&c.bytes)
+}
+
+// Reads returns the number of Read calls made so far.
+func (c *Counter) Reads() int64 {
+	return atomic.LoadInt64(&c.reads)
+}
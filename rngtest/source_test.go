@@ -0,0 +1,40 @@
+package rngtest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedSource(t *testing.T) {
+	src := FixedSource("12 34\n56")
+	assert.Equal(t, uint64(0x563412), rng.ReadUint64Bits(src, 24))
+	assert.Panics(t, func() {
+		rng.ReadUint64Bits(src, 8)
+	})
+
+	assert.Panics(t, func() {
+		FixedSource("xyz")
+	})
+}
+
+func TestSequenceSource(t *testing.T) {
+	src := SequenceSource(4, 0xffff, 1<<63, 300)
+	assert.Equal(t, 4, rng.ReadIntn(src, 6))
+	assert.Equal(t, uint64(0xff), rng.ReadUint64Bits(src, 8))
+	assert.Equal(t, uint64(1<<63), rng.ReadUint64Bits(src, 64))
+	assert.Equal(t, 300, rng.ReadIntn(src, 1000))
+
+	_, err := src.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCountingSource(t *testing.T) {
+	src := CountingSource(FixedSource("0102030405060708090a"))
+	rng.ReadUint64Bits(src, 16)
+	rng.ReadFloat64(src)
+	assert.Equal(t, int64(9), src.Bytes())
+	assert.Equal(t, int64(2), src.Reads())
+}
@@ -0,0 +1,85 @@
+package rngtest
+
+import (
+	"math"
+)
+
+// chiSquare returns Pearson's chi-square statistic of observed counts against
+// uniform expectation.
+func chiSquare(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	chi2 := 0.0
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	return chi2
+}
+
+// chiSquareP returns the probability of chi-square statistic with df degrees
+// of freedom being greater or equal to x, i.e. the test p-value.
+func chiSquareP(x float64, df int) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return gammaQ(float64(df)/2, x/2)
+}
+
+// gammaQ returns the regularized upper incomplete gamma function Q(a, x).
+func gammaQ(a, x float64) float64 {
+	if x < a+1 {
+		return 1 - gammaPSeries(a, x)
+	}
+	return gammaQFraction(a, x)
+}
+
+const (
+	gammaEps      = 1e-15
+	gammaMaxIters = 1000
+)
+
+// gammaPSeries evaluates P(a, x) by its series representation, converges
+// quickly for x < a+1.
+func gammaPSeries(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	sum := 1 / a
+	term := sum
+	for n := 1; n < gammaMaxIters; n++ {
+		term *= x / (a + float64(n))
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*gammaEps {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+// gammaQFraction evaluates Q(a, x) by its continued fraction representation
+// using modified Lentz's method, converges quickly for x >= a+1.
+func gammaQFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	lg, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < gammaMaxIters; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEps {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
@@ -0,0 +1,205 @@
+package rngtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/advbet/rng"
+)
+
+// StreamPrefixSize is the number of raw source bytes recorded in a Vector.
+const StreamPrefixSize = 64
+
+// Vector is a cross-language reference test vector of a deterministic source:
+// seed, a sequence of draw calls and their expected outputs. Implementations
+// of draw verification in other languages are validated by recomputing the
+// stream prefix and every call result from the seed.
+type Vector struct {
+	// Mode is "drbg" for rng.NewDRBG(Seed) or "fair" for
+	// rng.FairSource(Seed, ClientSeed, Nonce).
+	Mode       string `json:"mode"`
+	Seed       string `json:"seed"` // hex encoded
+	ClientSeed string `json:"client_seed,omitempty"`
+	Nonce      uint64 `json:"nonce,omitempty"`
+	// Stream holds the first StreamPrefixSize bytes of the source, hex
+	// encoded.
+	Stream string       `json:"stream"`
+	Calls  []VectorCall `json:"calls"`
+}
+
+// VectorCall is a single draw of a Vector. Calls are executed in order on a
+// single generator, each call continues the stream where the previous one
+// stopped.
+type VectorCall struct {
+	Op string `json:"op"`
+	N  int    `json:"n,omitempty"`
+	K  int    `json:"k,omitempty"`
+	// Algorithm is the ID of the draw algorithm, e.g. "sample/v2", empty for
+	// "uint64bits" which has no versions.
+	Algorithm string `json:"algorithm,omitempty"`
+	// Entropy holds the source bytes consumed by the call, hex encoded.
+	Entropy string `json:"entropy"`
+	// Result is the draw outcome. Results of "uint64bits" are decimal
+	// strings because they do not fit JSON numbers of most languages.
+	Result interface{} `json:"result"`
+}
+
+// VectorScript is the call sequence of ReferenceVectors. It covers power of
+// two and rejection sampling ranges around byte boundaries, float draws, both
+// branches of rng.SampleV2 and shuffles. All ranges fit in 32-bit ints.
+var VectorScript = []Op{
+	{Op: "uint64bits", N: 0},
+	{Op: "uint64bits", N: 1},
+	{Op: "uint64bits", N: 7},
+	{Op: "uint64bits", N: 8},
+	{Op: "uint64bits", N: 9},
+	{Op: "uint64bits", N: 53},
+	{Op: "uint64bits", N: 63},
+	{Op: "uint64bits", N: 64},
+	{Op: "intn", N: 1},
+	{Op: "intn", N: 2},
+	{Op: "intn", N: 3},
+	{Op: "intn", N: 6},
+	{Op: "intn", N: 37},
+	{Op: "intn", N: 255},
+	{Op: "intn", N: 256},
+	{Op: "intn", N: 257},
+	{Op: "intn", N: 1000},
+	{Op: "intn", N: 65535},
+	{Op: "intn", N: 65537},
+	{Op: "intn", N: 1000000},
+	{Op: "intn", N: 1<<31 - 1},
+	{Op: "float64"},
+	{Op: "float64"},
+	{Op: "float64"},
+	{Op: "perm", N: 0},
+	{Op: "perm", N: 1},
+	{Op: "perm", N: 10},
+	{Op: "perm", N: 52},
+	{Op: "sample", Algorithm: rng.SampleV2, N: 49, K: 6},
+	{Op: "sample", Algorithm: rng.SampleV2, N: 10, K: 8},
+	{Op: "sample", Algorithm: rng.SampleV2, N: 5, K: 0},
+	{Op: "sample", Algorithm: rng.SampleV2, N: 5, K: 10},
+	{Op: "shuffle", N: 5},
+	{Op: "shuffle", N: 52},
+}
+
+// SampleVectorScript is the call sequence of ReferenceVectors covering every
+// other sample algorithm version on the same ranges as VectorScript.
+var SampleVectorScript = []Op{
+	{Op: "sample", Algorithm: rng.SampleV1, N: 49, K: 6},
+	{Op: "sample", Algorithm: rng.SampleV1, N: 10, K: 8},
+	{Op: "sample", Algorithm: rng.SampleV1, N: 5, K: 0},
+	{Op: "sample", Algorithm: rng.SampleV1, N: 5, K: 10},
+	{Op: "sample", Algorithm: rng.SampleV3, N: 49, K: 6},
+	{Op: "sample", Algorithm: rng.SampleV3, N: 10, K: 8},
+	{Op: "sample", Algorithm: rng.SampleV3, N: 5, K: 0},
+	{Op: "sample", Algorithm: rng.SampleV3, N: 5, K: 10},
+}
+
+// DRBGVector returns the vector of script executed against rng.NewDRBG(seed).
+func DRBGVector(seed []byte, script []Op) (*Vector, error) {
+	v := &Vector{Mode: "drbg", Seed: hex.EncodeToString(seed)}
+	return v, v.run(func() io.Reader { return rng.NewDRBG(seed) }, script)
+}
+
+// FairVector returns the vector of script executed against
+// rng.FairSource(serverSeed, clientSeed, nonce).
+func FairVector(serverSeed []byte, clientSeed string, nonce uint64, script []Op) (*Vector, error) {
+	v := &Vector{
+		Mode:       "fair",
+		Seed:       hex.EncodeToString(serverSeed),
+		ClientSeed: clientSeed,
+		Nonce:      nonce,
+	}
+	return v, v.run(func() io.Reader { return rng.FairSource(serverSeed, clientSeed, nonce) }, script)
+}
+
+// run records the stream prefix and call results of fresh sources.
+func (v *Vector) run(source func() io.Reader, script []Op) error {
+	prefix := make([]byte, StreamPrefixSize)
+	if _, err := io.ReadFull(source(), prefix); err != nil {
+		return err
+	}
+	v.Stream = hex.EncodeToString(prefix)
+
+	var entropy bytes.Buffer
+	g := rng.New(io.TeeReader(source(), &entropy))
+	v.Calls = make([]VectorCall, 0, len(script))
+	for i, op := range script {
+		entropy.Reset()
+		result, err := execOp(g, op)
+		if err != nil {
+			return fmt.Errorf("rngtest: operation %d: %w", i, err)
+		}
+		if r, ok := result.(uint64); ok {
+			result = strconv.FormatUint(r, 10)
+		}
+		algorithm := op.Algorithm
+		if a, ok := rng.CurrentAlgorithm(op.Op); ok && algorithm == "" {
+			algorithm = a.ID
+		}
+		v.Calls = append(v.Calls, VectorCall{
+			Op:        op.Op,
+			N:         op.N,
+			K:         op.K,
+			Algorithm: algorithm,
+			Entropy:   hex.EncodeToString(entropy.Bytes()),
+			Result:    result,
+		})
+	}
+	return nil
+}
+
+// ReferenceVectors returns VectorScript executed against DRBG and fair
+// sources for a fixed set of seeds, including empty seeds and extreme nonces,
+// followed by SampleVectorScript executed against the DRBG seeds. Published
+// vectors are never changed, new ones are only appended.
+func ReferenceVectors() ([]*Vector, error) {
+	var vs []*Vector
+	for _, seed := range []string{"", "golden", "\x00\xff"} {
+		v, err := DRBGVector([]byte(seed), VectorScript)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	for _, f := range []struct {
+		serverSeed string
+		clientSeed string
+		nonce      uint64
+	}{
+		{"", "", 0},
+		{"server seed", "player", 1},
+		{"server seed", "player", 1<<64 - 1},
+		{"\x00\xff", "ünicode:client", 42},
+	} {
+		v, err := FairVector([]byte(f.serverSeed), f.clientSeed, f.nonce, VectorScript)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	for _, seed := range []string{"", "golden", "\x00\xff"} {
+		v, err := DRBGVector([]byte(seed), SampleVectorScript)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// EncodeVectors writes vectors as an indented JSON array.
+func EncodeVectors(w io.Writer, vs []*Vector) error {
+	data, err := json.MarshalIndent(vs, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
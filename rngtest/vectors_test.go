@@ -0,0 +1,64 @@
+package rngtest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceVectors(t *testing.T) {
+	vs, err := ReferenceVectors()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, EncodeVectors(&buf, vs))
+
+	// Like the script golden file, vectors lock down draw algorithms and are
+	// consumed by verifiers in other languages.
+	const path = "testdata/vectors.json"
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	if diff := firstDiff(expected, buf.Bytes()); diff != "" {
+		t.Errorf("vectors differ from %s\n%s", path, diff)
+	}
+}
+
+func TestDRBGVector(t *testing.T) {
+	v, err := DRBGVector([]byte("seed"), []Op{{Op: "uint64bits", N: 64}, {Op: "intn", N: 6}})
+	require.NoError(t, err)
+	assert.Equal(t, "drbg", v.Mode)
+	assert.Equal(t, hex.EncodeToString([]byte("seed")), v.Seed)
+
+	stream := make([]byte, StreamPrefixSize)
+	rng.NewDRBG([]byte("seed")).Read(stream)
+	assert.Equal(t, hex.EncodeToString(stream), v.Stream)
+
+	// calls consume the stream in order
+	require.Len(t, v.Calls, 2)
+	assert.Equal(t, hex.EncodeToString(stream[:8]), v.Calls[0].Entropy)
+	assert.IsType(t, "", v.Calls[0].Result)
+	assert.Empty(t, v.Calls[0].Algorithm)
+	assert.Equal(t, "intn/v1", v.Calls[1].Algorithm)
+	assert.Equal(t, hex.EncodeToString(stream[8:9]), v.Calls[1].Entropy[:2])
+	assert.Equal(t, rng.New(bytes.NewBuffer(stream[8:])).Intn(6), v.Calls[1].Result)
+
+	_, err = DRBGVector(nil, []Op{{Op: "intn"}})
+	assert.Error(t, err)
+}
+
+func TestFairVector(t *testing.T) {
+	v, err := FairVector([]byte("server"), "client", 7, []Op{{Op: "perm", N: 3}})
+	require.NoError(t, err)
+	assert.Equal(t, "fair", v.Mode)
+	assert.Equal(t, "client", v.ClientSeed)
+	assert.Equal(t, uint64(7), v.Nonce)
+	assert.Equal(t, rng.New(rng.FairSource([]byte("server"), "client", 7)).Perm(3), v.Calls[0].Result)
+}
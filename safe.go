@@ -0,0 +1,116 @@
+package rng
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+)
+
+// SafeGenerator is a Generator that can be shared between goroutines even if
+// its source is not safe for concurrent use (e.g. DRBG or a buffered reader).
+// Each draw holds an internal lock for its whole duration, so bytes consumed
+// by a single draw are always contiguous in the source stream.
+//
+// Sources known to be safe for concurrent use (crypto/rand.Reader) are read
+// without locking.
+type SafeGenerator struct {
+	mu     sync.Mutex
+	src    io.Reader
+	locked bool
+}
+
+var _ Interface = (*SafeGenerator)(nil)
+
+// NewSafe returns a SafeGenerator reading randomness from src. If src is nil
+// crypto/rand.Reader is used.
+func NewSafe(src io.Reader) *SafeGenerator {
+	if src == nil {
+		src = rand.Reader
+	}
+	return &SafeGenerator{
+		src:    src,
+		locked: src != rand.Reader,
+	}
+}
+
+func (g *SafeGenerator) lock() {
+	if g.locked {
+		g.mu.Lock()
+	}
+}
+
+func (g *SafeGenerator) unlock() {
+	if g.locked {
+		g.mu.Unlock()
+	}
+}
+
+// Uint64Bits returns a random uint64 value in range [0, 2^n), see
+// ReadUint64Bits.
+func (g *SafeGenerator) Uint64Bits(n uint) uint64 {
+	g.lock()
+	defer g.unlock()
+	return ReadUint64Bits(g.src, n)
+}
+
+// Intn returns a non negative int in [0, n), see ReadIntn.
+func (g *SafeGenerator) Intn(n int) int {
+	g.lock()
+	defer g.unlock()
+	return ReadIntn(g.src, n)
+}
+
+// Int31n returns a non negative int32 in [0, n), see ReadInt31n.
+func (g *SafeGenerator) Int31n(n int32) int32 {
+	g.lock()
+	defer g.unlock()
+	return ReadInt31n(g.src, n)
+}
+
+// Float64 returns a random number in [0.0,1.0), see ReadFloat64.
+func (g *SafeGenerator) Float64() float64 {
+	g.lock()
+	defer g.unlock()
+	return ReadFloat64(g.src)
+}
+
+// Perm returns a random permutation of integers [0,n), see ReadPerm.
+func (g *SafeGenerator) Perm(n int) []int {
+	g.lock()
+	defer g.unlock()
+	return ReadPerm(g.src, n)
+}
+
+// Sample returns random k integers from a range [0 n), see ReadSample.
+func (g *SafeGenerator) Sample(n int, k int) []int {
+	g.lock()
+	defer g.unlock()
+	return ReadSample(g.src, n, k)
+}
+
+// SampleSorted returns random k integers from a range [0 n) in ascending
+// order, see ReadSampleSorted.
+func (g *SafeGenerator) SampleSorted(n int, k int) []int {
+	g.lock()
+	defer g.unlock()
+	return ReadSampleSorted(g.src, n, k)
+}
+
+// Shuffle randomizes the order of n elements, see ReadShuffle. Swap indexes
+// are drawn while holding the lock, swap itself is called without it so it may
+// use the generator.
+func (g *SafeGenerator) Shuffle(n int, swap func(i, j int)) {
+	if n < 0 {
+		panic("invalid argument to Shuffle")
+	}
+	js := make([]int, 0, n)
+	g.lock()
+	ReadShuffle(g.src, n, func(i, j int) {
+		js = append(js, j)
+	})
+	g.unlock()
+
+	for k, i := 0, n-1; i > 0; k, i = k+1, i-1 {
+		swap(i, js[k])
+	}
+}
@@ -0,0 +1,64 @@
+package rng
+
+import (
+	"io"
+	"math/bits"
+)
+
+// StratifiedSample draws an independent simple random sample from each
+// stratum of a population, see ReadStratifiedSample.
+func StratifiedSample(strataSizes []int, perStratum []int) [][]int {
+	return ReadStratifiedSample(defaultSource(), strataSizes, perStratum)
+}
+
+// SystematicSample draws a circular systematic sample of k items from a
+// population of n, see ReadSystematicSample.
+func SystematicSample(n, k int) []int {
+	return ReadSystematicSample(defaultSource(), n, k)
+}
+
+// ReadStratifiedSample draws a simple random sample without replacement of
+// perStratum[i] items from stratum i of size strataSizes[i] reading randomness
+// from a given source. Result i holds indexes within stratum i in draw order.
+// It will panic if slices have different lengths or a stratum is smaller than
+// its sample.
+func ReadStratifiedSample(src io.Reader, strataSizes []int, perStratum []int) [][]int {
+	if len(strataSizes) != len(perStratum) {
+		panic("invalid argument to StratifiedSample, slices length mismatch")
+	}
+	for i, size := range strataSizes {
+		if size < 0 || perStratum[i] < 0 || perStratum[i] > size {
+			panic("invalid argument to StratifiedSample, sample larger than stratum")
+		}
+	}
+
+	samples := make([][]int, len(strataSizes))
+	for i, size := range strataSizes {
+		samples[i] = ReadSample(src, size, perStratum[i])
+	}
+	return samples
+}
+
+// ReadSystematicSample draws a circular systematic sample of k items from a
+// population of n reading randomness from a given source. A random start s is
+// drawn from [0, n) and items (s + floor(i*n/k)) mod n for i in [0, k) are
+// selected, so every item is included with probability exactly k/n. Indexes
+// are returned in selection order. It will panic if k < 0 or k > n.
+func ReadSystematicSample(src io.Reader, n, k int) []int {
+	if k < 0 || k > n {
+		panic("invalid argument to SystematicSample")
+	}
+	sample := make([]int, k)
+	if k == 0 {
+		return sample
+	}
+
+	start := uint64(ReadIntn(src, n))
+	for i := range sample {
+		// floor(i*n/k) without overflow, i < k <= n so the quotient fits
+		hi, lo := bits.Mul64(uint64(i), uint64(n))
+		offset, _ := bits.Div64(hi, lo, uint64(k))
+		sample[i] = int((start + offset) % uint64(n))
+	}
+	return sample
+}
@@ -0,0 +1,51 @@
+package rng
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStratifiedSample(t *testing.T) {
+	samples := StratifiedSample([]int{100, 5, 0, 20}, []int{10, 5, 0, 1})
+	assert.Len(t, samples, 4)
+	for i, size := range []int{10, 5, 0, 1} {
+		assert.Len(t, samples[i], size)
+	}
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, samples[1])
+	for _, v := range samples[0] {
+		assert.True(t, v >= 0 && v < 100)
+	}
+
+	assert.Panics(t, func() {
+		StratifiedSample([]int{10}, []int{1, 2})
+	})
+	assert.Panics(t, func() {
+		StratifiedSample([]int{10}, []int{11})
+	})
+}
+
+func TestSystematicSample(t *testing.T) {
+	assert.Empty(t, SystematicSample(10, 0))
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, SystematicSample(5, 5))
+
+	// start 3, interval 2.5
+	s := ReadSystematicSample(bytes.NewBuffer([]byte{3}), 10, 4)
+	assert.Equal(t, []int{3, 5, 8, 0}, s)
+
+	s = SystematicSample(1000, 7)
+	unique := make(map[int]bool)
+	for _, v := range s {
+		unique[v] = true
+	}
+	assert.Len(t, unique, 7)
+
+	s = SystematicSample(math.MaxInt, 3)
+	assert.Len(t, s, 3)
+
+	assert.Panics(t, func() {
+		SystematicSample(3, 4)
+	})
+}
@@ -0,0 +1,52 @@
+package rng
+
+import (
+	"io"
+	"sort"
+	"time"
+)
+
+// RandomTimesInWindow returns count sorted random times in [from, to) at
+// least minGap apart, see ReadRandomTimesInWindow.
+func RandomTimesInWindow(from, to time.Time, count int, minGap time.Duration) []time.Time {
+	return ReadRandomTimesInWindow(defaultSource(), from, to, count, minGap)
+}
+
+// ReadRandomTimesInWindow returns count sorted random times in [from, to) at
+// least minGap apart reading randomness from a given source, e.g. surprise
+// bonus drops within a promotional window.
+//
+// Times are uniform over all valid schedules with nanosecond resolution:
+// count offsets are drawn uniformly from the window shortened by
+// (count-1)*minGap, sorted, and the i-th offset is shifted by i*minGap. Times
+// keep the location of from. It will panic if count or minGap is negative, or
+// count times do not fit in the window.
+func ReadRandomTimesInWindow(src io.Reader, from, to time.Time, count int, minGap time.Duration) []time.Time {
+	if count < 0 || minGap < 0 {
+		panic("invalid argument to RandomTimesInWindow")
+	}
+	if count == 0 {
+		return []time.Time{}
+	}
+	window := to.Sub(from)
+	// overflow of (count-1)*minGap means the times can not fit
+	if minGap > 0 && int64(count-1) > int64(window)/int64(minGap) {
+		panic("invalid argument to RandomTimesInWindow, window too short")
+	}
+	slack := window - time.Duration(count-1)*minGap
+	if slack <= 0 {
+		panic("invalid argument to RandomTimesInWindow, window too short")
+	}
+
+	offsets := make([]int64, count)
+	for i := range offsets {
+		offsets[i] = int64(readUint64n(src, uint64(slack)))
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	times := make([]time.Time, count)
+	for i, off := range offsets {
+		times[i] = from.Add(time.Duration(off) + time.Duration(i)*minGap)
+	}
+	return times
+}
@@ -0,0 +1,49 @@
+package rng
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomTimesInWindow(t *testing.T) {
+	from := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	to := from.Add(4 * time.Hour)
+	src := NewDRBG([]byte("bonus drops"))
+
+	firstHour := 0
+	for i := 0; i < 2000; i++ {
+		times := ReadRandomTimesInWindow(src, from, to, 5, 30*time.Minute)
+		assert.Len(t, times, 5)
+		for j, tm := range times {
+			assert.False(t, tm.Before(from))
+			assert.True(t, tm.Before(to))
+			if j > 0 {
+				assert.True(t, tm.Sub(times[j-1]) >= 30*time.Minute)
+			}
+		}
+		if times[0].Before(from.Add(time.Hour)) {
+			firstHour++
+		}
+	}
+	// first of 5 offsets in a 2h slack window is below 1h with
+	// probability 1 - (1/2)^5
+	assert.InDelta(t, 2000*31.0/32, firstHour, 30)
+
+	// tight fit leaves a single nanosecond of slack
+	times := ReadRandomTimesInWindow(src, from, from.Add(3*time.Hour+1), 4, time.Hour)
+	for j, tm := range times {
+		assert.Equal(t, from.Add(time.Duration(j)*time.Hour), tm)
+	}
+
+	assert.Empty(t, ReadRandomTimesInWindow(src, from, from, 0, time.Hour))
+	assert.Len(t, ReadRandomTimesInWindow(src, from, from.Add(1), 3, 0), 3)
+
+	assert.Panics(t, func() { ReadRandomTimesInWindow(src, from, to, -1, 0) })
+	assert.Panics(t, func() { ReadRandomTimesInWindow(src, from, to, 1, -1) })
+	assert.Panics(t, func() { ReadRandomTimesInWindow(src, from, from.Add(3*time.Hour), 4, time.Hour) })
+	assert.Panics(t, func() { ReadRandomTimesInWindow(src, to, from, 1, 0) })
+	assert.Panics(t, func() { ReadRandomTimesInWindow(src, from, to, math.MaxInt, time.Hour) })
+}
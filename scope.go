@@ -0,0 +1,176 @@
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// ErrQuotaExceeded is matched by errors.Is for all QuotaError values.
+var ErrQuotaExceeded = errors.New("rng: quota exceeded")
+
+// QuotaError is returned by Scope draws when the draw would exceed a scope
+// quota.
+type QuotaError struct {
+	Scope string // scope name
+	Limit string // "draws" or "bits"
+	Max   int64  // configured quota
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("rng: scope %q exceeded quota of %d %s", e.Scope, e.Max, e.Limit)
+}
+
+// Is reports QuotaError to match ErrQuotaExceeded.
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// Quota limits randomness consumed by a Scope. Zero values mean no limit.
+type Quota struct {
+	MaxDraws int64 // maximum number of draws
+	MaxBits  int64 // maximum number of bits read from the source
+	// MaxDrawsPerSec limits the average draw rate, draws block until the
+	// rate allows them. Bursts of up to one second worth of draws are
+	// served without delay.
+	MaxDrawsPerSec float64
+}
+
+// Scope is an accounting scope (e.g. a game round or session) drawing from a
+// Generator source. It counts draws and bits consumed and enforces a Quota.
+// Draws return a *QuotaError instead of reading from the source once the quota
+// would be exceeded, and wait for the draw rate limit, so a misbehaving game
+// loop can not starve other scopes sharing the source. Source read errors still
+// panic as with other draws.
+//
+// Scope is safe for concurrent use.
+type Scope struct {
+	name  string
+	src   io.Reader
+	enc   Encoding
+	quota Quota
+	rate  *tokenBucket // nil without a draw rate limit
+
+	mu    sync.Mutex
+	draws int64
+	bits  int64
+}
+
+// Scope returns a new accounting scope reading from the generator source.
+// Every scope has its own draw rate limit. It will panic if
+// quota.MaxDrawsPerSec is negative or not finite.
+func (g *Generator) Scope(name string, quota Quota) *Scope {
+	if !(quota.MaxDrawsPerSec >= 0) || math.IsInf(quota.MaxDrawsPerSec, 1) {
+		panic("invalid argument to Scope")
+	}
+	s := &Scope{name: name, src: g.src, enc: g.enc, quota: quota}
+	if quota.MaxDrawsPerSec > 0 {
+		s.rate = newTokenBucket(quota.MaxDrawsPerSec)
+	}
+	return s
+}
+
+// Name returns the scope name.
+func (s *Scope) Name() string {
+	return s.name
+}
+
+// Draws returns the number of successful draws made in the scope.
+func (s *Scope) Draws() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draws
+}
+
+// Bits returns the number of bits read from the source by the scope.
+func (s *Scope) Bits() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bits
+}
+
+// Reset zeroes scope counters, e.g. at the start of a new game round.
+func (s *Scope) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draws = 0
+	s.bits = 0
+}
+
+// Uint64Bits returns a random uint64 value in range [0, 2^n), see
+// ReadUint64Bits.
+func (s *Scope) Uint64Bits(n uint) (r uint64, err error) {
+	err = s.draw(func(src io.Reader) { r = ReadUint64Bits(src, n) })
+	return r, err
+}
+
+// Intn returns a non negative int in [0, n), see ReadIntn.
+func (s *Scope) Intn(n int) (r int, err error) {
+	err = s.draw(func(src io.Reader) { r = ReadIntn(src, n) })
+	return r, err
+}
+
+// Float64 returns a random number in [0.0,1.0), see ReadFloat64.
+func (s *Scope) Float64() (r float64, err error) {
+	err = s.draw(func(src io.Reader) { r = ReadFloat64(src) })
+	return r, err
+}
+
+// Perm returns a random permutation of integers [0,n), see ReadPerm.
+func (s *Scope) Perm(n int) (r []int, err error) {
+	err = s.draw(func(src io.Reader) { r = ReadPerm(src, n) })
+	return r, err
+}
+
+// Sample returns random k integers from a range [0 n), see ReadSample.
+func (s *Scope) Sample(n int, k int) (r []int, err error) {
+	err = s.draw(func(src io.Reader) { r = ReadSample(src, n, k) })
+	return r, err
+}
+
+// draw runs a single accounted draw. Quota violations detected by the source
+// wrapper surface as panics carrying *QuotaError and are converted to errors.
+// The draw rate limit is waited for before taking the scope lock, so Draws and
+// Bits do not block on it.
+func (s *Scope) draw(fn func(src io.Reader)) (err error) {
+	if s.rate != nil {
+		s.rate.wait(1)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quota.MaxDraws > 0 && s.draws >= s.quota.MaxDraws {
+		return &QuotaError{Scope: s.name, Limit: "draws", Max: s.quota.MaxDraws}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			qe, ok := r.(*QuotaError)
+			if !ok {
+				panic(r)
+			}
+			err = qe
+		}
+	}()
+	fn(EncodedSource(scopeReader{s}, s.enc))
+	s.draws++
+	return nil
+}
+
+// scopeReader counts bits read by a scope and enforces its bit quota. Scope
+// lock must be held while reading.
+type scopeReader struct {
+	s *Scope
+}
+
+func (r scopeReader) Read(p []byte) (int, error) {
+	s := r.s
+	if s.quota.MaxBits > 0 && s.bits+int64(len(p))*8 > s.quota.MaxBits {
+		return 0, &QuotaError{Scope: s.name, Limit: "bits", Max: s.quota.MaxBits}
+	}
+	n, err := s.src.Read(p)
+	s.bits += int64(n) * 8
+	return n, err
+}
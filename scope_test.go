@@ -0,0 +1,98 @@
+package rng
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopeAccounting(t *testing.T) {
+	s := New(NewDRBG(nil)).Scope("round-1", Quota{})
+	assert.Equal(t, "round-1", s.Name())
+
+	_, err := s.Intn(1000)
+	assert.NoError(t, err)
+	_, err = s.Float64()
+	assert.NoError(t, err)
+	_, err = s.Uint64Bits(64)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), s.Draws())
+	assert.True(t, s.Bits() >= 16+56+64)
+
+	s.Reset()
+	assert.Zero(t, s.Draws())
+	assert.Zero(t, s.Bits())
+}
+
+func TestScopeDrawQuota(t *testing.T) {
+	s := New(NewDRBG(nil)).Scope("round", Quota{MaxDraws: 2})
+	_, err := s.Perm(5)
+	assert.NoError(t, err)
+	_, err = s.Sample(10, 2)
+	assert.NoError(t, err)
+
+	_, err = s.Intn(10)
+	assert.True(t, errors.Is(err, ErrQuotaExceeded))
+	var qe *QuotaError
+	assert.True(t, errors.As(err, &qe))
+	assert.Equal(t, &QuotaError{Scope: "round", Limit: "draws", Max: 2}, qe)
+	assert.Equal(t, int64(2), s.Draws())
+
+	s.Reset()
+	_, err = s.Intn(10)
+	assert.NoError(t, err)
+}
+
+func TestScopeBitQuota(t *testing.T) {
+	s := New(NewDRBG(nil)).Scope("session", Quota{MaxBits: 64})
+	_, err := s.Uint64Bits(32)
+	assert.NoError(t, err)
+
+	// 40 bits would exceed the quota, nothing must be read from the source
+	_, err = s.Uint64Bits(40)
+	assert.Equal(t, &QuotaError{Scope: "session", Limit: "bits", Max: 64}, err)
+	assert.Equal(t, int64(32), s.Bits())
+	assert.Equal(t, int64(1), s.Draws())
+
+	_, err = s.Uint64Bits(32)
+	assert.NoError(t, err)
+}
+
+func TestScopeSourceError(t *testing.T) {
+	s := New(bytes.NewReader(nil)).Scope("broken", Quota{MaxBits: 64})
+	assert.Panics(t, func() {
+		s.Intn(10)
+	})
+}
+
+func TestScopeDrawRate(t *testing.T) {
+	g := New(NewDRBG(nil))
+	s := g.Scope("table-1", Quota{MaxDrawsPerSec: 2})
+	clock := time.Unix(0, 0)
+	var slept time.Duration
+	s.rate.last = clock
+	s.rate.now = func() time.Time { return clock }
+	s.rate.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+
+	// a burst of one second worth of draws is free, the next one waits
+	for i := 0; i < 3; i++ {
+		_, err := s.Intn(10)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 500*time.Millisecond, slept)
+
+	// scopes are limited independently
+	other := g.Scope("table-2", Quota{MaxDrawsPerSec: 2})
+	assert.NotSame(t, s.rate, other.rate)
+	assert.Nil(t, g.Scope("table-3", Quota{}).rate)
+
+	assert.Panics(t, func() { g.Scope("bad", Quota{MaxDrawsPerSec: -1}) })
+	assert.Panics(t, func() { g.Scope("bad", Quota{MaxDrawsPerSec: math.Inf(1)}) })
+}
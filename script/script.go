@@ -0,0 +1,262 @@
+// Package script runs declarative draw scripts and records their results in
+// signed transcripts.
+//
+// A script is a JSON list of labelled draw operations, e.g. a certification
+// lab test plan. Every operation names a registered rng draw algorithm and its
+// params, so plans run exactly as specified without custom Go programs. The
+// transcript holds an rng.DrawResult per draw, the SHA-256 of the script and
+// an Ed25519 signature over its canonical encoding.
+package script
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/advbet/rng"
+)
+
+// MaxRepeat limits the number of repetitions of a single operation.
+const MaxRepeat = 1 << 20
+
+// MaxOutcomes limits the total number of values drawn by a script, it bounds
+// the size of its transcript. Single draws of built-in types are limited to
+// rng.MaxDrawSize values.
+const MaxOutcomes = 1 << 24
+
+// ErrBadSignature is returned by Transcript.Verify for unsigned transcripts
+// or transcripts modified after signing.
+var ErrBadSignature = errors.New("script: invalid transcript signature")
+
+// Op is a draw operation of a script.
+type Op struct {
+	// Label names the operation in the transcript, e.g. a test plan step.
+	Label string `json:"label"`
+	// Type is the draw type, e.g. "intn" or "sample".
+	Type string `json:"type"`
+	// Algorithm is the ID of the draw algorithm, empty selects the current
+	// algorithm of Type.
+	Algorithm string `json:"algorithm,omitempty"`
+	// Params holds draw arguments by name, e.g. {"n": 49, "k": 6}.
+	Params map[string]int64 `json:"params,omitempty"`
+	// Repeat is the number of draws, zero means a single draw.
+	Repeat int `json:"repeat,omitempty"`
+}
+
+// Script is a named list of draw operations.
+type Script struct {
+	Name string `json:"name"`
+	Ops  []Op   `json:"ops"`
+
+	digest []byte // SHA-256 of the parsed text
+}
+
+// Parse reads a script in JSON. It returns an error for unknown fields,
+// empty or duplicate labels, unknown draw algorithms, params out of
+// [0, math.MaxInt] or sizes above rng.MaxDrawSize, repeats out of
+// [0, MaxRepeat] and scripts drawing more than MaxOutcomes values.
+func Parse(r io.Reader) (*Script, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	s := &Script{}
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("script: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	s.digest = sum[:]
+	return s, nil
+}
+
+// Validate returns an error if the script can not be run, see Parse.
+func (s *Script) Validate() error {
+	labels := make(map[string]bool, len(s.Ops))
+	var outcomes int64
+	for i, op := range s.Ops {
+		if op.Label == "" {
+			return fmt.Errorf("script: op %d has no label", i)
+		}
+		if labels[op.Label] {
+			return fmt.Errorf("script: duplicate label %q", op.Label)
+		}
+		labels[op.Label] = true
+		if _, err := op.algorithm(); err != nil {
+			return fmt.Errorf("script: op %q: %w", op.Label, err)
+		}
+		if op.Repeat < 0 || op.Repeat > MaxRepeat {
+			return fmt.Errorf("script: op %q repeat out of range", op.Label)
+		}
+		for name, v := range op.Params {
+			if v < 0 || v > math.MaxInt {
+				return fmt.Errorf("script: op %q param %q out of range", op.Label, name)
+			}
+		}
+		size := op.size()
+		if size > rng.MaxDrawSize {
+			return fmt.Errorf("script: op %q draws more than %d values", op.Label, rng.MaxDrawSize)
+		}
+		if op.Repeat > 1 {
+			size *= int64(op.Repeat)
+		}
+		if outcomes += size; outcomes > MaxOutcomes {
+			return fmt.Errorf("script: more than %d values drawn", MaxOutcomes)
+		}
+	}
+	return nil
+}
+
+// size returns the number of values drawn by a single draw of op, as bounded
+// by rng.MaxDrawSize for built-in types.
+func (op *Op) size() int64 {
+	switch op.Type {
+	case "perm", "shuffle":
+		return op.Params["n"]
+	case "sample":
+		if k := op.Params["k"]; k < op.Params["n"] {
+			return k
+		}
+		return op.Params["n"]
+	}
+	return 1
+}
+
+// Digest returns SHA-256 of the parsed script text, or of its JSON encoding
+// for scripts not created by Parse.
+func (s *Script) Digest() []byte {
+	if s.digest != nil {
+		return s.digest
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func (op *Op) algorithm() (rng.Algorithm, error) {
+	if op.Algorithm == "" {
+		if a, ok := rng.CurrentAlgorithm(op.Type); ok {
+			return a, nil
+		}
+	}
+	return rng.ResolveAlgorithm(op.Type, op.Algorithm)
+}
+
+// Entry is a transcript record of a single draw.
+type Entry struct {
+	Label string          `json:"label"`
+	Draw  *rng.DrawResult `json:"draw"`
+}
+
+// Transcript records the results of a script run.
+type Transcript struct {
+	Script       string `json:"script"`
+	ScriptDigest []byte `json:"script_digest"`
+	// Source describes the randomness source, e.g. a DRBG seed. It is set
+	// by the caller and covered by the signature.
+	Source  string  `json:"source,omitempty"`
+	Entries []Entry `json:"entries"`
+	// PublicKey and Signature are set by Sign.
+	PublicKey ed25519.PublicKey `json:"public_key,omitempty"`
+	Signature []byte            `json:"signature,omitempty"`
+}
+
+// Run executes all script operations in order reading randomness from src.
+// Draw IDs are operation labels, followed by "/" and a 1-based repetition
+// number for repeated operations. It returns an error if a draw panics, e.g.
+// on invalid params or source failure.
+func Run(src io.Reader, s *Script) (*Transcript, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	t := &Transcript{Script: s.Name, ScriptDigest: s.Digest(), Entries: []Entry{}}
+	for _, op := range s.Ops {
+		a, _ := op.algorithm()
+		n := op.Repeat
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			id := op.Label
+			if op.Repeat > 0 {
+				id = fmt.Sprintf("%s/%d", op.Label, i+1)
+			}
+			d, err := draw(src, a, id, op.Params)
+			if err != nil {
+				return nil, fmt.Errorf("script: op %q: %v", op.Label, err)
+			}
+			t.Entries = append(t.Entries, Entry{Label: op.Label, Draw: d})
+		}
+	}
+	return t, nil
+}
+
+func draw(src io.Reader, a rng.Algorithm, id string, params map[string]int64) (d *rng.DrawResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			d, err = nil, fmt.Errorf("%v", r)
+		}
+	}()
+	h := sha256.New()
+	d = &rng.DrawResult{
+		ID:        id,
+		Type:      a.Type,
+		Algorithm: a.ID,
+		Params:    params,
+		StartedAt: time.Now().UTC(),
+	}
+	d.Outcome = a.Run(io.TeeReader(src, h), params)
+	d.EntropyDigest = h.Sum(nil)
+	d.FinishedAt = time.Now().UTC()
+	return d, nil
+}
+
+// signed returns the signed encoding of the transcript: compact JSON with
+// draws in canonical form and the signature omitted.
+func (t *Transcript) signed() []byte {
+	c := *t
+	c.Signature = nil
+	c.Entries = make([]Entry, len(t.Entries))
+	for i, e := range t.Entries {
+		var d rng.DrawResult
+		if err := json.Unmarshal(e.Draw.Canonical(), &d); err != nil {
+			panic(err)
+		}
+		c.Entries[i] = Entry{Label: e.Label, Draw: &d}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Sign signs the transcript with an Ed25519 key, setting PublicKey and
+// Signature.
+func (t *Transcript) Sign(key ed25519.PrivateKey) {
+	t.PublicKey = key.Public().(ed25519.PublicKey)
+	t.Signature = ed25519.Sign(key, t.signed())
+}
+
+// Verify checks the transcript signature against its embedded public key.
+// Callers must also check that PublicKey is the key of the signing party. It
+// returns ErrBadSignature if the transcript is unsigned or was modified.
+func (t *Transcript) Verify() error {
+	if len(t.PublicKey) != ed25519.PublicKeySize || !ed25519.Verify(t.PublicKey, t.signed(), t.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
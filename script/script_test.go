@@ -0,0 +1,116 @@
+package script
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const plan = `{
+	"name": "lab plan",
+	"ops": [
+		{"label": "lotto", "type": "sample", "algorithm": "sample/v1", "params": {"n": 49, "k": 6}},
+		{"label": "dice", "type": "intn", "params": {"n": 6}, "repeat": 3},
+		{"label": "deck", "type": "perm", "params": {"n": 52}}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	s, err := Parse(strings.NewReader(plan))
+	require.NoError(t, err)
+	assert.Equal(t, "lab plan", s.Name)
+	assert.Len(t, s.Ops, 3)
+	sum := sha256.Sum256([]byte(plan))
+	assert.Equal(t, sum[:], s.Digest())
+
+	for _, bad := range []string{
+		`{"ops": [{"label": "a", "type": "intn", "params": {"n": 6}, "extra": 1}]}`,
+		`{"ops": [{"type": "intn", "params": {"n": 6}}]}`,
+		`{"ops": [{"label": "a", "type": "intn"}, {"label": "a", "type": "intn"}]}`,
+		`{"ops": [{"label": "a", "type": "dice"}]}`,
+		`{"ops": [{"label": "a", "type": "intn", "algorithm": "perm/v1"}]}`,
+		`{"ops": [{"label": "a", "type": "intn", "repeat": -1}]}`,
+		`{"ops": [{"label": "a", "type": "intn", "params": {"n": -6}}]}`,
+		`{"ops": [{"label": "a", "type": "perm", "params": {"n": 1000000000000}}]}`,
+		`{"ops": [{"label": "a", "type": "sample", "params": {"n": 1000000000000, "k": 2000000}}]}`,
+		`{"ops": [{"label": "a", "type": "shuffle", "params": {"n": 1000000}, "repeat": 100}]}`,
+		`[]`,
+	} {
+		_, err := Parse(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRun(t *testing.T) {
+	s, err := Parse(strings.NewReader(plan))
+	require.NoError(t, err)
+	tr, err := Run(rng.NewDRBG([]byte("seed")), s)
+	require.NoError(t, err)
+
+	assert.Equal(t, "lab plan", tr.Script)
+	assert.Equal(t, s.Digest(), tr.ScriptDigest)
+	require.Len(t, tr.Entries, 5)
+	var ids []string
+	for _, e := range tr.Entries {
+		ids = append(ids, e.Draw.ID)
+	}
+	assert.Equal(t, []string{"lotto", "dice/1", "dice/2", "dice/3", "deck"}, ids)
+	assert.Equal(t, "sample/v1", tr.Entries[0].Draw.Algorithm)
+	assert.Equal(t, "intn/v1", tr.Entries[1].Draw.Algorithm)
+	assert.Len(t, tr.Entries[4].Draw.Outcome, 52)
+
+	// draws continue on the same source in script order
+	src := rng.NewDRBG([]byte("seed"))
+	for _, e := range tr.Entries {
+		assert.NoError(t, rng.VerifyDrawResult(src, e.Draw), e.Draw.ID)
+	}
+
+	// same seed, same outcomes
+	tr2, err := Run(rng.NewDRBG([]byte("seed")), s)
+	require.NoError(t, err)
+	for i := range tr.Entries {
+		assert.Equal(t, tr.Entries[i].Draw.Outcome, tr2.Entries[i].Draw.Outcome)
+	}
+
+	bad := &Script{Ops: []Op{{Label: "a", Type: "intn", Params: map[string]int64{"n": 0}}}}
+	_, err = Run(rng.NewDRBG(nil), bad)
+	assert.Error(t, err)
+	// scripts built in Go are validated too
+	huge := &Script{Ops: []Op{{Label: "a", Type: "perm", Params: map[string]int64{"n": 1 << 40}}}}
+	_, err = Run(rng.NewDRBG(nil), huge)
+	assert.Error(t, err)
+}
+
+func TestSign(t *testing.T) {
+	s, err := Parse(strings.NewReader(plan))
+	require.NoError(t, err)
+	tr, err := Run(rng.NewDRBG([]byte("seed")), s)
+	require.NoError(t, err)
+	tr.Source = "drbg seed=73656564"
+
+	assert.True(t, errors.Is(tr.Verify(), ErrBadSignature))
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	tr.Sign(key)
+	assert.NoError(t, tr.Verify())
+
+	// signature survives a JSON round trip
+	b, err := json.Marshal(tr)
+	require.NoError(t, err)
+	var decoded Transcript
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.NoError(t, decoded.Verify())
+
+	decoded.Source = "crypto/rand"
+	assert.True(t, errors.Is(decoded.Verify(), ErrBadSignature))
+	decoded.Source = tr.Source
+	decoded.Entries[1].Draw.Outcome[0]++
+	assert.True(t, errors.Is(decoded.Verify(), ErrBadSignature))
+}
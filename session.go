@@ -0,0 +1,141 @@
+package rng
+
+import (
+	"errors"
+	"math"
+	"sync"
+)
+
+// ErrNonceReused is returned by Session.Use for nonces at or below the last
+// used one.
+var ErrNonceReused = errors.New("rng: session nonce already used")
+
+// Session is a provably fair draw session: a server seed committed to with
+// ServerSeedHash, a player supplied client seed and a monotonically increasing
+// nonce. Every draw uses the next nonce and reads from its FairSource stream,
+// so a nonce is never reused for the same seed pair.
+//
+// Session implements Interface and is safe for concurrent use.
+type Session struct {
+	mu         sync.Mutex
+	serverSeed []byte
+	clientSeed string
+	next       uint64 // next unused nonce
+	exhausted  bool
+}
+
+var _ Interface = (*Session)(nil)
+
+// NewSession returns a session drawing with the given seeds starting at nonce
+// 0. serverSeed must be kept secret until the session is rotated.
+func NewSession(serverSeed []byte, clientSeed string) *Session {
+	return &Session{
+		serverSeed: append([]byte(nil), serverSeed...),
+		clientSeed: clientSeed,
+	}
+}
+
+// ServerSeedHash returns the commitment to the current server seed, see
+// HashServerSeed.
+func (s *Session) ServerSeedHash() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return HashServerSeed(s.serverSeed)
+}
+
+// ClientSeed returns the current client seed.
+func (s *Session) ClientSeed() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientSeed
+}
+
+// Nonce returns the nonce that will be used by the next draw.
+func (s *Session) Nonce() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
+
+// Rotate replaces the seed pair, resets the nonce to 0 and returns the
+// previous server seed so it can be revealed to players. It panics if the
+// server seed is not changed.
+func (s *Session) Rotate(serverSeed []byte, clientSeed string) (revealed []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if string(serverSeed) == string(s.serverSeed) {
+		panic("invalid argument to Rotate")
+	}
+	revealed = s.serverSeed
+	s.serverSeed = append([]byte(nil), serverSeed...)
+	s.clientSeed = clientSeed
+	s.next = 0
+	s.exhausted = false
+	return revealed
+}
+
+// Next reserves the next nonce and returns it with a Generator reading from
+// its fair stream. The generator must only be used for a single draw. It
+// panics once all nonces of the seed pair are used.
+func (s *Session) Next() (uint64, *Generator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.exhausted {
+		panic("rng: session nonces exhausted, rotate seeds")
+	}
+	nonce := s.next
+	if nonce == math.MaxUint64 {
+		s.exhausted = true
+	} else {
+		s.next++
+	}
+	return nonce, New(FairSource(s.serverSeed, s.clientSeed, nonce))
+}
+
+// Use reserves a given nonce, e.g. one restored from storage, and returns a
+// Generator reading from its fair stream. Later draws continue after nonce.
+// It returns ErrNonceReused if nonce is lower than Nonce().
+func (s *Session) Use(nonce uint64) (*Generator, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if nonce < s.next || s.exhausted {
+		return nil, ErrNonceReused
+	}
+	if nonce == math.MaxUint64 {
+		s.exhausted = true
+	} else {
+		s.next = nonce + 1
+	}
+	return New(FairSource(s.serverSeed, s.clientSeed, nonce)), nil
+}
+
+// Intn returns a non negative int in [0, n) drawn with the next nonce.
+func (s *Session) Intn(n int) int {
+	_, g := s.Next()
+	return g.Intn(n)
+}
+
+// Float64 returns a random number in [0.0,1.0) drawn with the next nonce.
+func (s *Session) Float64() float64 {
+	_, g := s.Next()
+	return g.Float64()
+}
+
+// Perm returns a random permutation of [0,n) drawn with the next nonce.
+func (s *Session) Perm(n int) []int {
+	_, g := s.Next()
+	return g.Perm(n)
+}
+
+// Sample returns random k integers from a range [0 n) drawn with the next
+// nonce.
+func (s *Session) Sample(n int, k int) []int {
+	_, g := s.Next()
+	return g.Sample(n, k)
+}
+
+// Shuffle randomizes the order of n elements with the next nonce.
+func (s *Session) Shuffle(n int, swap func(i, j int)) {
+	_, g := s.Next()
+	g.Shuffle(n, swap)
+}
@@ -0,0 +1,46 @@
+package rng
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	s := NewSession([]byte("server"), "client")
+	assert.Equal(t, HashServerSeed([]byte("server")), s.ServerSeedHash())
+	assert.Equal(t, "client", s.ClientSeed())
+
+	// draws match fair streams of consecutive nonces
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		want := ReadIntn(FairSource([]byte("server"), "client", nonce), 1000)
+		assert.Equal(t, want, s.Intn(1000))
+	}
+	assert.Equal(t, uint64(3), s.Nonce())
+
+	nonce, g := s.Next()
+	assert.Equal(t, uint64(3), nonce)
+	assert.Equal(t, ReadPerm(FairSource([]byte("server"), "client", 3), 5), g.Perm(5))
+
+	_, err := s.Use(2)
+	assert.Equal(t, ErrNonceReused, err)
+	_, err = s.Use(10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(11), s.Nonce())
+
+	revealed := s.Rotate([]byte("server2"), "client2")
+	assert.Equal(t, []byte("server"), revealed)
+	assert.Equal(t, uint64(0), s.Nonce())
+	assert.Panics(t, func() { s.Rotate([]byte("server2"), "client3") })
+}
+
+func TestSessionExhausted(t *testing.T) {
+	s := NewSession([]byte("server"), "client")
+	_, err := s.Use(math.MaxUint64)
+	require.NoError(t, err)
+	assert.Panics(t, func() { s.Next() })
+	_, err = s.Use(math.MaxUint64)
+	assert.Equal(t, ErrNonceReused, err)
+}
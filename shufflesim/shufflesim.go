@@ -0,0 +1,193 @@
+// Package shufflesim simulates physical card shuffling (riffles, cuts and
+// strips) for demonstrations and visualizations.
+//
+// Physical shuffles are NOT uniform, a deck riffled a few times is still far
+// from random. Real games must use rng.Perm or rng.Shuffle, which implement
+// the uniform Fisher–Yates shuffle. RiffleDistance quantifies how far
+// simulated riffles are from uniform.
+package shufflesim
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/advbet/rng"
+)
+
+// MaxRiffles is the largest number of riffles accepted by RiffleDistance and
+// searched by RifflesNeeded. A 52 card deck is within 1e-15 of uniform after
+// that many riffles.
+const MaxRiffles = 64
+
+// ErrTooManyRiffles is returned by RifflesNeeded if more than MaxRiffles
+// riffles are needed.
+var ErrTooManyRiffles = errors.New("shufflesim: more than MaxRiffles riffles needed")
+
+// Simulator performs physical shuffle simulations drawing randomness from a
+// source.
+type Simulator struct {
+	src io.Reader
+}
+
+// New returns a Simulator reading randomness from src. If src is nil the rng
+// package default source is used.
+func New(src io.Reader) *Simulator {
+	if src == nil {
+		src = rng.DefaultSource()
+	}
+	return &Simulator{src: src}
+}
+
+// Deck returns an ordered deck [0, n).
+func Deck(n int) []int {
+	deck := make([]int, n)
+	for i := range deck {
+		deck[i] = i
+	}
+	return deck
+}
+
+// Riffle returns deck after a single riffle shuffle following the
+// Gilbert–Shannon–Reeds model: the deck is cut into two packets with a
+// binomially distributed cut position, then cards are dropped one at a time
+// from a packet chosen with probability proportional to its size.
+func (s *Simulator) Riffle(deck []int) []int {
+	cut := s.binomial(len(deck))
+	left, right := deck[:cut], deck[cut:]
+	out := make([]int, 0, len(deck))
+	for len(left) > 0 || len(right) > 0 {
+		if rng.ReadIntn(s.src, len(left)+len(right)) < len(left) {
+			out = append(out, left[0])
+			left = left[1:]
+		} else {
+			out = append(out, right[0])
+			right = right[1:]
+		}
+	}
+	return out
+}
+
+// Cut returns deck after a single cut at a binomially distributed position,
+// the bottom packet is placed on top.
+func (s *Simulator) Cut(deck []int) []int {
+	cut := s.binomial(len(deck))
+	out := make([]int, 0, len(deck))
+	out = append(out, deck[cut:]...)
+	return append(out, deck[:cut]...)
+}
+
+// Strip returns deck after a strip (overhand) shuffle: the deck is broken
+// into packets, each gap between adjacent cards is a break with probability
+// p, and packet order is reversed. It will panic if p is not in [0, 1].
+func (s *Simulator) Strip(deck []int, p float64) []int {
+	if !(p >= 0 && p <= 1) {
+		panic("invalid probability argument to Strip")
+	}
+	out := make([]int, 0, len(deck))
+	end := len(deck)
+	for i := len(deck) - 1; i > 0; i-- {
+		if rng.ReadFloat64(s.src) < p {
+			out = append(out, deck[i:end]...)
+			end = i
+		}
+	}
+	return append(out, deck[:end]...)
+}
+
+// binomial returns a Binomial(n, 1/2) random number by counting set bits.
+func (s *Simulator) binomial(n int) int {
+	count := 0
+	for ; n >= 64; n -= 64 {
+		count += popcount(rng.ReadUint64Bits(s.src, 64))
+	}
+	return count + popcount(rng.ReadUint64Bits(s.src, uint(n)))
+}
+
+func popcount(x uint64) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
+// RiffleDistance returns the exact total variation distance between the
+// distribution of an n card deck after k GSR riffles and the uniform
+// distribution. It uses the Bayer–Diaconis formula: a permutation with r
+// rising sequences has probability C(2^k + n - r, n) / 2^(kn). It will panic
+// if n < 0 or k is not in [0, MaxRiffles].
+func RiffleDistance(n, k int) float64 {
+	if n < 0 || k < 0 || k > MaxRiffles {
+		panic("invalid argument to RiffleDistance")
+	}
+	if n <= 1 {
+		return 0
+	}
+	eulerian := eulerianRow(n)
+	twoK := new(big.Int).Lsh(big.NewInt(1), uint(k))
+	denominator := new(big.Int).Lsh(big.NewInt(1), uint(k*n))
+	factorial := new(big.Int).MulRange(1, int64(n))
+	uniform := new(big.Rat).SetFrac(big.NewInt(1), factorial)
+
+	sum := new(big.Rat)
+	for r := 1; r <= n; r++ {
+		// C(2^k + n - r, n) = (2^k + n - r)! / (2^k - r)! / n!, zero
+		// if 2^k < r
+		c := new(big.Int)
+		if twoK.Cmp(big.NewInt(int64(r))) >= 0 {
+			c.SetInt64(1)
+			top := new(big.Int).Add(twoK, big.NewInt(int64(n-r)))
+			for i := 0; i < n; i++ {
+				c.Mul(c, top)
+				top.Sub(top, big.NewInt(1))
+			}
+			c.Quo(c, factorial)
+		}
+		diff := new(big.Rat).SetFrac(c, denominator)
+		diff.Sub(diff, uniform)
+		diff.Abs(diff)
+		sum.Add(sum, diff.Mul(diff, new(big.Rat).SetInt(eulerian[r-1])))
+	}
+	sum.Mul(sum, big.NewRat(1, 2))
+	f, _ := sum.Float64()
+	return f
+}
+
+// RifflesNeeded returns the smallest number of GSR riffles bringing an n card
+// deck within total variation distance eps of uniform, e.g. 7 for a 52 card
+// deck and eps = 0.5. It returns ErrTooManyRiffles if more than MaxRiffles
+// riffles are needed and an error if eps is not positive.
+func RifflesNeeded(n int, eps float64) (int, error) {
+	if !(eps > 0) {
+		return 0, errors.New("shufflesim: distance must be positive")
+	}
+	for k := 0; k <= MaxRiffles; k++ {
+		if RiffleDistance(n, k) < eps {
+			return k, nil
+		}
+	}
+	return 0, ErrTooManyRiffles
+}
+
+// eulerianRow returns Eulerian numbers A(n, m) for m in [0, n), the number of
+// permutations of n elements with m descents (m+1 rising sequences).
+func eulerianRow(n int) []*big.Int {
+	row := []*big.Int{big.NewInt(1)}
+	for i := 2; i <= n; i++ {
+		next := make([]*big.Int, i)
+		for m := range next {
+			// A(i, m) = (i - m) A(i-1, m-1) + (m + 1) A(i-1, m)
+			v := new(big.Int)
+			if m > 0 {
+				v.Add(v, new(big.Int).Mul(big.NewInt(int64(i-m)), row[m-1]))
+			}
+			if m < len(row) {
+				v.Add(v, new(big.Int).Mul(big.NewInt(int64(m+1)), row[m]))
+			}
+			next[m] = v
+		}
+		row = next
+	}
+	return row
+}
@@ -0,0 +1,80 @@
+package shufflesim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffles(t *testing.T) {
+	s := New(rng.NewDRBG([]byte("sim")))
+	deck := Deck(52)
+	assert.ElementsMatch(t, deck, s.Riffle(deck))
+	assert.ElementsMatch(t, deck, s.Cut(deck))
+	assert.ElementsMatch(t, deck, s.Strip(deck, 0.2))
+	assert.Equal(t, Deck(52), deck, "input deck must not be modified")
+
+	// no breaks keeps the deck, break everywhere reverses it
+	assert.Equal(t, deck, s.Strip(deck, 0))
+	reversed := s.Strip(Deck(5), 1)
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, reversed)
+
+	assert.Empty(t, s.Riffle(nil))
+	assert.Panics(t, func() {
+		s.Strip(deck, 2)
+	})
+}
+
+func TestRiffleKeepsRisingSequences(t *testing.T) {
+	// single riffle interleaves two packets, result has at most two rising
+	// sequences
+	s := New(nil)
+	deck := s.Riffle(Deck(52))
+	pos := make([]int, len(deck))
+	for i, c := range deck {
+		pos[c] = i
+	}
+	rising := 1
+	for c := 1; c < len(pos); c++ {
+		if pos[c] < pos[c-1] {
+			rising++
+		}
+	}
+	assert.True(t, rising <= 2)
+}
+
+func TestEulerianRow(t *testing.T) {
+	row := eulerianRow(4)
+	assert.Equal(t, "[1 11 11 1]", fmt.Sprint(row))
+}
+
+func TestRiffleDistance(t *testing.T) {
+	assert.Equal(t, 0.0, RiffleDistance(1, 0))
+	assert.InDelta(t, 0.5, RiffleDistance(2, 0), 1e-12)
+	// Bayer–Diaconis table for 52 cards
+	assert.InDelta(t, 1.000, RiffleDistance(52, 4), 1e-3)
+	assert.InDelta(t, 0.334, RiffleDistance(52, 7), 1e-3)
+	assert.InDelta(t, 0.167, RiffleDistance(52, 8), 1e-3)
+	// riffle counts beyond int64 binomial arguments
+	assert.InDelta(t, 0, RiffleDistance(52, MaxRiffles), 1e-15)
+	assert.Panics(t, func() { RiffleDistance(52, MaxRiffles+1) })
+	assert.Panics(t, func() { RiffleDistance(52, -1) })
+
+	k, err := RifflesNeeded(52, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, 7, k)
+	k, err = RifflesNeeded(1, 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, k)
+	_, err = RifflesNeeded(52, 1e-300)
+	assert.True(t, errors.Is(err, ErrTooManyRiffles))
+	_, err = RifflesNeeded(52, 0)
+	assert.Error(t, err)
+	_, err = RifflesNeeded(52, math.NaN())
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+//go:build rngsim
+
+package rng
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// SimulationSeedEnv is the environment variable enabling simulation mode at
+// startup, its value is used as the DRBG seed.
+const SimulationSeedEnv = "RNG_SIMULATION_SEED"
+
+var simulation atomic.Value // bool
+
+func init() {
+	simulation.Store(false)
+	if seed, ok := os.LookupEnv(SimulationSeedEnv); ok {
+		_ = EnableSimulation([]byte(seed))
+	}
+}
+
+// lockedReader serializes reads of a source not safe for concurrent use.
+type lockedReader struct {
+	mu  sync.Mutex
+	src io.Reader
+}
+
+func (r *lockedReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.src.Read(p)
+}
+
+// EnableSimulation replaces the default source with a DRBG seeded with seed,
+// so package level draws of a whole session are reproducible. Draws from
+// concurrent goroutines interleave in scheduling order, reproducible sessions
+// must draw from a single goroutine.
+//
+// Simulation mode is only available in builds with the rngsim build tag.
+func EnableSimulation(seed []byte) error {
+	SetDefaultSource(&lockedReader{src: NewDRBG(seed)})
+	simulation.Store(true)
+	return nil
+}
+
+// DisableSimulation restores crypto/rand.Reader as the default source.
+func DisableSimulation() {
+	SetDefaultSource(nil)
+	simulation.Store(false)
+}
+
+// SimulationEnabled reports whether the default source is a simulation DRBG.
+func SimulationEnabled() bool {
+	return simulation.Load().(bool)
+}
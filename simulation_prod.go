@@ -0,0 +1,29 @@
+//go:build !rngsim
+
+package rng
+
+import "errors"
+
+// SimulationSeedEnv is the environment variable enabling simulation mode at
+// startup. It is ignored in builds without the rngsim build tag.
+const SimulationSeedEnv = "RNG_SIMULATION_SEED"
+
+// ErrSimulationDisabled is returned by EnableSimulation in builds without the
+// rngsim build tag.
+var ErrSimulationDisabled = errors.New("rng: simulation mode requires rngsim build tag")
+
+// EnableSimulation replaces the default source with a seeded DRBG in builds
+// with the rngsim build tag. Production builds never enable simulation mode,
+// it returns ErrSimulationDisabled.
+func EnableSimulation(seed []byte) error {
+	return ErrSimulationDisabled
+}
+
+// DisableSimulation is a no-op in builds without the rngsim build tag.
+func DisableSimulation() {}
+
+// SimulationEnabled reports whether the default source is a simulation DRBG.
+// It is always false in builds without the rngsim build tag.
+func SimulationEnabled() bool {
+	return false
+}
@@ -0,0 +1,16 @@
+//go:build !rngsim
+
+package rng
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulationDisabled(t *testing.T) {
+	assert.Equal(t, ErrSimulationDisabled, EnableSimulation([]byte("seed")))
+	assert.False(t, SimulationEnabled())
+	assert.Equal(t, rand.Reader, DefaultSource())
+}
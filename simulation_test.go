@@ -0,0 +1,27 @@
+//go:build rngsim
+
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulation(t *testing.T) {
+	require.NoError(t, EnableSimulation([]byte("session-42")))
+	defer DisableSimulation()
+	assert.True(t, SimulationEnabled())
+
+	first := []int{Intn(100), Intn(100), Intn(100)}
+	first = append(first, Perm(5)...)
+
+	require.NoError(t, EnableSimulation([]byte("session-42")))
+	second := []int{Intn(100), Intn(100), Intn(100)}
+	second = append(second, Perm(5)...)
+	assert.Equal(t, first, second)
+
+	DisableSimulation()
+	assert.False(t, SimulationEnabled())
+}
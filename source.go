@@ -0,0 +1,47 @@
+package rng
+
+import (
+	"crypto/rand"
+	"io"
+	"sync/atomic"
+)
+
+// sourceHolder wraps the default source so readers of different concrete
+// types can be stored in atomic.Value.
+type sourceHolder struct {
+	src io.Reader
+}
+
+// defaultSrc is initialized before init functions run, so they can replace
+// the default source.
+var defaultSrc = func() *atomic.Value {
+	v := new(atomic.Value)
+	v.Store(sourceHolder{src: rand.Reader})
+	return v
+}()
+
+// SetDefaultSource replaces the random source used by package level functions
+// (Intn, Float64, Perm, ...) and returns the previous one. Passing nil restores
+// crypto/rand.Reader. It is safe to call concurrently with draws.
+//
+// Tests should prefer the Read variants or a Generator with a deterministic
+// source instead of replacing the default source.
+func SetDefaultSource(src io.Reader) (prev io.Reader) {
+	if src == nil {
+		src = rand.Reader
+	}
+	return defaultSrc.Swap(sourceHolder{src: src}).(sourceHolder).src
+}
+
+// DefaultSource returns the random source used by package level functions.
+//
+// Unless replaced it is crypto/rand.Reader on all platforms. On js/wasm it is
+// backed by crypto.getRandomValues and on wasip1 by the random_get host call,
+// so draw code shared with browser clients needs no changes.
+func DefaultSource() io.Reader {
+	return defaultSource()
+}
+
+func defaultSource() io.Reader {
+	return defaultSrc.Load().(sourceHolder).src
+}
@@ -0,0 +1,45 @@
+package rng
+
+import (
+	"crypto/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetDefaultSource(t *testing.T) {
+	assert.Equal(t, rand.Reader, DefaultSource())
+
+	src := NewDRBG([]byte("default"))
+	prev := SetDefaultSource(src)
+	assert.Equal(t, rand.Reader, prev)
+	assert.Equal(t, src, DefaultSource())
+	assert.Equal(t, New(NewDRBG([]byte("default"))).Perm(10), Perm(10))
+
+	assert.Equal(t, src, SetDefaultSource(nil))
+	assert.Equal(t, rand.Reader, DefaultSource())
+}
+
+func TestSetDefaultSourceConcurrent(t *testing.T) {
+	// run with -race, swapping source must not race with draws
+	defer SetDefaultSource(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Intn(10)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetDefaultSource(rand.Reader)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,23 @@
+//go:build js || wasip1
+
+package rng
+
+import (
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWasmDefaultSource(t *testing.T) {
+	// js/wasm default source is backed by crypto.getRandomValues which
+	// limits a single call to 65536 bytes, wasip1 uses random_get.
+	assert.Equal(t, rand.Reader, DefaultSource())
+
+	buf := make([]byte, 1<<17)
+	_, err := io.ReadFull(DefaultSource(), buf)
+	assert.NoError(t, err)
+	assert.NotEqual(t, make([]byte, len(buf)), buf)
+	assert.Len(t, Perm(1000), 1000)
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package rng
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCNGSource(t *testing.T) {
+	buf := make([]byte, 1024)
+	_, err := io.ReadFull(CNGSource, buf)
+	assert.NoError(t, err)
+	assert.NotEqual(t, make([]byte, len(buf)), buf)
+
+	n, err := CNGSource.Read(nil)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	assert.Len(t, New(CNGSource).Perm(100), 100)
+}
@@ -0,0 +1,17 @@
+package spec
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+var cfg struct {
+	long bool
+}
+
+func TestMain(m *testing.M) {
+	flag.BoolVar(&cfg.long, "long", false, "Enable checks enumerating 3 byte strings")
+	flag.Parse()
+	os.Exit(m.Run())
+}
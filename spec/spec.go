@@ -0,0 +1,289 @@
+// Package spec verifies unbiasedness of rng draw functions by exhaustive
+// enumeration, giving certifiers machine-checkable evidence instead of
+// statistical tests.
+//
+// A Case draws from a source that returns a fixed byte string. Check runs the
+// draw for every one of the 256^Bytes strings of a given length and counts
+// the outcomes of draws that complete without running out of bytes. All byte
+// strings are equally likely for an ideal source, so the draw is unbiased iff
+// every possible outcome is produced by exactly the same number of strings.
+// Draws that need more bytes, e.g. after rejection sampling retries, are
+// conditioned away: checking them with a longer string gives the same
+// verdict for the draws it completes.
+//
+// The checks run as ordinary go tests of this package and from the command
+// line with bin/spec.
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/advbet/rng"
+)
+
+// MaxBytes limits the enumerated byte string length, Check runs a draw
+// 256^MaxBytes times.
+const MaxBytes = 3
+
+// ErrBiased is returned by Check for draws whose outcomes are not equally
+// likely.
+var ErrBiased = errors.New("spec: draw is biased")
+
+// Case is a draw to verify.
+type Case struct {
+	Name string
+	// Outcomes is the number of distinct outcomes the draw can produce, all
+	// of them must be equally likely.
+	Outcomes int
+	// Bytes is the length of enumerated source byte strings, at most
+	// MaxBytes.
+	Bytes int
+	// Draw runs the draw reading randomness from src and returns its outcome
+	// encoded as a string. Draws panicking with io.EOF or
+	// io.ErrUnexpectedEOF ran out of bytes and are not counted.
+	Draw func(src io.Reader) string
+}
+
+// Report holds the outcome distribution of an exhaustively enumerated Case.
+type Report struct {
+	Name  string `json:"name"`
+	Bytes int    `json:"bytes"`
+	// Strings is the number of enumerated byte strings, 256^Bytes.
+	Strings uint64 `json:"strings"`
+	// Completed is the number of strings the draw completed with.
+	Completed uint64 `json:"completed"`
+	// Outcomes is the number of distinct outcomes seen.
+	Outcomes int `json:"outcomes"`
+	// Min and Max are the smallest and largest number of strings producing
+	// the same outcome.
+	Min uint64 `json:"min"`
+	Max uint64 `json:"max"`
+	// Counts holds the number of strings producing each outcome.
+	Counts map[string]uint64 `json:"-"`
+	// Unbiased is true if all expected outcomes were produced equally often.
+	Unbiased bool `json:"unbiased"`
+}
+
+// String returns a single line summary of the report.
+func (r *Report) String() string {
+	verdict := "ok"
+	if !r.Unbiased {
+		verdict = "BIASED"
+	}
+	return fmt.Sprintf("%s\t%s\tbytes=%d completed=%d/%d outcomes=%d count=[%d, %d]",
+		verdict, r.Name, r.Bytes, r.Completed, r.Strings, r.Outcomes, r.Min, r.Max)
+}
+
+// Check enumerates all byte strings of c.Bytes length and reports the
+// outcome distribution of c.Draw. It returns the report together with an
+// error wrapping ErrBiased if not every one of c.Outcomes outcomes was
+// produced by the same number of strings, or no string completed the draw. It
+// returns an error without a report for invalid cases.
+func Check(c Case) (*Report, error) {
+	if c.Bytes < 0 || c.Bytes > MaxBytes || c.Outcomes <= 0 || c.Draw == nil {
+		return nil, fmt.Errorf("spec: invalid case %q", c.Name)
+	}
+
+	r := &Report{
+		Name:    c.Name,
+		Bytes:   c.Bytes,
+		Strings: 1 << (8 * c.Bytes),
+		Counts:  make(map[string]uint64),
+	}
+	b := make([]byte, c.Bytes)
+	src := &exhaustReader{}
+	for i := uint64(0); i < r.Strings; i++ {
+		for j := range b {
+			b[j] = byte(i >> (8 * j))
+		}
+		src.b = b
+		if outcome, ok := run(c.Draw, src); ok {
+			r.Counts[outcome]++
+			r.Completed++
+		}
+	}
+
+	r.Outcomes = len(r.Counts)
+	for _, n := range r.Counts {
+		if r.Min == 0 || n < r.Min {
+			r.Min = n
+		}
+		if n > r.Max {
+			r.Max = n
+		}
+	}
+	r.Unbiased = r.Completed > 0 && r.Outcomes == c.Outcomes && r.Min == r.Max
+	if !r.Unbiased {
+		return r, fmt.Errorf("%w: %s", ErrBiased, r)
+	}
+	return r, nil
+}
+
+// run returns the outcome of draw, ok is false if draw ran out of bytes.
+func run(draw func(io.Reader) string, src io.Reader) (outcome string, ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			if v != io.EOF && v != io.ErrUnexpectedEOF {
+				panic(v)
+			}
+			ok = false
+		}
+	}()
+	return draw(src), true
+}
+
+// exhaustReader returns bytes of b and io.EOF once they are consumed.
+type exhaustReader struct {
+	b []byte
+}
+
+func (r *exhaustReader) Read(p []byte) (int, error) {
+	if len(r.b) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b)
+	r.b = r.b[n:]
+	return n, nil
+}
+
+// Intn returns a case verifying rng.ReadIntn(src, n).
+func Intn(n, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("Intn(%d)", n),
+		Outcomes: n,
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			return strconv.Itoa(rng.ReadIntn(src, n))
+		},
+	}
+}
+
+// Int31n returns a case verifying rng.ReadInt31n(src, n).
+func Int31n(n int32, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("Int31n(%d)", n),
+		Outcomes: int(n),
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			return strconv.Itoa(int(rng.ReadInt31n(src, n)))
+		},
+	}
+}
+
+// IntnInclusive returns a case verifying rng.ReadIntnInclusive(src, n).
+func IntnInclusive(n, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("IntnInclusive(%d)", n),
+		Outcomes: n + 1,
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			return strconv.Itoa(rng.ReadIntnInclusive(src, n))
+		},
+	}
+}
+
+// Perm returns a case verifying that rng.ReadPerm(src, n) returns all n!
+// permutations equally likely.
+func Perm(n, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("Perm(%d)", n),
+		Outcomes: int(new(big.Int).MulRange(1, int64(n)).Int64()),
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			return fmt.Sprint(rng.ReadPerm(src, n))
+		},
+	}
+}
+
+// Cycle returns a case verifying that rng.ReadCycle(src, n) returns all
+// (n-1)! cyclic permutations equally likely.
+func Cycle(n, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("Cycle(%d)", n),
+		Outcomes: int(new(big.Int).MulRange(1, int64(n-1)).Int64()),
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			return fmt.Sprint(rng.ReadCycle(src, n))
+		},
+	}
+}
+
+// Sample returns a case verifying that rng.ReadSample(src, n, k) returns all
+// n!/(n-k)! ordered samples equally likely.
+func Sample(n, k, bytes int) Case {
+	c := SampleVersion(rng.SampleVersion, n, k, bytes)
+	c.Name = fmt.Sprintf("Sample(%d, %d)", n, k)
+	return c
+}
+
+// SampleVersion returns a case verifying that
+// rng.ReadSampleVersion(src, version, n, k) returns all n!/(n-k)! ordered
+// samples equally likely.
+func SampleVersion(version string, n, k, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("Sample[%s](%d, %d)", version, n, k),
+		Outcomes: int(new(big.Int).MulRange(int64(n-k+1), int64(n)).Int64()),
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			return fmt.Sprint(rng.ReadSampleVersion(src, version, n, k))
+		},
+	}
+}
+
+// SampleSet returns a case verifying that rng.ReadSample(src, n, k) returns
+// all n choose k subsets equally likely regardless of order.
+func SampleSet(n, k, bytes int) Case {
+	return Case{
+		Name:     fmt.Sprintf("SampleSet(%d, %d)", n, k),
+		Outcomes: int(new(big.Int).Binomial(int64(n), int64(k)).Int64()),
+		Bytes:    bytes,
+		Draw: func(src io.Reader) string {
+			s := rng.ReadSample(src, n, k)
+			sort.Ints(s)
+			return fmt.Sprint(s)
+		},
+	}
+}
+
+// Standard returns the cases certifying core draw functions: Intn for every
+// single byte range and a selection of multi byte ranges, Int31n,
+// IntnInclusive, and permutations and samples of small sizes. Integer cases
+// enumerate one byte beyond the minimal draw, covering the rejection sampling
+// retry path, larger cases enumerate as much as is affordable.
+func Standard() []Case {
+	var cases []Case
+	for n := 1; n <= 256; n++ {
+		cases = append(cases, Intn(n, 2))
+	}
+	for _, n := range []int{257, 0xffff} {
+		cases = append(cases, Intn(n, 3))
+	}
+	for _, n := range []int32{3, 10, 100, 255} {
+		cases = append(cases, Int31n(n, 2))
+	}
+	for _, n := range []int{0, 2, 9, 254, 255} {
+		cases = append(cases, IntnInclusive(n, 2))
+	}
+	for n := 1; n <= 3; n++ {
+		cases = append(cases, Perm(n, 2))
+	}
+	cases = append(cases, Perm(4, 3))
+	for n := 2; n <= 4; n++ {
+		cases = append(cases, Cycle(n, 2))
+	}
+	cases = append(cases,
+		Sample(5, 3, 3),
+		Sample(6, 2, 3),
+		SampleVersion(rng.SampleV1, 4, 3, 3), // full permutation prefix
+		SampleVersion(rng.SampleV1, 6, 2, 3), // duplicate rejection
+		SampleVersion(rng.SampleV2, 5, 3, 3), // partial Fisher–Yates
+		SampleSet(7, 2, 2),
+		SampleSet(10, 3, 3),
+	)
+	return cases
+}
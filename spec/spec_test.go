@@ -0,0 +1,66 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandard(t *testing.T) {
+	for _, c := range Standard() {
+		if c.Bytes > 2 && !cfg.long {
+			continue
+		}
+		r, err := Check(c)
+		require.NoError(t, err)
+		assert.True(t, r.Unbiased, r.String())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	r, err := Check(Intn(3, 2))
+	require.NoError(t, err)
+	// first byte below 255 completes, 255 retries with the second byte
+	assert.Equal(t, uint64(1<<16), r.Strings)
+	assert.Equal(t, uint64(255*256+255), r.Completed)
+	assert.Equal(t, 3, r.Outcomes)
+	assert.Equal(t, r.Completed/3, r.Min)
+	assert.Equal(t, r.Min, r.Max)
+	assert.Len(t, r.Counts, 3)
+	assert.Equal(t, "ok\tIntn(3)\tbytes=2 completed=65535/65536 outcomes=3 count=[21845, 21845]", r.String())
+
+	// naive modulo reduction favours small values
+	modulo := Case{Name: "modulo", Outcomes: 3, Bytes: 1, Draw: func(src io.Reader) string {
+		return fmt.Sprint(rng.ReadUint64Bits(src, 8) % 3)
+	}}
+	r, err = Check(modulo)
+	assert.True(t, errors.Is(err, ErrBiased))
+	assert.False(t, r.Unbiased)
+	assert.Equal(t, uint64(85), r.Min)
+	assert.Equal(t, uint64(86), r.Max)
+
+	// missing outcome
+	r, err = Check(Case{Name: "missing", Outcomes: 4, Bytes: 1, Draw: func(src io.Reader) string {
+		return fmt.Sprint(rng.ReadIntn(src, 2))
+	}})
+	assert.True(t, errors.Is(err, ErrBiased))
+	assert.Equal(t, 2, r.Outcomes)
+
+	// too few bytes to complete any draw
+	_, err = Check(Intn(300, 1))
+	assert.True(t, errors.Is(err, ErrBiased))
+
+	_, err = Check(Intn(3, MaxBytes+1))
+	assert.Error(t, err)
+	_, err = Check(Case{Name: "nil", Outcomes: 1})
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		Check(Case{Name: "panic", Outcomes: 1, Draw: func(io.Reader) string { panic("boom") }})
+	})
+}
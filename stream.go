@@ -0,0 +1,95 @@
+package rng
+
+import (
+	"io"
+)
+
+// digits used by DigitStream, base b uses the first b characters.
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// DigitStream returns an endless reader producing independent uniformly random
+// digits in a given base encoded as ASCII characters. Bases above 10 use
+// lowercase letters as digits. It will panic if base is not in [2, 36].
+func DigitStream(base int) io.Reader {
+	return ReadDigitStream(defaultSource(), base)
+}
+
+// ReadDigitStream returns an endless reader producing uniformly random ASCII
+// digits in a given base reading randomness from a given source. Source read
+// errors are returned by Read as *SourceError values rather than causing a
+// panic.
+func ReadDigitStream(src io.Reader, base int) io.Reader {
+	if base < 2 || base > len(digits) {
+		panic("invalid argument to DigitStream")
+	}
+	raw, enc := sourceEncoding(src)
+	return &digitReader{src: &Checked{src: raw, enc: enc}, base: base}
+}
+
+type digitReader struct {
+	src  *Checked
+	base int
+}
+
+// Read fills p with digits. On a source error it returns the number of digits
+// drawn before the failure.
+func (d *digitReader) Read(p []byte) (int, error) {
+	for i := range p {
+		v, err := d.src.Intn(d.base)
+		if err != nil {
+			return i, err
+		}
+		p[i] = digits[v]
+	}
+	return len(p), nil
+}
+
+// BitWriter writes random bits to an underlying writer. Bits are packed into
+// bytes, unused low order bits of the final byte are set to zero.
+type BitWriter struct {
+	src io.Reader
+	w   io.Writer
+}
+
+// NewBitWriter returns a BitWriter writing random bits to w.
+func NewBitWriter(w io.Writer) *BitWriter {
+	return ReadBitWriter(defaultSource(), w)
+}
+
+// ReadBitWriter returns a BitWriter writing random bits read from a given
+// source to w.
+func ReadBitWriter(src io.Reader, w io.Writer) *BitWriter {
+	return &BitWriter{src: src, w: w}
+}
+
+// WriteBits writes n random bits to the underlying writer as (n + 7) / 8
+// bytes. It returns the number of bytes written. Source read errors are
+// returned rather than causing a panic.
+func (b *BitWriter) WriteBits(n int64) (int64, error) {
+	if n < 0 {
+		panic("invalid argument to WriteBits")
+	}
+
+	buf := make([]byte, 4096)
+	var written int64
+	for n > 0 {
+		chunk := buf
+		if remaining := (n + 7) / 8; remaining < int64(len(chunk)) {
+			chunk = chunk[:remaining]
+		}
+		if _, err := io.ReadFull(b.src, chunk); err != nil {
+			return written, err
+		}
+		if bits := n - int64(len(chunk)-1)*8; bits < 8 {
+			// mask unused bits of the last byte
+			chunk[len(chunk)-1] &= byte(0xff << (8 - bits))
+		}
+		c, err := b.w.Write(chunk)
+		written += int64(c)
+		if err != nil {
+			return written, err
+		}
+		n -= int64(len(chunk)) * 8
+	}
+	return written, nil
+}
@@ -0,0 +1,65 @@
+package rng
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigitStream(t *testing.T) {
+	for _, base := range []int{2, 10, 16, 36} {
+		buf := make([]byte, 1000)
+		_, err := io.ReadFull(DigitStream(base), buf)
+		assert.NoError(t, err)
+		for _, c := range buf {
+			assert.True(t, strings.IndexByte(digits[:base], c) >= 0, "%q is not a base %d digit", c, base)
+		}
+	}
+
+	assert.Panics(t, func() {
+		DigitStream(1)
+	})
+	assert.Panics(t, func() {
+		DigitStream(37)
+	})
+
+	// source errors are returned
+	errHSM := errors.New("hsm down")
+	src := io.MultiReader(bytes.NewReader([]byte{3, 5}), iotest.ErrReader(errHSM))
+	n, err := ReadDigitStream(src, 16).Read(make([]byte, 4))
+	assert.Equal(t, 2, n)
+	var srcErr *SourceError
+	assert.True(t, errors.As(err, &srcErr))
+	assert.True(t, errors.Is(err, errHSM))
+}
+
+func TestBitWriter(t *testing.T) {
+	source := bytes.Repeat([]byte{0xff}, 10000)
+	tests := []struct {
+		bits int64
+		out  []byte
+	}{
+		{0, nil},
+		{1, []byte{0x80}},
+		{8, []byte{0xff}},
+		{12, []byte{0xff, 0xf0}},
+		{8 * 5000, bytes.Repeat([]byte{0xff}, 5000)},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		n, err := ReadBitWriter(bytes.NewReader(source), &out).WriteBits(test.bits)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(test.out)), n)
+		assert.Equal(t, test.out, out.Bytes())
+	}
+
+	var out bytes.Buffer
+	_, err := ReadBitWriter(bytes.NewReader(nil), &out).WriteBits(8)
+	assert.Error(t, err)
+}
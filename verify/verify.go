@@ -0,0 +1,219 @@
+// Package verify recomputes provably fair draws and serves the results over
+// HTTP, backing player facing "verify this result" pages.
+//
+// Draws use rng.FairSource streams, so anyone knowing the revealed server
+// seed, client seed and nonce can reproduce them independently.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/advbet/rng"
+)
+
+// MaxN limits draw size parameters accepted by Verify so a public endpoint
+// can not be abused for expensive computations.
+const MaxN = 1 << 16
+
+// ErrUnknownDraw is returned by Lookup implementations for unknown draw IDs or
+// draws whose server seed is not revealed yet.
+var ErrUnknownDraw = errors.New("verify: unknown draw")
+
+// Request holds everything needed to recompute a draw.
+type Request struct {
+	ServerSeed []byte `json:"server_seed"`
+	ClientSeed string `json:"client_seed"`
+	Nonce      uint64 `json:"nonce"`
+	// Game is the draw type of a registered rng algorithm, e.g. "intn",
+	// "float64", "perm", "sample" or "shuffle", with parameters N and K as
+	// for rng functions.
+	Game string `json:"game"`
+	N    int    `json:"n,omitempty"`
+	K    int    `json:"k,omitempty"`
+	// Algorithm is the rng algorithm ID the draw was made with, e.g.
+	// "sample/v2". Draws recorded without one were made with the first
+	// version of the game, see rng.ResolveAlgorithm.
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// Result is a recomputed draw with intermediate values.
+type Result struct {
+	Request
+	// ServerSeedHash is the commitment that was published before the draw.
+	ServerSeedHash []byte `json:"server_seed_hash"`
+	// Entropy holds the fair stream bytes consumed by the draw.
+	Entropy []byte `json:"entropy"`
+	// Outcome holds drawn values. Float draws hold the raw 53-bit integer,
+	// the float itself is in Float.
+	Outcome []int64  `json:"outcome"`
+	Float   *float64 `json:"float,omitempty"`
+}
+
+// Lookup finds stored draw parameters by draw ID. Implementations must only
+// return draws whose server seed was already revealed.
+type Lookup interface {
+	LookupDraw(ctx context.Context, id string) (*Request, error)
+}
+
+// Verify recomputes a draw.
+func Verify(req Request) (*Result, error) {
+	if req.N < 0 || req.N > MaxN || req.K < 0 || req.K > MaxN {
+		return nil, fmt.Errorf("verify: draw parameters out of range [0, %d]", MaxN)
+	}
+
+	var entropy bytes.Buffer
+	src := io.TeeReader(rng.FairSource(req.ServerSeed, req.ClientSeed, req.Nonce), &entropy)
+	res := &Result{
+		Request:        req,
+		ServerSeedHash: rng.HashServerSeed(req.ServerSeed),
+	}
+
+	alg, err := rng.ResolveAlgorithm(req.Game, req.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("verify: %w", err)
+	}
+	res.Algorithm = alg.ID
+	params := map[string]int64{"n": int64(req.N), "k": int64(req.K)}
+	outcome, err := rerun(src, alg, params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Game {
+	case "float64":
+		f := float64(outcome[0]) / float64(1<<53)
+		res.Float = &f
+	case "shuffle":
+		// the algorithm records swap indexes, players verify the order
+		s := make([]int64, req.N)
+		for i := range s {
+			s[i] = int64(i)
+		}
+		for x, j := range outcome {
+			i := req.N - 1 - x
+			s[i], s[j] = s[j], s[i]
+		}
+		outcome = s
+	}
+	res.Outcome = outcome
+	res.Entropy = entropy.Bytes()
+	return res, nil
+}
+
+// rerun runs a draw algorithm converting invalid argument panics to errors.
+func rerun(src io.Reader, alg rng.Algorithm, params map[string]int64) (outcome []int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			outcome, err = nil, fmt.Errorf("verify: %v", r)
+		}
+	}()
+	return alg.Run(src, params), nil
+}
+
+// Handler is an http.Handler recomputing draws. Draws are selected either by
+// query parameter id (requires Lookup) or by parameters server_seed (hex),
+// client_seed, nonce, game, n, k and algorithm. Response is a JSON encoded
+// Result.
+type Handler struct {
+	Lookup Lookup
+}
+
+// NewHandler returns a verification handler, lookup may be nil if draws are
+// only verified by explicit parameters.
+func NewHandler(lookup Lookup) *Handler {
+	return &Handler{Lookup: lookup}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		httpError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	var req *Request
+	if id := q.Get("id"); id != "" {
+		if h.Lookup == nil {
+			httpError(w, http.StatusNotFound, "draw lookup by id is not supported")
+			return
+		}
+		var err error
+		req, err = h.Lookup.LookupDraw(r.Context(), id)
+		if errors.Is(err, ErrUnknownDraw) {
+			httpError(w, http.StatusNotFound, err.Error())
+			return
+		} else if err != nil {
+			httpError(w, http.StatusInternalServerError, "draw lookup failed")
+			return
+		}
+	} else {
+		var err error
+		if req, err = parseQuery(q); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	res, err := Verify(*req)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func parseQuery(q map[string][]string) (*Request, error) {
+	get := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	seed, err := hex.DecodeString(get("server_seed"))
+	if err != nil {
+		return nil, fmt.Errorf("verify: server_seed must be hex encoded")
+	}
+	req := &Request{
+		ServerSeed: seed,
+		ClientSeed: get("client_seed"),
+		Game:       get("game"),
+		Algorithm:  get("algorithm"),
+	}
+	if req.Nonce, err = parseUint(get("nonce"), 64); err != nil {
+		return nil, fmt.Errorf("verify: invalid nonce")
+	}
+	n, err := parseUint(get("n"), 31)
+	if err != nil {
+		return nil, fmt.Errorf("verify: invalid n")
+	}
+	k, err := parseUint(get("k"), 31)
+	if err != nil {
+		return nil, fmt.Errorf("verify: invalid k")
+	}
+	req.N, req.K = int(n), int(k)
+	return req, nil
+}
+
+// parseUint parses an optional decimal number, empty string is zero.
+func parseUint(s string, bits int) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, bits)
+}
+
+func httpError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
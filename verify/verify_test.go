@@ -0,0 +1,119 @@
+package verify
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/advbet/rng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	seed := []byte("server seed")
+	res, err := Verify(Request{ServerSeed: seed, ClientSeed: "player", Nonce: 3, Game: "sample", N: 49, K: 6, Algorithm: rng.SampleVersion})
+	require.NoError(t, err)
+
+	expected := rng.New(rng.FairSource(seed, "player", 3)).Sample(49, 6)
+	assert.Len(t, res.Outcome, 6)
+	for i, v := range expected {
+		assert.Equal(t, int64(v), res.Outcome[i])
+	}
+	assert.Equal(t, rng.HashServerSeed(seed), res.ServerSeedHash)
+	assert.NotEmpty(t, res.Entropy)
+
+	// draws recorded without algorithm use the first version
+	res, err = Verify(Request{ServerSeed: seed, ClientSeed: "player", Nonce: 3, Game: "sample", N: 49, K: 6})
+	require.NoError(t, err)
+	assert.Equal(t, rng.SampleV1, res.Algorithm)
+	legacy := rng.ReadSampleVersion(rng.FairSource(seed, "player", 3), rng.SampleV1, 49, 6)
+	for i, v := range legacy {
+		assert.Equal(t, int64(v), res.Outcome[i])
+	}
+	_, err = Verify(Request{ServerSeed: seed, Game: "sample", N: 49, K: 6, Algorithm: "perm/v1"})
+	assert.True(t, errors.Is(err, rng.ErrUnknownAlgorithm))
+	_, err = Verify(Request{ServerSeed: seed, Game: "intn", N: 6, Algorithm: "intn/v1"})
+	assert.NoError(t, err)
+	_, err = Verify(Request{ServerSeed: seed, Game: "intn", N: 6, Algorithm: "intn/v2"})
+	assert.Error(t, err)
+
+	res, err = Verify(Request{ServerSeed: seed, Game: "float64"})
+	require.NoError(t, err)
+	assert.Len(t, res.Entropy, 7)
+	assert.Equal(t, rng.New(rng.FairSource(seed, "", 0)).Float64(), *res.Float)
+
+	for _, game := range []string{"intn", "perm", "shuffle"} {
+		res, err = Verify(Request{ServerSeed: seed, Game: game, N: 10})
+		require.NoError(t, err)
+		assert.NotEmpty(t, res.Outcome)
+		assert.Equal(t, game+"/v1", res.Algorithm)
+	}
+	// shuffles report the shuffled order
+	order := []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	rng.ReadShuffle(rng.FairSource(seed, "", 0), len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	assert.Equal(t, order, res.Outcome)
+
+	_, err = Verify(Request{Game: "intn", N: 0})
+	assert.Error(t, err)
+	_, err = Verify(Request{Game: "dice"})
+	assert.Error(t, err)
+	_, err = Verify(Request{Game: "perm", N: MaxN + 1})
+	assert.Error(t, err)
+}
+
+type lookupFunc func(ctx context.Context, id string) (*Request, error)
+
+func (f lookupFunc) LookupDraw(ctx context.Context, id string) (*Request, error) {
+	return f(ctx, id)
+}
+
+func TestHandler(t *testing.T) {
+	seed := []byte{1, 2, 3}
+	h := NewHandler(lookupFunc(func(ctx context.Context, id string) (*Request, error) {
+		if id != "draw-1" {
+			return nil, ErrUnknownDraw
+		}
+		return &Request{ServerSeed: seed, ClientSeed: "c", Nonce: 1, Game: "intn", N: 37}, nil
+	}))
+
+	get := func(url string) (int, *Result) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var res Result
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		}
+		return rec.Code, &res
+	}
+
+	code, byID := get("/verify?id=draw-1")
+	assert.Equal(t, http.StatusOK, code)
+	code, byParams := get("/verify?server_seed=" + hex.EncodeToString(seed) + "&client_seed=c&nonce=1&game=intn&n=37")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, byID, byParams)
+	assert.Equal(t, int64(rng.New(rng.FairSource(seed, "c", 1)).Intn(37)), byID.Outcome[0])
+
+	code, _ = get("/verify?id=draw-2")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = get("/verify?server_seed=zz&game=intn&n=2")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("/verify?game=intn&n=-2")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("/verify?game=intn")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/verify", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	NewHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verify?id=x", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
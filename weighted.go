@@ -0,0 +1,60 @@
+package rng
+
+import (
+	"io"
+	"math"
+	"sort"
+)
+
+// WeightedPerm returns a random ranking of len(weights) items as a
+// permutation of indexes. Heavier items tend to appear earlier: the ranking
+// follows the Plackett–Luce model, each next position is filled by a
+// remaining item with probability proportional to its weight. Items with zero
+// weight are ranked last in uniformly random order. It will panic if any
+// weight is negative, NaN or infinite.
+func WeightedPerm(weights []float64) []int {
+	return ReadWeightedPerm(defaultSource(), weights)
+}
+
+// ReadWeightedPerm returns a random Plackett–Luce ranking of items with given
+// weights reading randomness from a given source.
+//
+// Ranking is obtained by drawing exponential keys -ln(U)/w for each item and
+// sorting items by ascending key. Keys are compared in log space,
+// ln(-ln(U)) - ln(w), so tiny weights do not overflow to the infinite key of
+// zero weight items.
+func ReadWeightedPerm(src io.Reader, weights []float64) []int {
+	type item struct {
+		index int
+		key   float64
+		tie   uint64
+	}
+
+	items := make([]item, len(weights))
+	for i, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 1) {
+			panic("invalid weight argument to WeightedPerm")
+		}
+		items[i].index = i
+		if w == 0 {
+			items[i].key = math.Inf(1)
+		} else {
+			items[i].key = math.Log(-math.Log(ReadFloat64Open(src))) - math.Log(w)
+		}
+		// random tie breaker, orders zero weight items uniformly
+		items[i].tie = ReadUint64Bits(src, 64)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].key != items[j].key {
+			return items[i].key < items[j].key
+		}
+		return items[i].tie < items[j].tie
+	})
+
+	perm := make([]int, len(items))
+	for i, it := range items {
+		perm[i] = it.index
+	}
+	return perm
+}
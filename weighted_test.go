@@ -0,0 +1,44 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedPerm(t *testing.T) {
+	assert.Empty(t, WeightedPerm(nil))
+
+	p := WeightedPerm([]float64{1, 0, 2, 0, 5})
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, p)
+	// zero weight items are always last
+	assert.ElementsMatch(t, []int{1, 3}, p[3:])
+
+	assert.Panics(t, func() {
+		WeightedPerm([]float64{1, -1})
+	})
+}
+
+func TestWeightedPermTinyWeights(t *testing.T) {
+	// -ln(U)/w overflows for tiny weights, they must still rank before zero
+	// weight items
+	src := NewDRBG([]byte("tiny"))
+	for i := 0; i < 100; i++ {
+		perm := ReadWeightedPerm(src, []float64{0, 5e-324, 0, 1e-308, 0})
+		assert.ElementsMatch(t, []int{1, 3}, perm[:2])
+	}
+}
+
+func TestWeightedPermFirst(t *testing.T) {
+	// probability of an item being ranked first is proportional to its
+	// weight: 1/6, 2/6, 3/6
+	g := New(NewDRBG([]byte("weighted")))
+	N := 60000
+	first := make([]int, 3)
+	for i := 0; i < N; i++ {
+		first[ReadWeightedPerm(g.Source(), []float64{1, 2, 3})[0]]++
+	}
+	for i, count := range first {
+		assert.InEpsilon(t, float64(i+1)/6, float64(count)/float64(N), 0.05)
+	}
+}
@@ -0,0 +1,37 @@
+package rng
+
+import "io"
+
+// RandomWindow returns a uniformly random contiguous sub-slice of s of a
+// given width, see ReadRandomWindow.
+func RandomWindow[T any](s []T, width int) []T {
+	return ReadRandomWindow(defaultSource(), s, width)
+}
+
+// RandomRangeIndices returns bounds of a uniformly random window of a given
+// width within [0, n), see ReadRandomRangeIndices.
+func RandomRangeIndices(n, width int) (lo, hi int) {
+	return ReadRandomRangeIndices(defaultSource(), n, width)
+}
+
+// ReadRandomWindow returns a uniformly random contiguous sub-slice of s of a
+// given width reading randomness from a given source, e.g. an hour of events
+// from a day long log selected for audit. Returned slice shares the backing
+// array of s. It will panic if width < 0 or width > len(s).
+func ReadRandomWindow[T any](src io.Reader, s []T, width int) []T {
+	lo, hi := ReadRandomRangeIndices(src, len(s), width)
+	return s[lo:hi:hi]
+}
+
+// ReadRandomRangeIndices returns bounds of a uniformly random window
+// [lo, hi) with hi - lo = width within [0, n) reading randomness from a given
+// source. Each of the n - width + 1 possible windows is equally likely, so
+// elements near the ends of the range are covered less often than those in
+// the middle. It will panic if width < 0 or width > n.
+func ReadRandomRangeIndices(src io.Reader, n, width int) (lo, hi int) {
+	if width < 0 || width > n {
+		panic("invalid argument to RandomRangeIndices")
+	}
+	lo = ReadIntnInclusive(src, n-width)
+	return lo, lo + width
+}
@@ -0,0 +1,41 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomRangeIndices(t *testing.T) {
+	src := NewDRBG([]byte("window"))
+	counts := make([]int, 8)
+	for i := 0; i < 8000; i++ {
+		lo, hi := ReadRandomRangeIndices(src, 10, 3)
+		assert.Equal(t, 3, hi-lo)
+		counts[lo]++
+	}
+	for _, c := range counts {
+		assert.InDelta(t, 1000, c, 150)
+	}
+
+	lo, hi := ReadRandomRangeIndices(src, 5, 5)
+	assert.Equal(t, [2]int{0, 5}, [2]int{lo, hi})
+	lo, hi = ReadRandomRangeIndices(src, 0, 0)
+	assert.Equal(t, [2]int{0, 0}, [2]int{lo, hi})
+
+	assert.Panics(t, func() { ReadRandomRangeIndices(src, 3, 4) })
+	assert.Panics(t, func() { ReadRandomRangeIndices(src, 3, -1) })
+}
+
+func TestRandomWindow(t *testing.T) {
+	s := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	w := ReadRandomWindow(NewDRBG([]byte("window")), s, 4)
+	assert.Len(t, w, 4)
+	assert.Equal(t, 4, cap(w))
+	for i := 1; i < len(w); i++ {
+		assert.Equal(t, w[0]+i, w[i])
+	}
+
+	w[0] = -1
+	assert.Contains(t, s, -1, "window shares memory with s")
+}
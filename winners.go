@@ -0,0 +1,200 @@
+package rng
+
+import (
+	"io"
+	"sort"
+)
+
+// Entrant is a participant of a WinnersDraw.
+type Entrant struct {
+	ID string
+	// Weight is the chance of the entrant relative to others, e.g. the
+	// number of tickets held. Entrants with zero weight never win.
+	Weight uint64
+	// MaxWins limits the number of prizes the entrant can win, 0 selects
+	// WinnersDraw.MaxWins.
+	MaxWins int
+}
+
+// WinnersDraw selects winners of k prizes among weighted entrants, e.g. for
+// a promotion campaign. Prizes are drawn in order, each prize is won by an
+// eligible entrant with probability exactly proportional to its weight.
+// Entrants are eligible unless excluded, disqualified or already holding
+// their maximum number of prizes.
+//
+// Entrants are ordered by ID before drawing, so the order they are given in
+// does not affect results and the same source stream always selects the same
+// winners.
+type WinnersDraw struct {
+	Entrants []Entrant
+	// Prizes is the number of prizes k.
+	Prizes int
+	// MaxWins is the default number of prizes an entrant can win, 0 means 1.
+	MaxWins int
+	// Exclude lists IDs of entrants that can not win, e.g. employees. IDs
+	// not among entrants are ignored.
+	Exclude []string
+	// Check, if set, is called for every drawn entrant. A non nil error
+	// disqualifies the entrant from this and all later prizes and the prize
+	// is drawn again.
+	Check func(id string) error
+}
+
+// WinnerPick is an entry of the audit trail of a WinnersDraw.
+type WinnerPick struct {
+	// Prize is the index of the prize drawn.
+	Prize int `json:"prize"`
+	// EntrantID is the drawn entrant.
+	EntrantID string `json:"entrant_id"`
+	// Total is the sum of weights of eligible entrants and Ticket the drawn
+	// value in [0, Total). Eligible entrants own consecutive ticket ranges
+	// in ID order. Both are zero for disqualifications made by Redraw, no
+	// randomness is drawn for them.
+	Total  uint64 `json:"total"`
+	Ticket uint64 `json:"ticket"`
+	// Disqualified holds the reason the entrant was disqualified, empty if
+	// the pick stands.
+	Disqualified string `json:"disqualified,omitempty"`
+}
+
+// WinnersResult holds the winners of a WinnersDraw and its audit trail.
+type WinnersResult struct {
+	// Winners holds the winning entrant ID of every prize, empty for prizes
+	// left unawarded because no eligible entrant remained.
+	Winners []string `json:"winners"`
+	// Trail lists all picks in draw order.
+	Trail []WinnerPick `json:"trail"`
+
+	entrants     []Entrant // sorted by ID, MaxWins resolved
+	wins         map[string]int
+	disqualified map[string]bool
+	check        func(id string) error
+}
+
+// Draw draws winners of all prizes, see ReadDraw.
+func (d *WinnersDraw) Draw() *WinnersResult {
+	return d.ReadDraw(defaultSource())
+}
+
+// ReadDraw draws winners of all prizes reading randomness from a given
+// source. It will panic if Prizes or MaxWins are negative, entrant IDs are
+// not unique or the sum of weights overflows uint64.
+func (d *WinnersDraw) ReadDraw(src io.Reader) *WinnersResult {
+	if d.Prizes < 0 || d.MaxWins < 0 {
+		panic("invalid argument to WinnersDraw")
+	}
+	maxWins := d.MaxWins
+	if maxWins == 0 {
+		maxWins = 1
+	}
+	excluded := make(map[string]bool, len(d.Exclude))
+	for _, id := range d.Exclude {
+		excluded[id] = true
+	}
+	r := &WinnersResult{
+		Winners:      make([]string, d.Prizes),
+		Trail:        []WinnerPick{},
+		wins:         make(map[string]int),
+		disqualified: make(map[string]bool),
+		check:        d.Check,
+	}
+	seen := make(map[string]bool, len(d.Entrants))
+	var total uint64
+	for _, e := range d.Entrants {
+		if e.MaxWins < 0 || total+e.Weight < total {
+			panic("invalid argument to WinnersDraw")
+		}
+		if seen[e.ID] {
+			panic("invalid argument to WinnersDraw, duplicate entrant " + e.ID)
+		}
+		seen[e.ID] = true
+		total += e.Weight
+		if e.MaxWins == 0 {
+			e.MaxWins = maxWins
+		}
+		if !excluded[e.ID] {
+			r.entrants = append(r.entrants, e)
+		}
+	}
+	sort.Slice(r.entrants, func(i, j int) bool { return r.entrants[i].ID < r.entrants[j].ID })
+
+	for prize := range r.Winners {
+		r.award(src, prize)
+	}
+	return r
+}
+
+// Redraw disqualifies an entrant after the draw and redraws its prizes, see
+// ReadRedraw.
+func (r *WinnersResult) Redraw(id, reason string) {
+	r.ReadRedraw(defaultSource(), id, reason)
+}
+
+// ReadRedraw disqualifies an entrant after the draw, e.g. for failing
+// identity checks, and redraws every prize it won in prize order reading
+// randomness from a given source. Redraws continue the original draw: the
+// disqualified entrant and all entrants holding their maximum number of
+// prizes are not eligible. Picks are appended to the audit trail, so drawing
+// and redrawing from one deterministic stream is reproducible.
+func (r *WinnersResult) ReadRedraw(src io.Reader, id, reason string) {
+	if reason == "" {
+		reason = "disqualified"
+	}
+	r.disqualified[id] = true
+	for prize, w := range r.Winners {
+		if w != id {
+			continue
+		}
+		r.wins[id]--
+		r.Winners[prize] = ""
+		r.Trail = append(r.Trail, WinnerPick{Prize: prize, EntrantID: id, Disqualified: reason})
+		r.award(src, prize)
+	}
+}
+
+// award draws the winner of a prize among eligible entrants, redrawing until
+// a drawn entrant passes the check.
+func (r *WinnersResult) award(src io.Reader, prize int) {
+	for {
+		var total uint64
+		for _, e := range r.entrants {
+			if r.eligible(e) {
+				total += e.Weight
+			}
+		}
+		if total == 0 {
+			return
+		}
+		ticket := readUint64n(src, total)
+		pick := WinnerPick{Prize: prize, Total: total, Ticket: ticket}
+		for _, e := range r.entrants {
+			if !r.eligible(e) {
+				continue
+			}
+			if ticket < e.Weight {
+				pick.EntrantID = e.ID
+				break
+			}
+			ticket -= e.Weight
+		}
+		if r.check != nil {
+			if err := r.check(pick.EntrantID); err != nil {
+				pick.Disqualified = err.Error()
+				if pick.Disqualified == "" {
+					pick.Disqualified = "disqualified"
+				}
+				r.disqualified[pick.EntrantID] = true
+				r.Trail = append(r.Trail, pick)
+				continue
+			}
+		}
+		r.Trail = append(r.Trail, pick)
+		r.Winners[prize] = pick.EntrantID
+		r.wins[pick.EntrantID]++
+		return
+	}
+}
+
+func (r *WinnersResult) eligible(e Entrant) bool {
+	return e.Weight > 0 && !r.disqualified[e.ID] && r.wins[e.ID] < e.MaxWins
+}
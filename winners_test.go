@@ -0,0 +1,151 @@
+package rng
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWinnersDraw(t *testing.T) {
+	d := &WinnersDraw{
+		Entrants: []Entrant{
+			{ID: "carol", Weight: 5},
+			{ID: "alice", Weight: 10, MaxWins: 2},
+			{ID: "bob", Weight: 1},
+			{ID: "dave", Weight: 0},
+			{ID: "erin", Weight: 100},
+		},
+		Prizes:  4,
+		Exclude: []string{"erin", "mallory"},
+	}
+	r := d.ReadDraw(NewDRBG([]byte("seed")))
+	require.Len(t, r.Winners, 4)
+	require.Len(t, r.Trail, 4)
+	wins := map[string]int{}
+	for i, w := range r.Winners {
+		wins[w]++
+		assert.Equal(t, i, r.Trail[i].Prize)
+		assert.Equal(t, w, r.Trail[i].EntrantID)
+		assert.True(t, r.Trail[i].Ticket < r.Trail[i].Total)
+	}
+	// erin is excluded and dave has no weight, alice wins twice at most
+	assert.Equal(t, map[string]int{"alice": 2, "bob": 1, "carol": 1}, wins)
+	assert.Equal(t, uint64(16), r.Trail[0].Total)
+
+	// entrant order does not matter
+	d.Entrants[0], d.Entrants[4] = d.Entrants[4], d.Entrants[0]
+	assert.Equal(t, r, d.ReadDraw(NewDRBG([]byte("seed"))))
+
+	// prizes without eligible entrants stay unawarded
+	d.Prizes = 6
+	r = d.ReadDraw(NewDRBG([]byte("seed")))
+	assert.Equal(t, []string{"", ""}, r.Winners[4:])
+	assert.Len(t, r.Trail, 4)
+
+	assert.Panics(t, func() { (&WinnersDraw{Prizes: -1}).Draw() })
+	assert.Panics(t, func() {
+		(&WinnersDraw{Entrants: []Entrant{{ID: "a", Weight: 1}, {ID: "a", Weight: 1}}}).Draw()
+	})
+	assert.Panics(t, func() {
+		(&WinnersDraw{Entrants: []Entrant{{ID: "a", Weight: 1 << 63}, {ID: "b", Weight: 1 << 63}}}).Draw()
+	})
+}
+
+func TestWinnersDrawDistribution(t *testing.T) {
+	d := &WinnersDraw{
+		Entrants: []Entrant{{ID: "a", Weight: 1}, {ID: "b", Weight: 2}, {ID: "c", Weight: 3}},
+		Prizes:   1,
+	}
+	counts := map[string]int{}
+	src := NewDRBG([]byte("distribution"))
+	for i := 0; i < 6000; i++ {
+		counts[d.ReadDraw(src).Winners[0]]++
+	}
+	assert.InDelta(t, 1000, counts["a"], 150)
+	assert.InDelta(t, 2000, counts["b"], 150)
+	assert.InDelta(t, 3000, counts["c"], 150)
+}
+
+func TestWinnersDrawCheck(t *testing.T) {
+	entrants := []Entrant{}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		entrants = append(entrants, Entrant{ID: id, Weight: 1})
+	}
+	errKYC := errors.New("kyc failed")
+	d := &WinnersDraw{
+		Entrants: entrants,
+		Prizes:   3,
+		Check: func(id string) error {
+			if id == "a" || id == "b" {
+				return errKYC
+			}
+			return nil
+		},
+	}
+	for i := 0; i < 20; i++ {
+		r := d.Draw()
+		assert.ElementsMatch(t, []string{"c", "d", "e"}, r.Winners)
+		disqualified := 0
+		for _, p := range r.Trail {
+			if p.Disqualified != "" {
+				assert.Equal(t, "kyc failed", p.Disqualified)
+				assert.Contains(t, []string{"a", "b"}, p.EntrantID)
+				disqualified++
+			}
+		}
+		assert.Equal(t, len(r.Trail)-3, disqualified)
+		assert.True(t, disqualified <= 2)
+	}
+
+	// checks failing with an empty message still mark disqualified picks
+	d.Check = func(id string) error {
+		if id == "a" {
+			return errors.New("")
+		}
+		return nil
+	}
+	r := d.ReadDraw(NewDRBG([]byte("empty reason")))
+	assert.NotContains(t, r.Winners, "a")
+	for _, p := range r.Trail {
+		if p.EntrantID == "a" {
+			assert.Equal(t, "disqualified", p.Disqualified)
+		}
+	}
+}
+
+func TestWinnersRedraw(t *testing.T) {
+	d := &WinnersDraw{
+		Entrants: []Entrant{{ID: "a", Weight: 1}, {ID: "b", Weight: 1}, {ID: "c", Weight: 1}, {ID: "d", Weight: 1}},
+		Prizes:   2,
+		MaxWins:  2,
+	}
+	src := NewDRBG([]byte("redraw"))
+	r := d.ReadDraw(src)
+	first := r.Winners[0]
+	held := 0
+	for _, w := range r.Winners {
+		if w == first {
+			held++
+		}
+	}
+	r.ReadRedraw(src, first, "")
+	assert.NotContains(t, r.Winners, first)
+	assert.NotContains(t, r.Winners, "")
+	require.Len(t, r.Trail, 2+2*held)
+	assert.Equal(t, WinnerPick{Prize: 0, EntrantID: first, Disqualified: "disqualified"}, r.Trail[2])
+
+	// the same stream reproduces draw and redraw
+	src = NewDRBG([]byte("redraw"))
+	r2 := d.ReadDraw(src)
+	r2.ReadRedraw(src, first, "")
+	assert.Equal(t, r.Winners, r2.Winners)
+	assert.Equal(t, r.Trail, r2.Trail)
+
+	// disqualifying everyone leaves prizes unawarded
+	for _, e := range d.Entrants {
+		r.ReadRedraw(src, e.ID, "void")
+	}
+	assert.Equal(t, []string{"", ""}, r.Winners)
+}